@@ -0,0 +1,141 @@
+package cache
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// BreakerState описывает состояние автомата защиты загрузчика
+type BreakerState int
+
+const (
+	BreakerClosed   BreakerState = iota // Загрузки выполняются
+	BreakerOpen                         // Загрузки сразу завершаются ErrCircuitOpen
+	BreakerHalfOpen                     // Выполняется одна пробная загрузка
+)
+
+// String возвращает строковое представление состояния
+func (s BreakerState) String() string {
+	switch s {
+	case BreakerClosed:
+		return "closed"
+	case BreakerOpen:
+		return "open"
+	case BreakerHalfOpen:
+		return "half-open"
+	default:
+		return "unknown"
+	}
+}
+
+// BreakerCallback вызывается при смене состояния автомата защиты.
+// Вызов происходит вне блокировки автомата.
+type BreakerCallback func(from, to BreakerState)
+
+// errLoaderPanic учитывается автоматом защиты вместо результата загрузки, завершившейся паникой
+var errLoaderPanic = errors.New("паника загрузчика")
+
+// breaker размыкается после threshold ошибок подряд и пропускает одну
+// пробную загрузку после cooldown. Успешная проба замыкает его, ошибка размыкает снова.
+type breaker struct {
+	mu        sync.Mutex
+	threshold int // 0 - автомат отключен
+	cooldown  time.Duration
+	onChange  BreakerCallback
+
+	state    BreakerState
+	failures int
+	openedAt time.Time
+}
+
+// allow решает, можно ли выполнить загрузку
+func (b *breaker) allow() bool {
+	b.mu.Lock()
+	if b.threshold <= 0 {
+		b.mu.Unlock()
+		return true
+	}
+
+	switch b.state {
+	case BreakerOpen:
+		if time.Since(b.openedAt) < b.cooldown {
+			b.mu.Unlock()
+			return false
+		}
+		notify := b.transition(BreakerHalfOpen)
+		b.mu.Unlock()
+		notify()
+		return true
+	case BreakerHalfOpen:
+		// Пока идет проба, остальные загрузки не выполняются
+		b.mu.Unlock()
+		return false
+	default:
+		b.mu.Unlock()
+		return true
+	}
+}
+
+// record учитывает результат разрешенной загрузки
+func (b *breaker) record(err error) {
+	b.mu.Lock()
+	if b.threshold <= 0 {
+		b.mu.Unlock()
+		return
+	}
+
+	notify := func() {}
+	switch {
+	case err == nil:
+		b.failures = 0
+		if b.state != BreakerClosed {
+			notify = b.transition(BreakerClosed)
+		}
+	case b.state == BreakerHalfOpen:
+		notify = b.transition(BreakerOpen)
+	default:
+		b.failures++
+		if b.failures >= b.threshold {
+			notify = b.transition(BreakerOpen)
+		}
+	}
+	b.mu.Unlock()
+	notify()
+}
+
+// transition меняет состояние и возвращает уведомление для вызова после снятия блокировки.
+// Вызывается под mu.
+func (b *breaker) transition(to BreakerState) func() {
+	from := b.state
+	b.state = to
+	if to == BreakerOpen {
+		b.openedAt = time.Now()
+	}
+	if to == BreakerClosed {
+		b.failures = 0
+	}
+
+	onChange := b.onChange
+	if onChange == nil || from == to {
+		return func() {}
+	}
+	return func() { onChange(from, to) }
+}
+
+// configure задает порог и время ожидания, сбрасывая состояние
+func (b *breaker) configure(threshold int, cooldown time.Duration) {
+	b.mu.Lock()
+	b.threshold = threshold
+	b.cooldown = cooldown
+	b.state = BreakerClosed
+	b.failures = 0
+	b.mu.Unlock()
+}
+
+// current возвращает текущее состояние
+func (b *breaker) current() BreakerState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.state
+}
@@ -21,9 +21,38 @@ type Cache interface {
 	
 	// SetWithTTL сохраняет значение с указанным временем жизни
 	SetWithTTL(key string, value []byte, ttl time.Duration) error
+
+	// GetOrSet возвращает значение по ключу, а при промахе вызывает loader
+	// и сохраняет результат с указанным TTL. Одновременные вызовы для одного
+	// ключа выполняют loader только один раз и получают общий результат.
+	// Ошибка loader не кэшируется и возвращается всем ожидающим.
+	GetOrSet(key string, loader func() ([]byte, error), ttl time.Duration) ([]byte, error)
 	
+	// GetTTL возвращает оставшееся время жизни ключа и признак его наличия.
+	// Для элементов без срока жизни возвращается NoExpiration.
+	// Вызов не считается обращением к ключу и не влияет на статистику и вытеснение.
+	GetTTL(key string) (time.Duration, bool)
+
+	// Expire устанавливает новое время жизни существующего ключа без перезаписи значения.
+	// ttl <= 0 снимает ограничение времени жизни. Возвращает false если ключ
+	// отсутствует или уже истек.
+	Expire(key string, ttl time.Duration) bool
+
+	// Persist снимает ограничение времени жизни с ключа.
+	// Возвращает false если ключ отсутствует или уже истек.
+	Persist(key string) bool
+
 	// Delete удаляет ключ из кэша
 	Delete(key string) bool
+
+	// Keys возвращает все неистекшие ключи на момент вызова.
+	// Порядок не определен, кроме LRU кэша, где ключи идут
+	// от недавно использованных к давно использованным.
+	Keys() []string
+
+	// Len возвращает текущее количество элементов. Дешевле Stats для горячего пути,
+	// но для кэшей с ленивым удалением может учитывать еще не удаленные истекшие элементы.
+	Len() int
 	
 	// Clear очищает весь кэш
 	Clear()
@@ -31,7 +60,11 @@ type Cache interface {
 	// Stats возвращает статистику кэша
 	Stats() Stats
 	
-	// Close корректно завершает работу кэша
+	// Close корректно завершает работу кэша. Закрытие окончательное: фоновые горутины
+	// остановлены, колбэки закрытия уже вызваны, поэтому повторно открыть кэш нельзя,
+	// нужно создать новый. После Close чтения промахиваются, запись возвращает
+	// ErrCacheClosed, Delete возвращает false, повторный Close ничего не делает.
+	// Stats, Len и Keys остаются доступными для итогового отчета.
 	Close() error
 }
 
@@ -41,7 +74,16 @@ type Stats struct {
 	Misses    int64   `json:"misses"`     // Промахи
 	Keys      int64   `json:"keys"`       // Количество ключей
 	Evictions int64   `json:"evictions"`  // Вытеснения
+	Bytes     int64   `json:"bytes"`      // Объем хранимых данных в байтах
+	RawBytes  int64   `json:"raw_bytes"`  // Объем данных до сжатия
 	HitRate   float64 `json:"hit_rate"`   // Процент попаданий
+
+	// Неизрасходованный бюджет стоимости WeightedCache, у остальных кэшей 0
+	RemainingCost int64 `json:"remaining_cost"`
+
+	// Промахи по истекшим, но еще не удаленным элементам, входят в Misses.
+	// Учитываются LRU, LFU, Simple и шардированным кэшем.
+	ExpiredMisses int64 `json:"expired_misses"`
 }
 
 // CalculateHitRate вычисляет процент попаданий
@@ -52,6 +94,145 @@ func (s *Stats) CalculateHitRate() {
 	}
 }
 
+// KeyStat описывает частоту обращений к ключу для поиска горячих ключей
+type KeyStat struct {
+	Key        string    `json:"key"`
+	Hits       int64     `json:"hits"`        // Попадания по ключу
+	LastAccess time.Time `json:"last_access"` // Последнее попадание или запись
+}
+
+// HealthStatus описывает работоспособность кэша для проверок готовности
+type HealthStatus struct {
+	Healthy bool `json:"healthy"` // Кэш открыт и фоновая очистка не зависла
+	Closed  bool `json:"closed"`  // Кэш закрыт через Close
+
+	// Фоновая очистка выполнялась не позже двух периодов назад.
+	// true, если очистка отключена: зависать нечему.
+	ReaperAlive bool `json:"reaper_alive"`
+
+	// Заполнение как доля от лимита количества элементов или объема, большая из двух.
+	// 0 для кэшей без ограничения.
+	Saturation float64 `json:"saturation"`
+
+	// Завершение последнего прохода фоновой очистки, нулевое до первого прохода
+	LastCleanup time.Time `json:"last_cleanup"`
+}
+
+// EvictionReason описывает причину удаления элемента из кэша
+type EvictionReason int
+
+const (
+	ReasonCapacity EvictionReason = iota // Вытеснение из-за переполнения
+	ReasonExpired                        // Истечение времени жизни
+	ReasonDeleted                        // Явное удаление
+	ReasonCleared                        // Очистка всего кэша
+)
+
+// String возвращает строковое представление причины удаления
+func (r EvictionReason) String() string {
+	switch r {
+	case ReasonCapacity:
+		return "capacity"
+	case ReasonExpired:
+		return "expired"
+	case ReasonDeleted:
+		return "deleted"
+	case ReasonCleared:
+		return "cleared"
+	default:
+		return "unknown"
+	}
+}
+
+// EvictCallback вызывается при удалении элемента из кэша.
+// Вызов происходит после снятия блокировки кэша, поэтому обработчик
+// может обращаться к тому же кэшу.
+type EvictCallback func(key string, value []byte, reason EvictionReason)
+
+// EventType описывает вид изменения в событиях подписки
+type EventType int
+
+const (
+	EventSet    EventType = iota // Запись значения
+	EventDelete                  // Явное удаление
+	EventEvict                   // Вытеснение из-за переполнения
+	EventExpire                  // Удаление истекшего элемента
+	EventClear                   // Очистка всего кэша, Key пустой
+)
+
+// String возвращает строковое представление вида события
+func (t EventType) String() string {
+	switch t {
+	case EventSet:
+		return "set"
+	case EventDelete:
+		return "delete"
+	case EventEvict:
+		return "evict"
+	case EventExpire:
+		return "expire"
+	case EventClear:
+		return "clear"
+	default:
+		return "unknown"
+	}
+}
+
+// Event - изменение кэша, доставляемое подписчикам
+type Event struct {
+	Type EventType
+	Key  string
+}
+
+// NoExpiration обозначает отсутствие срока жизни у элемента
+const NoExpiration time.Duration = -1
+
+// EntryState описывает результат поиска ключа с учетом отрицательного кэширования
+type EntryState int
+
+const (
+	StateMiss     EntryState = iota // Ключа нет в кэше
+	StateHit                        // Найдено значение
+	StateNegative                   // Закэшировано отсутствие значения
+)
+
+// String возвращает строковое представление состояния
+func (s EntryState) String() string {
+	switch s {
+	case StateMiss:
+		return "miss"
+	case StateHit:
+		return "hit"
+	case StateNegative:
+		return "negative"
+	default:
+		return "unknown"
+	}
+}
+
+// Outcome описывает результат GetDetailed: попадание или причину промаха
+type Outcome int
+
+const (
+	OutcomeHit         Outcome = iota // Найдено значение
+	OutcomeExpiredMiss                // Ключ был в кэше, но его TTL истек
+	OutcomeAbsentMiss                 // Ключа нет в кэше
+)
+
+// String возвращает строковое представление результата
+func (o Outcome) String() string {
+	switch o {
+	case OutcomeHit:
+		return "hit"
+	case OutcomeExpiredMiss:
+		return "expired_miss"
+	case OutcomeAbsentMiss:
+		return "absent_miss"
+	default:
+		return "unknown"
+	}
+}
+
 // EvictionPolicy определяет политику вытеснения элементов
 type EvictionPolicy int
 
@@ -75,10 +256,18 @@ func (e EvictionPolicy) String() string {
 	}
 }
 
-// Общие ошибки для всех реализаций кэша
+// Общие ошибки для всех реализаций кэша. Реализации могут оборачивать их
+// с подробностями через fmt.Errorf("%w: ..."), поэтому сравнивать следует через errors.Is.
 var (
-	ErrKeyEmpty      = errors.New("ключ не может быть пустым")
-	ErrValueTooLarge = errors.New("значение слишком большое")
-	ErrCacheClosed   = errors.New("кэш закрыт")
-	ErrCacheFull     = errors.New("кэш переполнен")
+	ErrKeyEmpty        = errors.New("ключ не может быть пустым")
+	ErrValueTooLarge   = errors.New("значение слишком большое")
+	ErrCacheClosed     = errors.New("кэш закрыт")
+	ErrCacheFull       = errors.New("кэш переполнен")
+	ErrNotANumber      = errors.New("значение не является целым числом")
+	ErrOverflow        = errors.New("инкремент или декремент приводит к переполнению")
+	ErrInvalidSnapshot = errors.New("некорректный формат снимка")
+	ErrNotFound        = errors.New("ключ не найден")
+	ErrKeyTooLong      = errors.New("ключ слишком длинный")
+	ErrCircuitOpen     = errors.New("загрузка отключена после серии ошибок")
+	ErrInvalidConfig   = errors.New("некорректная конфигурация")
 )
\ No newline at end of file
@@ -0,0 +1,151 @@
+package cache
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Config описывает настройки кэша в виде, пригодном для загрузки из файла конфигурации.
+// В JSON политика записывается именем, а длительности - строками вида "5m".
+type Config struct {
+	EvictionPolicy  EvictionPolicy `json:"eviction_policy"`
+	MaxSize         int            `json:"max_size"`         // Максимальное количество элементов
+	DefaultTTL      time.Duration  `json:"default_ttl"`      // TTL по умолчанию, 0 - бессрочно
+	CleanupInterval time.Duration  `json:"cleanup_interval"` // Период фоновой очистки истекших элементов
+
+	// Границы явно запрошенного TTL: меньший поднимается до MinTTL, больший
+	// опускается до MaxTTL. 0 - без границы. TTL по умолчанию не ограничивается.
+	MinTTL time.Duration `json:"min_ttl"`
+	MaxTTL time.Duration `json:"max_ttl"`
+}
+
+// configJSON - представление Config в JSON с длительностями в виде строк
+type configJSON struct {
+	EvictionPolicy  EvictionPolicy `json:"eviction_policy"`
+	MaxSize         int            `json:"max_size"`
+	DefaultTTL      jsonDuration   `json:"default_ttl"`
+	CleanupInterval jsonDuration   `json:"cleanup_interval"`
+	MinTTL          jsonDuration   `json:"min_ttl"`
+	MaxTTL          jsonDuration   `json:"max_ttl"`
+}
+
+// MarshalJSON записывает длительности строками вида "1h30m0s"
+func (c Config) MarshalJSON() ([]byte, error) {
+	return json.Marshal(configJSON{
+		EvictionPolicy:  c.EvictionPolicy,
+		MaxSize:         c.MaxSize,
+		DefaultTTL:      jsonDuration(c.DefaultTTL),
+		CleanupInterval: jsonDuration(c.CleanupInterval),
+		MinTTL:          jsonDuration(c.MinTTL),
+		MaxTTL:          jsonDuration(c.MaxTTL),
+	})
+}
+
+// UnmarshalJSON читает длительности строками для time.ParseDuration
+// или числом наносекунд. Отсутствующие поля остаются нулевыми.
+func (c *Config) UnmarshalJSON(data []byte) error {
+	var raw configJSON
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	*c = Config{
+		EvictionPolicy:  raw.EvictionPolicy,
+		MaxSize:         raw.MaxSize,
+		DefaultTTL:      time.Duration(raw.DefaultTTL),
+		CleanupInterval: time.Duration(raw.CleanupInterval),
+		MinTTL:          time.Duration(raw.MinTTL),
+		MaxTTL:          time.Duration(raw.MaxTTL),
+	}
+	return nil
+}
+
+// MarshalJSON записывает политику ее именем
+func (e EvictionPolicy) MarshalJSON() ([]byte, error) {
+	name := e.String()
+	if name == "Unknown" {
+		return nil, fmt.Errorf("неизвестная политика вытеснения %d", int(e))
+	}
+	return json.Marshal(name)
+}
+
+// UnmarshalJSON читает политику по имени без учета регистра: "LRU", "LFU" или "FIFO"
+func (e *EvictionPolicy) UnmarshalJSON(data []byte) error {
+	var name string
+	if err := json.Unmarshal(data, &name); err != nil {
+		return fmt.Errorf("политика вытеснения должна быть строкой: %w", err)
+	}
+
+	policy, err := ParsePolicy(name)
+	if err != nil {
+		return err
+	}
+	*e = policy
+	return nil
+}
+
+// ParsePolicy возвращает политику вытеснения по имени без учета регистра,
+// например "lfu" или "LRU"
+func ParsePolicy(s string) (EvictionPolicy, error) {
+	for _, policy := range []EvictionPolicy{LRU, LFU, FIFO} {
+		if strings.EqualFold(policy.String(), s) {
+			return policy, nil
+		}
+	}
+	return 0, fmt.Errorf("неизвестная политика вытеснения %q", s)
+}
+
+// Validate проверяет, что настройки имеют смысл. Ошибка оборачивает ErrInvalidConfig
+// и называет первое неверное поле.
+func (c Config) Validate() error {
+	switch {
+	case c.EvictionPolicy.String() == "Unknown":
+		return fmt.Errorf("%w: неизвестная политика вытеснения %d", ErrInvalidConfig, int(c.EvictionPolicy))
+	case c.MaxSize < 0:
+		return fmt.Errorf("%w: max_size не может быть отрицательным, получено %d", ErrInvalidConfig, c.MaxSize)
+	case c.DefaultTTL < 0:
+		return fmt.Errorf("%w: default_ttl не может быть отрицательным, получено %v", ErrInvalidConfig, c.DefaultTTL)
+	case c.CleanupInterval < 0:
+		return fmt.Errorf("%w: cleanup_interval не может быть отрицательным, получено %v", ErrInvalidConfig, c.CleanupInterval)
+	case c.MinTTL < 0:
+		return fmt.Errorf("%w: min_ttl не может быть отрицательным, получено %v", ErrInvalidConfig, c.MinTTL)
+	case c.MaxTTL < 0:
+		return fmt.Errorf("%w: max_ttl не может быть отрицательным, получено %v", ErrInvalidConfig, c.MaxTTL)
+	case c.MaxTTL > 0 && c.MinTTL > c.MaxTTL:
+		return fmt.Errorf("%w: min_ttl %v больше max_ttl %v", ErrInvalidConfig, c.MinTTL, c.MaxTTL)
+	}
+	return nil
+}
+
+// jsonDuration - time.Duration, записываемая в JSON строкой
+type jsonDuration time.Duration
+
+// MarshalJSON записывает длительность строкой
+func (d jsonDuration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+// UnmarshalJSON читает длительность из строки или числа наносекунд
+func (d *jsonDuration) UnmarshalJSON(data []byte) error {
+	var value any
+	if err := json.Unmarshal(data, &value); err != nil {
+		return err
+	}
+
+	switch value := value.(type) {
+	case float64:
+		*d = jsonDuration(value)
+		return nil
+	case string:
+		parsed, err := time.ParseDuration(value)
+		if err != nil {
+			return fmt.Errorf("некорректная длительность %q: %w", value, err)
+		}
+		*d = jsonDuration(parsed)
+		return nil
+	default:
+		return fmt.Errorf("некорректная длительность %s", data)
+	}
+}
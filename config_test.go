@@ -0,0 +1,139 @@
+package cache_test
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	cache "github.com/VsRnA/High-Performance-HTTP-Cache"
+)
+
+// TestConfigJSON проверяет запись и чтение конфигурации с именами политик и строковыми длительностями
+func TestConfigJSON(t *testing.T) {
+	config := cache.Config{
+		EvictionPolicy:  cache.LFU,
+		MaxSize:         10000,
+		DefaultTTL:      5 * time.Minute,
+		CleanupInterval: 30 * time.Second,
+		MinTTL:          time.Second,
+		MaxTTL:          time.Hour,
+	}
+
+	data, err := json.Marshal(config)
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	expected := `{"eviction_policy":"LFU","max_size":10000,"default_ttl":"5m0s","cleanup_interval":"30s","min_ttl":"1s","max_ttl":"1h0m0s"}`
+	if string(data) != expected {
+		t.Errorf("Expected %s, got %s", expected, data)
+	}
+
+	var decoded cache.Config
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	if decoded != config {
+		t.Errorf("Round trip mismatch: expected %+v, got %+v", config, decoded)
+	}
+
+	// Длительности принимаются в коротком виде и числом наносекунд
+	input := `{"eviction_policy":"FIFO","max_size":5,"default_ttl":"1h30m","cleanup_interval":1000000000}`
+	if err := json.Unmarshal([]byte(input), &decoded); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	if decoded.EvictionPolicy != cache.FIFO || decoded.DefaultTTL != 90*time.Minute || decoded.CleanupInterval != time.Second {
+		t.Errorf("Unexpected config %+v", decoded)
+	}
+}
+
+// TestConfigJSONErrors проверяет отклонение неизвестных политик и некорректных длительностей
+func TestConfigJSONErrors(t *testing.T) {
+	inputs := map[string]string{
+		"unknown policy":   `{"eviction_policy":"MRU"}`,
+		"numeric policy":   `{"eviction_policy":1}`,
+		"bad duration":     `{"default_ttl":"five minutes"}`,
+		"boolean duration": `{"cleanup_interval":true}`,
+	}
+
+	for name, input := range inputs {
+		t.Run(name, func(t *testing.T) {
+			var config cache.Config
+			if err := json.Unmarshal([]byte(input), &config); err == nil {
+				t.Errorf("Expected error for %s", input)
+			}
+		})
+	}
+
+	if _, err := json.Marshal(cache.EvictionPolicy(42)); err == nil || !strings.Contains(err.Error(), "42") {
+		t.Errorf("Expected error for unknown policy, got %v", err)
+	}
+
+	for _, policy := range []cache.EvictionPolicy{cache.LRU, cache.LFU, cache.FIFO} {
+		data, err := json.Marshal(policy)
+		if err != nil {
+			t.Fatalf("Marshal %v failed: %v", policy, err)
+		}
+		var decoded cache.EvictionPolicy
+		if err := json.Unmarshal(data, &decoded); err != nil || decoded != policy {
+			t.Errorf("Round trip of %v failed: %v, %v", policy, decoded, err)
+		}
+	}
+}
+
+// TestParsePolicy проверяет разбор имен политик без учета регистра
+func TestParsePolicy(t *testing.T) {
+	cases := map[string]cache.EvictionPolicy{
+		"lru":  cache.LRU,
+		"LFU":  cache.LFU,
+		"Fifo": cache.FIFO,
+	}
+	for input, expected := range cases {
+		policy, err := cache.ParsePolicy(input)
+		if err != nil || policy != expected {
+			t.Errorf("ParsePolicy(%q): expected %v, got %v, %v", input, expected, policy, err)
+		}
+	}
+
+	for _, input := range []string{"", "mru", "lru "} {
+		if _, err := cache.ParsePolicy(input); err == nil {
+			t.Errorf("Expected error for %q", input)
+		}
+	}
+}
+
+// TestConfigValidate проверяет отклонение каждой некорректной настройки
+func TestConfigValidate(t *testing.T) {
+	valid := cache.Config{
+		EvictionPolicy:  cache.LRU,
+		MaxSize:         100,
+		DefaultTTL:      time.Minute,
+		CleanupInterval: 10 * time.Second,
+	}
+	if err := valid.Validate(); err != nil {
+		t.Fatalf("Expected valid config, got %v", err)
+	}
+	if err := (cache.Config{}).Validate(); err != nil {
+		t.Errorf("Zero config should be valid, got %v", err)
+	}
+
+	invalid := map[string]func(c *cache.Config){
+		"unknown policy":            func(c *cache.Config) { c.EvictionPolicy = 42 },
+		"negative max size":         func(c *cache.Config) { c.MaxSize = -1 },
+		"negative default ttl":      func(c *cache.Config) { c.DefaultTTL = -time.Second },
+		"negative cleanup interval": func(c *cache.Config) { c.CleanupInterval = -time.Second },
+		"negative min ttl":          func(c *cache.Config) { c.MinTTL = -time.Second },
+		"negative max ttl":          func(c *cache.Config) { c.MaxTTL = -time.Second },
+		"min ttl above max ttl":     func(c *cache.Config) { c.MinTTL, c.MaxTTL = time.Hour, time.Minute },
+	}
+	for name, mutate := range invalid {
+		t.Run(name, func(t *testing.T) {
+			config := valid
+			mutate(&config)
+			if err := config.Validate(); !errors.Is(err, cache.ErrInvalidConfig) {
+				t.Errorf("Expected ErrInvalidConfig, got %v", err)
+			}
+		})
+	}
+}
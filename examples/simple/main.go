@@ -29,13 +29,19 @@ func testEvictionPolicies() {
 	// Создаем кэши
 	lruCache := memory.NewLRU(3)
 	lfuCache := memory.NewLFU(3)
+	fifoCache := memory.NewFIFO(3)
+	arcCache := memory.NewARC(3)
 	defer lruCache.Close()
 	defer lfuCache.Close()
+	defer fifoCache.Close()
+	defer arcCache.Close()
 	
 	// Сценарий: добавляем элементы и создаем разные паттерны доступа
 	caches := map[string]cache.Cache{
-		"LRU": lruCache,
-		"LFU": lfuCache,
+		"LRU":  lruCache,
+		"LFU":  lfuCache,
+		"FIFO": fifoCache,
+		"ARC":  arcCache,
 	}
 	
 	for name, c := range caches {
@@ -84,9 +90,13 @@ func testPerformance() {
 	fmt.Println("2. Сравнение производительности")
 	
 	cacheTypes := map[string]func() cache.Cache{
-		"Simple": func() cache.Cache { return memory.NewSimple() },
-		"LRU":    func() cache.Cache { return memory.NewLRU(10000) },
-		"LFU":    func() cache.Cache { return memory.NewLFU(10000) },
+		"Simple":  func() cache.Cache { return memory.NewSimple() },
+		"LRU":     func() cache.Cache { return memory.NewLRU(10000) },
+		"LFU":     func() cache.Cache { return memory.NewLFU(10000) },
+		"FIFO":    func() cache.Cache { return memory.NewFIFO(10000) },
+		"Random":  func() cache.Cache { return memory.NewRandom(10000) },
+		"ARC":     func() cache.Cache { return memory.NewARC(10000) },
+		"TinyLFU": func() cache.Cache { return memory.NewTinyLFU(10000) },
 	}
 	
 	operations := 10000
@@ -0,0 +1,54 @@
+package cache
+
+import (
+	"expvar"
+	"reflect"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// expvarSources хранит метрики, опубликованные под каждым префиксом.
+// expvar не позволяет снять регистрацию, поэтому повторная публикация
+// с тем же префиксом только переключает источник значений.
+var expvarSources = struct {
+	sync.Mutex
+	byPrefix map[string]*atomic.Pointer[Metrics]
+}{byPrefix: make(map[string]*atomic.Pointer[Metrics])}
+
+// PublishExpvar публикует поля MetricsSnapshot как переменные expvar с именами
+// prefix + "." + json-имя поля, например cache.hits и cache.hit_rate.
+// Значения вычисляются при каждом чтении /debug/vars. Длительности отдаются в наносекундах.
+// Повторный вызов с тем же префиксом не паникует, а переключает переменные на m.
+// Имена, уже занятые другими переменными expvar, пропускаются.
+func PublishExpvar(prefix string, m *Metrics) {
+	expvarSources.Lock()
+	defer expvarSources.Unlock()
+
+	if source, exists := expvarSources.byPrefix[prefix]; exists {
+		source.Store(m)
+		return
+	}
+
+	source := new(atomic.Pointer[Metrics])
+	source.Store(m)
+	expvarSources.byPrefix[prefix] = source
+
+	snapshotType := reflect.TypeOf(MetricsSnapshot{})
+	for i := 0; i < snapshotType.NumField(); i++ {
+		name, _, _ := strings.Cut(snapshotType.Field(i).Tag.Get("json"), ",")
+		if name == "" || name == "-" {
+			continue
+		}
+
+		fullName := prefix + "." + name
+		if expvar.Get(fullName) != nil {
+			continue
+		}
+
+		field := i
+		expvar.Publish(fullName, expvar.Func(func() any {
+			return reflect.ValueOf(source.Load().GetSnapshot()).Field(field).Interface()
+		}))
+	}
+}
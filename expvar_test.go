@@ -0,0 +1,57 @@
+package cache_test
+
+import (
+	"encoding/json"
+	"expvar"
+	"testing"
+	"time"
+
+	cache "github.com/VsRnA/High-Performance-HTTP-Cache"
+)
+
+func TestPublishExpvar(t *testing.T) {
+	m := cache.NewMetrics()
+	cache.PublishExpvar("test_cache", m)
+
+	m.RecordHit()
+	m.RecordHit()
+	m.RecordHit()
+	m.RecordMiss()
+	m.RecordGet(time.Millisecond)
+
+	// Значения читаются при обращении, а не в момент публикации
+	if got := expvar.Get("test_cache.hits").String(); got != "3" {
+		t.Errorf("Expected 3 hits, got %s", got)
+	}
+	if got := expvar.Get("test_cache.misses").String(); got != "1" {
+		t.Errorf("Expected 1 miss, got %s", got)
+	}
+
+	var hitRate float64
+	if err := json.Unmarshal([]byte(expvar.Get("test_cache.hit_rate").String()), &hitRate); err != nil || hitRate != 75 {
+		t.Errorf("Expected hit rate 75, got %v (%v)", hitRate, err)
+	}
+	if expvar.Get("test_cache.get_p99") == nil {
+		t.Error("Expected percentile variables to be published")
+	}
+
+	// Повторная публикация с тем же префиксом переключает источник без паники
+	other := cache.NewMetrics()
+	other.RecordHit()
+	cache.PublishExpvar("test_cache", other)
+	if got := expvar.Get("test_cache.hits").String(); got != "1" {
+		t.Errorf("Expected variables to follow the new metrics, got %s hits", got)
+	}
+
+	// Занятое постороннее имя пропускается
+	if expvar.Get("taken.hits") == nil {
+		expvar.NewInt("taken.hits").Set(42)
+	}
+	cache.PublishExpvar("taken", m)
+	if got := expvar.Get("taken.hits").String(); got != "42" {
+		t.Errorf("Existing variable should be left intact, got %s", got)
+	}
+	if got := expvar.Get("taken.misses").String(); got != "1" {
+		t.Errorf("Expected other fields to be published, got %s", got)
+	}
+}
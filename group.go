@@ -0,0 +1,76 @@
+package cache
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"sync"
+)
+
+// Group хранит именованные кэши приложения, чтобы закрыть их все при остановке
+// и снимать статистику из одного места. Безопасна для конкурентного использования.
+type Group struct {
+	mu     sync.RWMutex
+	caches map[string]Cache
+}
+
+// NewGroup создает пустую группу кэшей
+func NewGroup() *Group {
+	return &Group{caches: make(map[string]Cache)}
+}
+
+// Register добавляет кэш под именем name. Повторная регистрация имени - ошибка
+// программиста, как в http.ServeMux: прежний кэш остался бы незакрытым, поэтому вызов паникует.
+func (g *Group) Register(name string, c Cache) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if _, exists := g.caches[name]; exists {
+		panic(fmt.Sprintf("cache: кэш %q уже зарегистрирован в группе", name))
+	}
+	g.caches[name] = c
+}
+
+// Get возвращает кэш, зарегистрированный под именем name
+func (g *Group) Get(name string) (Cache, bool) {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+
+	c, exists := g.caches[name]
+	return c, exists
+}
+
+// CloseAll закрывает все кэши группы в порядке имен, даже если часть из них
+// вернула ошибку. Ошибки объединяются через errors.Join с именем кэша.
+// Кэши остаются зарегистрированными, повторный вызов закрывает их повторно.
+func (g *Group) CloseAll() error {
+	g.mu.RLock()
+	caches := maps.Clone(g.caches)
+	g.mu.RUnlock()
+
+	names := make([]string, 0, len(caches))
+	for name := range caches {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	var errs []error
+	for _, name := range names {
+		if err := caches[name].Close(); err != nil {
+			errs = append(errs, fmt.Errorf("кэш %q: %w", name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// AggregateStats возвращает статистику каждого кэша группы по имени
+func (g *Group) AggregateStats() map[string]Stats {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+
+	stats := make(map[string]Stats, len(g.caches))
+	for name, c := range g.caches {
+		stats[name] = c.Stats()
+	}
+	return stats
+}
@@ -0,0 +1,68 @@
+package cache_test
+
+import (
+	"errors"
+	"testing"
+
+	cache "github.com/VsRnA/High-Performance-HTTP-Cache"
+	"github.com/VsRnA/High-Performance-HTTP-Cache/memory"
+)
+
+// failingClose - кэш, Close которого возвращает ошибку
+type failingClose struct {
+	cache.Cache
+	err error
+}
+
+func (f failingClose) Close() error {
+	f.Cache.Close()
+	return f.err
+}
+
+// TestGroup проверяет регистрацию, статистику и закрытие всех кэшей группы
+func TestGroup(t *testing.T) {
+	closeErr := errors.New("disk unavailable")
+	caches := map[string]cache.Cache{
+		"users":    memory.NewLRU(10),
+		"sessions": memory.NewLFU(10),
+		"configs":  memory.NewSimple(),
+		"reports":  failingClose{Cache: memory.NewLRU(10), err: closeErr},
+	}
+
+	g := cache.NewGroup()
+	for name, c := range caches {
+		g.Register(name, c)
+	}
+
+	users, ok := g.Get("users")
+	if !ok || users != caches["users"] {
+		t.Fatal("Expected registered cache to be returned by name")
+	}
+	if _, ok := g.Get("missing"); ok {
+		t.Error("Expected no cache for unknown name")
+	}
+
+	users.Set("a", []byte("1"))
+	users.Get("a")
+	stats := g.AggregateStats()
+	if len(stats) != len(caches) || stats["users"].Hits != 1 || stats["users"].Keys != 1 {
+		t.Fatalf("Unexpected aggregate stats: %+v", stats)
+	}
+
+	err := g.CloseAll()
+	if !errors.Is(err, closeErr) {
+		t.Fatalf("Expected CloseAll to return the failing cache error, got %v", err)
+	}
+	for name, c := range caches {
+		if err := c.Set("k", []byte("v")); !errors.Is(err, cache.ErrCacheClosed) {
+			t.Errorf("Cache %s should be closed, Set returned %v", name, err)
+		}
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("Expected duplicate registration to panic")
+		}
+	}()
+	g.Register("users", memory.NewSimple())
+}
@@ -0,0 +1,78 @@
+package internal
+
+import (
+	"math"
+	"sync/atomic"
+)
+
+// defaultFalsePositiveRate - доля ложных срабатываний при некорректно заданной
+const defaultFalsePositiveRate = 0.01
+
+// CountingBloom - счетный фильтр Блума. Отвечает, что ключ точно отсутствует
+// или, возможно, присутствует, и в отличие от обычного фильтра позволяет удалять ключи.
+// Каждая позиция - 32-битный счетчик, поэтому фильтр занимает примерно
+// 4 * 1.44 * log2(1/p) байт на ключ: около 40 байт при p = 1%.
+// Add, Remove и Reset вызываются под внешней блокировкой, MayContain безопасен
+// параллельно с ними: счетчики читаются и изменяются атомарно.
+type CountingBloom struct {
+	counters []atomic.Uint32
+	hashes   uint64
+}
+
+// NewCountingBloom создает фильтр на expectedItems ключей с долей ложных
+// срабатываний falsePositiveRate при таком заполнении. Доля вне (0, 1) заменяется на 1%.
+func NewCountingBloom(expectedItems int, falsePositiveRate float64) *CountingBloom {
+	n := float64(max(expectedItems, 1))
+	if !(falsePositiveRate > 0 && falsePositiveRate < 1) {
+		falsePositiveRate = defaultFalsePositiveRate
+	}
+
+	// Оптимальные размер m = -n*ln(p)/ln(2)^2 и число хешей k = m/n*ln(2)
+	m := math.Ceil(-n * math.Log(falsePositiveRate) / (math.Ln2 * math.Ln2))
+	k := max(math.Round(m/n*math.Ln2), 1)
+
+	return &CountingBloom{
+		counters: make([]atomic.Uint32, int(m)),
+		hashes:   uint64(k),
+	}
+}
+
+// index возвращает позицию i-го хеша по схеме двойного хеширования
+func (b *CountingBloom) index(hash, i uint64) uint64 {
+	return (hash + i*(hash>>32|1)) % uint64(len(b.counters))
+}
+
+// Add добавляет ключ. Повторное добавление требует такого же числа Remove.
+func (b *CountingBloom) Add(key string) {
+	hash := Hash64(key)
+	for i := uint64(0); i < b.hashes; i++ {
+		b.counters[b.index(hash, i)].Add(1)
+	}
+}
+
+// Remove удаляет ранее добавленный ключ. Удаление не добавленного ключа
+// нарушает фильтр: другие ключи начнут определяться как отсутствующие.
+func (b *CountingBloom) Remove(key string) {
+	hash := Hash64(key)
+	for i := uint64(0); i < b.hashes; i++ {
+		b.counters[b.index(hash, i)].Add(^uint32(0))
+	}
+}
+
+// MayContain возвращает false, если ключ точно не добавлен
+func (b *CountingBloom) MayContain(key string) bool {
+	hash := Hash64(key)
+	for i := uint64(0); i < b.hashes; i++ {
+		if b.counters[b.index(hash, i)].Load() == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// Reset удаляет все ключи
+func (b *CountingBloom) Reset() {
+	for i := range b.counters {
+		b.counters[i].Store(0)
+	}
+}
@@ -0,0 +1,56 @@
+package internal
+
+import (
+	"fmt"
+	"testing"
+)
+
+// TestCountingBloom проверяет отсутствие ложноотрицательных ответов, удаление
+// и долю ложных срабатываний при расчетном заполнении
+func TestCountingBloom(t *testing.T) {
+	const items = 10000
+	b := NewCountingBloom(items, 0.01)
+
+	for i := 0; i < items; i++ {
+		b.Add(fmt.Sprintf("key:%d", i))
+	}
+	for i := 0; i < items; i++ {
+		if !b.MayContain(fmt.Sprintf("key:%d", i)) {
+			t.Fatalf("Expected added key:%d to be reported as present", i)
+		}
+	}
+
+	falsePositives := 0
+	for i := 0; i < items; i++ {
+		if b.MayContain(fmt.Sprintf("absent:%d", i)) {
+			falsePositives++
+		}
+	}
+	if rate := float64(falsePositives) / items; rate > 0.02 {
+		t.Errorf("Expected false positive rate near 1%%, got %.2f%%", rate*100)
+	}
+
+	// Удаленные ключи перестают находиться, оставшиеся находятся
+	for i := 0; i < items/2; i++ {
+		b.Remove(fmt.Sprintf("key:%d", i))
+	}
+	removedFound := 0
+	for i := 0; i < items/2; i++ {
+		if b.MayContain(fmt.Sprintf("key:%d", i)) {
+			removedFound++
+		}
+	}
+	if removedFound > items/50 {
+		t.Errorf("Expected removed keys to be absent, %d still reported", removedFound)
+	}
+	for i := items / 2; i < items; i++ {
+		if !b.MayContain(fmt.Sprintf("key:%d", i)) {
+			t.Fatalf("Expected key:%d to survive removal of other keys", i)
+		}
+	}
+
+	b.Reset()
+	if b.MayContain("key:9999") {
+		t.Error("Expected empty filter after Reset")
+	}
+}
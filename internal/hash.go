@@ -20,15 +20,27 @@ func Hash32(s string) uint32 {
 	return h.Sum32()
 }
 
+// HashFunc вычисляет 64-битный хеш ключа для шардинга и кольца консистентного хеширования.
+// Позволяет заменить FNV-1a, например, на xxhash для длинных ключей.
+type HashFunc func(string) uint64
+
 // ShardIndex возвращает индекс шарда для ключа
 // shardCount должен быть степенью 2 для эффективности
 func ShardIndex(key string, shardCount int) int {
+	return ShardIndexFunc(key, shardCount, Hash64)
+}
+
+// ShardIndexFunc возвращает индекс шарда для ключа, используя hash вместо Hash64.
+// nil hash означает Hash64. shardCount должен быть степенью 2.
+func ShardIndexFunc(key string, shardCount int, hash HashFunc) int {
 	if shardCount <= 1 {
 		return 0
 	}
+	if hash == nil {
+		hash = Hash64
+	}
 	
-	hash := Hash64(key)
-	return int(hash) & (shardCount - 1) // Быстрое вычисление остатка для степеней 2
+	return int(hash(key)) & (shardCount - 1) // Быстрое вычисление остатка для степеней 2
 }
 
 // IsPowerOfTwo проверяет является ли число степенью двойки
@@ -0,0 +1,44 @@
+package internal
+
+import (
+	"fmt"
+	"hash/maphash"
+	"testing"
+)
+
+// TestShardIndexFunc проверяет маскирование индекса и равномерность распределения
+// со сторонней хеш-функцией
+func TestShardIndexFunc(t *testing.T) {
+	seed := maphash.MakeSeed()
+	custom := func(s string) uint64 { return maphash.String(seed, s) }
+
+	for _, shardCount := range []int{1, 2, 8, 64} {
+		for i := 0; i < 1000; i++ {
+			key := fmt.Sprintf("key:%d", i)
+			index := ShardIndexFunc(key, shardCount, custom)
+			if index < 0 || index >= shardCount {
+				t.Fatalf("Index %d out of range for %d shards", index, shardCount)
+			}
+			if shardCount > 1 && index != int(custom(key)&uint64(shardCount-1)) {
+				t.Fatalf("Index %d is not the masked hash for %d shards", index, shardCount)
+			}
+		}
+	}
+
+	// nil означает Hash64, ShardIndex использует его же
+	if ShardIndexFunc("key", 16, nil) != ShardIndex("key", 16) {
+		t.Error("Expected nil hash to fall back to Hash64")
+	}
+
+	const shards, keys = 16, 160000
+	counts := make([]int, shards)
+	for i := 0; i < keys; i++ {
+		counts[ShardIndexFunc(fmt.Sprintf("user:%d", i), shards, custom)]++
+	}
+	expected := keys / shards
+	for shard, count := range counts {
+		if count < expected*9/10 || count > expected*11/10 {
+			t.Errorf("Shard %d got %d keys, expected about %d", shard, count, expected)
+		}
+	}
+}
@@ -0,0 +1,139 @@
+// Package hashring реализует консистентное хеширование для распределения ключей по узлам
+package hashring
+
+import (
+	"slices"
+	"sort"
+	"strconv"
+	"sync"
+
+	"github.com/VsRnA/High-Performance-HTTP-Cache/internal"
+)
+
+// Ring - кольцо консистентного хеширования с виртуальными узлами.
+// Каждый узел занимает replicas точек на кольце, поэтому добавление
+// или удаление узла переносит примерно 1/N ключей. Безопасно для конкурентного использования.
+type Ring struct {
+	mu       sync.RWMutex
+	replicas int
+	hashes   []uint64          // Отсортированные точки кольца
+	owners   map[uint64]string // Узел, которому принадлежит точка
+	nodes    map[string]struct{}
+	hash     internal.HashFunc
+}
+
+// NewRing создает кольцо с указанным количеством виртуальных узлов на каждый узел.
+// Больше виртуальных узлов - равномернее распределение, но дороже Add и Remove.
+func NewRing(replicas int) *Ring {
+	return NewRingWithHash(replicas, internal.Hash64)
+}
+
+// NewRingWithHash создает кольцо, размещающее узлы и ключи хеш-функцией hash.
+// nil hash означает internal.Hash64.
+func NewRingWithHash(replicas int, hash internal.HashFunc) *Ring {
+	if replicas <= 0 {
+		replicas = 100
+	}
+	if hash == nil {
+		hash = internal.Hash64
+	}
+
+	return &Ring{
+		replicas: replicas,
+		owners:   make(map[uint64]string),
+		nodes:    make(map[string]struct{}),
+		hash:     hash,
+	}
+}
+
+// Add добавляет узел в кольцо. Повторное добавление ничего не меняет.
+func (r *Ring) Add(node string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.nodes[node]; exists {
+		return
+	}
+	r.nodes[node] = struct{}{}
+
+	for i := 0; i < r.replicas; i++ {
+		hash := r.virtualHash(node, i)
+		// При редкой коллизии точка остается за узлом, добавленным раньше
+		if _, taken := r.owners[hash]; taken {
+			continue
+		}
+		r.owners[hash] = node
+		r.hashes = append(r.hashes, hash)
+	}
+	slices.Sort(r.hashes)
+}
+
+// Remove удаляет узел из кольца. Его ключи переходят к соседним узлам.
+func (r *Ring) Remove(node string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.nodes[node]; !exists {
+		return
+	}
+	delete(r.nodes, node)
+
+	hashes := r.hashes[:0]
+	for _, hash := range r.hashes {
+		if r.owners[hash] == node {
+			delete(r.owners, hash)
+			continue
+		}
+		hashes = append(hashes, hash)
+	}
+	r.hashes = hashes
+}
+
+// Get возвращает узел, отвечающий за ключ, - владельца первой точки кольца
+// по часовой стрелке от хеша ключа. Для пустого кольца возвращает пустую строку.
+func (r *Ring) Get(key string) string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if len(r.hashes) == 0 {
+		return ""
+	}
+
+	hash := r.ringHash(key)
+	i := sort.Search(len(r.hashes), func(i int) bool { return r.hashes[i] >= hash })
+	if i == len(r.hashes) {
+		i = 0
+	}
+	return r.owners[r.hashes[i]]
+}
+
+// Nodes возвращает узлы кольца в порядке сортировки
+func (r *Ring) Nodes() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	nodes := make([]string, 0, len(r.nodes))
+	for node := range r.nodes {
+		nodes = append(nodes, node)
+	}
+	slices.Sort(nodes)
+	return nodes
+}
+
+// virtualHash вычисляет положение i-го виртуального узла на кольце
+func (r *Ring) virtualHash(node string, i int) uint64 {
+	return r.ringHash(node + "#" + strconv.Itoa(i))
+}
+
+// ringHash вычисляет положение строки на кольце. У FNV-1a строки, различающиеся
+// последними символами, получают близкие хеши и скапливаются на одном участке кольца,
+// поэтому результат хеш-функции дополнительно перемешивается финализатором MurmurHash3.
+func (r *Ring) ringHash(s string) uint64 {
+	h := r.hash(s)
+	h ^= h >> 33
+	h *= 0xff51afd7ed558ccd
+	h ^= h >> 33
+	h *= 0xc4ceb9fe1a85ec53
+	h ^= h >> 33
+	return h
+}
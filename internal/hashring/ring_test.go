@@ -0,0 +1,114 @@
+package hashring
+
+import (
+	"fmt"
+	"hash/maphash"
+	"testing"
+)
+
+// TestRingDistribution проверяет равномерность распределения ключей по узлам
+func TestRingDistribution(t *testing.T) {
+	ring := NewRing(100)
+	nodes := []string{"cache-1:6379", "cache-2:6379", "cache-3:6379", "cache-4:6379"}
+	for _, node := range nodes {
+		ring.Add(node)
+	}
+
+	const keys = 100000
+	counts := make(map[string]int)
+	for i := 0; i < keys; i++ {
+		counts[ring.Get(fmt.Sprintf("user:%d", i))]++
+	}
+
+	expected := keys / len(nodes)
+	for _, node := range nodes {
+		count := counts[node]
+		t.Logf("%s: %d keys", node, count)
+		if count < expected*7/10 || count > expected*13/10 {
+			t.Errorf("Node %s got %d keys, expected about %d", node, count, expected)
+		}
+	}
+}
+
+// TestRingCustomHash проверяет распределение ключей со сторонней хеш-функцией
+func TestRingCustomHash(t *testing.T) {
+	seed := maphash.MakeSeed()
+	ring := NewRingWithHash(100, func(s string) uint64 { return maphash.String(seed, s) })
+	nodes := []string{"cache-1", "cache-2", "cache-3", "cache-4"}
+	for _, node := range nodes {
+		ring.Add(node)
+	}
+
+	const keys = 40000
+	counts := make(map[string]int)
+	for i := 0; i < keys; i++ {
+		counts[ring.Get(fmt.Sprintf("user:%d", i))]++
+	}
+
+	expected := keys / len(nodes)
+	for _, node := range nodes {
+		if count := counts[node]; count < expected*7/10 || count > expected*13/10 {
+			t.Errorf("Node %s got %d keys, expected about %d", node, count, expected)
+		}
+	}
+}
+
+// TestRingRemap проверяет, что добавление и удаление узла переносит около 1/N ключей
+func TestRingRemap(t *testing.T) {
+	ring := NewRing(100)
+	for i := 1; i <= 4; i++ {
+		ring.Add(fmt.Sprintf("cache-%d", i))
+	}
+
+	const keys = 20000
+	before := make([]string, keys)
+	for i := range before {
+		before[i] = ring.Get(fmt.Sprintf("key:%d", i))
+	}
+
+	ring.Add("cache-5")
+	moved := 0
+	for i, node := range before {
+		owner := ring.Get(fmt.Sprintf("key:%d", i))
+		if owner != node {
+			moved++
+			if owner != "cache-5" {
+				t.Fatalf("Key moved between existing nodes: %s -> %s", node, owner)
+			}
+		}
+	}
+	// Новый узел должен забрать около 1/5 ключей
+	if moved < keys/10 || moved > keys*3/10 {
+		t.Errorf("Adding a node moved %d of %d keys, expected about %d", moved, keys, keys/5)
+	}
+
+	ring.Remove("cache-5")
+	for i, node := range before {
+		if owner := ring.Get(fmt.Sprintf("key:%d", i)); owner != node {
+			t.Fatalf("Removing the added node should restore mapping, key %d: %s -> %s", i, node, owner)
+		}
+	}
+}
+
+// TestRingEdgeCases проверяет пустое кольцо и повторные операции
+func TestRingEdgeCases(t *testing.T) {
+	ring := NewRing(10)
+	if node := ring.Get("key"); node != "" {
+		t.Fatalf("Empty ring should return empty node, got %q", node)
+	}
+
+	ring.Add("a")
+	ring.Add("a")
+	if node := ring.Get("key"); node != "a" {
+		t.Fatalf("Single node ring should route everything to it, got %q", node)
+	}
+	if nodes := ring.Nodes(); len(nodes) != 1 {
+		t.Fatalf("Duplicate Add should not create a second node, got %v", nodes)
+	}
+
+	ring.Remove("missing")
+	ring.Remove("a")
+	if node := ring.Get("key"); node != "" {
+		t.Fatalf("Ring without nodes should return empty node, got %q", node)
+	}
+}
@@ -0,0 +1,89 @@
+package internal
+
+import (
+	"math"
+	"math/bits"
+	"sync/atomic"
+	"time"
+)
+
+// Параметры корзин гистограммы. Каждая степень двойки делится на histogramSub
+// равных корзин, поэтому относительная погрешность перцентиля не превышает 1/16.
+const (
+	histogramSubBits = 4
+	histogramSub     = 1 << histogramSubBits
+	histogramMaxExp  = 40 // Длительности от 2^40 нс (около 18 минут) попадают в последнюю корзину
+	histogramBuckets = (histogramMaxExp - histogramSubBits + 1) * histogramSub
+)
+
+// Histogram - гистограмма длительностей с логарифмическими корзинами в стиле HDR.
+// Запись не использует блокировок: каждая корзина - атомарный счетчик.
+// Нулевое значение готово к использованию.
+type Histogram struct {
+	counts [histogramBuckets]int64
+}
+
+// Record добавляет длительность в гистограмму
+func (h *Histogram) Record(d time.Duration) {
+	atomic.AddInt64(&h.counts[bucketIndex(int64(d))], 1)
+}
+
+// Quantile возвращает верхнюю границу корзины, в которую попадает перцентиль q от 0 до 1.
+// Для пустой гистограммы возвращает 0.
+func (h *Histogram) Quantile(q float64) time.Duration {
+	var counts [histogramBuckets]int64
+	var total int64
+	for i := range counts {
+		counts[i] = atomic.LoadInt64(&h.counts[i])
+		total += counts[i]
+	}
+	if total == 0 {
+		return 0
+	}
+
+	rank := int64(math.Ceil(q * float64(total)))
+	rank = min(max(rank, 1), total)
+
+	var seen int64
+	for i, count := range counts {
+		seen += count
+		if seen >= rank {
+			return time.Duration(bucketUpperBound(i))
+		}
+	}
+	return time.Duration(bucketUpperBound(histogramBuckets - 1))
+}
+
+// Reset обнуляет все корзины
+func (h *Histogram) Reset() {
+	for i := range h.counts {
+		atomic.StoreInt64(&h.counts[i], 0)
+	}
+}
+
+// bucketIndex возвращает номер корзины для длительности в наносекундах.
+// Значения меньше histogramSub хранятся точно, остальные - по старшим
+// histogramSubBits битам после ведущей единицы.
+func bucketIndex(ns int64) int {
+	if ns < histogramSub {
+		return int(max(ns, 0))
+	}
+
+	exp := bits.Len64(uint64(ns)) - 1
+	if exp >= histogramMaxExp {
+		return histogramBuckets - 1
+	}
+	mantissa := int(ns>>(exp-histogramSubBits)) - histogramSub
+	return (exp-histogramSubBits+1)*histogramSub + mantissa
+}
+
+// bucketUpperBound возвращает наибольшую длительность в наносекундах, попадающую в корзину
+func bucketUpperBound(i int) int64 {
+	if i < histogramSub {
+		return int64(i)
+	}
+
+	group, mantissa := i/histogramSub, i%histogramSub
+	shift := group - 1
+	return int64(histogramSub+mantissa+1)<<shift - 1
+}
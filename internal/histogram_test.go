@@ -0,0 +1,123 @@
+package internal
+
+import (
+	"testing"
+	"time"
+)
+
+// withinPrecision проверяет, что значение отличается от ожидаемого не больше чем на точность корзины
+func withinPrecision(got, want time.Duration) bool {
+	diff := got - want
+	if diff < 0 {
+		diff = -diff
+	}
+	return diff <= want/histogramSub
+}
+
+// TestHistogramQuantile проверяет перцентили на известном распределении
+func TestHistogramQuantile(t *testing.T) {
+	var h Histogram
+
+	// Равномерное распределение от 1 до 1000 микросекунд
+	for i := 1; i <= 1000; i++ {
+		h.Record(time.Duration(i) * time.Microsecond)
+	}
+
+	tests := []struct {
+		q    float64
+		want time.Duration
+	}{
+		{0.50, 500 * time.Microsecond},
+		{0.95, 950 * time.Microsecond},
+		{0.99, 990 * time.Microsecond},
+		{1.00, 1000 * time.Microsecond},
+	}
+	for _, tt := range tests {
+		if got := h.Quantile(tt.q); !withinPrecision(got, tt.want) {
+			t.Errorf("Quantile(%v) = %v, want about %v", tt.q, got, tt.want)
+		}
+	}
+}
+
+// TestHistogramSkewed проверяет, что редкие медленные операции видны в p99, но не в p50
+func TestHistogramSkewed(t *testing.T) {
+	var h Histogram
+
+	for i := 0; i < 980; i++ {
+		h.Record(100 * time.Microsecond)
+	}
+	for i := 0; i < 20; i++ {
+		h.Record(50 * time.Millisecond)
+	}
+
+	if got := h.Quantile(0.50); !withinPrecision(got, 100*time.Microsecond) {
+		t.Errorf("p50 = %v, want about 100µs", got)
+	}
+	if got := h.Quantile(0.99); !withinPrecision(got, 50*time.Millisecond) {
+		t.Errorf("p99 = %v, want about 50ms", got)
+	}
+}
+
+// TestHistogramEdgeCases проверяет пустую гистограмму, малые и огромные значения
+func TestHistogramEdgeCases(t *testing.T) {
+	var h Histogram
+
+	if got := h.Quantile(0.99); got != 0 {
+		t.Errorf("Empty histogram p99 = %v, want 0", got)
+	}
+
+	h.Record(5)
+	if got := h.Quantile(0.5); got != 5 {
+		t.Errorf("Small values should be exact, got %v", got)
+	}
+
+	h.Reset()
+	h.Record(-time.Second)
+	if got := h.Quantile(0.5); got != 0 {
+		t.Errorf("Negative duration should land in zero bucket, got %v", got)
+	}
+
+	h.Reset()
+	h.Record(time.Hour)
+	if got := h.Quantile(0.5); got <= 0 {
+		t.Errorf("Huge duration should land in last bucket, got %v", got)
+	}
+}
+
+// TestBucketBounds проверяет, что каждое значение не превышает верхнюю границу своей корзины
+func TestBucketBounds(t *testing.T) {
+	for ns := int64(0); ns < 1<<20; ns += 37 {
+		i := bucketIndex(ns)
+		if upper := bucketUpperBound(i); ns > upper {
+			t.Fatalf("Value %d exceeds upper bound %d of bucket %d", ns, upper, i)
+		}
+		if i > 0 && ns <= bucketUpperBound(i-1) {
+			t.Fatalf("Value %d fits previous bucket %d", ns, i-1)
+		}
+	}
+}
+
+// TestMetricsPercentiles проверяет, что снимок метрик содержит перцентили по операциям
+func TestMetricsPercentiles(t *testing.T) {
+	m := NewMetrics()
+	for i := 1; i <= 100; i++ {
+		m.RecordGet(time.Duration(i) * time.Millisecond)
+		m.RecordSet(2 * time.Millisecond)
+	}
+
+	snap := m.GetSnapshot()
+	if !withinPrecision(snap.GetP99, 99*time.Millisecond) {
+		t.Errorf("GetP99 = %v, want about 99ms", snap.GetP99)
+	}
+	if !withinPrecision(snap.SetP50, 2*time.Millisecond) {
+		t.Errorf("SetP50 = %v, want about 2ms", snap.SetP50)
+	}
+	if snap.DeleteP99 != 0 {
+		t.Errorf("DeleteP99 = %v, want 0 without deletes", snap.DeleteP99)
+	}
+
+	m.Reset()
+	if snap := m.GetSnapshot(); snap.GetP99 != 0 {
+		t.Errorf("GetP99 after Reset = %v, want 0", snap.GetP99)
+	}
+}
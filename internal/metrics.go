@@ -18,6 +18,11 @@ type Metrics struct {
 	totalSetTime    int64 // В наносекундах
 	totalGetTime    int64 // В наносекундах
 	totalDeleteTime int64 // В наносекундах
+
+	// Распределения времени выполнения для перцентилей
+	setLatency    Histogram
+	getLatency    Histogram
+	deleteLatency Histogram
 	
 	// Размеры
 	keyCount    int64
@@ -48,17 +53,20 @@ func (m *Metrics) RecordMiss() {
 func (m *Metrics) RecordSet(duration time.Duration) {
 	atomic.AddInt64(&m.sets, 1)
 	atomic.AddInt64(&m.totalSetTime, int64(duration))
+	m.setLatency.Record(duration)
 }
 
 // RecordGet записывает операцию чтения с временем выполнения
 func (m *Metrics) RecordGet(duration time.Duration) {
 	atomic.AddInt64(&m.totalGetTime, int64(duration))
+	m.getLatency.Record(duration)
 }
 
 // RecordDelete записывает операцию удаления
 func (m *Metrics) RecordDelete(duration time.Duration) {
 	atomic.AddInt64(&m.deletes, 1)
 	atomic.AddInt64(&m.totalDeleteTime, int64(duration))
+	m.deleteLatency.Record(duration)
 }
 
 // RecordEviction записывает вытеснение элемента
@@ -92,6 +100,17 @@ type Snapshot struct {
 	AvgSetTime    time.Duration `json:"avg_set_time"`
 	AvgGetTime    time.Duration `json:"avg_get_time"`
 	AvgDeleteTime time.Duration `json:"avg_delete_time"`
+
+	// Перцентили времени выполнения с погрешностью до 1/16
+	GetP50    time.Duration `json:"get_p50"`
+	GetP95    time.Duration `json:"get_p95"`
+	GetP99    time.Duration `json:"get_p99"`
+	SetP50    time.Duration `json:"set_p50"`
+	SetP95    time.Duration `json:"set_p95"`
+	SetP99    time.Duration `json:"set_p99"`
+	DeleteP50 time.Duration `json:"delete_p50"`
+	DeleteP95 time.Duration `json:"delete_p95"`
+	DeleteP99 time.Duration `json:"delete_p99"`
 	
 	// Операции в секунду
 	SetsPerSec    float64 `json:"sets_per_sec"`
@@ -125,6 +144,16 @@ func (m *Metrics) GetSnapshot() Snapshot {
 		KeyCount:  keyCount,
 		Memory:    memory,
 		Uptime:    uptime,
+
+		GetP50:    m.getLatency.Quantile(0.50),
+		GetP95:    m.getLatency.Quantile(0.95),
+		GetP99:    m.getLatency.Quantile(0.99),
+		SetP50:    m.setLatency.Quantile(0.50),
+		SetP95:    m.setLatency.Quantile(0.95),
+		SetP99:    m.setLatency.Quantile(0.99),
+		DeleteP50: m.deleteLatency.Quantile(0.50),
+		DeleteP95: m.deleteLatency.Quantile(0.95),
+		DeleteP99: m.deleteLatency.Quantile(0.99),
 	}
 	
 	// Вычисляем hit rate
@@ -169,6 +198,9 @@ func (m *Metrics) Reset() {
 	atomic.StoreInt64(&m.totalDeleteTime, 0)
 	atomic.StoreInt64(&m.keyCount, 0)
 	atomic.StoreInt64(&m.memoryUsage, 0)
+	m.setLatency.Reset()
+	m.getLatency.Reset()
+	m.deleteLatency.Reset()
 	m.startTime = time.Now()
 }
 
@@ -0,0 +1,113 @@
+package internal
+
+import (
+	"context"
+	"fmt"
+	"runtime/debug"
+	"sync"
+)
+
+// panicError - паника в функции загрузки. Сохраняется вместо ошибки, чтобы
+// ожидающие не приняли прерванную загрузку за успешную, и повторяется в них.
+type panicError struct {
+	value any
+	stack []byte // Стек горутины, в которой произошла паника
+}
+
+func (p *panicError) Error() string {
+	return fmt.Sprintf("%v\n\n%s", p.value, p.stack)
+}
+
+// call описывает выполняющуюся или завершенную загрузку значения
+type call struct {
+	done chan struct{} // Закрывается после завершения загрузки
+	val  []byte
+	err  error
+}
+
+// Group дедуплицирует одновременные загрузки по одному ключу.
+// Пока загрузка ключа выполняется, остальные вызывающие ждут и получают тот же результат.
+// Загрузки разных ключей не блокируют друг друга.
+type Group struct {
+	mu    sync.Mutex
+	calls map[string]*call
+}
+
+// Do выполняет fn для ключа, если загрузка этого ключа еще не идет,
+// иначе дожидается уже запущенной загрузки и возвращает ее результат.
+// Возвращаемый срез общий для всех ожидающих и не должен изменяться.
+// Паника в fn повторяется у всех вызывающих, ожидающих эту загрузку.
+func (g *Group) Do(key string, fn func() ([]byte, error)) ([]byte, error) {
+	c, leader := g.start(key)
+	if leader {
+		g.run(key, c, fn)
+	}
+
+	<-c.done
+	return c.result()
+}
+
+// DoContext работает как Do, но прекращает ожидание при отмене ctx и возвращает ctx.Err().
+// Загрузка при этом не прерывается: она завершается в фоне, и ее результат
+// получают остальные ожидающие. Паника в fn не завершает процесс из фоновой горутины,
+// а повторяется у вызывающих, которые дождались загрузки.
+func (g *Group) DoContext(ctx context.Context, key string, fn func() ([]byte, error)) ([]byte, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	c, leader := g.start(key)
+	if leader {
+		go g.run(key, c, fn)
+	}
+
+	select {
+	case <-c.done:
+		return c.result()
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// start регистрирует загрузку ключа или возвращает уже идущую.
+// leader равен true, если загрузку должен выполнить вызывающий.
+func (g *Group) start(key string) (c *call, leader bool) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if g.calls == nil {
+		g.calls = make(map[string]*call)
+	}
+	if c, ok := g.calls[key]; ok {
+		return c, false
+	}
+
+	c = &call{done: make(chan struct{})}
+	g.calls[key] = c
+	return c, true
+}
+
+// result возвращает результат завершенной загрузки или повторяет панику fn
+func (c *call) result() ([]byte, error) {
+	if p, ok := c.err.(*panicError); ok {
+		panic(p)
+	}
+	return c.val, c.err
+}
+
+// run выполняет загрузку и освобождает ожидающих
+func (g *Group) run(key string, c *call, fn func() ([]byte, error)) {
+	// defer гарантирует освобождение ожидающих даже при панике в fn,
+	// а паника сохраняется в c.err и повторяется в result
+	defer func() {
+		if r := recover(); r != nil {
+			c.val, c.err = nil, &panicError{value: r, stack: debug.Stack()}
+		}
+		g.mu.Lock()
+		delete(g.calls, key)
+		g.mu.Unlock()
+		close(c.done)
+	}()
+
+	c.val, c.err = fn()
+}
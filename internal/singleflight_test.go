@@ -0,0 +1,105 @@
+package internal
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+)
+
+// recoverDo вызывает do и возвращает значение паники, nil - если паники не было
+func recoverDo(do func() ([]byte, error)) (recovered any) {
+	defer func() {
+		recovered = recover()
+	}()
+	do()
+	return nil
+}
+
+// TestGroupPanic проверяет, что паника в загрузке повторяется у ведущего и у ожидающих,
+// а не возвращается им как успешная пустая загрузка
+func TestGroupPanic(t *testing.T) {
+	var g Group
+	started := make(chan struct{})
+
+	var waiter any
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		<-started
+		waiter = recoverDo(func() ([]byte, error) {
+			return g.Do("key", func() ([]byte, error) { return []byte("unused"), nil })
+		})
+	}()
+
+	leader := recoverDo(func() ([]byte, error) {
+		return g.Do("key", func() ([]byte, error) {
+			close(started)
+			// Даем ожидающему присоединиться к загрузке
+			time.Sleep(50 * time.Millisecond)
+			panic("loader failed")
+		})
+	})
+	wg.Wait()
+
+	for name, recovered := range map[string]any{"leader": leader, "waiter": waiter} {
+		p, ok := recovered.(*panicError)
+		if !ok || p.value != "loader failed" {
+			t.Errorf("Expected %s to re-panic with loader panic, got %v", name, recovered)
+		}
+	}
+
+	// После паники ключ освобождается и следующая загрузка выполняется заново
+	if value, err := g.Do("key", func() ([]byte, error) { return []byte("ok"), nil }); err != nil || string(value) != "ok" {
+		t.Errorf("Expected fresh load after panic, got %q, %v", value, err)
+	}
+}
+
+// TestGroupDoContextPanic проверяет, что паника в фоновой загрузке DoContext
+// не завершает процесс, а повторяется у дождавшегося вызывающего
+func TestGroupDoContextPanic(t *testing.T) {
+	var g Group
+
+	recovered := recoverDo(func() ([]byte, error) {
+		return g.DoContext(context.Background(), "key", func() ([]byte, error) {
+			panic("loader failed")
+		})
+	})
+	if p, ok := recovered.(*panicError); !ok || p.value != "loader failed" {
+		t.Fatalf("Expected DoContext to re-panic with loader panic, got %v", recovered)
+	}
+
+	// Вызывающий, прекративший ожидание, паники не получает
+	ctx, cancel := context.WithCancel(context.Background())
+	release := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_, err := g.DoContext(ctx, "other", func() ([]byte, error) {
+			<-release
+			panic("loader failed")
+		})
+		if err != context.Canceled {
+			t.Errorf("Expected context.Canceled, got %v", err)
+		}
+	}()
+	cancel()
+	<-done
+	close(release)
+
+	// Фоновая загрузка завершается и освобождает ключ
+	deadline := time.Now().Add(time.Second)
+	for {
+		g.mu.Lock()
+		_, running := g.calls["other"]
+		g.mu.Unlock()
+		if !running {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("Expected key to be released after background panic")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
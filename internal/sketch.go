@@ -0,0 +1,97 @@
+package internal
+
+// sketchDepth - количество строк (независимых хешей) в count-min sketch
+const sketchDepth = 4
+
+// sketchMaxCount - предел 4-битного счетчика
+const sketchMaxCount = 15
+
+// sketchSeeds задают независимые хеш функции для строк
+var sketchSeeds = [sketchDepth]uint64{
+	0xc3a5c85c97cb3127,
+	0xb492b66fbe98f273,
+	0x9ae16a3b2f90404f,
+	0xcbf29ce484222325,
+}
+
+// CountMinSketch приблизительно оценивает частоту обращений к ключам.
+// Счетчики насыщаются на 15 и периодически делятся пополам (старение),
+// чтобы давние обращения переставали влиять на оценку.
+// Не потокобезопасен, синхронизация остается на вызывающей стороне.
+type CountMinSketch struct {
+	rows          [sketchDepth][]uint8
+	mask          uint64
+	additions     int
+	resetInterval int
+}
+
+// NewCountMinSketch создает sketch шириной width (округляется до степени двойки).
+// Каждые resetInterval инкрементов все счетчики делятся пополам.
+func NewCountMinSketch(width int, resetInterval int) *CountMinSketch {
+	width = NextPowerOfTwo(width)
+	if resetInterval <= 0 {
+		resetInterval = 10 * width
+	}
+
+	s := &CountMinSketch{
+		mask:          uint64(width - 1),
+		resetInterval: resetInterval,
+	}
+	for i := range s.rows {
+		s.rows[i] = make([]uint8, width)
+	}
+	return s
+}
+
+// index вычисляет позицию счетчика ключа в строке row
+func (s *CountMinSketch) index(hash uint64, row int) uint64 {
+	h := (hash ^ sketchSeeds[row]) * 0x9e3779b97f4a7c15
+	h ^= h >> 32
+	return h & s.mask
+}
+
+// Increment учитывает обращение к ключу
+func (s *CountMinSketch) Increment(key string) {
+	hash := Hash64(key)
+	for row := range s.rows {
+		idx := s.index(hash, row)
+		if s.rows[row][idx] < sketchMaxCount {
+			s.rows[row][idx]++
+		}
+	}
+
+	s.additions++
+	if s.additions >= s.resetInterval {
+		s.Reset()
+	}
+}
+
+// Estimate возвращает оценку частоты обращений к ключу сверху
+func (s *CountMinSketch) Estimate(key string) uint8 {
+	hash := Hash64(key)
+	estimate := uint8(sketchMaxCount)
+	for row := range s.rows {
+		if count := s.rows[row][s.index(hash, row)]; count < estimate {
+			estimate = count
+		}
+	}
+	return estimate
+}
+
+// Reset выполняет шаг старения: делит все счетчики пополам
+func (s *CountMinSketch) Reset() {
+	for row := range s.rows {
+		for i := range s.rows[row] {
+			s.rows[row][i] >>= 1
+		}
+	}
+	s.additions /= 2
+}
+
+// Clear обнуляет все счетчики
+func (s *CountMinSketch) Clear() {
+	for row := range s.rows {
+		clear(s.rows[row])
+	}
+	s.additions = 0
+}
@@ -0,0 +1,155 @@
+package cache
+
+import (
+	"fmt"
+	"time"
+)
+
+// KeyPolicy задает проверку и нормализацию ключей на входе в кэш
+type KeyPolicy struct {
+	// MaxLength ограничивает длину ключа в байтах после нормализации, 0 - без ограничения
+	MaxLength int
+
+	// Validate проверяет нормализованный ключ. Ошибка возвращается вызывающему без изменений.
+	Validate func(key string) error
+
+	// Normalize приводит ключ к каноническому виду, например к нижнему регистру.
+	// Должна быть идемпотентной: ключи из Keys уже нормализованы и могут передаваться обратно.
+	Normalize func(key string) string
+}
+
+// Keyed оборачивает кэш и применяет KeyPolicy ко всем операциям с ключами,
+// поэтому Set и Get с разным написанием одного ключа обращаются к одному элементу.
+// Нижележащий кэш не меняется и хранит уже нормализованные ключи.
+type Keyed struct {
+	cache  Cache
+	policy KeyPolicy
+}
+
+// NewKeyed создает обертку над c, проверяющую и нормализующую ключи
+func NewKeyed(c Cache, policy KeyPolicy) *Keyed {
+	return &Keyed{cache: c, policy: policy}
+}
+
+// key нормализует и проверяет ключ
+func (k *Keyed) key(key string) (string, error) {
+	if k.policy.Normalize != nil {
+		key = k.policy.Normalize(key)
+	}
+	if key == "" {
+		return "", ErrKeyEmpty
+	}
+	if k.policy.MaxLength > 0 && len(key) > k.policy.MaxLength {
+		return "", fmt.Errorf("%w: %d байт при ограничении %d", ErrKeyTooLong, len(key), k.policy.MaxLength)
+	}
+	if k.policy.Validate != nil {
+		if err := k.policy.Validate(key); err != nil {
+			return "", err
+		}
+	}
+	return key, nil
+}
+
+// Get получает значение по ключу. Недопустимый ключ считается промахом
+// и не доходит до нижележащего кэша.
+func (k *Keyed) Get(key string) ([]byte, bool) {
+	key, err := k.key(key)
+	if err != nil {
+		return nil, false
+	}
+	return k.cache.Get(key)
+}
+
+// Set сохраняет значение с TTL по умолчанию или возвращает ошибку проверки ключа
+func (k *Keyed) Set(key string, value []byte) error {
+	key, err := k.key(key)
+	if err != nil {
+		return err
+	}
+	return k.cache.Set(key, value)
+}
+
+// SetWithTTL сохраняет значение с указанным TTL или возвращает ошибку проверки ключа
+func (k *Keyed) SetWithTTL(key string, value []byte, ttl time.Duration) error {
+	key, err := k.key(key)
+	if err != nil {
+		return err
+	}
+	return k.cache.SetWithTTL(key, value, ttl)
+}
+
+// GetOrSet возвращает значение или загружает его через loader.
+// При недопустимом ключе loader не вызывается.
+func (k *Keyed) GetOrSet(key string, loader func() ([]byte, error), ttl time.Duration) ([]byte, error) {
+	key, err := k.key(key)
+	if err != nil {
+		return nil, err
+	}
+	return k.cache.GetOrSet(key, loader, ttl)
+}
+
+// GetTTL возвращает оставшееся время жизни ключа
+func (k *Keyed) GetTTL(key string) (time.Duration, bool) {
+	key, err := k.key(key)
+	if err != nil {
+		return 0, false
+	}
+	return k.cache.GetTTL(key)
+}
+
+// Expire устанавливает новое время жизни ключа
+func (k *Keyed) Expire(key string, ttl time.Duration) bool {
+	key, err := k.key(key)
+	if err != nil {
+		return false
+	}
+	return k.cache.Expire(key, ttl)
+}
+
+// Persist снимает ограничение времени жизни с ключа
+func (k *Keyed) Persist(key string) bool {
+	key, err := k.key(key)
+	if err != nil {
+		return false
+	}
+	return k.cache.Persist(key)
+}
+
+// Delete удаляет ключ из кэша
+func (k *Keyed) Delete(key string) bool {
+	key, err := k.key(key)
+	if err != nil {
+		return false
+	}
+	return k.cache.Delete(key)
+}
+
+// Keys возвращает нормализованные ключи нижележащего кэша
+func (k *Keyed) Keys() []string {
+	return k.cache.Keys()
+}
+
+// Len возвращает количество элементов нижележащего кэша
+func (k *Keyed) Len() int {
+	return k.cache.Len()
+}
+
+// Clear очищает нижележащий кэш
+func (k *Keyed) Clear() {
+	k.cache.Clear()
+}
+
+// Stats возвращает статистику нижележащего кэша
+func (k *Keyed) Stats() Stats {
+	return k.cache.Stats()
+}
+
+// Close закрывает нижележащий кэш
+func (k *Keyed) Close() error {
+	return k.cache.Close()
+}
+
+// Cache возвращает нижележащий кэш
+func (k *Keyed) Cache() Cache {
+	return k.cache
+}
@@ -0,0 +1,105 @@
+package cache_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"unicode"
+
+	cache "github.com/VsRnA/High-Performance-HTTP-Cache"
+	"github.com/VsRnA/High-Performance-HTTP-Cache/memory"
+)
+
+// TestKeyedValidation проверяет отклонение длинных и недопустимых ключей
+func TestKeyedValidation(t *testing.T) {
+	errControl := errors.New("control character in key")
+
+	c := cache.NewKeyed(memory.NewLRU(100), cache.KeyPolicy{
+		MaxLength: 8,
+		Validate: func(key string) error {
+			if strings.IndexFunc(key, unicode.IsControl) >= 0 {
+				return errControl
+			}
+			return nil
+		},
+	})
+	defer c.Close()
+
+	if err := c.Set("too-long-key", []byte("v")); !errors.Is(err, cache.ErrKeyTooLong) {
+		t.Errorf("Expected ErrKeyTooLong, got %v", err)
+	}
+	if err := c.Set("bad\nkey", []byte("v")); !errors.Is(err, errControl) {
+		t.Errorf("Expected validator error, got %v", err)
+	}
+	if err := c.Set("", []byte("v")); !errors.Is(err, cache.ErrKeyEmpty) {
+		t.Errorf("Expected ErrKeyEmpty, got %v", err)
+	}
+
+	loaderCalled := false
+	_, err := c.GetOrSet("too-long-key", func() ([]byte, error) {
+		loaderCalled = true
+		return []byte("v"), nil
+	}, 0)
+	if !errors.Is(err, cache.ErrKeyTooLong) || loaderCalled {
+		t.Errorf("Expected ErrKeyTooLong without loading, got %v (loader called: %v)", err, loaderCalled)
+	}
+
+	if err := c.Set("ok", []byte("v")); err != nil {
+		t.Fatalf("Set failed: %v", err)
+	}
+	if _, exists := c.Get("too-long-key"); exists {
+		t.Error("Invalid key should be a miss")
+	}
+	if c.Len() != 1 {
+		t.Errorf("Expected only the valid key to be stored, got %d keys", c.Len())
+	}
+	if stats := c.Stats(); stats.Misses != 0 {
+		t.Errorf("Rejected keys should not reach the cache, got %d misses", stats.Misses)
+	}
+}
+
+// TestKeyedNormalization проверяет, что все операции видят нормализованный ключ
+func TestKeyedNormalization(t *testing.T) {
+	c := cache.NewKeyed(memory.NewLRU(100), cache.KeyPolicy{
+		MaxLength: 10,
+		Normalize: func(key string) string {
+			return strings.ToLower(strings.TrimSpace(key))
+		},
+	})
+	defer c.Close()
+
+	if err := c.Set("  User:1 ", []byte("alice")); err != nil {
+		t.Fatalf("Set failed: %v", err)
+	}
+
+	value, exists := c.Get("USER:1")
+	if !exists || string(value) != "alice" {
+		t.Errorf("Expected normalized lookup to hit, got %q, %v", value, exists)
+	}
+	if _, exists := c.Cache().Get("user:1"); !exists {
+		t.Error("Underlying cache should store the normalized key")
+	}
+
+	keys := c.Keys()
+	if len(keys) != 1 || keys[0] != "user:1" {
+		t.Errorf("Expected normalized keys, got %v", keys)
+	}
+	if _, exists := c.Get(keys[0]); !exists {
+		t.Error("Keys returned by Keys should be usable as-is")
+	}
+
+	// Длина проверяется после нормализации
+	if err := c.Set("   short   ", []byte("v")); err != nil {
+		t.Errorf("Expected trimmed key to fit the limit, got %v", err)
+	}
+	if err := c.Set("   ", []byte("v")); !errors.Is(err, cache.ErrKeyEmpty) {
+		t.Errorf("Expected ErrKeyEmpty for a blank key, got %v", err)
+	}
+
+	if !c.Delete(" USER:1") {
+		t.Error("Delete should use the normalized key")
+	}
+	if _, exists := c.Get("user:1"); exists {
+		t.Error("Key should be deleted")
+	}
+}
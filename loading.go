@@ -0,0 +1,119 @@
+package cache
+
+import (
+	"bytes"
+	"time"
+
+	"github.com/VsRnA/High-Performance-HTTP-Cache/internal"
+)
+
+// LoadingCache реализует сквозное чтение: при промахе значение загружается
+// через loader, сохраняется в нижележащий кэш с возвращенным TTL и отдается вызывающему.
+// Одновременные загрузки одного ключа дедуплицируются. Ошибки загрузки не кэшируются.
+type LoadingCache struct {
+	cache  Cache
+	loader WarmLoader
+
+	loads     internal.Group // Загрузки при промахе
+	refreshes internal.Group // Принудительные обновления
+
+	// Автомат защиты основного хранилища, по умолчанию отключен
+	breaker breaker
+}
+
+// NewLoadingCache создает сквозной кэш над backing.
+// loader получает ключ и возвращает значение и его TTL, 0 означает TTL по умолчанию backing.
+func NewLoadingCache(backing Cache, loader WarmLoader) *LoadingCache {
+	return &LoadingCache{cache: backing, loader: loader}
+}
+
+// Get возвращает значение из кэша, а при промахе загружает и сохраняет его.
+// Ошибка loader или записи возвращается обернутой, исходная доступна через errors.Is и errors.As.
+func (l *LoadingCache) Get(key string) ([]byte, error) {
+	if key == "" {
+		return nil, ErrKeyEmpty
+	}
+
+	if value, exists := l.cache.Get(key); exists {
+		return value, nil
+	}
+
+	shared, err := l.loads.Do(key, func() ([]byte, error) {
+		return loadKey(l.cache, key, l.load)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Результат общий для всех ожидающих, поэтому каждый получает свою копию
+	return bytes.Clone(shared), nil
+}
+
+// Refresh загружает ключ заново независимо от наличия в кэше и сохраняет результат.
+// Одновременные обновления одного ключа выполняются один раз. При ошибке
+// прежнее значение остается в кэше.
+func (l *LoadingCache) Refresh(key string) ([]byte, error) {
+	if key == "" {
+		return nil, ErrKeyEmpty
+	}
+
+	shared, err := l.refreshes.Do(key, func() ([]byte, error) {
+		return loadKey(l.cache, key, l.load)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return bytes.Clone(shared), nil
+}
+
+// SetCircuitBreaker включает автомат защиты основного хранилища: после threshold
+// ошибок загрузчика подряд загрузки на время cooldown сразу завершаются ErrCircuitOpen
+// без вызова loader. Затем выполняется одна пробная загрузка: успех возвращает
+// обычный режим, ошибка снова отключает загрузки на cooldown.
+// Значения, уже находящиеся в кэше, по-прежнему отдаются. threshold <= 0 отключает автомат.
+func (l *LoadingCache) SetCircuitBreaker(threshold int, cooldown time.Duration) {
+	l.breaker.configure(threshold, cooldown)
+}
+
+// SetOnBreakerChange устанавливает колбэк смены состояния автомата защиты.
+// Переход из открытого состояния в полуоткрытое происходит при первой загрузке после cooldown.
+func (l *LoadingCache) SetOnBreakerChange(fn BreakerCallback) {
+	l.breaker.mu.Lock()
+	l.breaker.onChange = fn
+	l.breaker.mu.Unlock()
+}
+
+// BreakerState возвращает текущее состояние автомата защиты
+func (l *LoadingCache) BreakerState() BreakerState {
+	return l.breaker.current()
+}
+
+// load вызывает loader, если автомат защиты разрешает загрузку
+func (l *LoadingCache) load(key string) ([]byte, time.Duration, error) {
+	if !l.breaker.allow() {
+		return nil, 0, ErrCircuitOpen
+	}
+
+	// Паника загрузчика учитывается как ошибка, иначе пробная загрузка
+	// оставила бы автомат полуоткрытым и все следующие загрузки отклонялись бы
+	defer func() {
+		if r := recover(); r != nil {
+			l.breaker.record(errLoaderPanic)
+			panic(r)
+		}
+	}()
+
+	value, ttl, err := l.loader(key)
+	l.breaker.record(err)
+	return value, ttl, err
+}
+
+// Invalidate удаляет ключ, следующий Get загрузит его заново
+func (l *LoadingCache) Invalidate(key string) bool {
+	return l.cache.Delete(key)
+}
+
+// Cache возвращает нижележащий кэш
+func (l *LoadingCache) Cache() Cache {
+	return l.cache
+}
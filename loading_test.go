@@ -0,0 +1,272 @@
+package cache_test
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	cache "github.com/VsRnA/High-Performance-HTTP-Cache"
+	"github.com/VsRnA/High-Performance-HTTP-Cache/memory"
+)
+
+// TestLoadingCache проверяет загрузку при промахе, попадание без загрузки и TTL загрузчика
+func TestLoadingCache(t *testing.T) {
+	backing := memory.NewLRU(100)
+	defer backing.Close()
+
+	var calls atomic.Int64
+	version := "v1"
+	c := cache.NewLoadingCache(backing, func(key string) ([]byte, time.Duration, error) {
+		calls.Add(1)
+		return []byte(key + ":" + version), time.Hour, nil
+	})
+
+	value, err := c.Get("user:1")
+	if err != nil || string(value) != "user:1:v1" {
+		t.Fatalf("Expected loaded value, got %q, %v", value, err)
+	}
+	if ttl, _ := backing.GetTTL("user:1"); ttl <= 59*time.Minute {
+		t.Errorf("Expected loader TTL to apply, got %v", ttl)
+	}
+
+	if value, _ := c.Get("user:1"); string(value) != "user:1:v1" || calls.Load() != 1 {
+		t.Errorf("Expected cached value without reload, got %q after %d loads", value, calls.Load())
+	}
+
+	// Refresh загружает значение заново даже при попадании
+	version = "v2"
+	if value, err := c.Refresh("user:1"); err != nil || string(value) != "user:1:v2" {
+		t.Errorf("Expected refreshed value, got %q, %v", value, err)
+	}
+	if value, _ := backing.Get("user:1"); string(value) != "user:1:v2" {
+		t.Errorf("Refresh should store the new value, got %q", value)
+	}
+
+	if !c.Invalidate("user:1") {
+		t.Error("Expected Invalidate to remove the key")
+	}
+	version = "v3"
+	if value, _ := c.Get("user:1"); string(value) != "user:1:v3" {
+		t.Errorf("Expected reload after Invalidate, got %q", value)
+	}
+
+	if _, err := c.Get(""); !errors.Is(err, cache.ErrKeyEmpty) {
+		t.Errorf("Expected ErrKeyEmpty, got %v", err)
+	}
+}
+
+// TestLoadingCacheErrors проверяет, что ошибка загрузки возвращается и не кэшируется
+func TestLoadingCacheErrors(t *testing.T) {
+	backing := memory.NewLRU(100)
+	defer backing.Close()
+
+	errDown := errors.New("database is down")
+	var fail atomic.Bool
+	fail.Store(true)
+	c := cache.NewLoadingCache(backing, func(key string) ([]byte, time.Duration, error) {
+		if fail.Load() {
+			return nil, 0, errDown
+		}
+		return []byte("value"), 0, nil
+	})
+
+	if _, err := c.Get("key"); !errors.Is(err, errDown) {
+		t.Fatalf("Expected loader error, got %v", err)
+	}
+	if backing.Len() != 0 {
+		t.Error("Failed load should not be stored")
+	}
+
+	fail.Store(false)
+	if value, err := c.Get("key"); err != nil || string(value) != "value" {
+		t.Errorf("Expected retry after error to succeed, got %q, %v", value, err)
+	}
+
+	// Неудачное обновление сохраняет прежнее значение
+	fail.Store(true)
+	if _, err := c.Refresh("key"); !errors.Is(err, errDown) {
+		t.Errorf("Expected refresh error, got %v", err)
+	}
+	if value, _ := backing.Get("key"); string(value) != "value" {
+		t.Errorf("Expected old value to survive failed refresh, got %q", value)
+	}
+}
+
+// TestLoadingCacheDeduplication проверяет, что одновременные промахи загружают ключ один раз
+func TestLoadingCacheDeduplication(t *testing.T) {
+	backing := memory.NewLRU(100)
+	defer backing.Close()
+
+	var calls atomic.Int64
+	release := make(chan struct{})
+	c := cache.NewLoadingCache(backing, func(key string) ([]byte, time.Duration, error) {
+		calls.Add(1)
+		<-release
+		return []byte("value"), 0, nil
+	})
+
+	var wg sync.WaitGroup
+	results := make([][]byte, 10)
+	for i := range results {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i], _ = c.Get("key")
+		}()
+	}
+
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	if calls.Load() != 1 {
+		t.Errorf("Expected a single load, got %d", calls.Load())
+	}
+	for i, value := range results {
+		if string(value) != "value" {
+			t.Errorf("Caller %d got %q", i, value)
+		}
+	}
+
+	// Каждый вызывающий получает собственную копию
+	results[0][0] = 'X'
+	if string(results[1]) != "value" {
+		t.Error("Callers should not share the returned slice")
+	}
+}
+
+// TestLoadingCacheCircuitBreakerPanic проверяет, что паника в пробной загрузке
+// снова размыкает автомат, а не оставляет его полуоткрытым навсегда
+func TestLoadingCacheCircuitBreakerPanic(t *testing.T) {
+	backing := memory.NewLRU(100)
+	defer backing.Close()
+
+	errDown := errors.New("database is down")
+	var mode atomic.Int64 // 0 - ошибка, 1 - паника, 2 - успех
+	c := cache.NewLoadingCache(backing, func(key string) ([]byte, time.Duration, error) {
+		switch mode.Load() {
+		case 0:
+			return nil, 0, errDown
+		case 1:
+			panic("loader failed")
+		default:
+			return []byte("value"), 0, nil
+		}
+	})
+	c.SetCircuitBreaker(1, 20*time.Millisecond)
+
+	if _, err := c.Get("key"); !errors.Is(err, errDown) {
+		t.Fatalf("Expected loader error, got %v", err)
+	}
+
+	time.Sleep(30 * time.Millisecond)
+	mode.Store(1)
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Error("Expected loader panic to propagate")
+			}
+		}()
+		c.Get("key")
+	}()
+	if c.BreakerState() != cache.BreakerOpen {
+		t.Fatalf("Expected panicking probe to reopen the breaker, got %v", c.BreakerState())
+	}
+
+	time.Sleep(30 * time.Millisecond)
+	mode.Store(2)
+	if value, err := c.Get("key"); err != nil || string(value) != "value" {
+		t.Errorf("Expected successful probe after panic, got %q, %v", value, err)
+	}
+	if c.BreakerState() != cache.BreakerClosed {
+		t.Errorf("Expected breaker to close, got %v", c.BreakerState())
+	}
+}
+
+// TestLoadingCacheCircuitBreaker проверяет переходы closed→open→half-open→closed
+// и повторное размыкание после неудачной пробы
+func TestLoadingCacheCircuitBreaker(t *testing.T) {
+	backing := memory.NewLRU(100)
+	defer backing.Close()
+
+	errDown := errors.New("database is down")
+	var calls atomic.Int64
+	var fail atomic.Bool
+	fail.Store(true)
+	c := cache.NewLoadingCache(backing, func(key string) ([]byte, time.Duration, error) {
+		calls.Add(1)
+		if fail.Load() {
+			return nil, 0, errDown
+		}
+		return []byte("value"), 0, nil
+	})
+
+	var mu sync.Mutex
+	var transitions []string
+	c.SetOnBreakerChange(func(from, to cache.BreakerState) {
+		mu.Lock()
+		transitions = append(transitions, from.String()+"->"+to.String())
+		mu.Unlock()
+	})
+	c.SetCircuitBreaker(3, 30*time.Millisecond)
+	backing.Set("cached", []byte("stale-safe"))
+
+	for i := 0; i < 3; i++ {
+		if _, err := c.Get("key"); !errors.Is(err, errDown) {
+			t.Fatalf("Expected loader error before opening, got %v", err)
+		}
+	}
+	if c.BreakerState() != cache.BreakerOpen {
+		t.Fatalf("Expected breaker to open after 3 failures, got %v", c.BreakerState())
+	}
+
+	// В открытом состоянии загрузчик не вызывается, а кэш продолжает отвечать
+	if _, err := c.Get("key"); !errors.Is(err, cache.ErrCircuitOpen) {
+		t.Errorf("Expected ErrCircuitOpen, got %v", err)
+	}
+	if calls.Load() != 3 {
+		t.Errorf("Loader should not be called while open, got %d calls", calls.Load())
+	}
+	if value, err := c.Get("cached"); err != nil || string(value) != "stale-safe" {
+		t.Errorf("Cached values should still be served, got %q, %v", value, err)
+	}
+
+	// Неудачная проба снова размыкает автомат
+	time.Sleep(40 * time.Millisecond)
+	if _, err := c.Get("key"); !errors.Is(err, errDown) {
+		t.Errorf("Expected probe to reach the loader, got %v", err)
+	}
+	if c.BreakerState() != cache.BreakerOpen {
+		t.Errorf("Expected failed probe to reopen the breaker, got %v", c.BreakerState())
+	}
+
+	// Успешная проба замыкает автомат
+	time.Sleep(40 * time.Millisecond)
+	fail.Store(false)
+	if value, err := c.Get("key"); err != nil || string(value) != "value" {
+		t.Errorf("Expected successful probe, got %q, %v", value, err)
+	}
+	if c.BreakerState() != cache.BreakerClosed {
+		t.Errorf("Expected breaker to close, got %v", c.BreakerState())
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	expected := []string{
+		"closed->open",
+		"open->half-open",
+		"half-open->open",
+		"open->half-open",
+		"half-open->closed",
+	}
+	if len(transitions) != len(expected) {
+		t.Fatalf("Expected transitions %v, got %v", expected, transitions)
+	}
+	for i := range expected {
+		if transitions[i] != expected[i] {
+			t.Errorf("Transition %d: expected %s, got %s", i, expected[i], transitions[i])
+		}
+	}
+}
@@ -0,0 +1,64 @@
+package memory
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// GetWithAge получает значение и его возраст - время с последней записи значения.
+// Перезапись ключа обнуляет возраст, а продление TTL через Expire, Touch или скользящий
+// TTL - нет. Считается обращением, как Get.
+func (c *LRUCache) GetWithAge(key string) (value []byte, age time.Duration, ok bool) {
+	if key == "" {
+		atomic.AddInt64(&c.misses, 1)
+		return nil, 0, false
+	}
+
+	c.mu.Lock()
+	defer c.unlock()
+
+	item := c.lookupLocked(key)
+	if item == nil {
+		return nil, 0, false
+	}
+	return decodeValue(item.value, item.compressed), c.clock.Now().Sub(item.createdAt), true
+}
+
+// GetWithAge получает значение и время с его последней записи, см. LRUCache.GetWithAge
+func (c *LFUCache) GetWithAge(key string) (value []byte, age time.Duration, ok bool) {
+	if key == "" {
+		atomic.AddInt64(&c.misses, 1)
+		return nil, 0, false
+	}
+
+	c.mu.Lock()
+	defer c.unlock()
+
+	item := c.lookupLocked(key)
+	if item == nil {
+		return nil, 0, false
+	}
+	return decodeValue(item.value, item.compressed), c.clock.Now().Sub(item.createdAt), true
+}
+
+// GetWithAge получает значение и время с его последней записи, см. LRUCache.GetWithAge
+func (c *SimpleCache) GetWithAge(key string) (value []byte, age time.Duration, ok bool) {
+	if key == "" {
+		atomic.AddInt64(&c.misses, 1)
+		return nil, 0, false
+	}
+
+	c.mu.Lock()
+	defer c.unlock()
+
+	item := c.lookupLocked(key)
+	if item == nil {
+		return nil, 0, false
+	}
+	return decodeValue(item.value, item.compressed), c.clock.Now().Sub(item.createdAt), true
+}
+
+// GetWithAge получает значение и время с его последней записи из шарда ключа
+func (c *ShardedCache) GetWithAge(key string) (value []byte, age time.Duration, ok bool) {
+	return c.shard(key).GetWithAge(key)
+}
@@ -0,0 +1,463 @@
+package memory
+
+import (
+	"math/rand/v2"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	cache "github.com/VsRnA/High-Performance-HTTP-Cache"
+	"github.com/VsRnA/High-Performance-HTTP-Cache/internal"
+)
+
+// defaultApproxSampleSize - размер выборки по умолчанию, как maxmemory-samples в Redis
+const defaultApproxSampleSize = 5
+
+// approxItem представляет элемент в приближенном LRU кэше
+type approxItem struct {
+	key        string
+	value      []byte
+	expiresAt  time.Time
+	lastAccess int64 // Отметка логических часов последнего обращения, изменяется атомарно
+	index      int   // Позиция в срезе order для случайной выборки за O(1)
+}
+
+// size возвращает объем ключа и значения в байтах
+func (item *approxItem) size() int64 {
+	return int64(len(item.key) + len(item.value))
+}
+
+// isExpired проверяет истек ли элемент на момент now
+func (item *approxItem) isExpired(now time.Time) bool {
+	return !item.expiresAt.IsZero() && now.After(item.expiresAt)
+}
+
+// ApproxLRUCache реализует приближенный LRU без связного списка.
+// При переполнении выбирается sampleSize случайных элементов и вытесняется
+// давно использованный из них, как в Redis. Чтение не меняет структуру кэша,
+// а только атомарно обновляет отметку обращения, поэтому выполняется
+// под блокировкой на чтение и не конкурирует с другими чтениями.
+type ApproxLRUCache struct {
+	// Основные данные
+	items map[string]*approxItem
+	order []*approxItem // Все элементы для случайной выборки кандидатов
+	mu    sync.RWMutex
+
+	// Конфигурация
+	maxSize    int
+	sampleSize int
+	defaultTTL time.Duration
+
+	// Логические часы обращений, изменяются атомарно
+	accessTick int64
+
+	// Объем хранимых ключей и значений в байтах, изменяется под mu
+	bytes int64
+
+	// Источник времени для TTL, изменяется под mu
+	clock Clock
+
+	// Управление жизненным циклом
+	stopCh chan struct{}
+	closed bool
+
+	// Уведомления об удаленных элементах
+	evictQueue evictionQueue
+
+	// Дедупликация одновременных загрузок в GetOrSet
+	loads internal.Group
+
+	// Статистика
+	hits      int64
+	misses    int64
+	evictions int64
+}
+
+// NewApproxLRU создает приближенный LRU кэш с указанным максимальным размером.
+// sampleSize задает число кандидатов на вытеснение, sampleSize <= 0 - 5 кандидатов.
+// Большая выборка точнее приближает LRU, но удорожает вытеснение.
+func NewApproxLRU(maxSize, sampleSize int) cache.Cache {
+	return NewApproxLRUWithTTL(maxSize, sampleSize, 0)
+}
+
+// NewApproxLRUWithTTL создает приближенный LRU кэш с TTL по умолчанию
+func NewApproxLRUWithTTL(maxSize, sampleSize int, defaultTTL time.Duration) cache.Cache {
+	if maxSize <= 0 {
+		maxSize = 1000
+	}
+	if sampleSize <= 0 {
+		sampleSize = defaultApproxSampleSize
+	}
+
+	c := &ApproxLRUCache{
+		items:      make(map[string]*approxItem, maxSize),
+		order:      make([]*approxItem, 0, maxSize),
+		maxSize:    maxSize,
+		sampleSize: sampleSize,
+		defaultTTL: defaultTTL,
+		stopCh:     make(chan struct{}),
+		clock:      realClock{},
+	}
+
+	if defaultTTL > 0 {
+		go c.cleanup()
+	}
+
+	return c
+}
+
+// Get получает значение по ключу.
+// Попадание выполняется под блокировкой на чтение.
+func (c *ApproxLRUCache) Get(key string) ([]byte, bool) {
+	if key == "" {
+		atomic.AddInt64(&c.misses, 1)
+		return nil, false
+	}
+
+	c.mu.RLock()
+	item, exists := c.items[key]
+	if !exists || c.closed {
+		c.mu.RUnlock()
+		atomic.AddInt64(&c.misses, 1)
+		return nil, false
+	}
+
+	if item.isExpired(c.clock.Now()) {
+		c.mu.RUnlock()
+
+		// Удаляется только тот же элемент: его могли перезаписать после RUnlock
+		c.mu.Lock()
+		if current, exists := c.items[key]; exists && current == item {
+			c.removeItem(item, cache.ReasonExpired)
+		}
+		c.unlock()
+
+		atomic.AddInt64(&c.misses, 1)
+		return nil, false
+	}
+
+	atomic.StoreInt64(&item.lastAccess, atomic.AddInt64(&c.accessTick, 1))
+	value := make([]byte, len(item.value))
+	copy(value, item.value)
+	c.mu.RUnlock()
+
+	atomic.AddInt64(&c.hits, 1)
+	return value, true
+}
+
+// Set сохраняет значение с TTL по умолчанию
+func (c *ApproxLRUCache) Set(key string, value []byte) error {
+	return c.SetWithTTL(key, value, c.defaultTTL)
+}
+
+// SetWithTTL сохраняет значение с указанным TTL
+func (c *ApproxLRUCache) SetWithTTL(key string, value []byte, ttl time.Duration) error {
+	if key == "" {
+		return cache.ErrKeyEmpty
+	}
+
+	c.mu.Lock()
+	defer c.unlock()
+
+	if c.closed {
+		return cache.ErrCacheClosed
+	}
+
+	if ttl <= 0 {
+		ttl = c.defaultTTL
+	}
+	expiresAt := expirationTime(c.clock.Now(), ttl)
+
+	valueCopy := make([]byte, len(value))
+	copy(valueCopy, value)
+	access := atomic.AddInt64(&c.accessTick, 1)
+
+	if existingItem, exists := c.items[key]; exists {
+		// Значение заменяется, а не изменяется: Get копирует его под блокировкой на чтение
+		c.bytes += int64(len(valueCopy) - len(existingItem.value))
+		existingItem.value = valueCopy
+		existingItem.expiresAt = expiresAt
+		atomic.StoreInt64(&existingItem.lastAccess, access)
+		return nil
+	}
+
+	if len(c.items) >= c.maxSize {
+		c.evictSample()
+	}
+
+	newItem := &approxItem{
+		key:        key,
+		value:      valueCopy,
+		expiresAt:  expiresAt,
+		lastAccess: access,
+		index:      len(c.order),
+	}
+
+	c.items[key] = newItem
+	c.order = append(c.order, newItem)
+	c.bytes += newItem.size()
+	return nil
+}
+
+// GetOrSet возвращает значение по ключу или загружает его через loader при промахе
+func (c *ApproxLRUCache) GetOrSet(key string, loader func() ([]byte, error), ttl time.Duration) ([]byte, error) {
+	if key == "" {
+		return nil, cache.ErrKeyEmpty
+	}
+
+	if value, exists := c.Get(key); exists {
+		return value, nil
+	}
+
+	shared, err := c.loads.Do(key, func() ([]byte, error) {
+		value, err := loader()
+		if err != nil {
+			return nil, err
+		}
+		if err := c.SetWithTTL(key, value, ttl); err != nil {
+			return nil, err
+		}
+		return value, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Результат общий для всех ожидающих, поэтому каждый получает свою копию
+	value := make([]byte, len(shared))
+	copy(value, shared)
+	return value, nil
+}
+
+// GetTTL возвращает оставшееся время жизни ключа без обновления статистики
+func (c *ApproxLRUCache) GetTTL(key string) (time.Duration, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	item, exists := c.items[key]
+	if !exists || item.isExpired(c.clock.Now()) {
+		return 0, false
+	}
+	return remainingTTL(c.clock.Now(), item.expiresAt)
+}
+
+// Expire устанавливает новое время жизни ключа.
+// Изменение TTL не считается обращением.
+func (c *ApproxLRUCache) Expire(key string, ttl time.Duration) bool {
+	c.mu.Lock()
+	defer c.unlock()
+
+	item, exists := c.items[key]
+	if !exists || item.isExpired(c.clock.Now()) {
+		return false
+	}
+
+	item.expiresAt = expirationTime(c.clock.Now(), ttl)
+	return true
+}
+
+// Persist делает ключ бессрочным
+func (c *ApproxLRUCache) Persist(key string) bool {
+	return c.Expire(key, 0)
+}
+
+// Delete удаляет ключ из кэша
+func (c *ApproxLRUCache) Delete(key string) bool {
+	if key == "" {
+		return false
+	}
+
+	c.mu.Lock()
+	defer c.unlock()
+
+	item, exists := c.items[key]
+	if !exists || c.closed {
+		return false
+	}
+
+	c.removeItem(item, cache.ReasonDeleted)
+	return true
+}
+
+// Keys возвращает все неистекшие ключи в неопределенном порядке
+func (c *ApproxLRUCache) Keys() []string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	keys := make([]string, 0, len(c.items))
+	for key, item := range c.items {
+		if !item.isExpired(c.clock.Now()) {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// Len возвращает количество элементов без построения Stats.
+// Может учитывать истекшие элементы, которые еще не удалены.
+func (c *ApproxLRUCache) Len() int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return len(c.items)
+}
+
+// Clear очищает весь кэш
+func (c *ApproxLRUCache) Clear() {
+	c.mu.Lock()
+	defer c.unlock()
+
+	for key, item := range c.items {
+		c.evictQueue.push(key, item.value, cache.ReasonCleared)
+	}
+
+	c.items = make(map[string]*approxItem)
+	c.order = nil
+	c.bytes = 0
+
+	atomic.StoreInt64(&c.hits, 0)
+	atomic.StoreInt64(&c.misses, 0)
+	atomic.StoreInt64(&c.evictions, 0)
+}
+
+// Stats возвращает статистику кэша
+func (c *ApproxLRUCache) Stats() cache.Stats {
+	c.mu.RLock()
+	keys := int64(len(c.items))
+	bytes := c.bytes
+	c.mu.RUnlock()
+
+	stats := cache.Stats{
+		Hits:      atomic.LoadInt64(&c.hits),
+		Misses:    atomic.LoadInt64(&c.misses),
+		Keys:      keys,
+		Evictions: atomic.LoadInt64(&c.evictions),
+		Bytes:     bytes,
+		RawBytes:  bytes, // Значения хранятся без сжатия
+	}
+
+	stats.CalculateHitRate()
+	return stats
+}
+
+// Close корректно завершает работу кэша
+func (c *ApproxLRUCache) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.closed {
+		return nil
+	}
+
+	c.closed = true
+	close(c.stopCh)
+	return nil
+}
+
+// SetOnEvict устанавливает колбэк, вызываемый при вытеснении, истечении,
+// удалении и очистке элементов. nil отключает уведомления.
+func (c *ApproxLRUCache) SetOnEvict(fn cache.EvictCallback) {
+	c.mu.Lock()
+	c.evictQueue.onEvict = fn
+	c.mu.Unlock()
+}
+
+// unlock снимает блокировку на запись и вызывает колбэк для элементов,
+// удаленных пока она удерживалась
+func (c *ApproxLRUCache) unlock() {
+	onEvict, evicted := c.evictQueue.take()
+	c.mu.Unlock()
+	notifyEvicted(onEvict, evicted)
+}
+
+// evictSample удаляет давно использованный элемент из случайной выборки.
+// Истекший элемент в выборке удаляется в первую очередь.
+// Если элементов не больше sampleSize, просматриваются все и вытеснение точное.
+func (c *ApproxLRUCache) evictSample() {
+	if len(c.order) == 0 {
+		return
+	}
+
+	var victim *approxItem
+	consider := func(item *approxItem) bool {
+		if item.isExpired(c.clock.Now()) {
+			victim = item
+			return true
+		}
+		if victim == nil || atomic.LoadInt64(&item.lastAccess) < atomic.LoadInt64(&victim.lastAccess) {
+			victim = item
+		}
+		return false
+	}
+
+	if len(c.order) <= c.sampleSize {
+		for _, item := range c.order {
+			if consider(item) {
+				break
+			}
+		}
+	} else {
+		for i := 0; i < c.sampleSize; i++ {
+			if consider(c.order[rand.IntN(len(c.order))]) {
+				break
+			}
+		}
+	}
+
+	reason := cache.ReasonCapacity
+	if victim.isExpired(c.clock.Now()) {
+		reason = cache.ReasonExpired
+	}
+	c.removeItem(victim, reason)
+	atomic.AddInt64(&c.evictions, 1)
+}
+
+// removeItem полностью удаляет элемент из кэша.
+// На место удаленного элемента в order переносится последний, чтобы удаление было O(1).
+func (c *ApproxLRUCache) removeItem(item *approxItem, reason cache.EvictionReason) {
+	delete(c.items, item.key)
+
+	last := c.order[len(c.order)-1]
+	c.order[item.index] = last
+	last.index = item.index
+	c.order[len(c.order)-1] = nil
+	c.order = c.order[:len(c.order)-1]
+
+	c.bytes -= item.size()
+	c.evictQueue.push(item.key, item.value, reason)
+}
+
+// cleanup фоновая очистка истекших элементов
+func (c *ApproxLRUCache) cleanup() {
+	ticker := time.NewTicker(1 * time.Minute)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			c.removeExpired()
+		case <-c.stopCh:
+			return
+		}
+	}
+}
+
+// removeExpired удаляет все истекшие элементы
+func (c *ApproxLRUCache) removeExpired() {
+	c.mu.Lock()
+	defer c.unlock()
+
+	var expired []*approxItem
+	for _, item := range c.items {
+		if item.isExpired(c.clock.Now()) {
+			expired = append(expired, item)
+		}
+	}
+
+	for _, item := range expired {
+		c.removeItem(item, cache.ReasonExpired)
+	}
+
+	if len(expired) > 0 {
+		atomic.AddInt64(&c.evictions, int64(len(expired)))
+	}
+}
@@ -0,0 +1,530 @@
+package memory
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+
+	cache "github.com/VsRnA/High-Performance-HTTP-Cache"
+	"github.com/VsRnA/High-Performance-HTTP-Cache/internal"
+)
+
+// arcItem представляет элемент в одном из четырех списков ARC кэша.
+// Элементы призрачных списков B1 и B2 хранят только ключ.
+type arcItem struct {
+	key        string
+	value      []byte
+	expiresAt  time.Time
+	list       *arcList
+	prev, next *arcItem
+}
+
+// size возвращает объем ключа и значения в байтах
+func (item *arcItem) size() int64 {
+	return int64(len(item.key) + len(item.value))
+}
+
+// isExpired проверяет истек ли элемент на момент now
+func (item *arcItem) isExpired(now time.Time) bool {
+	return !item.expiresAt.IsZero() && now.After(item.expiresAt)
+}
+
+// arcList - двусвязный список с ограничителем, начало списка - самый недавний элемент
+type arcList struct {
+	root arcItem
+	len  int
+}
+
+// init подготавливает пустой список
+func (l *arcList) init() {
+	l.root.next = &l.root
+	l.root.prev = &l.root
+	l.len = 0
+}
+
+// pushFront добавляет элемент в начало списка
+func (l *arcList) pushFront(item *arcItem) {
+	item.list = l
+	item.prev = &l.root
+	item.next = l.root.next
+	l.root.next.prev = item
+	l.root.next = item
+	l.len++
+}
+
+// remove удаляет элемент из списка
+func (l *arcList) remove(item *arcItem) {
+	item.prev.next = item.next
+	item.next.prev = item.prev
+	item.prev, item.next, item.list = nil, nil, nil
+	l.len--
+}
+
+// back возвращает самый давний элемент или nil для пустого списка
+func (l *arcList) back() *arcItem {
+	if l.len == 0 {
+		return nil
+	}
+	return l.root.prev
+}
+
+// ARCCache реализует Adaptive Replacement Cache.
+// T1 хранит элементы, использованные один раз, T2 - использованные повторно.
+// B1 и B2 хранят ключи недавно вытесненных из T1 и T2 элементов, попадания в них
+// смещают целевой размер T1 (p) в пользу давности или частоты обращений.
+type ARCCache struct {
+	// Основные данные
+	items          map[string]*arcItem // Элементы всех четырех списков
+	t1, t2, b1, b2 arcList
+	p              int // Целевой размер T1
+	mu             sync.RWMutex
+
+	// Конфигурация
+	maxSize    int
+	defaultTTL time.Duration
+
+	// Объем хранимых ключей и значений T1 и T2 в байтах, изменяется под mu
+	bytes int64
+
+	// Источник времени для TTL, изменяется под mu
+	clock Clock
+
+	// Управление жизненным циклом
+	stopCh chan struct{}
+	closed bool
+
+	// Уведомления об удаленных элементах
+	evictQueue evictionQueue
+
+	// Дедупликация одновременных загрузок в GetOrSet
+	loads internal.Group
+
+	// Статистика
+	hits      int64
+	misses    int64
+	evictions int64
+}
+
+// NewARC создает новый ARC кэш с указанным максимальным размером
+func NewARC(maxSize int) cache.Cache {
+	return NewARCWithTTL(maxSize, 0)
+}
+
+// NewARCWithTTL создает новый ARC кэш с максимальным размером и TTL по умолчанию
+func NewARCWithTTL(maxSize int, defaultTTL time.Duration) cache.Cache {
+	if maxSize <= 0 {
+		maxSize = 1000
+	}
+
+	c := &ARCCache{
+		items:      make(map[string]*arcItem, 2*maxSize),
+		maxSize:    maxSize,
+		defaultTTL: defaultTTL,
+		stopCh:     make(chan struct{}),
+		clock:      realClock{},
+	}
+	c.t1.init()
+	c.t2.init()
+	c.b1.init()
+	c.b2.init()
+
+	if defaultTTL > 0 {
+		go c.cleanup()
+	}
+
+	return c
+}
+
+// isResident проверяет что элемент хранит значение (находится в T1 или T2)
+func (c *ARCCache) isResident(item *arcItem) bool {
+	return item.list == &c.t1 || item.list == &c.t2
+}
+
+// Get получает значение по ключу. Попадание переносит элемент в T2.
+func (c *ARCCache) Get(key string) ([]byte, bool) {
+	if key == "" {
+		atomic.AddInt64(&c.misses, 1)
+		return nil, false
+	}
+
+	c.mu.Lock()
+	defer c.unlock()
+
+	item, exists := c.items[key]
+	if !exists || c.closed || !c.isResident(item) {
+		atomic.AddInt64(&c.misses, 1)
+		return nil, false
+	}
+
+	if item.isExpired(c.clock.Now()) {
+		c.removeItem(item, cache.ReasonExpired)
+		atomic.AddInt64(&c.misses, 1)
+		return nil, false
+	}
+
+	item.list.remove(item)
+	c.t2.pushFront(item)
+
+	atomic.AddInt64(&c.hits, 1)
+
+	value := make([]byte, len(item.value))
+	copy(value, item.value)
+	return value, true
+}
+
+// Set сохраняет значение с TTL по умолчанию
+func (c *ARCCache) Set(key string, value []byte) error {
+	return c.SetWithTTL(key, value, c.defaultTTL)
+}
+
+// SetWithTTL сохраняет значение с указанным TTL
+func (c *ARCCache) SetWithTTL(key string, value []byte, ttl time.Duration) error {
+	if key == "" {
+		return cache.ErrKeyEmpty
+	}
+
+	c.mu.Lock()
+	defer c.unlock()
+
+	if c.closed {
+		return cache.ErrCacheClosed
+	}
+
+	var expiresAt time.Time
+	if ttl > 0 {
+		expiresAt = c.clock.Now().Add(ttl)
+	} else if c.defaultTTL > 0 {
+		expiresAt = c.clock.Now().Add(c.defaultTTL)
+	}
+
+	valueCopy := make([]byte, len(value))
+	copy(valueCopy, value)
+
+	item, exists := c.items[key]
+	switch {
+	case exists && c.isResident(item):
+		// Повторное обращение к хранимому элементу - переносим в T2
+		c.bytes += int64(len(valueCopy) - len(item.value))
+		item.value = valueCopy
+		item.expiresAt = expiresAt
+		item.list.remove(item)
+		c.t2.pushFront(item)
+
+	case exists && item.list == &c.b1:
+		// Попадание в B1: T1 был слишком мал, увеличиваем p
+		delta := 1
+		if c.b2.len > c.b1.len {
+			delta = c.b2.len / c.b1.len
+		}
+		c.p = min(c.p+delta, c.maxSize)
+
+		c.b1.remove(item)
+		if c.t1.len+c.t2.len >= c.maxSize {
+			c.replace(false)
+		}
+		item.value = valueCopy
+		c.bytes += item.size()
+		item.expiresAt = expiresAt
+		c.t2.pushFront(item)
+
+	case exists && item.list == &c.b2:
+		// Попадание в B2: T2 был слишком мал, уменьшаем p
+		delta := 1
+		if c.b1.len > c.b2.len {
+			delta = c.b1.len / c.b2.len
+		}
+		c.p = max(c.p-delta, 0)
+
+		c.b2.remove(item)
+		if c.t1.len+c.t2.len >= c.maxSize {
+			c.replace(true)
+		}
+		item.value = valueCopy
+		c.bytes += item.size()
+		item.expiresAt = expiresAt
+		c.t2.pushFront(item)
+
+	default:
+		// Новый ключ попадает в T1
+		if c.t1.len+c.t2.len >= c.maxSize {
+			c.replace(false)
+		}
+
+		// Ограничиваем размеры призрачных списков
+		if c.b1.len > c.maxSize-c.p {
+			c.removeGhost(c.b1.back())
+		}
+		if c.b2.len > c.p {
+			c.removeGhost(c.b2.back())
+		}
+
+		item = &arcItem{
+			key:       key,
+			value:     valueCopy,
+			expiresAt: expiresAt,
+		}
+		c.items[key] = item
+		c.t1.pushFront(item)
+		c.bytes += item.size()
+	}
+
+	return nil
+}
+
+// GetOrSet возвращает значение по ключу или загружает его через loader при промахе
+func (c *ARCCache) GetOrSet(key string, loader func() ([]byte, error), ttl time.Duration) ([]byte, error) {
+	if key == "" {
+		return nil, cache.ErrKeyEmpty
+	}
+
+	if value, exists := c.Get(key); exists {
+		return value, nil
+	}
+
+	shared, err := c.loads.Do(key, func() ([]byte, error) {
+		value, err := loader()
+		if err != nil {
+			return nil, err
+		}
+		if err := c.SetWithTTL(key, value, ttl); err != nil {
+			return nil, err
+		}
+		return value, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Результат общий для всех ожидающих, поэтому каждый получает свою копию
+	value := make([]byte, len(shared))
+	copy(value, shared)
+	return value, nil
+}
+
+// GetTTL возвращает оставшееся время жизни ключа без обновления статистики
+func (c *ARCCache) GetTTL(key string) (time.Duration, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	item, exists := c.items[key]
+	if !exists || !c.isResident(item) || item.isExpired(c.clock.Now()) {
+		return 0, false
+	}
+	return remainingTTL(c.clock.Now(), item.expiresAt)
+}
+
+// Expire устанавливает новое время жизни ключа не меняя его положение в списках
+func (c *ARCCache) Expire(key string, ttl time.Duration) bool {
+	c.mu.Lock()
+	defer c.unlock()
+
+	item, exists := c.items[key]
+	if !exists || !c.isResident(item) || item.isExpired(c.clock.Now()) {
+		return false
+	}
+
+	item.expiresAt = expirationTime(c.clock.Now(), ttl)
+	return true
+}
+
+// Persist делает ключ бессрочным
+func (c *ARCCache) Persist(key string) bool {
+	return c.Expire(key, 0)
+}
+
+// Delete удаляет ключ из кэша вместе с его историей в призрачных списках
+func (c *ARCCache) Delete(key string) bool {
+	if key == "" {
+		return false
+	}
+
+	c.mu.Lock()
+	defer c.unlock()
+
+	item, exists := c.items[key]
+	if !exists || c.closed {
+		return false
+	}
+
+	if !c.isResident(item) {
+		c.removeGhost(item)
+		return false
+	}
+
+	c.removeItem(item, cache.ReasonDeleted)
+	return true
+}
+
+// Keys возвращает все неистекшие ключи в неопределенном порядке
+func (c *ARCCache) Keys() []string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	keys := make([]string, 0, c.t1.len+c.t2.len)
+	for _, list := range []*arcList{&c.t1, &c.t2} {
+		for item := list.root.next; item != &list.root; item = item.next {
+			if !item.isExpired(c.clock.Now()) {
+				keys = append(keys, item.key)
+			}
+		}
+	}
+	return keys
+}
+
+// Len возвращает количество резидентных элементов без построения Stats.
+// Может учитывать истекшие элементы, которые еще не удалены.
+func (c *ARCCache) Len() int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.t1.len + c.t2.len
+}
+
+// Clear очищает весь кэш вместе с историей вытеснений
+func (c *ARCCache) Clear() {
+	c.mu.Lock()
+	defer c.unlock()
+
+	for _, item := range c.items {
+		if c.isResident(item) {
+			c.evictQueue.push(item.key, item.value, cache.ReasonCleared)
+		}
+	}
+
+	c.items = make(map[string]*arcItem, 2*c.maxSize)
+	c.t1.init()
+	c.t2.init()
+	c.b1.init()
+	c.b2.init()
+	c.p = 0
+	c.bytes = 0
+
+	atomic.StoreInt64(&c.hits, 0)
+	atomic.StoreInt64(&c.misses, 0)
+	atomic.StoreInt64(&c.evictions, 0)
+}
+
+// Stats возвращает статистику кэша
+func (c *ARCCache) Stats() cache.Stats {
+	c.mu.RLock()
+	keys := int64(c.t1.len + c.t2.len)
+	bytes := c.bytes
+	c.mu.RUnlock()
+
+	stats := cache.Stats{
+		Hits:      atomic.LoadInt64(&c.hits),
+		Misses:    atomic.LoadInt64(&c.misses),
+		Keys:      keys,
+		Evictions: atomic.LoadInt64(&c.evictions),
+		Bytes:     bytes,
+		RawBytes:  bytes, // Значения хранятся без сжатия
+	}
+
+	stats.CalculateHitRate()
+	return stats
+}
+
+// Close корректно завершает работу кэша
+func (c *ARCCache) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.closed {
+		return nil
+	}
+
+	c.closed = true
+	close(c.stopCh)
+	return nil
+}
+
+// SetOnEvict устанавливает колбэк, вызываемый при вытеснении, истечении,
+// удалении и очистке элементов. nil отключает уведомления.
+func (c *ARCCache) SetOnEvict(fn cache.EvictCallback) {
+	c.mu.Lock()
+	c.evictQueue.onEvict = fn
+	c.mu.Unlock()
+}
+
+// unlock снимает блокировку на запись и вызывает колбэк для элементов,
+// удаленных пока она удерживалась
+func (c *ARCCache) unlock() {
+	onEvict, evicted := c.evictQueue.take()
+	c.mu.Unlock()
+	notifyEvicted(onEvict, evicted)
+}
+
+// replace вытесняет один хранимый элемент в соответствующий призрачный список.
+// Из T1 вытесняется если он больше целевого размера p (или равен ему при попадании в B2).
+func (c *ARCCache) replace(inB2 bool) {
+	if c.t1.len > 0 && (c.t1.len > c.p || (c.t1.len == c.p && inB2) || c.t2.len == 0) {
+		c.evictToGhost(c.t1.back(), &c.b1)
+		return
+	}
+	if c.t2.len > 0 {
+		c.evictToGhost(c.t2.back(), &c.b2)
+	}
+}
+
+// evictToGhost вытесняет значение элемента, оставляя его ключ в призрачном списке
+func (c *ARCCache) evictToGhost(item *arcItem, ghost *arcList) {
+	item.list.remove(item)
+	c.evictQueue.push(item.key, item.value, cache.ReasonCapacity)
+	c.bytes -= item.size()
+	item.value = nil
+	item.expiresAt = time.Time{}
+	ghost.pushFront(item)
+	atomic.AddInt64(&c.evictions, 1)
+}
+
+// removeGhost удаляет ключ из призрачного списка
+func (c *ARCCache) removeGhost(item *arcItem) {
+	if item == nil {
+		return
+	}
+	item.list.remove(item)
+	delete(c.items, item.key)
+}
+
+// removeItem полностью удаляет хранимый элемент из кэша
+func (c *ARCCache) removeItem(item *arcItem, reason cache.EvictionReason) {
+	item.list.remove(item)
+	delete(c.items, item.key)
+	c.bytes -= item.size()
+	c.evictQueue.push(item.key, item.value, reason)
+}
+
+// cleanup фоновая очистка истекших элементов
+func (c *ARCCache) cleanup() {
+	ticker := time.NewTicker(1 * time.Minute)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			c.removeExpired()
+		case <-c.stopCh:
+			return
+		}
+	}
+}
+
+// removeExpired удаляет все истекшие элементы
+func (c *ARCCache) removeExpired() {
+	c.mu.Lock()
+	defer c.unlock()
+
+	var expired []*arcItem
+	for _, item := range c.items {
+		if c.isResident(item) && item.isExpired(c.clock.Now()) {
+			expired = append(expired, item)
+		}
+	}
+
+	for _, item := range expired {
+		c.removeItem(item, cache.ReasonExpired)
+	}
+
+	if len(expired) > 0 {
+		atomic.AddInt64(&c.evictions, int64(len(expired)))
+	}
+}
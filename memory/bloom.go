@@ -0,0 +1,148 @@
+package memory
+
+import "github.com/VsRnA/High-Performance-HTTP-Cache/internal"
+
+// SetBloomFilter ставит перед картой счетный фильтр Блума на size ключей
+// с долей ложных срабатываний falsePositiveRate. Get отвечает промахом без
+// блокировки, если фильтр говорит, что ключа точно нет, - это ускоряет промахи
+// при низкой доле попаданий. Остальные операции чтения фильтр не используют.
+// Текущие ключи добавляются в фильтр сразу. При заполнении выше size доля ложных
+// срабатываний растет, но ложных промахов не бывает. size <= 0 отключает фильтр.
+func (c *LRUCache) SetBloomFilter(size int, falsePositiveRate float64) {
+	c.mu.Lock()
+	defer c.unlock()
+
+	if size <= 0 {
+		c.bloom.Store(nil)
+		return
+	}
+	filter := internal.NewCountingBloom(size, falsePositiveRate)
+	for key := range c.items {
+		filter.Add(key)
+	}
+	c.bloom.Store(filter)
+}
+
+// SetBloomFilter ставит фильтр Блума перед картой, см. LRUCache.SetBloomFilter
+func (c *LFUCache) SetBloomFilter(size int, falsePositiveRate float64) {
+	c.mu.Lock()
+	defer c.unlock()
+
+	if size <= 0 {
+		c.bloom.Store(nil)
+		return
+	}
+	filter := internal.NewCountingBloom(size, falsePositiveRate)
+	for key := range c.items {
+		filter.Add(key)
+	}
+	c.bloom.Store(filter)
+}
+
+// SetBloomFilter ставит фильтр Блума перед картой, см. LRUCache.SetBloomFilter
+func (c *SimpleCache) SetBloomFilter(size int, falsePositiveRate float64) {
+	c.mu.Lock()
+	defer c.unlock()
+
+	if size <= 0 {
+		c.bloom.Store(nil)
+		return
+	}
+	filter := internal.NewCountingBloom(size, falsePositiveRate)
+	for key := range c.items {
+		filter.Add(key)
+	}
+	c.bloom.Store(filter)
+}
+
+// SetBloomFilter ставит фильтр Блума в каждом шарде, size делится между шардами поровну
+func (c *ShardedCache) SetBloomFilter(size int, falsePositiveRate float64) {
+	perShard := 0
+	if size > 0 {
+		perShard = (size + len(c.shards) - 1) / len(c.shards)
+	}
+	for _, shard := range c.shards {
+		shard.SetBloomFilter(perShard, falsePositiveRate)
+	}
+}
+
+// bloomRejects возвращает true, если ключа точно нет в кэше. Вызывается без блокировки.
+func (c *LRUCache) bloomRejects(key string) bool {
+	filter := c.bloom.Load()
+	return filter != nil && !filter.MayContain(key)
+}
+
+// bloomAdd добавляет новый ключ в фильтр. Вызывается под mu.
+func (c *LRUCache) bloomAdd(key string) {
+	if filter := c.bloom.Load(); filter != nil {
+		filter.Add(key)
+	}
+}
+
+// bloomRemove удаляет ключ из фильтра. Вызывается под mu.
+func (c *LRUCache) bloomRemove(key string) {
+	if filter := c.bloom.Load(); filter != nil {
+		filter.Remove(key)
+	}
+}
+
+// bloomReset очищает фильтр. Вызывается под mu.
+func (c *LRUCache) bloomReset() {
+	if filter := c.bloom.Load(); filter != nil {
+		filter.Reset()
+	}
+}
+
+// bloomRejects возвращает true, если ключа точно нет в кэше. Вызывается без блокировки.
+func (c *LFUCache) bloomRejects(key string) bool {
+	filter := c.bloom.Load()
+	return filter != nil && !filter.MayContain(key)
+}
+
+// bloomAdd добавляет новый ключ в фильтр. Вызывается под mu.
+func (c *LFUCache) bloomAdd(key string) {
+	if filter := c.bloom.Load(); filter != nil {
+		filter.Add(key)
+	}
+}
+
+// bloomRemove удаляет ключ из фильтра. Вызывается под mu.
+func (c *LFUCache) bloomRemove(key string) {
+	if filter := c.bloom.Load(); filter != nil {
+		filter.Remove(key)
+	}
+}
+
+// bloomReset очищает фильтр. Вызывается под mu.
+func (c *LFUCache) bloomReset() {
+	if filter := c.bloom.Load(); filter != nil {
+		filter.Reset()
+	}
+}
+
+// bloomRejects возвращает true, если ключа точно нет в кэше. Вызывается без блокировки.
+func (c *SimpleCache) bloomRejects(key string) bool {
+	filter := c.bloom.Load()
+	return filter != nil && !filter.MayContain(key)
+}
+
+// bloomAdd добавляет новый ключ в фильтр. Вызывается под mu.
+func (c *SimpleCache) bloomAdd(key string) {
+	if filter := c.bloom.Load(); filter != nil {
+		filter.Add(key)
+	}
+}
+
+// bloomRemove удаляет ключ из фильтра. Вызывается под mu.
+func (c *SimpleCache) bloomRemove(key string) {
+	if filter := c.bloom.Load(); filter != nil {
+		filter.Remove(key)
+	}
+}
+
+// bloomReset очищает фильтр. Вызывается под mu.
+func (c *SimpleCache) bloomReset() {
+	if filter := c.bloom.Load(); filter != nil {
+		filter.Reset()
+	}
+}
@@ -0,0 +1,38 @@
+package memory
+
+import (
+	"sync/atomic"
+
+	cache "github.com/VsRnA/High-Performance-HTTP-Cache"
+)
+
+// NewSimpleBounded создает простой кэш, хранящий не больше maxSize элементов.
+// Запись нового ключа в заполненный кэш вытесняет произвольный элемент: без политики
+// вытеснения кэш не знает, какие ключи полезнее, зато вставка остается O(1).
+// Проверка и вставка выполняются под одной блокировкой, поэтому лимит соблюдается
+// и при конкурентной записи. Перезапись существующего ключа ничего не вытесняет.
+// maxSize <= 0 создает кэш без ограничения, как NewSimple.
+func NewSimpleBounded(maxSize int) cache.Cache {
+	c := NewSimpleWithTTL(0).(*SimpleCache)
+	c.maxSize = max(maxSize, 0)
+	return c
+}
+
+// evictIfFull вытесняет произвольный элемент, если новый ключ не помещается в maxSize.
+// Истекшие элементы не ищутся: полный обход сделал бы каждую запись O(n),
+// их по-прежнему удаляет фоновая очистка. Вызывается под mu.
+func (c *SimpleCache) evictIfFull() {
+	if c.maxSize <= 0 || len(c.items) < c.maxSize {
+		return
+	}
+
+	// Порядок обхода map случаен, поэтому первый элемент - случайная жертва
+	for _, item := range c.items {
+		c.removeItem(item, cache.ReasonCapacity)
+		atomic.AddInt64(&c.evictions, 1)
+		if m := c.metrics.Load(); m != nil {
+			m.RecordEviction()
+		}
+		return
+	}
+}
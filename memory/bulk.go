@@ -0,0 +1,263 @@
+package memory
+
+import (
+	"time"
+
+	cache "github.com/VsRnA/High-Performance-HTTP-Cache"
+)
+
+// MGet получает несколько значений за одну блокировку.
+// Возвращает только найденные неистекшие ключи, статистика обновляется для каждого ключа.
+func (c *LRUCache) MGet(keys []string) map[string][]byte {
+	c.mu.Lock()
+	defer c.unlock()
+
+	result := make(map[string][]byte, len(keys))
+	for _, key := range keys {
+		if value, exists := c.getLocked(key); exists {
+			result[key] = value
+		}
+	}
+	return result
+}
+
+// GetMulti получает несколько значений за одну блокировку и отдельно
+// возвращает промахи, чтобы загрузить из источника только их.
+// missing сохраняет порядок keys без повторов, значения в found - копии.
+func (c *LRUCache) GetMulti(keys []string) (found map[string][]byte, missing []string) {
+	found = c.MGet(keys)
+	return found, missingKeys(keys, found)
+}
+
+// MSet сохраняет несколько значений с одним TTL за одну блокировку.
+// Вытеснение выполняется для каждого вставляемого ключа.
+// При ошибке валидации любого элемента ничего не сохраняется.
+func (c *LRUCache) MSet(items map[string][]byte, ttl time.Duration) error {
+	for key, value := range items {
+		if err := c.validate(key, value); err != nil {
+			return err
+		}
+	}
+
+	c.mu.Lock()
+	defer c.unlock()
+
+	if c.closed {
+		return cache.ErrCacheClosed
+	}
+
+	for key, value := range items {
+		c.setLocked(key, value, ttl)
+	}
+	return nil
+}
+
+// MDelete удаляет несколько ключей за одну блокировку и возвращает количество удаленных
+func (c *LRUCache) MDelete(keys []string) int {
+	c.mu.Lock()
+	defer c.unlock()
+
+	deleted := 0
+	for _, key := range keys {
+		if c.deleteLocked(key) {
+			deleted++
+		}
+	}
+	return deleted
+}
+
+// MGet получает несколько значений за одну блокировку.
+// Возвращает только найденные неистекшие ключи, статистика обновляется для каждого ключа.
+func (c *LFUCache) MGet(keys []string) map[string][]byte {
+	c.mu.Lock()
+	defer c.unlock()
+
+	result := make(map[string][]byte, len(keys))
+	for _, key := range keys {
+		if value, exists := c.getLocked(key); exists {
+			result[key] = value
+		}
+	}
+	return result
+}
+
+// GetMulti получает несколько значений за одну блокировку и отдельно
+// возвращает промахи, чтобы загрузить из источника только их.
+// missing сохраняет порядок keys без повторов, значения в found - копии.
+func (c *LFUCache) GetMulti(keys []string) (found map[string][]byte, missing []string) {
+	found = c.MGet(keys)
+	return found, missingKeys(keys, found)
+}
+
+// MSet сохраняет несколько значений с одним TTL за одну блокировку.
+// Вытеснение выполняется для каждого вставляемого ключа.
+// При ошибке валидации любого элемента ничего не сохраняется.
+func (c *LFUCache) MSet(items map[string][]byte, ttl time.Duration) error {
+	for key, value := range items {
+		if err := c.validate(key, value); err != nil {
+			return err
+		}
+	}
+
+	c.mu.Lock()
+	defer c.unlock()
+
+	if c.closed {
+		return cache.ErrCacheClosed
+	}
+
+	for key, value := range items {
+		c.setLocked(key, value, ttl)
+	}
+	return nil
+}
+
+// MDelete удаляет несколько ключей за одну блокировку и возвращает количество удаленных
+func (c *LFUCache) MDelete(keys []string) int {
+	c.mu.Lock()
+	defer c.unlock()
+
+	deleted := 0
+	for _, key := range keys {
+		if c.deleteLocked(key) {
+			deleted++
+		}
+	}
+	return deleted
+}
+
+// MGet получает несколько значений за одну блокировку.
+// Возвращает только найденные неистекшие ключи, статистика обновляется для каждого ключа.
+func (c *SimpleCache) MGet(keys []string) map[string][]byte {
+	c.mu.Lock()
+	defer c.unlock()
+
+	result := make(map[string][]byte, len(keys))
+	for _, key := range keys {
+		if value, exists := c.getLocked(key); exists {
+			result[key] = value
+		}
+	}
+	return result
+}
+
+// GetMulti получает несколько значений за одну блокировку и отдельно
+// возвращает промахи, чтобы загрузить из источника только их.
+// missing сохраняет порядок keys без повторов, значения в found - копии.
+func (c *SimpleCache) GetMulti(keys []string) (found map[string][]byte, missing []string) {
+	found = c.MGet(keys)
+	return found, missingKeys(keys, found)
+}
+
+// MSet сохраняет несколько значений с одним TTL за одну блокировку.
+// При ошибке валидации любого элемента ничего не сохраняется.
+func (c *SimpleCache) MSet(items map[string][]byte, ttl time.Duration) error {
+	for key, value := range items {
+		if err := c.validate(key, value); err != nil {
+			return err
+		}
+	}
+
+	c.mu.Lock()
+	defer c.unlock()
+
+	if c.closed {
+		return cache.ErrCacheClosed
+	}
+
+	for key, value := range items {
+		c.setLocked(key, value, ttl)
+	}
+	return nil
+}
+
+// MDelete удаляет несколько ключей за одну блокировку и возвращает количество удаленных
+func (c *SimpleCache) MDelete(keys []string) int {
+	c.mu.Lock()
+	defer c.unlock()
+
+	deleted := 0
+	for _, key := range keys {
+		if c.deleteLocked(key) {
+			deleted++
+		}
+	}
+	return deleted
+}
+
+// MGet получает несколько значений, блокируя каждый затронутый шард один раз
+func (c *ShardedCache) MGet(keys []string) map[string][]byte {
+	result := make(map[string][]byte, len(keys))
+	for shard, shardKeys := range c.groupKeys(keys) {
+		for key, value := range shard.MGet(shardKeys) {
+			result[key] = value
+		}
+	}
+	return result
+}
+
+// GetMulti получает несколько значений, блокируя каждый затронутый шард один раз,
+// и отдельно возвращает промахи в порядке keys
+func (c *ShardedCache) GetMulti(keys []string) (found map[string][]byte, missing []string) {
+	found = c.MGet(keys)
+	return found, missingKeys(keys, found)
+}
+
+// MSet сохраняет несколько значений, блокируя каждый затронутый шард один раз.
+// Валидация выполняется до записи, поэтому при ошибке ничего не сохраняется.
+func (c *ShardedCache) MSet(items map[string][]byte, ttl time.Duration) error {
+	batches := make(map[*LRUCache]map[string][]byte)
+	for key, value := range items {
+		shard := c.shard(key)
+		if err := shard.validate(key, value); err != nil {
+			return err
+		}
+		if batches[shard] == nil {
+			batches[shard] = make(map[string][]byte)
+		}
+		batches[shard][key] = value
+	}
+
+	for shard, batch := range batches {
+		if err := shard.MSet(batch, ttl); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// MDelete удаляет несколько ключей, блокируя каждый затронутый шард один раз
+func (c *ShardedCache) MDelete(keys []string) int {
+	deleted := 0
+	for shard, shardKeys := range c.groupKeys(keys) {
+		deleted += shard.MDelete(shardKeys)
+	}
+	return deleted
+}
+
+// groupKeys группирует ключи по шардам
+func (c *ShardedCache) groupKeys(keys []string) map[*LRUCache][]string {
+	groups := make(map[*LRUCache][]string)
+	for _, key := range keys {
+		shard := c.shard(key)
+		groups[shard] = append(groups[shard], key)
+	}
+	return groups
+}
+
+// missingKeys возвращает ключи из keys, отсутствующие в found, в исходном порядке без повторов
+func missingKeys(keys []string, found map[string][]byte) []string {
+	var missing []string
+	seen := make(map[string]struct{})
+	for _, key := range keys {
+		if _, exists := found[key]; exists {
+			continue
+		}
+		if _, duplicate := seen[key]; duplicate {
+			continue
+		}
+		seen[key] = struct{}{}
+		missing = append(missing, key)
+	}
+	return missing
+}
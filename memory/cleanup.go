@@ -0,0 +1,77 @@
+package memory
+
+import "time"
+
+// defaultCleanupInterval - период фоновой очистки для кэшей, созданных с TTL по умолчанию
+const defaultCleanupInterval = time.Minute
+
+// SetCleanupInterval задает период фоновой очистки истекших элементов.
+// Очистка работает независимо от TTL по умолчанию, поэтому ее можно включить
+// для кэша, где TTL задается только отдельным ключам. 0 отключает очистку.
+func (c *LRUCache) SetCleanupInterval(interval time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if !c.closed {
+		c.cleanupStop = restartPeriodic(c.cleanupStop, interval, c.cleanup)
+		c.reaper.restart(interval)
+	}
+}
+
+// SetCleanupInterval задает период фоновой очистки истекших элементов.
+// Очистка работает независимо от TTL по умолчанию, 0 отключает ее.
+func (c *LFUCache) SetCleanupInterval(interval time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if !c.closed {
+		c.cleanupStop = restartPeriodic(c.cleanupStop, interval, c.cleanup)
+		c.reaper.restart(interval)
+	}
+}
+
+// SetCleanupInterval задает период фоновой очистки истекших элементов.
+// Очистка работает независимо от TTL по умолчанию, 0 отключает ее.
+func (c *SimpleCache) SetCleanupInterval(interval time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if !c.closed {
+		c.cleanupStop = restartPeriodic(c.cleanupStop, interval, c.cleanup)
+		c.reaper.restart(interval)
+	}
+}
+
+// SetCleanupInterval задает период фоновой очистки истекших элементов.
+// Очистка работает независимо от TTL по умолчанию, 0 отключает ее.
+func (c *FIFOCache) SetCleanupInterval(interval time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if !c.closed {
+		c.cleanupStop = restartPeriodic(c.cleanupStop, interval, c.cleanup)
+	}
+}
+
+// SetCleanupInterval задает период фоновой очистки во всех шардах
+func (c *ShardedCache) SetCleanupInterval(interval time.Duration) {
+	for _, shard := range c.shards {
+		shard.SetCleanupInterval(interval)
+	}
+}
+
+// restartPeriodic останавливает фоновую горутину, связанную со stop, и запускает run
+// в новой горутине с периодом interval. Возвращает канал остановки новой горутины
+// или nil, если interval <= 0 и горутина не нужна. Вызывается под mu.
+func restartPeriodic(stop chan struct{}, interval time.Duration, run func(time.Duration, <-chan struct{})) chan struct{} {
+	if stop != nil {
+		close(stop)
+	}
+	if interval <= 0 {
+		return nil
+	}
+
+	stop = make(chan struct{})
+	go run(interval, stop)
+	return stop
+}
@@ -0,0 +1,127 @@
+package memory
+
+import "time"
+
+// Clock - источник текущего времени для TTL, возраста и времени обращения элементов.
+// Подменяется в тестах, чтобы истечение наступало без ожидания реального времени.
+// Фоновые горутины по-прежнему запускаются по реальным таймерам.
+type Clock interface {
+	Now() time.Time
+}
+
+// realClock - системное время, используется по умолчанию
+type realClock struct{}
+
+func (realClock) Now() time.Time { return time.Now() }
+
+// WithClock задает источник времени вместо системного для конструкторов с опциями
+// и для New. nil оставляет системное время.
+func WithClock(clock Clock) Option {
+	return func(o *options) {
+		o.clock = clock
+	}
+}
+
+// setClock заменяет источник времени, вызывается до записи элементов
+func (c *LRUCache) setClock(clock Clock) {
+	c.mu.Lock()
+	c.clock = clock
+	c.mu.Unlock()
+}
+
+// setClock заменяет источник времени, вызывается до записи элементов
+func (c *LFUCache) setClock(clock Clock) {
+	c.mu.Lock()
+	c.clock = clock
+	c.mu.Unlock()
+}
+
+// setClock заменяет источник времени, вызывается до записи элементов
+func (c *SimpleCache) setClock(clock Clock) {
+	c.mu.Lock()
+	c.clock = clock
+	c.mu.Unlock()
+}
+
+// setClock заменяет источник времени, вызывается до записи элементов
+func (c *FIFOCache) setClock(clock Clock) {
+	c.mu.Lock()
+	c.clock = clock
+	c.mu.Unlock()
+}
+
+// setClock заменяет источник времени, вызывается до записи элементов
+func (c *RandomCache) setClock(clock Clock) {
+	c.mu.Lock()
+	c.clock = clock
+	c.mu.Unlock()
+}
+
+// setClock заменяет источник времени, вызывается до записи элементов
+func (c *ARCCache) setClock(clock Clock) {
+	c.mu.Lock()
+	c.clock = clock
+	c.mu.Unlock()
+}
+
+// setClock заменяет источник времени, вызывается до записи элементов
+func (c *TinyLFUCache) setClock(clock Clock) {
+	c.mu.Lock()
+	c.clock = clock
+	c.mu.Unlock()
+}
+
+// setClock заменяет источник времени, вызывается до записи элементов
+func (c *WeightedCache) setClock(clock Clock) {
+	c.mu.Lock()
+	c.clock = clock
+	c.mu.Unlock()
+}
+
+// setClock заменяет источник времени, вызывается до записи элементов
+func (c *ApproxLRUCache) setClock(clock Clock) {
+	c.mu.Lock()
+	c.clock = clock
+	c.mu.Unlock()
+}
+
+// setClock заменяет источник времени во всех шардах, вызывается до записи элементов
+func (c *ShardedCache) setClock(clock Clock) {
+	for _, shard := range c.shards {
+		shard.setClock(clock)
+	}
+}
+
+// setClock заменяет источник времени обоих уровней, вызывается до записи элементов
+func (t *TieredCache) setClock(clock Clock) {
+	t.mu.Lock()
+	t.clock = clock
+	t.mu.Unlock()
+	t.hot.setClock(clock)
+}
+
+// now возвращает текущее время по источнику времени кэша
+func (c *LRUCache) now() time.Time {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.clock.Now()
+}
+
+// now возвращает текущее время по источнику времени кэша
+func (c *LFUCache) now() time.Time {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.clock.Now()
+}
+
+// now возвращает текущее время по источнику времени кэша
+func (c *SimpleCache) now() time.Time {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.clock.Now()
+}
+
+// now возвращает текущее время по источнику времени шардов
+func (c *ShardedCache) now() time.Time {
+	return c.shards[0].now()
+}
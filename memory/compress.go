@@ -0,0 +1,78 @@
+package memory
+
+import (
+	"bytes"
+	"compress/flate"
+	"io"
+	"sync"
+)
+
+// flateWriters переиспользует компрессоры: каждый flate.Writer выделяет сотни килобайт
+var flateWriters = sync.Pool{
+	New: func() any {
+		w, _ := flate.NewWriter(nil, flate.DefaultCompression)
+		return w
+	},
+}
+
+// encodeValue подготавливает значение к хранению.
+// Значения длиннее threshold сжимаются, если это уменьшает их размер,
+// остальные копируются как есть. threshold <= 0 отключает сжатие.
+func encodeValue(value []byte, threshold int) (data []byte, compressed bool) {
+	if threshold > 0 && len(value) > threshold {
+		var buf bytes.Buffer
+		w := flateWriters.Get().(*flate.Writer)
+		w.Reset(&buf)
+		w.Write(value)
+		w.Close()
+		flateWriters.Put(w)
+
+		if buf.Len() < len(value) {
+			return buf.Bytes(), true
+		}
+	}
+
+	data = make([]byte, len(value))
+	copy(data, value)
+	return data, false
+}
+
+// decodeValue возвращает копию исходного значения, которой владеет вызывающий
+func decodeValue(data []byte, compressed bool) []byte {
+	if !compressed {
+		value := make([]byte, len(data))
+		copy(value, data)
+		return value
+	}
+
+	value, err := io.ReadAll(flate.NewReader(bytes.NewReader(data)))
+	if err != nil {
+		// Данные сжимает сам кэш, поэтому ошибка означает повреждение памяти
+		panic("memory: поврежденное сжатое значение: " + err.Error())
+	}
+	return value
+}
+
+// rawValue возвращает исходное значение. Несжатые данные возвращаются без копирования,
+// поэтому результат нельзя изменять.
+func rawValue(data []byte, compressed bool) []byte {
+	if !compressed {
+		return data
+	}
+	return decodeValue(data, true)
+}
+
+// inlineValueSize - наибольшая длина значения, которое хранится во встроенном
+// буфере элемента без отдельного выделения памяти
+const inlineValueSize = 24
+
+// storeValue кодирует значение как encodeValue, но короткое несжимаемое значение
+// копирует во встроенный буфер inline. Буфер нельзя изменять после записи:
+// на него могут ссылаться срезы, выданные GetRef.
+func storeValue(value []byte, threshold int, inline *[inlineValueSize]byte) (data []byte, compressed bool) {
+	if len(value) <= inlineValueSize && (threshold <= 0 || len(value) <= threshold) {
+		n := copy(inline[:], value)
+		return inline[:n:n], false
+	}
+	return encodeValue(value, threshold)
+}
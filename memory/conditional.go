@@ -0,0 +1,252 @@
+package memory
+
+import (
+	"sync/atomic"
+	"time"
+
+	cache "github.com/VsRnA/High-Performance-HTTP-Cache"
+)
+
+// SetNX сохраняет значение, только если ключ отсутствует или истек, и возвращает true.
+// Если ключ существует, значение не перезаписывается и возвращается false.
+// Проверка и запись выполняются под одной блокировкой, поэтому из одновременных
+// вызовов для одного ключа успешен только один.
+func (c *LRUCache) SetNX(key string, value []byte, ttl time.Duration) (bool, error) {
+	if err := c.validate(key, value); err != nil {
+		return false, err
+	}
+
+	c.mu.Lock()
+	defer c.unlock()
+
+	if c.closed {
+		return false, cache.ErrCacheClosed
+	}
+
+	if item, exists := c.items[key]; exists && !item.isExpired(c.clock.Now()) && !item.negative {
+		return false, nil
+	}
+
+	c.setLocked(key, value, ttl)
+	return true, nil
+}
+
+// SetNX сохраняет значение, только если ключ отсутствует или истек, и возвращает true.
+// Если ключ существует, значение не перезаписывается и возвращается false.
+// Проверка и запись выполняются под одной блокировкой, поэтому из одновременных
+// вызовов для одного ключа успешен только один.
+func (c *LFUCache) SetNX(key string, value []byte, ttl time.Duration) (bool, error) {
+	if err := c.validate(key, value); err != nil {
+		return false, err
+	}
+
+	c.mu.Lock()
+	defer c.unlock()
+
+	if c.closed {
+		return false, cache.ErrCacheClosed
+	}
+
+	if item, exists := c.items[key]; exists && !item.isExpired(c.clock.Now()) && !item.negative {
+		return false, nil
+	}
+
+	c.setLocked(key, value, ttl)
+	return true, nil
+}
+
+// SetNX сохраняет значение, только если ключ отсутствует или истек, и возвращает true.
+// Если ключ существует, значение не перезаписывается и возвращается false.
+// Проверка и запись выполняются под одной блокировкой, поэтому из одновременных
+// вызовов для одного ключа успешен только один.
+func (c *SimpleCache) SetNX(key string, value []byte, ttl time.Duration) (bool, error) {
+	if err := c.validate(key, value); err != nil {
+		return false, err
+	}
+
+	c.mu.Lock()
+	defer c.unlock()
+
+	if c.closed {
+		return false, cache.ErrCacheClosed
+	}
+
+	if item, exists := c.items[key]; exists && !item.isExpired(c.clock.Now()) && !item.negative {
+		return false, nil
+	}
+
+	c.setLocked(key, value, ttl)
+	return true, nil
+}
+
+// GetSet сохраняет новое значение и возвращает предыдущее под одной блокировкой.
+// exists = false если ключ отсутствовал или истек. Чтение учитывается в статистике как Get.
+func (c *LRUCache) GetSet(key string, value []byte, ttl time.Duration) (old []byte, exists bool, err error) {
+	if err := c.validate(key, value); err != nil {
+		return nil, false, err
+	}
+
+	c.mu.Lock()
+	defer c.unlock()
+
+	if c.closed {
+		return nil, false, cache.ErrCacheClosed
+	}
+
+	if item, found := c.items[key]; found && !item.isExpired(c.clock.Now()) && !item.negative {
+		old, exists = decodeValue(item.value, item.compressed), true
+		atomic.AddInt64(&c.hits, 1)
+	} else {
+		atomic.AddInt64(&c.misses, 1)
+	}
+
+	// Существующий узел переиспользуется и перемещается в начало списка
+	c.setLocked(key, value, ttl)
+	return old, exists, nil
+}
+
+// GetSet сохраняет новое значение и возвращает предыдущее под одной блокировкой.
+// exists = false если ключ отсутствовал или истек. Чтение учитывается в статистике как Get.
+func (c *LFUCache) GetSet(key string, value []byte, ttl time.Duration) (old []byte, exists bool, err error) {
+	if err := c.validate(key, value); err != nil {
+		return nil, false, err
+	}
+
+	c.mu.Lock()
+	defer c.unlock()
+
+	if c.closed {
+		return nil, false, cache.ErrCacheClosed
+	}
+
+	if item, found := c.items[key]; found && !item.isExpired(c.clock.Now()) && !item.negative {
+		old, exists = decodeValue(item.value, item.compressed), true
+		atomic.AddInt64(&c.hits, 1)
+	} else {
+		atomic.AddInt64(&c.misses, 1)
+	}
+
+	c.setLocked(key, value, ttl)
+	return old, exists, nil
+}
+
+// GetSet сохраняет новое значение и возвращает предыдущее под одной блокировкой.
+// exists = false если ключ отсутствовал или истек. Чтение учитывается в статистике как Get.
+func (c *SimpleCache) GetSet(key string, value []byte, ttl time.Duration) (old []byte, exists bool, err error) {
+	if err := c.validate(key, value); err != nil {
+		return nil, false, err
+	}
+
+	c.mu.Lock()
+	defer c.unlock()
+
+	if c.closed {
+		return nil, false, cache.ErrCacheClosed
+	}
+
+	if item, found := c.items[key]; found && !item.isExpired(c.clock.Now()) && !item.negative {
+		old, exists = decodeValue(item.value, item.compressed), true
+		atomic.AddInt64(&c.hits, 1)
+	} else {
+		atomic.AddInt64(&c.misses, 1)
+	}
+
+	c.setLocked(key, value, ttl)
+	return old, exists, nil
+}
+
+// SetIf сохраняет значение, только если cond вернул true, и возвращает true.
+// cond получает копию текущего значения или nil, если ключ отсутствует или истек.
+// Проверка и запись выполняются под одной блокировкой, поэтому SetIf обобщает
+// SetNX и сравнение с обменом, например запись только более новой версии.
+// cond вызывается под блокировкой и не должен обращаться к кэшу.
+func (c *LRUCache) SetIf(key string, value []byte, ttl time.Duration, cond func(old []byte) bool) (bool, error) {
+	if err := c.validate(key, value); err != nil {
+		return false, err
+	}
+
+	c.mu.Lock()
+	defer c.unlock()
+
+	if c.closed {
+		return false, cache.ErrCacheClosed
+	}
+
+	var old []byte
+	if item, exists := c.items[key]; exists && !item.isExpired(c.clock.Now()) && !item.negative {
+		old = decodeValue(item.value, item.compressed)
+	}
+	if !cond(old) {
+		return false, nil
+	}
+
+	c.setLocked(key, value, ttl)
+	return true, nil
+}
+
+// SetIf сохраняет значение, только если cond вернул true для текущего значения,
+// см. LRUCache.SetIf
+func (c *LFUCache) SetIf(key string, value []byte, ttl time.Duration, cond func(old []byte) bool) (bool, error) {
+	if err := c.validate(key, value); err != nil {
+		return false, err
+	}
+
+	c.mu.Lock()
+	defer c.unlock()
+
+	if c.closed {
+		return false, cache.ErrCacheClosed
+	}
+
+	var old []byte
+	if item, exists := c.items[key]; exists && !item.isExpired(c.clock.Now()) && !item.negative {
+		old = decodeValue(item.value, item.compressed)
+	}
+	if !cond(old) {
+		return false, nil
+	}
+
+	c.setLocked(key, value, ttl)
+	return true, nil
+}
+
+// SetIf сохраняет значение, только если cond вернул true для текущего значения,
+// см. LRUCache.SetIf
+func (c *SimpleCache) SetIf(key string, value []byte, ttl time.Duration, cond func(old []byte) bool) (bool, error) {
+	if err := c.validate(key, value); err != nil {
+		return false, err
+	}
+
+	c.mu.Lock()
+	defer c.unlock()
+
+	if c.closed {
+		return false, cache.ErrCacheClosed
+	}
+
+	var old []byte
+	if item, exists := c.items[key]; exists && !item.isExpired(c.clock.Now()) && !item.negative {
+		old = decodeValue(item.value, item.compressed)
+	}
+	if !cond(old) {
+		return false, nil
+	}
+
+	c.setLocked(key, value, ttl)
+	return true, nil
+}
+
+// SetNX сохраняет значение в шарде ключа, только если ключ отсутствует или истек
+func (c *ShardedCache) SetNX(key string, value []byte, ttl time.Duration) (bool, error) {
+	return c.shard(key).SetNX(key, value, ttl)
+}
+
+// GetSet сохраняет новое значение в шарде ключа и возвращает предыдущее
+func (c *ShardedCache) GetSet(key string, value []byte, ttl time.Duration) ([]byte, bool, error) {
+	return c.shard(key).GetSet(key, value, ttl)
+}
+
+// SetIf сохраняет значение в шарде ключа, только если cond вернул true для текущего значения
+func (c *ShardedCache) SetIf(key string, value []byte, ttl time.Duration, cond func(old []byte) bool) (bool, error) {
+	return c.shard(key).SetIf(key, value, ttl, cond)
+}
@@ -0,0 +1,51 @@
+package memory
+
+import (
+	"fmt"
+	"time"
+
+	cache "github.com/VsRnA/High-Performance-HTTP-Cache"
+)
+
+// configuredCache - кэш, который New настраивает по cache.Config
+type configuredCache interface {
+	cache.Cache
+	SetCleanupInterval(interval time.Duration)
+	SetTTLBounds(minTTL, maxTTL time.Duration)
+	setClock(clock Clock)
+}
+
+// New создает кэш по настройкам config: EvictionPolicy выбирает LRU, LFU или FIFO кэш
+// с лимитом MaxSize и TTL по умолчанию DefaultTTL. CleanupInterval задает период
+// фоновой очистки, 0 оставляет период по умолчанию: раз в минуту при DefaultTTL,
+// иначе без очистки. MinTTL и MaxTTL ограничивают явно запрошенный TTL.
+// Конфигурация проверяется через Validate, ошибка оборачивает cache.ErrInvalidConfig.
+// Из opts учитывается только WithClock: остальные настройки задаются полями config.
+func New(config cache.Config, opts ...Option) (cache.Cache, error) {
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+
+	var c configuredCache
+	switch config.EvictionPolicy {
+	case cache.LRU:
+		c = NewLRUWithTTL(config.MaxSize, config.DefaultTTL).(*LRUCache)
+	case cache.LFU:
+		c = NewLFUWithTTL(config.MaxSize, config.DefaultTTL).(*LFUCache)
+	case cache.FIFO:
+		c = NewFIFOWithTTL(config.MaxSize, config.DefaultTTL).(*FIFOCache)
+	default:
+		return nil, fmt.Errorf("%w: политика %s не поддерживается", cache.ErrInvalidConfig, config.EvictionPolicy)
+	}
+
+	if o := newOptions(opts); o.clock != nil {
+		c.setClock(o.clock)
+	}
+	if config.CleanupInterval > 0 {
+		c.SetCleanupInterval(config.CleanupInterval)
+	}
+	if config.MinTTL > 0 || config.MaxTTL > 0 {
+		c.SetTTLBounds(config.MinTTL, config.MaxTTL)
+	}
+	return c, nil
+}
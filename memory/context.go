@@ -0,0 +1,176 @@
+package memory
+
+import (
+	"context"
+	"time"
+
+	cache "github.com/VsRnA/High-Performance-HTTP-Cache"
+)
+
+// GetContext получает значение по ключу, если ctx еще не отменен.
+// Вызов с отмененным контекстом считается промахом и не влияет на статистику.
+func (c *LRUCache) GetContext(ctx context.Context, key string) ([]byte, bool) {
+	if ctx.Err() != nil {
+		return nil, false
+	}
+	return c.Get(key)
+}
+
+// SetContext сохраняет значение с указанным TTL, если ctx еще не отменен
+func (c *LRUCache) SetContext(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return c.SetWithTTL(key, value, ttl)
+}
+
+// GetOrSetContext работает как GetOrSet, но при отмене ctx перестает ждать загрузку
+// и возвращает ctx.Err(). Начатая загрузка завершается в фоне и сохраняет значение в кэш.
+func (c *LRUCache) GetOrSetContext(ctx context.Context, key string, loader func() ([]byte, error), ttl time.Duration) ([]byte, error) {
+	if key == "" {
+		return nil, cache.ErrKeyEmpty
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	if value, exists := c.Get(key); exists {
+		return value, nil
+	}
+
+	shared, err := c.loads.DoContext(ctx, key, func() ([]byte, error) {
+		value, err := loader()
+		if err != nil {
+			return nil, err
+		}
+		if err := c.SetWithTTL(key, value, ttl); err != nil {
+			return nil, err
+		}
+		return value, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Результат общий для всех ожидающих, поэтому каждый получает свою копию
+	value := make([]byte, len(shared))
+	copy(value, shared)
+	return value, nil
+}
+
+// GetContext получает значение по ключу, если ctx еще не отменен.
+// Вызов с отмененным контекстом считается промахом и не влияет на статистику.
+func (c *LFUCache) GetContext(ctx context.Context, key string) ([]byte, bool) {
+	if ctx.Err() != nil {
+		return nil, false
+	}
+	return c.Get(key)
+}
+
+// SetContext сохраняет значение с указанным TTL, если ctx еще не отменен
+func (c *LFUCache) SetContext(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return c.SetWithTTL(key, value, ttl)
+}
+
+// GetOrSetContext работает как GetOrSet, но при отмене ctx перестает ждать загрузку
+// и возвращает ctx.Err(). Начатая загрузка завершается в фоне и сохраняет значение в кэш.
+func (c *LFUCache) GetOrSetContext(ctx context.Context, key string, loader func() ([]byte, error), ttl time.Duration) ([]byte, error) {
+	if key == "" {
+		return nil, cache.ErrKeyEmpty
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	if value, exists := c.Get(key); exists {
+		return value, nil
+	}
+
+	shared, err := c.loads.DoContext(ctx, key, func() ([]byte, error) {
+		value, err := loader()
+		if err != nil {
+			return nil, err
+		}
+		if err := c.SetWithTTL(key, value, ttl); err != nil {
+			return nil, err
+		}
+		return value, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Результат общий для всех ожидающих, поэтому каждый получает свою копию
+	value := make([]byte, len(shared))
+	copy(value, shared)
+	return value, nil
+}
+
+// GetContext получает значение по ключу, если ctx еще не отменен.
+// Вызов с отмененным контекстом считается промахом и не влияет на статистику.
+func (c *SimpleCache) GetContext(ctx context.Context, key string) ([]byte, bool) {
+	if ctx.Err() != nil {
+		return nil, false
+	}
+	return c.Get(key)
+}
+
+// SetContext сохраняет значение с указанным TTL, если ctx еще не отменен
+func (c *SimpleCache) SetContext(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return c.SetWithTTL(key, value, ttl)
+}
+
+// GetOrSetContext работает как GetOrSet, но при отмене ctx перестает ждать загрузку
+// и возвращает ctx.Err(). Начатая загрузка завершается в фоне и сохраняет значение в кэш.
+func (c *SimpleCache) GetOrSetContext(ctx context.Context, key string, loader func() ([]byte, error), ttl time.Duration) ([]byte, error) {
+	if key == "" {
+		return nil, cache.ErrKeyEmpty
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	if value, exists := c.Get(key); exists {
+		return value, nil
+	}
+
+	shared, err := c.loads.DoContext(ctx, key, func() ([]byte, error) {
+		value, err := loader()
+		if err != nil {
+			return nil, err
+		}
+		if err := c.SetWithTTL(key, value, ttl); err != nil {
+			return nil, err
+		}
+		return value, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Результат общий для всех ожидающих, поэтому каждый получает свою копию
+	value := make([]byte, len(shared))
+	copy(value, shared)
+	return value, nil
+}
+
+// GetContext получает значение по ключу, если ctx еще не отменен
+func (c *ShardedCache) GetContext(ctx context.Context, key string) ([]byte, bool) {
+	return c.shard(key).GetContext(ctx, key)
+}
+
+// SetContext сохраняет значение с указанным TTL, если ctx еще не отменен
+func (c *ShardedCache) SetContext(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	return c.shard(key).SetContext(ctx, key, value, ttl)
+}
+
+// GetOrSetContext работает как GetOrSet, но при отмене ctx перестает ждать загрузку
+func (c *ShardedCache) GetOrSetContext(ctx context.Context, key string, loader func() ([]byte, error), ttl time.Duration) ([]byte, error) {
+	return c.shard(key).GetOrSetContext(ctx, key, loader, ttl)
+}
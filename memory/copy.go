@@ -0,0 +1,76 @@
+package memory
+
+// Copy возвращает копию всех неистекших элементов, снятую под одной блокировкой.
+// Значения копируются, поэтому изменение результата не влияет на кэш.
+// Порядок LRU не сохраняется. В отличие от SaveSnapshot результат остается в памяти
+// и подходит для сравнения состояний или заполнения другого кэша.
+func (c *LRUCache) Copy() map[string][]byte {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.copyLocked()
+}
+
+// Copy возвращает копию всех неистекших элементов, снятую под одной блокировкой.
+// Значения копируются, частоты обращений не сохраняются.
+func (c *LFUCache) Copy() map[string][]byte {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.copyLocked()
+}
+
+// Copy возвращает копию всех неистекших элементов, снятую под одной блокировкой.
+// Значения копируются, поэтому изменение результата не влияет на кэш.
+func (c *SimpleCache) Copy() map[string][]byte {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.copyLocked()
+}
+
+// Copy возвращает копию всех неистекших элементов. Каждый шард копируется
+// под своей блокировкой, поэтому копия согласована в пределах шарда,
+// но не между шардами.
+func (c *ShardedCache) Copy() map[string][]byte {
+	items := make(map[string][]byte)
+	for _, shard := range c.shards {
+		for key, value := range shard.Copy() {
+			items[key] = value
+		}
+	}
+	return items
+}
+
+// copyLocked копирует неистекшие элементы. Вызывается под mu.
+func (c *LRUCache) copyLocked() map[string][]byte {
+	items := make(map[string][]byte, len(c.items))
+	for key, item := range c.items {
+		if !item.isExpired(c.clock.Now()) && !item.negative {
+			items[key] = decodeValue(item.value, item.compressed)
+		}
+	}
+	return items
+}
+
+// copyLocked копирует неистекшие элементы. Вызывается под mu.
+func (c *LFUCache) copyLocked() map[string][]byte {
+	items := make(map[string][]byte, len(c.items))
+	for key, item := range c.items {
+		if !item.isExpired(c.clock.Now()) && !item.negative {
+			items[key] = decodeValue(item.value, item.compressed)
+		}
+	}
+	return items
+}
+
+// copyLocked копирует неистекшие элементы. Вызывается под mu.
+func (c *SimpleCache) copyLocked() map[string][]byte {
+	items := make(map[string][]byte, len(c.items))
+	for key, item := range c.items {
+		if !item.isExpired(c.clock.Now()) && !item.negative {
+			items[key] = decodeValue(item.value, item.compressed)
+		}
+	}
+	return items
+}
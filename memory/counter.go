@@ -0,0 +1,215 @@
+package memory
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"sync/atomic"
+
+	cache "github.com/VsRnA/High-Performance-HTTP-Cache"
+)
+
+// parseCounter разбирает значение счетчика в десятичном формате
+func parseCounter(value []byte) (int64, error) {
+	n, err := strconv.ParseInt(string(value), 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %w", cache.ErrNotANumber, err)
+	}
+	return n, nil
+}
+
+// addCounter складывает значение счетчика с delta, возвращая ErrOverflow
+// вместо переполнения int64
+func addCounter(current, delta int64) (int64, error) {
+	if (delta > 0 && current > math.MaxInt64-delta) || (delta < 0 && current < math.MinInt64-delta) {
+		return 0, cache.ErrOverflow
+	}
+	return current + delta, nil
+}
+
+// negateDelta меняет знак delta для Decrement. math.MinInt64 не имеет
+// положительной пары в int64, поэтому такой декремент отклоняется, как в Redis.
+func negateDelta(delta int64) (int64, error) {
+	if delta == math.MinInt64 {
+		return 0, cache.ErrOverflow
+	}
+	return -delta, nil
+}
+
+// Increment атомарно увеличивает целочисленное значение ключа на delta и возвращает результат.
+// Отсутствующий ключ считается равным 0 и создается с TTL по умолчанию,
+// у существующего ключа TTL сохраняется. При переполнении int64 значение
+// не меняется и возвращается ErrOverflow.
+func (c *LRUCache) Increment(key string, delta int64) (int64, error) {
+	if key == "" {
+		return 0, cache.ErrKeyEmpty
+	}
+
+	c.mu.Lock()
+	defer c.unlock()
+
+	if c.closed {
+		return 0, cache.ErrCacheClosed
+	}
+
+	item, exists := c.items[key]
+	if !exists || item.isExpired(c.clock.Now()) {
+		c.setLocked(key, strconv.AppendInt(nil, delta, 10), 0)
+		return delta, nil
+	}
+
+	current, err := parseCounter(rawValue(item.value, item.compressed))
+	if err != nil {
+		return 0, err
+	}
+
+	result, err := addCounter(current, delta)
+	if err != nil {
+		return 0, err
+	}
+	value := strconv.AppendInt(nil, result, 10)
+	size := int64(len(key) + len(value))
+	atomic.AddInt64(&c.bytes, size-item.size)
+	atomic.AddInt64(&c.rawBytes, size-item.rawSize)
+	item.value = value
+	item.compressed = false
+	item.size = size
+	item.rawSize = size
+	c.moveToHead(item)
+	c.events.publish(cache.EventSet, key)
+	c.evictOverBytes()
+	c.signalTrim()
+
+	return result, nil
+}
+
+// Decrement атомарно уменьшает целочисленное значение ключа на delta и возвращает результат
+func (c *LRUCache) Decrement(key string, delta int64) (int64, error) {
+	delta, err := negateDelta(delta)
+	if err != nil {
+		return 0, err
+	}
+	return c.Increment(key, delta)
+}
+
+// Increment атомарно увеличивает целочисленное значение ключа на delta и возвращает результат.
+// Отсутствующий ключ считается равным 0 и создается с TTL по умолчанию,
+// у существующего ключа TTL сохраняется. При переполнении int64 значение
+// не меняется и возвращается ErrOverflow.
+func (c *LFUCache) Increment(key string, delta int64) (int64, error) {
+	if key == "" {
+		return 0, cache.ErrKeyEmpty
+	}
+
+	c.mu.Lock()
+	defer c.unlock()
+
+	if c.closed {
+		return 0, cache.ErrCacheClosed
+	}
+
+	item, exists := c.items[key]
+	if !exists || item.isExpired(c.clock.Now()) {
+		c.setLocked(key, strconv.AppendInt(nil, delta, 10), 0)
+		return delta, nil
+	}
+
+	current, err := parseCounter(rawValue(item.value, item.compressed))
+	if err != nil {
+		return 0, err
+	}
+
+	result, err := addCounter(current, delta)
+	if err != nil {
+		return 0, err
+	}
+	value := strconv.AppendInt(nil, result, 10)
+	size := int64(len(key) + len(value))
+	atomic.AddInt64(&c.bytes, size-item.size())
+	atomic.AddInt64(&c.rawBytes, size-item.rawSize)
+	item.value = value
+	item.compressed = false
+	item.rawSize = size
+	c.events.publish(cache.EventSet, key)
+	return result, nil
+}
+
+// Decrement атомарно уменьшает целочисленное значение ключа на delta и возвращает результат
+func (c *LFUCache) Decrement(key string, delta int64) (int64, error) {
+	delta, err := negateDelta(delta)
+	if err != nil {
+		return 0, err
+	}
+	return c.Increment(key, delta)
+}
+
+// Increment атомарно увеличивает целочисленное значение ключа на delta и возвращает результат.
+// Отсутствующий ключ считается равным 0 и создается с TTL по умолчанию,
+// у существующего ключа TTL сохраняется. При переполнении int64 значение
+// не меняется и возвращается ErrOverflow.
+func (c *SimpleCache) Increment(key string, delta int64) (int64, error) {
+	if key == "" {
+		return 0, cache.ErrKeyEmpty
+	}
+
+	c.mu.Lock()
+	defer c.unlock()
+
+	if c.closed {
+		return 0, cache.ErrCacheClosed
+	}
+
+	item, exists := c.items[key]
+	if !exists || item.isExpired(c.clock.Now()) {
+		c.setLocked(key, strconv.AppendInt(nil, delta, 10), 0)
+		return delta, nil
+	}
+
+	current, err := parseCounter(rawValue(item.value, item.compressed))
+	if err != nil {
+		return 0, err
+	}
+
+	result, err := addCounter(current, delta)
+	if err != nil {
+		return 0, err
+	}
+	value := strconv.AppendInt(nil, result, 10)
+	size := int64(len(key) + len(value))
+	atomic.AddInt64(&c.bytes, size-item.size())
+	atomic.AddInt64(&c.rawBytes, size-item.rawSize)
+
+	// Get читает элемент после снятия блокировки, поэтому элемент заменяется, а не изменяется
+	c.items[key] = &simpleItem{
+		key:        key,
+		value:      value,
+		expiresAt:  item.expiresAt,
+		createdAt:  item.createdAt,
+		ttl:        item.ttl,
+		rawSize:    size,
+		tags:       item.tags,
+		hits:       item.hits,
+		lastAccess: item.lastAccess,
+	}
+	c.events.publish(cache.EventSet, key)
+	return result, nil
+}
+
+// Decrement атомарно уменьшает целочисленное значение ключа на delta и возвращает результат
+func (c *SimpleCache) Decrement(key string, delta int64) (int64, error) {
+	delta, err := negateDelta(delta)
+	if err != nil {
+		return 0, err
+	}
+	return c.Increment(key, delta)
+}
+
+// Increment атомарно увеличивает целочисленное значение ключа на delta и возвращает результат
+func (c *ShardedCache) Increment(key string, delta int64) (int64, error) {
+	return c.shard(key).Increment(key, delta)
+}
+
+// Decrement атомарно уменьшает целочисленное значение ключа на delta и возвращает результат
+func (c *ShardedCache) Decrement(key string, delta int64) (int64, error) {
+	return c.shard(key).Decrement(key, delta)
+}
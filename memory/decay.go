@@ -0,0 +1,66 @@
+package memory
+
+import (
+	"sort"
+	"time"
+)
+
+// SetFrequencyDecay включает старение частот: раз в interval частота каждого элемента
+// уменьшается вдвое, но не ниже 1. Без старения ключ, бывший популярным давно, сохраняет высокую
+// частоту и не вытесняется, даже если к нему больше не обращаются.
+// 0 отключает старение.
+func (c *LFUCache) SetFrequencyDecay(interval time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if !c.closed {
+		c.decayStop = restartPeriodic(c.decayStop, interval, c.decay)
+	}
+}
+
+// decay периодически уменьшает частоты до закрытия stop или кэша
+func (c *LFUCache) decay(interval time.Duration, stop <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			c.decayFrequencies()
+		case <-stop:
+			return
+		case <-c.stopCh:
+			return
+		}
+	}
+}
+
+// decayFrequencies уменьшает вдвое частоту каждого элемента и перестраивает корзины.
+// Частота не опускается ниже 1: элемент в кэше был записан хотя бы один раз.
+// Порядок вытеснения среди элементов с равной новой частотой определяется давностью обращения.
+func (c *LFUCache) decayFrequencies() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	items := make([]*lfuItem, 0, len(c.items))
+	for _, item := range c.items {
+		item.frequency = max(item.frequency/2, 1)
+		items = append(items, item)
+	}
+	sort.Slice(items, func(i, j int) bool {
+		if items[i].frequency != items[j].frequency {
+			return items[i].frequency < items[j].frequency
+		}
+		return items[i].lastAccess.Before(items[j].lastAccess)
+	})
+
+	// Элементы идут по возрастанию частоты, поэтому нужная корзина - последняя или новая после нее
+	c.buckets.init()
+	for _, item := range items {
+		last := c.buckets.root.prev
+		if last == &c.buckets.root || last.frequency != item.frequency {
+			last = c.buckets.bucketAfter(last, item.frequency)
+		}
+		last.pushFront(item)
+	}
+}
@@ -0,0 +1,106 @@
+package memory
+
+import (
+	"sync"
+	"sync/atomic"
+
+	cache "github.com/VsRnA/High-Performance-HTTP-Cache"
+)
+
+// eventBufferSize - емкость канала каждого подписчика
+const eventBufferSize = 1024
+
+// eventHub рассылает события изменений подписчикам. Отправка не блокируется:
+// если буфер подписчика заполнен, событие для него отбрасывается.
+type eventHub struct {
+	mu          sync.RWMutex
+	subscribers map[chan cache.Event]struct{}
+	count       atomic.Int32 // Количество подписчиков для быстрого пропуска publish
+}
+
+// newEventHub создает пустой рассыльщик событий
+func newEventHub() *eventHub {
+	return &eventHub{subscribers: make(map[chan cache.Event]struct{})}
+}
+
+// subscribe регистрирует подписчика и возвращает его канал и функцию отписки.
+// Отписка закрывает канал, повторный вызов ничего не делает.
+func (h *eventHub) subscribe() (<-chan cache.Event, func()) {
+	ch := make(chan cache.Event, eventBufferSize)
+
+	h.mu.Lock()
+	h.subscribers[ch] = struct{}{}
+	h.count.Add(1)
+	h.mu.Unlock()
+
+	var once sync.Once
+	return ch, func() {
+		once.Do(func() {
+			h.mu.Lock()
+			delete(h.subscribers, ch)
+			h.count.Add(-1)
+			close(ch)
+			h.mu.Unlock()
+		})
+	}
+}
+
+// publish отправляет событие всем подписчикам без ожидания
+func (h *eventHub) publish(eventType cache.EventType, key string) {
+	if h.count.Load() == 0 {
+		return
+	}
+
+	event := cache.Event{Type: eventType, Key: key}
+	h.mu.RLock()
+	for ch := range h.subscribers {
+		select {
+		case ch <- event:
+		default:
+		}
+	}
+	h.mu.RUnlock()
+}
+
+// publishRemoved отправляет событие об удалении элемента по причине из колбэка OnEvict
+func (h *eventHub) publishRemoved(key string, reason cache.EvictionReason) {
+	switch reason {
+	case cache.ReasonCapacity:
+		h.publish(cache.EventEvict, key)
+	case cache.ReasonExpired:
+		h.publish(cache.EventExpire, key)
+	case cache.ReasonDeleted:
+		h.publish(cache.EventDelete, key)
+	}
+}
+
+// Subscribe возвращает канал событий об изменениях кэша и функцию отписки.
+// События отправляются под блокировкой кэша без ожидания: канал буферизован на
+// eventBufferSize событий, и при заполненном буфере новые события для этого
+// подписчика отбрасываются. Медленный подписчик не замедляет кэш, но может
+// пропустить изменения; если это недопустимо, после переполнения нужно
+// перечитать состояние через Copy. Отписка закрывает канал.
+func (c *LRUCache) Subscribe() (<-chan cache.Event, func()) {
+	return c.events.subscribe()
+}
+
+// Subscribe возвращает канал событий об изменениях кэша и функцию отписки.
+// Доставка неблокирующая, при заполненном буфере события отбрасываются,
+// как описано у LRUCache.Subscribe.
+func (c *LFUCache) Subscribe() (<-chan cache.Event, func()) {
+	return c.events.subscribe()
+}
+
+// Subscribe возвращает канал событий об изменениях кэша и функцию отписки.
+// Доставка неблокирующая, при заполненном буфере события отбрасываются,
+// как описано у LRUCache.Subscribe.
+func (c *SimpleCache) Subscribe() (<-chan cache.Event, func()) {
+	return c.events.subscribe()
+}
+
+// Subscribe возвращает канал событий всех шардов и функцию отписки.
+// Шарды используют общий рассыльщик, поэтому события приходят в одном канале.
+// Порядок событий гарантируется только в пределах одного ключа.
+func (c *ShardedCache) Subscribe() (<-chan cache.Event, func()) {
+	return c.shards[0].events.subscribe()
+}
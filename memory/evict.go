@@ -0,0 +1,55 @@
+package memory
+
+import (
+	cache "github.com/VsRnA/High-Performance-HTTP-Cache"
+)
+
+// evictedItem описывает удаленный элемент, ожидающий вызова колбэка
+type evictedItem struct {
+	key    string
+	value  []byte
+	reason cache.EvictionReason
+}
+
+// evictionQueue накапливает удаленные элементы под блокировкой кэша,
+// чтобы вызвать колбэк после ее снятия и избежать взаимоблокировки.
+// Все методы вызываются под блокировкой кэша на запись.
+type evictionQueue struct {
+	onEvict cache.EvictCallback
+	pending []evictedItem
+}
+
+// push добавляет удаленный элемент в очередь, если колбэк установлен.
+// Значение передается без копирования: кэш больше не ссылается на него.
+func (q *evictionQueue) push(key string, value []byte, reason cache.EvictionReason) {
+	if q.onEvict == nil {
+		return
+	}
+	q.pending = append(q.pending, evictedItem{key: key, value: value, reason: reason})
+}
+
+// pushEncoded добавляет в очередь элемент, значение которого может храниться сжатым.
+// Распаковка выполняется только если колбэк установлен.
+func (q *evictionQueue) pushEncoded(key string, data []byte, compressed bool, reason cache.EvictionReason) {
+	if q.onEvict == nil {
+		return
+	}
+	q.push(key, rawValue(data, compressed), reason)
+}
+
+// take забирает накопленные элементы вместе с колбэком для вызова вне блокировки
+func (q *evictionQueue) take() (cache.EvictCallback, []evictedItem) {
+	if len(q.pending) == 0 {
+		return nil, nil
+	}
+	pending := q.pending
+	q.pending = nil
+	return q.onEvict, pending
+}
+
+// notifyEvicted вызывает колбэк для каждого удаленного элемента
+func notifyEvicted(onEvict cache.EvictCallback, items []evictedItem) {
+	for _, item := range items {
+		onEvict(item.key, item.value, item.reason)
+	}
+}
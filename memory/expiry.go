@@ -0,0 +1,36 @@
+package memory
+
+import (
+	"time"
+
+	cache "github.com/VsRnA/High-Performance-HTTP-Cache"
+)
+
+// expirationTime вычисляет момент истечения для ttl, отсчитанного от now.
+// ttl <= 0 означает отсутствие срока жизни.
+func expirationTime(now time.Time, ttl time.Duration) time.Time {
+	if ttl <= 0 {
+		return time.Time{}
+	}
+	return now.Add(ttl)
+}
+
+// hardExpired проверяет, истекло ли окно устаревания после момента истечения.
+// Такой элемент больше нельзя вернуть даже через GetStale.
+func hardExpired(now, expiresAt time.Time, staleWindow time.Duration) bool {
+	return !expiresAt.IsZero() && now.After(expiresAt.Add(staleWindow))
+}
+
+// remainingTTL вычисляет оставшееся на момент now время жизни по моменту истечения.
+// Нулевой момент означает отсутствие срока жизни.
+func remainingTTL(now, expiresAt time.Time) (time.Duration, bool) {
+	if expiresAt.IsZero() {
+		return cache.NoExpiration, true
+	}
+
+	remaining := expiresAt.Sub(now)
+	if remaining <= 0 {
+		return 0, false
+	}
+	return remaining, true
+}
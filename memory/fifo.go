@@ -0,0 +1,411 @@
+package memory
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+
+	cache "github.com/VsRnA/High-Performance-HTTP-Cache"
+	"github.com/VsRnA/High-Performance-HTTP-Cache/internal"
+)
+
+// fifoItem представляет элемент в FIFO кэше
+type fifoItem struct {
+	key        string
+	value      []byte
+	expiresAt  time.Time
+	prev, next *fifoItem
+}
+
+// size возвращает объем ключа и значения в байтах
+func (item *fifoItem) size() int64 {
+	return int64(len(item.key) + len(item.value))
+}
+
+// isExpired проверяет истек ли элемент на момент now
+func (item *fifoItem) isExpired(now time.Time) bool {
+	return !item.expiresAt.IsZero() && now.After(item.expiresAt)
+}
+
+// FIFOCache реализует First In, First Out кэш
+// Вытесняет элементы в порядке добавления независимо от обращений к ним
+type FIFOCache struct {
+	// Основные данные
+	items map[string]*fifoItem
+	head  *fifoItem // Самый новый элемент
+	tail  *fifoItem // Самый старый элемент
+	mu    sync.RWMutex
+
+	// Конфигурация
+	maxSize    int
+	defaultTTL time.Duration
+	ttlBounds  ttlBounds // Границы явно запрошенного TTL
+
+	// Объем хранимых ключей и значений в байтах, изменяется под mu
+	bytes int64
+
+	// Источник времени для TTL, изменяется под mu
+	clock Clock
+
+	// Управление жизненным циклом
+	stopCh      chan struct{}
+	cleanupStop chan struct{} // Останавливает текущую горутину очистки, nil - очистка не запущена
+	closed      bool
+
+	// Уведомления об удаленных элементах
+	evictQueue evictionQueue
+
+	// Дедупликация одновременных загрузок в GetOrSet
+	loads internal.Group
+
+	// Статистика
+	hits      int64
+	misses    int64
+	evictions int64
+}
+
+// NewFIFO создает новый FIFO кэш с указанным максимальным размером
+func NewFIFO(maxSize int) cache.Cache {
+	return NewFIFOWithTTL(maxSize, 0)
+}
+
+// NewFIFOWithTTL создает новый FIFO кэш с максимальным размером и TTL по умолчанию
+func NewFIFOWithTTL(maxSize int, defaultTTL time.Duration) cache.Cache {
+	if maxSize <= 0 {
+		maxSize = 1000
+	}
+
+	c := &FIFOCache{
+		items:      make(map[string]*fifoItem, maxSize),
+		maxSize:    maxSize,
+		defaultTTL: defaultTTL,
+		stopCh:     make(chan struct{}),
+		clock:      realClock{},
+	}
+
+	c.head = &fifoItem{}
+	c.tail = &fifoItem{}
+	c.head.next = c.tail
+	c.tail.prev = c.head
+
+	if defaultTTL > 0 {
+		c.SetCleanupInterval(defaultCleanupInterval)
+	}
+
+	return c
+}
+
+// Get получает значение по ключу. Обращение не меняет порядок вытеснения.
+func (c *FIFOCache) Get(key string) ([]byte, bool) {
+	if key == "" {
+		atomic.AddInt64(&c.misses, 1)
+		return nil, false
+	}
+
+	c.mu.Lock()
+	defer c.unlock()
+
+	item, exists := c.items[key]
+	if !exists || c.closed {
+		atomic.AddInt64(&c.misses, 1)
+		return nil, false
+	}
+
+	if item.isExpired(c.clock.Now()) {
+		c.removeItem(item, cache.ReasonExpired)
+		atomic.AddInt64(&c.misses, 1)
+		return nil, false
+	}
+
+	atomic.AddInt64(&c.hits, 1)
+
+	value := make([]byte, len(item.value))
+	copy(value, item.value)
+	return value, true
+}
+
+// Set сохраняет значение с TTL по умолчанию
+func (c *FIFOCache) Set(key string, value []byte) error {
+	return c.SetWithTTL(key, value, c.defaultTTL)
+}
+
+// SetWithTTL сохраняет значение с указанным TTL.
+// Перезапись существующего ключа не меняет его позицию в очереди.
+func (c *FIFOCache) SetWithTTL(key string, value []byte, ttl time.Duration) error {
+	if key == "" {
+		return cache.ErrKeyEmpty
+	}
+
+	c.mu.Lock()
+	defer c.unlock()
+
+	if c.closed {
+		return cache.ErrCacheClosed
+	}
+
+	var expiresAt time.Time
+	if ttl > 0 {
+		expiresAt = c.clock.Now().Add(c.ttlBounds.clamp(ttl))
+	} else if c.defaultTTL > 0 {
+		expiresAt = c.clock.Now().Add(c.defaultTTL)
+	}
+
+	valueCopy := make([]byte, len(value))
+	copy(valueCopy, value)
+
+	if existingItem, exists := c.items[key]; exists {
+		c.bytes += int64(len(valueCopy) - len(existingItem.value))
+		existingItem.value = valueCopy
+		existingItem.expiresAt = expiresAt
+		return nil
+	}
+
+	if len(c.items) >= c.maxSize {
+		c.evictOldest()
+	}
+
+	newItem := &fifoItem{
+		key:       key,
+		value:     valueCopy,
+		expiresAt: expiresAt,
+	}
+
+	c.items[key] = newItem
+	c.addToHead(newItem)
+	c.bytes += newItem.size()
+	return nil
+}
+
+// GetOrSet возвращает значение по ключу или загружает его через loader при промахе
+func (c *FIFOCache) GetOrSet(key string, loader func() ([]byte, error), ttl time.Duration) ([]byte, error) {
+	if key == "" {
+		return nil, cache.ErrKeyEmpty
+	}
+
+	if value, exists := c.Get(key); exists {
+		return value, nil
+	}
+
+	shared, err := c.loads.Do(key, func() ([]byte, error) {
+		value, err := loader()
+		if err != nil {
+			return nil, err
+		}
+		if err := c.SetWithTTL(key, value, ttl); err != nil {
+			return nil, err
+		}
+		return value, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Результат общий для всех ожидающих, поэтому каждый получает свою копию
+	value := make([]byte, len(shared))
+	copy(value, shared)
+	return value, nil
+}
+
+// GetTTL возвращает оставшееся время жизни ключа без обновления статистики
+func (c *FIFOCache) GetTTL(key string) (time.Duration, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	item, exists := c.items[key]
+	if !exists || item.isExpired(c.clock.Now()) {
+		return 0, false
+	}
+	return remainingTTL(c.clock.Now(), item.expiresAt)
+}
+
+// Expire устанавливает новое время жизни ключа
+func (c *FIFOCache) Expire(key string, ttl time.Duration) bool {
+	c.mu.Lock()
+	defer c.unlock()
+
+	item, exists := c.items[key]
+	if !exists || item.isExpired(c.clock.Now()) {
+		return false
+	}
+
+	item.expiresAt = expirationTime(c.clock.Now(), ttl)
+	return true
+}
+
+// Persist делает ключ бессрочным
+func (c *FIFOCache) Persist(key string) bool {
+	return c.Expire(key, 0)
+}
+
+// Delete удаляет ключ из кэша
+func (c *FIFOCache) Delete(key string) bool {
+	if key == "" {
+		return false
+	}
+
+	c.mu.Lock()
+	defer c.unlock()
+
+	item, exists := c.items[key]
+	if !exists || c.closed {
+		return false
+	}
+
+	c.removeItem(item, cache.ReasonDeleted)
+	return true
+}
+
+// Keys возвращает все неистекшие ключи от новых к старым
+func (c *FIFOCache) Keys() []string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	keys := make([]string, 0, len(c.items))
+	for item := c.head.next; item != c.tail; item = item.next {
+		if !item.isExpired(c.clock.Now()) {
+			keys = append(keys, item.key)
+		}
+	}
+	return keys
+}
+
+// Len возвращает количество элементов без построения Stats.
+// Может учитывать истекшие элементы, которые еще не удалены.
+func (c *FIFOCache) Len() int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return len(c.items)
+}
+
+// Clear очищает весь кэш
+func (c *FIFOCache) Clear() {
+	c.mu.Lock()
+	defer c.unlock()
+
+	for item := c.head.next; item != c.tail; item = item.next {
+		c.evictQueue.push(item.key, item.value, cache.ReasonCleared)
+	}
+
+	c.items = make(map[string]*fifoItem)
+	c.head.next = c.tail
+	c.tail.prev = c.head
+	c.bytes = 0
+
+	atomic.StoreInt64(&c.hits, 0)
+	atomic.StoreInt64(&c.misses, 0)
+	atomic.StoreInt64(&c.evictions, 0)
+}
+
+// Stats возвращает статистику кэша
+func (c *FIFOCache) Stats() cache.Stats {
+	c.mu.RLock()
+	keys := int64(len(c.items))
+	bytes := c.bytes
+	c.mu.RUnlock()
+
+	stats := cache.Stats{
+		Hits:      atomic.LoadInt64(&c.hits),
+		Misses:    atomic.LoadInt64(&c.misses),
+		Keys:      keys,
+		Evictions: atomic.LoadInt64(&c.evictions),
+		Bytes:     bytes,
+		RawBytes:  bytes, // Значения хранятся без сжатия
+	}
+
+	stats.CalculateHitRate()
+	return stats
+}
+
+// Close корректно завершает работу кэша
+func (c *FIFOCache) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.closed {
+		return nil
+	}
+
+	c.closed = true
+	close(c.stopCh)
+	return nil
+}
+
+// SetOnEvict устанавливает колбэк, вызываемый при вытеснении, истечении,
+// удалении и очистке элементов. nil отключает уведомления.
+func (c *FIFOCache) SetOnEvict(fn cache.EvictCallback) {
+	c.mu.Lock()
+	c.evictQueue.onEvict = fn
+	c.mu.Unlock()
+}
+
+// unlock снимает блокировку на запись и вызывает колбэк для элементов,
+// удаленных пока она удерживалась
+func (c *FIFOCache) unlock() {
+	onEvict, evicted := c.evictQueue.take()
+	c.mu.Unlock()
+	notifyEvicted(onEvict, evicted)
+}
+
+// addToHead добавляет элемент в начало очереди
+func (c *FIFOCache) addToHead(item *fifoItem) {
+	item.prev = c.head
+	item.next = c.head.next
+	c.head.next.prev = item
+	c.head.next = item
+}
+
+// evictOldest удаляет самый старый элемент
+func (c *FIFOCache) evictOldest() {
+	oldest := c.tail.prev
+	if oldest != c.head {
+		c.removeItem(oldest, cache.ReasonCapacity)
+		atomic.AddInt64(&c.evictions, 1)
+	}
+}
+
+// removeItem полностью удаляет элемент из кэша
+func (c *FIFOCache) removeItem(item *fifoItem, reason cache.EvictionReason) {
+	delete(c.items, item.key)
+	item.prev.next = item.next
+	item.next.prev = item.prev
+	c.bytes -= item.size()
+	c.evictQueue.push(item.key, item.value, reason)
+}
+
+// cleanup фоновая очистка истекших элементов с периодом interval до закрытия stop или кэша
+func (c *FIFOCache) cleanup(interval time.Duration, stop <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			c.removeExpired()
+		case <-stop:
+			return
+		case <-c.stopCh:
+			return
+		}
+	}
+}
+
+// removeExpired удаляет все истекшие элементы
+func (c *FIFOCache) removeExpired() {
+	c.mu.Lock()
+	defer c.unlock()
+
+	var expired []*fifoItem
+	for item := c.head.next; item != c.tail; item = item.next {
+		if item.isExpired(c.clock.Now()) {
+			expired = append(expired, item)
+		}
+	}
+
+	for _, item := range expired {
+		c.removeItem(item, cache.ReasonExpired)
+	}
+
+	if len(expired) > 0 {
+		atomic.AddInt64(&c.evictions, int64(len(expired)))
+	}
+}
@@ -0,0 +1,81 @@
+package memory
+
+import (
+	"sync/atomic"
+
+	cache "github.com/VsRnA/High-Performance-HTTP-Cache"
+)
+
+// GetDetailed получает значение и сообщает причину промаха: истек TTL ключа
+// или ключа нет. Истекшие промахи учитываются в Stats.ExpiredMisses и входят в Misses.
+// Ключ, который уже удалила фоновая очистка, считается отсутствующим,
+// отрицательная запись SetNegative - тоже.
+func (c *LRUCache) GetDetailed(key string) ([]byte, cache.Outcome) {
+	if key == "" {
+		atomic.AddInt64(&c.misses, 1)
+		return nil, cache.OutcomeAbsentMiss
+	}
+
+	c.mu.Lock()
+	defer c.unlock()
+
+	item, exists := c.items[key]
+	expired := exists && !c.closed && item.isExpired(c.clock.Now())
+
+	if value, ok := c.getLocked(key); ok {
+		return value, cache.OutcomeHit
+	}
+	if expired {
+		return nil, cache.OutcomeExpiredMiss
+	}
+	return nil, cache.OutcomeAbsentMiss
+}
+
+// GetDetailed получает значение и сообщает причину промаха, см. LRUCache.GetDetailed
+func (c *LFUCache) GetDetailed(key string) ([]byte, cache.Outcome) {
+	if key == "" {
+		atomic.AddInt64(&c.misses, 1)
+		return nil, cache.OutcomeAbsentMiss
+	}
+
+	c.mu.Lock()
+	defer c.unlock()
+
+	item, exists := c.items[key]
+	expired := exists && !c.closed && item.isExpired(c.clock.Now())
+
+	if value, ok := c.getLocked(key); ok {
+		return value, cache.OutcomeHit
+	}
+	if expired {
+		return nil, cache.OutcomeExpiredMiss
+	}
+	return nil, cache.OutcomeAbsentMiss
+}
+
+// GetDetailed получает значение и сообщает причину промаха, см. LRUCache.GetDetailed
+func (c *SimpleCache) GetDetailed(key string) ([]byte, cache.Outcome) {
+	if key == "" {
+		atomic.AddInt64(&c.misses, 1)
+		return nil, cache.OutcomeAbsentMiss
+	}
+
+	c.mu.Lock()
+	defer c.unlock()
+
+	item, exists := c.items[key]
+	expired := exists && !c.closed && item.isExpired(c.clock.Now())
+
+	if value, ok := c.getLocked(key); ok {
+		return value, cache.OutcomeHit
+	}
+	if expired {
+		return nil, cache.OutcomeExpiredMiss
+	}
+	return nil, cache.OutcomeAbsentMiss
+}
+
+// GetDetailed получает значение из шарда ключа и сообщает причину промаха
+func (c *ShardedCache) GetDetailed(key string) ([]byte, cache.Outcome) {
+	return c.shard(key).GetDetailed(key)
+}
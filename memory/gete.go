@@ -0,0 +1,92 @@
+package memory
+
+import (
+	cache "github.com/VsRnA/High-Performance-HTTP-Cache"
+	"github.com/VsRnA/High-Performance-HTTP-Cache/internal"
+)
+
+// GetE получает значение по ключу, различая причины отсутствия значения:
+// cache.ErrKeyEmpty для пустого ключа, cache.ErrCacheClosed после Close
+// и cache.ErrNotFound для обычного промаха. Попадания и промахи учитываются как в Get.
+func (c *LRUCache) GetE(key string) (value []byte, err error) {
+	if key == "" {
+		return nil, cache.ErrKeyEmpty
+	}
+
+	if m := c.metrics.Load(); m != nil {
+		timer := internal.NewTimer()
+		defer func() { recordGet(m, timer, err == nil) }()
+	}
+
+	c.mu.Lock()
+	defer c.unlock()
+
+	if c.closed {
+		return nil, cache.ErrCacheClosed
+	}
+
+	value, ok := c.getLocked(key)
+	if !ok {
+		return nil, cache.ErrNotFound
+	}
+	return value, nil
+}
+
+// GetE получает значение по ключу, различая пустой ключ, закрытый кэш и промах
+func (c *LFUCache) GetE(key string) (value []byte, err error) {
+	if key == "" {
+		return nil, cache.ErrKeyEmpty
+	}
+
+	if m := c.metrics.Load(); m != nil {
+		timer := internal.NewTimer()
+		defer func() { recordGet(m, timer, err == nil) }()
+	}
+
+	c.mu.Lock()
+	defer c.unlock()
+
+	if c.closed {
+		return nil, cache.ErrCacheClosed
+	}
+
+	value, ok := c.getLocked(key)
+	if !ok {
+		return nil, cache.ErrNotFound
+	}
+	return value, nil
+}
+
+// GetE получает значение по ключу, различая пустой ключ, закрытый кэш и промах.
+// В отличие от Get выполняется под эксклюзивной блокировкой.
+func (c *SimpleCache) GetE(key string) (value []byte, err error) {
+	if key == "" {
+		return nil, cache.ErrKeyEmpty
+	}
+
+	if m := c.metrics.Load(); m != nil {
+		timer := internal.NewTimer()
+		defer func() { recordGet(m, timer, err == nil) }()
+	}
+
+	c.mu.Lock()
+	defer c.unlock()
+
+	if c.closed {
+		return nil, cache.ErrCacheClosed
+	}
+
+	value, ok := c.getLocked(key)
+	if !ok {
+		return nil, cache.ErrNotFound
+	}
+	return value, nil
+}
+
+// GetE получает значение из шарда ключа, различая пустой ключ, закрытый кэш и промах
+func (c *ShardedCache) GetE(key string) ([]byte, error) {
+	if key == "" {
+		return nil, cache.ErrKeyEmpty
+	}
+	return c.shard(key).GetE(key)
+}
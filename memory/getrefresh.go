@@ -0,0 +1,104 @@
+package memory
+
+import (
+	"sync/atomic"
+	"time"
+
+	"github.com/VsRnA/High-Performance-HTTP-Cache/internal"
+)
+
+// refreshedExpiry сдвигает момент истечения на extendBy, только если до него
+// осталось меньше threshold. Бессрочные элементы и extendBy <= 0 не изменяются.
+func refreshedExpiry(now, expiresAt time.Time, extendBy, threshold time.Duration) time.Time {
+	if expiresAt.IsZero() || extendBy <= 0 || expiresAt.Sub(now) >= threshold {
+		return expiresAt
+	}
+	return expiresAt.Add(extendBy)
+}
+
+// GetAndRefresh получает значение по ключу и продлевает срок жизни на extendBy,
+// только если до истечения осталось меньше onlyIfRemainingBelow. В отличие от
+// SetSlidingTTL большинство чтений не меняет expiresAt. Продление ограничено SetMaxAge.
+func (c *LRUCache) GetAndRefresh(key string, extendBy, onlyIfRemainingBelow time.Duration) (value []byte, ok bool) {
+	if m := c.metrics.Load(); m != nil {
+		timer := internal.NewTimer()
+		defer func() { recordGet(m, timer, ok) }()
+	}
+
+	if key == "" {
+		atomic.AddInt64(&c.misses, 1)
+		return nil, false
+	}
+
+	c.mu.Lock()
+	defer c.unlock()
+
+	item := c.lookupLocked(key)
+	if item == nil {
+		return nil, false
+	}
+	item.expiresAt = capExpiry(refreshedExpiry(c.clock.Now(), item.expiresAt, extendBy, onlyIfRemainingBelow), item.createdAt, c.maxAge)
+	return decodeValue(item.value, item.compressed), true
+}
+
+// GetAndRefresh получает значение по ключу и продлевает срок жизни на extendBy,
+// только если до истечения осталось меньше onlyIfRemainingBelow
+func (c *LFUCache) GetAndRefresh(key string, extendBy, onlyIfRemainingBelow time.Duration) (value []byte, ok bool) {
+	if m := c.metrics.Load(); m != nil {
+		timer := internal.NewTimer()
+		defer func() { recordGet(m, timer, ok) }()
+	}
+
+	if key == "" {
+		atomic.AddInt64(&c.misses, 1)
+		return nil, false
+	}
+
+	c.mu.Lock()
+	defer c.unlock()
+
+	item := c.lookupLocked(key)
+	if item == nil {
+		return nil, false
+	}
+	item.expiresAt = capExpiry(refreshedExpiry(c.clock.Now(), item.expiresAt, extendBy, onlyIfRemainingBelow), item.createdAt, c.maxAge)
+	return decodeValue(item.value, item.compressed), true
+}
+
+// GetAndRefresh получает значение по ключу и продлевает срок жизни на extendBy,
+// только если до истечения осталось меньше onlyIfRemainingBelow.
+// Поиск выполняется под блокировкой на чтение, блокировка на запись берется
+// только когда элемент действительно нужно продлить.
+func (c *SimpleCache) GetAndRefresh(key string, extendBy, onlyIfRemainingBelow time.Duration) (value []byte, ok bool) {
+	if m := c.metrics.Load(); m != nil {
+		timer := internal.NewTimer()
+		defer func() { recordGet(m, timer, ok) }()
+	}
+
+	item := c.lookup(key)
+	if item == nil {
+		return nil, false
+	}
+
+	c.mu.RLock()
+	due := refreshedExpiry(c.clock.Now(), item.expiresAt, extendBy, onlyIfRemainingBelow) != item.expiresAt
+	c.mu.RUnlock()
+
+	if due {
+		c.mu.Lock()
+		// Продлевается только тот же элемент: его могли перезаписать после RUnlock.
+		// Другие Get читают элемент без блокировки, поэтому он заменяется копией.
+		if current, exists := c.items[key]; exists && current == item {
+			refreshed := *item
+			refreshed.expiresAt = capExpiry(refreshedExpiry(c.clock.Now(), item.expiresAt, extendBy, onlyIfRemainingBelow), item.createdAt, c.maxAge)
+			c.items[key] = &refreshed
+		}
+		c.mu.Unlock()
+	}
+	return decodeValue(item.value, item.compressed), true
+}
+
+// GetAndRefresh получает значение из шарда ключа, продлевая срок жизни вблизи истечения
+func (c *ShardedCache) GetAndRefresh(key string, extendBy, onlyIfRemainingBelow time.Duration) ([]byte, bool) {
+	return c.shard(key).GetAndRefresh(key, extendBy, onlyIfRemainingBelow)
+}
@@ -0,0 +1,90 @@
+package memory
+
+// trimBatchSize - сколько элементов фоновое вытеснение удаляет за одну блокировку.
+// Между пачками блокировка снимается, чтобы чтения и записи не ждали всю очистку.
+const trimBatchSize = 64
+
+// SetEvictionHeadroom переносит вытеснение с пути записи в фоновую горутину.
+// Кэш может временно превышать лимиты на долю fraction: не больше
+// maxSize + floor(maxSize*fraction) элементов и maxBytes + floor(maxBytes*fraction) байт.
+// Запись, упирающаяся в эту границу, вытесняет синхронно, как без запаса.
+// После каждой записи сверх лимита фоновая горутина вытесняет давно
+// использованные элементы пачками по trimBatchSize, пока кэш не вернется к лимитам.
+// Так пакетная загрузка не вызывает всплеск вытеснений внутри одной операции.
+// 0 возвращает синхронное вытеснение.
+func (c *LRUCache) SetEvictionHeadroom(fraction float64) {
+	c.mu.Lock()
+	defer c.unlock()
+
+	c.headroom = max(fraction, 0)
+	if c.headroom > 0 && c.trimCh == nil && !c.closed {
+		c.trimCh = make(chan struct{}, 1)
+		go c.trim(c.trimCh)
+	}
+
+	// Превышение, оставшееся после отключения запаса, убирается фоновой горутиной
+	c.signalTrim()
+}
+
+// SetEvictionHeadroom задает допустимое превышение лимитов во всех шардах
+func (c *ShardedCache) SetEvictionHeadroom(fraction float64) {
+	for _, shard := range c.shards {
+		shard.SetEvictionHeadroom(fraction)
+	}
+}
+
+// hardMaxSize возвращает число элементов, при котором запись вытесняет синхронно
+func (c *LRUCache) hardMaxSize() int {
+	return c.maxSize + int(float64(c.maxSize)*c.headroom)
+}
+
+// hardMaxBytes возвращает объем, при превышении которого запись вытесняет синхронно
+func (c *LRUCache) hardMaxBytes() int64 {
+	return c.maxBytes + int64(float64(c.maxBytes)*c.headroom)
+}
+
+// overLimit проверяет превышение лимитов без учета запаса. Вызывается под mu.
+func (c *LRUCache) overLimit() bool {
+	return (c.maxSize > 0 && len(c.items) > c.maxSize) ||
+		(c.maxBytes > 0 && c.bytes > c.maxBytes)
+}
+
+// signalTrim будит фоновое вытеснение, если кэш превышает лимиты.
+// Не блокируется: повторный сигнал до обработки предыдущего не нужен. Вызывается под mu.
+func (c *LRUCache) signalTrim() {
+	if c.trimCh == nil || !c.overLimit() {
+		return
+	}
+	select {
+	case c.trimCh <- struct{}{}:
+	default:
+	}
+}
+
+// trim фоновое вытеснение до лимитов после сигналов signalTrim
+func (c *LRUCache) trim(trimCh <-chan struct{}) {
+	for {
+		select {
+		case <-trimCh:
+			for c.trimBatch() {
+			}
+		case <-c.stopCh:
+			return
+		}
+	}
+}
+
+// trimBatch вытесняет до trimBatchSize элементов сверх лимитов
+// и возвращает true, если превышение осталось
+func (c *LRUCache) trimBatch() bool {
+	c.mu.Lock()
+	defer c.unlock()
+
+	if c.closed {
+		return false
+	}
+	for i := 0; i < trimBatchSize && c.overLimit() && c.tail.prev != c.head; i++ {
+		c.evictTail()
+	}
+	return c.overLimit()
+}
@@ -0,0 +1,133 @@
+package memory
+
+import (
+	"sync/atomic"
+	"time"
+
+	cache "github.com/VsRnA/High-Performance-HTTP-Cache"
+)
+
+// reaperStallPeriods - число периодов очистки без прохода, после которого
+// Health считает фоновую очистку зависшей
+const reaperStallPeriods = 2
+
+// reaperState отслеживает работу фоновой очистки для Health
+type reaperState struct {
+	interval    time.Duration // Период текущей очистки, 0 - очистка отключена. Изменяется под mu.
+	started     time.Time     // Запуск текущей горутины очистки. Изменяется под mu.
+	lastCleanup int64         // Завершение последнего прохода в UnixNano, изменяется атомарно
+}
+
+// restart запоминает период запущенной очистки. Вызывается под mu.
+func (r *reaperState) restart(interval time.Duration) {
+	r.interval = max(interval, 0)
+	r.started = time.Now()
+}
+
+// done отмечает завершение прохода очистки
+func (r *reaperState) done() {
+	atomic.StoreInt64(&r.lastCleanup, time.Now().UnixNano())
+}
+
+// last возвращает момент последнего прохода, нулевой до первого
+func (r *reaperState) last() time.Time {
+	if nanos := atomic.LoadInt64(&r.lastCleanup); nanos != 0 {
+		return time.Unix(0, nanos)
+	}
+	return time.Time{}
+}
+
+// alive сообщает, что очистка отключена или проходила не позже reaperStallPeriods
+// периодов назад. До первого прохода отсчет идет от запуска. Вызывается под mu.
+func (r *reaperState) alive(closed bool) bool {
+	if closed {
+		return false
+	}
+	if r.interval == 0 {
+		return true
+	}
+	since := r.started
+	if last := r.last(); last.After(since) {
+		since = last
+	}
+	return time.Since(since) <= reaperStallPeriods*r.interval
+}
+
+// saturation возвращает большую из долей заполнения по количеству и объему.
+// Нулевой лимит не учитывается.
+func saturation(keys, maxKeys, bytes, maxBytes int64) float64 {
+	var ratio float64
+	if maxKeys > 0 {
+		ratio = float64(keys) / float64(maxKeys)
+	}
+	if maxBytes > 0 {
+		ratio = max(ratio, float64(bytes)/float64(maxBytes))
+	}
+	return ratio
+}
+
+// Health сообщает, открыт ли кэш, не зависла ли фоновая очистка и насколько он заполнен.
+// Healthy становится false после Close или если очистка не проходила дольше двух периодов.
+// Предназначен для проверок готовности, например обработчика /healthz.
+func (c *LRUCache) Health() cache.HealthStatus {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	alive := c.reaper.alive(c.closed)
+	return cache.HealthStatus{
+		Healthy:     !c.closed && alive,
+		Closed:      c.closed,
+		ReaperAlive: alive,
+		Saturation:  saturation(int64(len(c.items)), int64(c.maxSize), atomic.LoadInt64(&c.bytes), c.maxBytes),
+		LastCleanup: c.reaper.last(),
+	}
+}
+
+// Health сообщает состояние кэша для проверок готовности, см. LRUCache.Health
+func (c *LFUCache) Health() cache.HealthStatus {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	alive := c.reaper.alive(c.closed)
+	return cache.HealthStatus{
+		Healthy:     !c.closed && alive,
+		Closed:      c.closed,
+		ReaperAlive: alive,
+		Saturation:  saturation(int64(len(c.items)), int64(c.maxSize), 0, 0),
+		LastCleanup: c.reaper.last(),
+	}
+}
+
+// Health сообщает состояние кэша для проверок готовности, см. LRUCache.Health.
+// Saturation ненулевая только у NewSimpleBounded.
+func (c *SimpleCache) Health() cache.HealthStatus {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	alive := c.reaper.alive(c.closed)
+	return cache.HealthStatus{
+		Healthy:     !c.closed && alive,
+		Closed:      c.closed,
+		ReaperAlive: alive,
+		Saturation:  saturation(int64(len(c.items)), int64(c.maxSize), 0, 0),
+		LastCleanup: c.reaper.last(),
+	}
+}
+
+// Health объединяет состояние шардов: кэш здоров, только если здоровы все шарды.
+// Saturation - заполнение самого полного шарда, LastCleanup - самый давний
+// последний проход очистки среди шардов.
+func (c *ShardedCache) Health() cache.HealthStatus {
+	status := cache.HealthStatus{Healthy: true, ReaperAlive: true}
+	for i, shard := range c.shards {
+		s := shard.Health()
+		status.Healthy = status.Healthy && s.Healthy
+		status.Closed = status.Closed || s.Closed
+		status.ReaperAlive = status.ReaperAlive && s.ReaperAlive
+		status.Saturation = max(status.Saturation, s.Saturation)
+		if i == 0 || s.LastCleanup.Before(status.LastCleanup) {
+			status.LastCleanup = s.LastCleanup
+		}
+	}
+	return status
+}
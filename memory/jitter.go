@@ -0,0 +1,129 @@
+package memory
+
+import (
+	"math/rand"
+	"time"
+
+	cache "github.com/VsRnA/High-Performance-HTTP-Cache"
+)
+
+// ttlJitter случайно отклоняет TTL в обе стороны, чтобы ключи, записанные
+// одновременно, не истекали одновременно
+type ttlJitter struct {
+	fraction float64        // Максимальное отклонение как доля TTL, 0 - без отклонения
+	random   func() float64 // Источник случайных чисел в [0, 1)
+}
+
+// newTTLJitter создает отклонение с долей fraction, ограниченной диапазоном [0, 1].
+// random = nil использует math/rand.
+func newTTLJitter(fraction float64, random func() float64) ttlJitter {
+	if random == nil {
+		random = rand.Float64
+	}
+	return ttlJitter{fraction: min(max(fraction, 0), 1), random: random}
+}
+
+// apply возвращает ttl, отклоненный не более чем на fraction в каждую сторону.
+// Бессрочные элементы не изменяются.
+func (j ttlJitter) apply(ttl time.Duration) time.Duration {
+	if j.fraction == 0 || ttl <= 0 {
+		return ttl
+	}
+	jittered := ttl + time.Duration((2*j.random()-1)*j.fraction*float64(ttl))
+	// Элемент с TTL не должен стать бессрочным при отклонении до нуля
+	return max(jittered, time.Nanosecond)
+}
+
+// SetTTLJitter включает случайное отклонение TTL на долю fraction, например 0.1 для ±10%.
+// Отклонение применяется ко всем записям, кроме SetWithExactTTL. random возвращает
+// числа в [0, 1) и позволяет сделать отклонение детерминированным в тестах; nil использует math/rand.
+func (c *LRUCache) SetTTLJitter(fraction float64, random func() float64) {
+	c.mu.Lock()
+	c.ttlJitter = newTTLJitter(fraction, random)
+	c.mu.Unlock()
+}
+
+// SetWithExactTTL сохраняет значение с указанным TTL без случайного отклонения.
+// Границы SetTTLBounds применяются и к нему.
+func (c *LRUCache) SetWithExactTTL(key string, value []byte, ttl time.Duration) error {
+	if err := c.validate(key, value); err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	defer c.unlock()
+
+	if c.closed {
+		return cache.ErrCacheClosed
+	}
+
+	c.setExactLocked(key, value, c.ttlBounds.clamp(ttl))
+	return nil
+}
+
+// SetTTLJitter включает случайное отклонение TTL на долю fraction, например 0.1 для ±10%.
+// Отклонение применяется ко всем записям, кроме SetWithExactTTL. random возвращает
+// числа в [0, 1) и позволяет сделать отклонение детерминированным в тестах; nil использует math/rand.
+func (c *LFUCache) SetTTLJitter(fraction float64, random func() float64) {
+	c.mu.Lock()
+	c.ttlJitter = newTTLJitter(fraction, random)
+	c.mu.Unlock()
+}
+
+// SetWithExactTTL сохраняет значение с указанным TTL без случайного отклонения.
+// Границы SetTTLBounds применяются и к нему.
+func (c *LFUCache) SetWithExactTTL(key string, value []byte, ttl time.Duration) error {
+	if err := c.validate(key, value); err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	defer c.unlock()
+
+	if c.closed {
+		return cache.ErrCacheClosed
+	}
+
+	c.setExactLocked(key, value, c.ttlBounds.clamp(ttl))
+	return nil
+}
+
+// SetTTLJitter включает случайное отклонение TTL на долю fraction, например 0.1 для ±10%.
+// Отклонение применяется ко всем записям, кроме SetWithExactTTL. random возвращает
+// числа в [0, 1) и позволяет сделать отклонение детерминированным в тестах; nil использует math/rand.
+func (c *SimpleCache) SetTTLJitter(fraction float64, random func() float64) {
+	c.mu.Lock()
+	c.ttlJitter = newTTLJitter(fraction, random)
+	c.mu.Unlock()
+}
+
+// SetWithExactTTL сохраняет значение с указанным TTL без случайного отклонения.
+// Границы SetTTLBounds применяются и к нему.
+func (c *SimpleCache) SetWithExactTTL(key string, value []byte, ttl time.Duration) error {
+	if err := c.validate(key, value); err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	defer c.unlock()
+
+	if c.closed {
+		return cache.ErrCacheClosed
+	}
+
+	c.setExactLocked(key, value, c.ttlBounds.clamp(ttl))
+	return nil
+}
+
+// SetTTLJitter включает случайное отклонение TTL во всех шардах.
+// Шарды вызывают random параллельно, поэтому он должен быть безопасен для конкурентного использования.
+func (c *ShardedCache) SetTTLJitter(fraction float64, random func() float64) {
+	for _, shard := range c.shards {
+		shard.SetTTLJitter(fraction, random)
+	}
+}
+
+// SetWithExactTTL сохраняет значение с указанным TTL без случайного отклонения
+func (c *ShardedCache) SetWithExactTTL(key string, value []byte, ttl time.Duration) error {
+	return c.shard(key).SetWithExactTTL(key, value, ttl)
+}
@@ -6,6 +6,7 @@ import (
 	"time"
 
 	cache "github.com/VsRnA/High-Performance-HTTP-Cache"
+	"github.com/VsRnA/High-Performance-HTTP-Cache/internal"
 )
 
 // lfuItem представляет элемент в LFU кэше
@@ -13,40 +14,172 @@ type lfuItem struct {
 	key        string
 	value      []byte
 	expiresAt  time.Time
+	createdAt  time.Time // Момент записи текущего значения для SetMaxAge
+	ttl        time.Duration
 	frequency  int64 // Частота использования
 	lastAccess time.Time
+	rawSize    int64 // Длина ключа плюс длина значения до сжатия
+	compressed bool  // value хранится сжатым flate
+	negative   bool  // Отрицательная запись SetNegative без значения
+	tags       []string
+
+	// Положение в списке корзины с той же частотой
+	bucket     *lfuBucket
+	prev, next *lfuItem
+}
+
+// size возвращает занимаемый объем: длина ключа плюс длина хранимого значения
+func (item *lfuItem) size() int64 {
+	return int64(len(item.key) + len(item.value))
+}
+
+// isExpired проверяет истек ли элемент на момент now
+func (item *lfuItem) isExpired(now time.Time) bool {
+	return !item.expiresAt.IsZero() && now.After(item.expiresAt)
+}
+
+// lfuBucket - корзина элементов с одинаковой частотой. Элементы упорядочены
+// по давности обращения, в начале самый недавно использованный.
+type lfuBucket struct {
+	frequency  int64
+	root       lfuItem // Ограничитель списка элементов
+	prev, next *lfuBucket
+}
+
+// pushFront добавляет элемент в начало корзины
+func (b *lfuBucket) pushFront(item *lfuItem) {
+	item.bucket = b
+	item.prev = &b.root
+	item.next = b.root.next
+	b.root.next.prev = item
+	b.root.next = item
+}
+
+// unlink удаляет элемент из списка его корзины, оставляя корзину на месте
+func (item *lfuItem) unlink() {
+	item.prev.next = item.next
+	item.next.prev = item.prev
+	item.prev, item.next = nil, nil
 }
 
-// isExpired проверяет истек ли элемент
-func (item *lfuItem) isExpired() bool {
-	return !item.expiresAt.IsZero() && time.Now().After(item.expiresAt)
+// lfuBuckets - список непустых корзин по возрастанию частоты.
+// Последний элемент первой корзины - кандидат на вытеснение: у него наименьшая
+// частота, а среди равных по частоте он дольше всех не использовался.
+type lfuBuckets struct {
+	root lfuBucket
 }
 
-// touch увеличивает частоту использования
-func (item *lfuItem) touch() {
-	atomic.AddInt64(&item.frequency, 1)
-	item.lastAccess = time.Now()
+// init подготавливает пустой список корзин
+func (l *lfuBuckets) init() {
+	l.root.frequency = -1
+	l.root.next = &l.root
+	l.root.prev = &l.root
+}
+
+// bucketAfter возвращает корзину с частотой frequency, создавая ее при необходимости.
+// Поиск идет от корзины from вперед, поэтому при увеличении частоты на единицу
+// и при добавлении нового элемента от начала списка он занимает O(1).
+func (l *lfuBuckets) bucketAfter(from *lfuBucket, frequency int64) *lfuBucket {
+	for from.next != &l.root && from.next.frequency < frequency {
+		from = from.next
+	}
+	if from.next != &l.root && from.next.frequency == frequency {
+		return from.next
+	}
+
+	b := &lfuBucket{frequency: frequency, prev: from, next: from.next}
+	b.root.next = &b.root
+	b.root.prev = &b.root
+	from.next.prev = b
+	from.next = b
+	return b
+}
+
+// remove удаляет элемент из его корзины и удаляет опустевшую корзину
+func (l *lfuBuckets) remove(item *lfuItem) {
+	b := item.bucket
+	item.unlink()
+	item.bucket = nil
+
+	if b.root.next == &b.root {
+		b.prev.next = b.next
+		b.next.prev = b.prev
+		b.prev, b.next = nil, nil
+	}
+}
+
+// back возвращает кандидата на вытеснение или nil для пустого списка
+func (l *lfuBuckets) back() *lfuItem {
+	if l.root.next == &l.root {
+		return nil
+	}
+	return l.root.next.root.prev
 }
 
 // LFUCache реализует Least Frequently Used кэш
 // Вытесняет элементы которые используются реже всего
 type LFUCache struct {
 	// Основные данные
-	items map[string]*lfuItem
-	mu    sync.RWMutex
+	items   map[string]*lfuItem
+	buckets lfuBuckets // Элементы, сгруппированные по частоте, изменяется под mu
+	mu      sync.RWMutex
 	
 	// Конфигурация
-	maxSize    int
-	defaultTTL time.Duration
+	maxSize              int
+	defaultTTL           time.Duration
+	compressionThreshold int           // Значения длиннее порога сжимаются, 0 - без сжатия
+	staleWindow          time.Duration // Окно после истечения TTL для GetStale
+	ttlJitter            ttlJitter     // Случайное отклонение TTL при записи
+	ttlBounds            ttlBounds     // Границы явно запрошенного TTL
+	slidingTTL           bool          // Get продлевает элемент на его исходный TTL
+	maxAge               time.Duration // Предельный возраст элемента независимо от TTL
+	maxValueBytes        int64         // Максимальная длина значения, изменяется атомарно
+	clock                Clock         // Источник времени для TTL и времени обращения
+
+	// Упреждающее обновление элементов перед истечением TTL
+	refreshAhead RefreshAhead
+	refreshes    refreshGroup
+
+	// Текущий объем хранимых данных, объем до сжатия и число элементов в items.
+	// Изменяются под mu атомарно, чтобы Stats читал их без блокировки.
+	bytes    int64
+	rawBytes int64
+	keyCount int64
+
+	// Обратный индекс тегов SetWithTags, изменяется под mu
+	tags tagIndex
 	
 	// Управление жизненным циклом
-	stopCh chan struct{}
-	closed bool
+	stopCh      chan struct{}
+	cleanupStop chan struct{} // Останавливает текущую горутину очистки, nil - очистка не запущена
+	reaper      reaperState   // Состояние фоновой очистки для Health
+	decayStop   chan struct{} // Останавливает горутину старения частот, nil - старение отключено
+	closed      bool
+	onClose     func(items map[string][]byte) // Получает живые элементы при Close
 	
+	// Уведомления об удаленных элементах
+	evictQueue evictionQueue
+
+	// Подписчики на события изменений
+	events *eventHub
+
+	// Дедупликация одновременных загрузок в GetOrSet
+	loads internal.Group
+	
+	// Детальные метрики SetMetrics, nil - сбор отключен
+	metrics atomic.Pointer[cache.Metrics]
+
+	// Фильтр Блума SetBloomFilter перед картой, nil - отключен.
+	// Изменяется под mu, Get читает его без блокировки.
+	bloom atomic.Pointer[internal.CountingBloom]
+
 	// Статистика
 	hits      int64
 	misses    int64
 	evictions int64
+
+	// Промахи по истекшим, но еще не удаленным элементам, входят в misses
+	expiredMisses int64
 }
 
 // NewLFU создает новый LFU кэш с указанным максимальным размером
@@ -65,43 +198,80 @@ func NewLFUWithTTL(maxSize int, defaultTTL time.Duration) cache.Cache {
 		maxSize:    maxSize,
 		defaultTTL: defaultTTL,
 		stopCh:     make(chan struct{}),
+		clock:      realClock{},
+		events:     newEventHub(),
 	}
+	c.buckets.init()
 
 	if defaultTTL > 0 {
-		go c.cleanup()
+		c.SetCleanupInterval(defaultCleanupInterval)
 	}
 	
 	return c
 }
 
 // Get получает значение по ключу
-func (c *LFUCache) Get(key string) ([]byte, bool) {
-	if key == "" {
+func (c *LFUCache) Get(key string) (value []byte, ok bool) {
+	if m := c.metrics.Load(); m != nil {
+		timer := internal.NewTimer()
+		defer func() { recordGet(m, timer, ok) }()
+	}
+
+	if key == "" || c.bloomRejects(key) {
 		atomic.AddInt64(&c.misses, 1)
 		return nil, false
 	}
 	
 	c.mu.Lock()
-	defer c.mu.Unlock()
-	
+	defer c.unlock()
+
+	return c.getLocked(key)
+}
+
+// lookupLocked находит неистекший элемент и обновляет частоту и статистику.
+// Возвращает nil при промахе. Вызывается под mu.
+func (c *LFUCache) lookupLocked(key string) *lfuItem {
 	item, exists := c.items[key]
-	if !exists {
+	if !exists || c.closed {
 		atomic.AddInt64(&c.misses, 1)
-		return nil, false
+		return nil
 	}
 
-	if item.isExpired() {
-		delete(c.items, key)
+	now := c.clock.Now()
+	if item.isExpired(now) {
+		// Элемент в окне устаревания остается доступным для GetStale
+		if hardExpired(now, item.expiresAt, c.staleWindow) {
+			c.removeItem(item, cache.ReasonExpired)
+		}
 		atomic.AddInt64(&c.misses, 1)
-		return nil, false
+		atomic.AddInt64(&c.expiredMisses, 1)
+		return nil
 	}
 
-	item.touch()
+	if item.negative {
+		atomic.AddInt64(&c.misses, 1)
+		return nil
+	}
+
+	c.touch(item)
+	if c.slidingTTL && item.ttl > 0 {
+		item.expiresAt = capExpiry(expirationTime(now, item.ttl), item.createdAt, c.maxAge)
+	}
+	if c.refreshAhead.due(now, item.expiresAt, item.ttl) {
+		c.startRefresh(key, item.ttl)
+	}
 	atomic.AddInt64(&c.hits, 1)
 
-	value := make([]byte, len(item.value))
-	copy(value, item.value)
-	return value, true
+	return item
+}
+
+// getLocked получает копию значения и обновляет статистику, вызывается под mu
+func (c *LFUCache) getLocked(key string) ([]byte, bool) {
+	item := c.lookupLocked(key)
+	if item == nil {
+		return nil, false
+	}
+	return decodeValue(item.value, item.compressed), true
 }
 
 // Set сохраняет значение с TTL по умолчанию
@@ -110,94 +280,279 @@ func (c *LFUCache) Set(key string, value []byte) error {
 }
 
 // SetWithTTL сохраняет значение с указанным TTL
-func (c *LFUCache) SetWithTTL(key string, value []byte, ttl time.Duration) error {
-	if key == "" {
-		return cache.ErrKeyEmpty
+func (c *LFUCache) SetWithTTL(key string, value []byte, ttl time.Duration) (err error) {
+	if m := c.metrics.Load(); m != nil {
+		timer := internal.NewTimer()
+		defer func() {
+			if err == nil {
+				m.RecordSet(timer.Duration())
+			}
+		}()
+	}
+
+	if err := c.validate(key, value); err != nil {
+		return err
 	}
 	
 	c.mu.Lock()
-	defer c.mu.Unlock()
+	defer c.unlock()
 	
 	if c.closed {
 		return cache.ErrCacheClosed
 	}
 
-	var expiresAt time.Time
-	if ttl > 0 {
-		expiresAt = time.Now().Add(ttl)
-	} else if c.defaultTTL > 0 {
-		expiresAt = time.Now().Add(c.defaultTTL)
+	c.setLocked(key, value, ttl)
+	return nil
+}
+
+// validate проверяет что ключ и значение могут быть сохранены
+func (c *LFUCache) validate(key string, value []byte) error {
+	if key == "" {
+		return cache.ErrKeyEmpty
+	}
+	if limit := atomic.LoadInt64(&c.maxValueBytes); limit > 0 && int64(len(value)) > limit {
+		return valueTooLarge(len(value), limit)
+	}
+	return nil
+}
+
+// setLocked сохраняет значение с TTL, отклоненным на настроенную долю, вызывается под mu
+func (c *LFUCache) setLocked(key string, value []byte, ttl time.Duration) {
+	if ttl <= 0 {
+		ttl = c.defaultTTL
+	} else {
+		ttl = c.ttlBounds.clamp(ttl)
 	}
+	c.setExactLocked(key, value, c.ttlJitter.apply(ttl))
+}
 
-	valueCopy := make([]byte, len(value))
-	copy(valueCopy, value)
-	
-	now := time.Now()
+// setExactLocked сохраняет значение с вытеснением при необходимости, вызывается под mu
+func (c *LFUCache) setExactLocked(key string, value []byte, ttl time.Duration) {
+	if ttl <= 0 {
+		ttl = c.defaultTTL
+	}
+	now := c.clock.Now()
+	expiresAt := capExpiry(expirationTime(now, ttl), now, c.maxAge)
+
+	data, compressed := encodeValue(value, c.compressionThreshold)
+	rawSize := int64(len(key) + len(value))
 
 	if existingItem, exists := c.items[key]; exists {
-		existingItem.value = valueCopy
+		atomic.AddInt64(&c.bytes, -existingItem.size())
+		atomic.AddInt64(&c.rawBytes, rawSize-existingItem.rawSize)
+		existingItem.value = data
+		existingItem.compressed = compressed
+		existingItem.negative = false
+		existingItem.rawSize = rawSize
 		existingItem.expiresAt = expiresAt
+		existingItem.createdAt = now
+		existingItem.ttl = ttl
 		existingItem.lastAccess = now
-		return nil
+		c.tags.remove(key, existingItem.tags)
+		existingItem.tags = nil
+		atomic.AddInt64(&c.bytes, existingItem.size())
+
+		// Перезапись считается обращением, но не увеличивает частоту
+		bucket := existingItem.bucket
+		existingItem.unlink()
+		bucket.pushFront(existingItem)
+		c.events.publish(cache.EventSet, key)
+		return
 	}
 
-	if len(c.items) >= c.maxSize {
+	if len(c.items) >= c.maxSize && c.reapExpiredSample() == 0 {
 		c.evictLFU()
 	}
 
 	newItem := &lfuItem{
 		key:        key,
-		value:      valueCopy,
+		value:      data,
 		expiresAt:  expiresAt,
+		createdAt:  now,
+		ttl:        ttl,
 		frequency:  1, // Начальная частота
 		lastAccess: now,
+		rawSize:    rawSize,
+		compressed: compressed,
 	}
 	
 	c.items[key] = newItem
-	return nil
+	atomic.AddInt64(&c.keyCount, 1)
+	c.bloomAdd(key)
+	c.buckets.bucketAfter(&c.buckets.root, newItem.frequency).pushFront(newItem)
+	atomic.AddInt64(&c.bytes, newItem.size())
+	atomic.AddInt64(&c.rawBytes, rawSize)
+	c.events.publish(cache.EventSet, key)
+}
+
+// GetOrSet возвращает значение по ключу или загружает его через loader при промахе
+func (c *LFUCache) GetOrSet(key string, loader func() ([]byte, error), ttl time.Duration) ([]byte, error) {
+	if key == "" {
+		return nil, cache.ErrKeyEmpty
+	}
+
+	if value, exists := c.Get(key); exists {
+		return value, nil
+	}
+
+	shared, err := c.loads.Do(key, func() ([]byte, error) {
+		value, err := loader()
+		if err != nil {
+			return nil, err
+		}
+		if err := c.SetWithTTL(key, value, ttl); err != nil {
+			return nil, err
+		}
+		return value, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Результат общий для всех ожидающих, поэтому каждый получает свою копию
+	value := make([]byte, len(shared))
+	copy(value, shared)
+	return value, nil
+}
+
+// GetTTL возвращает оставшееся время жизни ключа без обновления статистики
+func (c *LFUCache) GetTTL(key string) (time.Duration, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	item, exists := c.items[key]
+	if !exists || item.isExpired(c.clock.Now()) {
+		return 0, false
+	}
+	return remainingTTL(c.clock.Now(), item.expiresAt)
+}
+
+// Expire устанавливает новое время жизни ключа не увеличивая частоту использования
+func (c *LFUCache) Expire(key string, ttl time.Duration) bool {
+	c.mu.Lock()
+	defer c.unlock()
+
+	item, exists := c.items[key]
+	if !exists || item.isExpired(c.clock.Now()) {
+		return false
+	}
+
+	item.expiresAt = capExpiry(expirationTime(c.clock.Now(), ttl), item.createdAt, c.maxAge)
+	item.ttl = max(ttl, 0)
+	return true
+}
+
+// Persist делает ключ бессрочным. Фоновая очистка не трогает элементы
+// с нулевым expiresAt, поэтому такой ключ может быть удален только явно или вытеснением.
+func (c *LFUCache) Persist(key string) bool {
+	return c.Expire(key, 0)
 }
 
 // Delete удаляет ключ из кэша
 func (c *LFUCache) Delete(key string) bool {
+	if m := c.metrics.Load(); m != nil {
+		timer := internal.NewTimer()
+		defer func() { m.RecordDelete(timer.Duration()) }()
+	}
+
 	if key == "" {
 		return false
 	}
 	
 	c.mu.Lock()
-	defer c.mu.Unlock()
-	
-	_, exists := c.items[key]
+	defer c.unlock()
+
+	if c.closed {
+		return false
+	}
+	return c.deleteLocked(key)
+}
+
+// deleteLocked удаляет ключ, вызывается под mu
+func (c *LFUCache) deleteLocked(key string) bool {
+	item, exists := c.items[key]
 	if exists {
-		delete(c.items, key)
+		c.removeItem(item, cache.ReasonDeleted)
 		return true
 	}
 	
 	return false
 }
 
+// Keys возвращает все неистекшие ключи в неопределенном порядке
+func (c *LFUCache) Keys() []string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	keys := make([]string, 0, len(c.items))
+	for key, item := range c.items {
+		if !item.isExpired(c.clock.Now()) && !item.negative {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// ForEach вызывает fn для каждого неистекшего элемента, пока fn возвращает true.
+// fn получает копию значения. Во время обхода удерживается блокировка на чтение,
+// поэтому fn не должна обращаться к этому же кэшу - это приведет к взаимоблокировке.
+func (c *LFUCache) ForEach(fn func(key string, value []byte) bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	for key, item := range c.items {
+		if item.isExpired(c.clock.Now()) || item.negative {
+			continue
+		}
+
+		if !fn(key, decodeValue(item.value, item.compressed)) {
+			return
+		}
+	}
+}
+
+// Len возвращает количество элементов без построения Stats.
+// Может учитывать истекшие элементы, которые еще не удалены.
+func (c *LFUCache) Len() int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return len(c.items)
+}
+
 // Clear очищает весь кэш
 func (c *LFUCache) Clear() {
 	c.mu.Lock()
-	defer c.mu.Unlock()
+	defer c.unlock()
+
+	for key, item := range c.items {
+		c.evictQueue.pushEncoded(key, item.value, item.compressed, cache.ReasonCleared)
+	}
 	
 	c.items = make(map[string]*lfuItem)
+	c.buckets.init()
+	atomic.StoreInt64(&c.bytes, 0)
+	atomic.StoreInt64(&c.rawBytes, 0)
+	atomic.StoreInt64(&c.keyCount, 0)
+	c.bloomReset()
+	c.tags = nil
+	c.events.publish(cache.EventClear, "")
 
 	atomic.StoreInt64(&c.hits, 0)
 	atomic.StoreInt64(&c.misses, 0)
+	atomic.StoreInt64(&c.expiredMisses, 0)
 	atomic.StoreInt64(&c.evictions, 0)
 }
 
 // Stats возвращает статистику кэша
 func (c *LFUCache) Stats() cache.Stats {
-	c.mu.RLock()
-	keys := int64(len(c.items))
-	c.mu.RUnlock()
-	
 	stats := cache.Stats{
-		Hits:      atomic.LoadInt64(&c.hits),
-		Misses:    atomic.LoadInt64(&c.misses),
-		Keys:      keys,
-		Evictions: atomic.LoadInt64(&c.evictions),
+		Hits:          atomic.LoadInt64(&c.hits),
+		Misses:        atomic.LoadInt64(&c.misses),
+		ExpiredMisses: atomic.LoadInt64(&c.expiredMisses),
+		Keys:          atomic.LoadInt64(&c.keyCount),
+		Evictions:     atomic.LoadInt64(&c.evictions),
+		Bytes:         atomic.LoadInt64(&c.bytes),
+		RawBytes:      atomic.LoadInt64(&c.rawBytes),
 	}
 	
 	stats.CalculateHitRate()
@@ -206,55 +561,86 @@ func (c *LFUCache) Stats() cache.Stats {
 
 // Close корректно завершает работу кэша
 func (c *LFUCache) Close() error {
-	c.mu.Lock()
-	defer c.mu.Unlock()
-	
-	if c.closed {
-		return nil
+	if items, onClose := c.shutdown(); onClose != nil {
+		onClose(items)
 	}
-	
-	c.closed = true
-	close(c.stopCh)
 	return nil
 }
 
-// evictLFU удаляет наименее часто используемый элемент
+// removeItem удаляет элемент из кэша
+func (c *LFUCache) removeItem(item *lfuItem, reason cache.EvictionReason) {
+	delete(c.items, item.key)
+	atomic.AddInt64(&c.keyCount, -1)
+	c.bloomRemove(item.key)
+	c.buckets.remove(item)
+	c.evictQueue.pushEncoded(item.key, item.value, item.compressed, reason)
+	c.events.publishRemoved(item.key, reason)
+	c.tags.remove(item.key, item.tags)
+	atomic.AddInt64(&c.bytes, -item.size())
+	atomic.AddInt64(&c.rawBytes, -item.rawSize)
+}
+
+// SetCompressionThreshold включает сжатие flate для значений длиннее threshold байт.
+// Действует на последующие записи, 0 отключает сжатие.
+func (c *LFUCache) SetCompressionThreshold(threshold int) {
+	c.mu.Lock()
+	c.compressionThreshold = threshold
+	c.mu.Unlock()
+}
+
+// SetOnEvict устанавливает колбэк, вызываемый при вытеснении, истечении,
+// удалении и очистке элементов. nil отключает уведомления.
+func (c *LFUCache) SetOnEvict(fn cache.EvictCallback) {
+	c.mu.Lock()
+	c.evictQueue.onEvict = fn
+	c.mu.Unlock()
+}
+
+// unlock снимает блокировку на запись и вызывает колбэк для элементов,
+// удаленных пока она удерживалась
+func (c *LFUCache) unlock() {
+	c.reportSizeLocked()
+	onEvict, evicted := c.evictQueue.take()
+	c.mu.Unlock()
+	notifyEvicted(onEvict, evicted)
+}
+
+// touch увеличивает частоту элемента и переносит его в начало следующей корзины, вызывается под mu
+func (c *LFUCache) touch(item *lfuItem) {
+	item.frequency++
+	item.lastAccess = c.clock.Now()
+
+	next := c.buckets.bucketAfter(item.bucket, item.frequency)
+	c.buckets.remove(item)
+	next.pushFront(item)
+}
+
+// evictLFU удаляет наименее часто используемый элемент за O(1)
 func (c *LFUCache) evictLFU() {
-	if len(c.items) == 0 {
+	item := c.buckets.back()
+	if item == nil {
 		return
 	}
-	
-	var evictKey string
-	var minFrequency int64 = -1
-	var oldestTime time.Time
 
-	for key, item := range c.items {
-		frequency := atomic.LoadInt64(&item.frequency)
-		
-		if minFrequency == -1 || 
-		   frequency < minFrequency || 
-		   (frequency == minFrequency && item.lastAccess.Before(oldestTime)) {
-			minFrequency = frequency
-			evictKey = key
-			oldestTime = item.lastAccess
-		}
-	}
-	
-	if evictKey != "" {
-		delete(c.items, evictKey)
-		atomic.AddInt64(&c.evictions, 1)
+	c.removeItem(item, cache.ReasonCapacity)
+	atomic.AddInt64(&c.evictions, 1)
+	if m := c.metrics.Load(); m != nil {
+		m.RecordEviction()
 	}
 }
 
-// cleanup фоновая очистка истекших элементов
-func (c *LFUCache) cleanup() {
-	ticker := time.NewTicker(1 * time.Minute)
+// cleanup фоновая очистка истекших элементов с периодом interval до закрытия stop или кэша
+func (c *LFUCache) cleanup(interval time.Duration, stop <-chan struct{}) {
+	ticker := time.NewTicker(interval)
 	defer ticker.Stop()
 	
 	for {
 		select {
 		case <-ticker.C:
 			c.removeExpired()
+			c.reaper.done()
+		case <-stop:
+			return
 		case <-c.stopCh:
 			return
 		}
@@ -264,18 +650,18 @@ func (c *LFUCache) cleanup() {
 // removeExpired удаляет все истекшие элементы
 func (c *LFUCache) removeExpired() {
 	c.mu.Lock()
-	defer c.mu.Unlock()
+	defer c.unlock()
 	
 	var expiredKeys []string
 	
 	for key, item := range c.items {
-		if item.isExpired() {
+		if hardExpired(c.clock.Now(), item.expiresAt, c.staleWindow) {
 			expiredKeys = append(expiredKeys, key)
 		}
 	}
 
 	for _, key := range expiredKeys {
-		delete(c.items, key)
+		c.removeItem(c.items[key], cache.ReasonExpired)
 	}
 	
 	if len(expiredKeys) > 0 {
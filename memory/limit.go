@@ -0,0 +1,38 @@
+package memory
+
+import (
+	"fmt"
+	"sync/atomic"
+
+	cache "github.com/VsRnA/High-Performance-HTTP-Cache"
+)
+
+// SetMaxValueBytes ограничивает длину сохраняемых значений. Запись большего значения
+// возвращает cache.ErrValueTooLarge до копирования данных. 0 снимает ограничение.
+func (c *LRUCache) SetMaxValueBytes(limit int) {
+	atomic.StoreInt64(&c.maxValueBytes, int64(max(limit, 0)))
+}
+
+// SetMaxValueBytes ограничивает длину сохраняемых значений. Запись большего значения
+// возвращает cache.ErrValueTooLarge до копирования данных. 0 снимает ограничение.
+func (c *LFUCache) SetMaxValueBytes(limit int) {
+	atomic.StoreInt64(&c.maxValueBytes, int64(max(limit, 0)))
+}
+
+// SetMaxValueBytes ограничивает длину сохраняемых значений. Запись большего значения
+// возвращает cache.ErrValueTooLarge до копирования данных. 0 снимает ограничение.
+func (c *SimpleCache) SetMaxValueBytes(limit int) {
+	atomic.StoreInt64(&c.maxValueBytes, int64(max(limit, 0)))
+}
+
+// SetMaxValueBytes ограничивает длину сохраняемых значений во всех шардах
+func (c *ShardedCache) SetMaxValueBytes(limit int) {
+	for _, shard := range c.shards {
+		shard.SetMaxValueBytes(limit)
+	}
+}
+
+// valueTooLarge оборачивает ErrValueTooLarge с длиной значения и ограничением SetMaxValueBytes
+func valueTooLarge(size int, limit int64) error {
+	return fmt.Errorf("%w: %d байт при ограничении %d", cache.ErrValueTooLarge, size, limit)
+}
@@ -0,0 +1,81 @@
+package memory
+
+import (
+	"time"
+
+	cache "github.com/VsRnA/High-Performance-HTTP-Cache"
+)
+
+// LoadOrStore возвращает текущее значение ключа и loaded = true, если ключ
+// существует и не истек. Иначе сохраняет value с ttl и возвращает его с loaded = false.
+// В отличие от SetNX возвращает победившее значение в обоих случаях, как sync.Map.
+// Проверка и запись выполняются под одной блокировкой. Найденное значение
+// считается обращением, как при Get, запись - промахом.
+func (c *LRUCache) LoadOrStore(key string, value []byte, ttl time.Duration) (actual []byte, loaded bool, err error) {
+	if err := c.validate(key, value); err != nil {
+		return nil, false, err
+	}
+
+	c.mu.Lock()
+	defer c.unlock()
+
+	if c.closed {
+		return nil, false, cache.ErrCacheClosed
+	}
+
+	if item := c.lookupLocked(key); item != nil {
+		return decodeValue(item.value, item.compressed), true, nil
+	}
+
+	c.setLocked(key, value, ttl)
+	return value, false, nil
+}
+
+// LoadOrStore возвращает текущее значение ключа или сохраняет value,
+// см. LRUCache.LoadOrStore
+func (c *LFUCache) LoadOrStore(key string, value []byte, ttl time.Duration) (actual []byte, loaded bool, err error) {
+	if err := c.validate(key, value); err != nil {
+		return nil, false, err
+	}
+
+	c.mu.Lock()
+	defer c.unlock()
+
+	if c.closed {
+		return nil, false, cache.ErrCacheClosed
+	}
+
+	if item := c.lookupLocked(key); item != nil {
+		return decodeValue(item.value, item.compressed), true, nil
+	}
+
+	c.setLocked(key, value, ttl)
+	return value, false, nil
+}
+
+// LoadOrStore возвращает текущее значение ключа или сохраняет value,
+// см. LRUCache.LoadOrStore
+func (c *SimpleCache) LoadOrStore(key string, value []byte, ttl time.Duration) (actual []byte, loaded bool, err error) {
+	if err := c.validate(key, value); err != nil {
+		return nil, false, err
+	}
+
+	c.mu.Lock()
+	defer c.unlock()
+
+	if c.closed {
+		return nil, false, cache.ErrCacheClosed
+	}
+
+	if item := c.lookupLocked(key); item != nil {
+		return decodeValue(item.value, item.compressed), true, nil
+	}
+
+	c.setLocked(key, value, ttl)
+	return value, false, nil
+}
+
+// LoadOrStore возвращает текущее значение ключа в его шарде или сохраняет value
+func (c *ShardedCache) LoadOrStore(key string, value []byte, ttl time.Duration) ([]byte, bool, error) {
+	return c.shard(key).LoadOrStore(key, value, ttl)
+}
@@ -2,11 +2,13 @@
 package memory
 
 import (
+	"fmt"
 	"sync"
 	"sync/atomic"
 	"time"
 
 	cache "github.com/VsRnA/High-Performance-HTTP-Cache"
+	"github.com/VsRnA/High-Performance-HTTP-Cache/internal"
 )
 
 // lruItem представляет элемент в LRU кэше
@@ -14,12 +16,24 @@ type lruItem struct {
 	key        string
 	value      []byte
 	expiresAt  time.Time
+	createdAt  time.Time // Момент записи текущего значения для SetMaxAge
+	ttl        time.Duration
+	size       int64 // Занимаемый объем: длина ключа плюс длина хранимого значения
+	rawSize    int64 // Объем до сжатия
+	compressed bool  // value хранится сжатым flate
+	negative   bool  // Отрицательная запись SetNegative без значения
+	tags       []string
+	hits       int64     // Попадания для TopKeys
+	lastAccess time.Time // Последнее попадание или запись
 	prev, next *lruItem
+
+	// Хранилище коротких значений, заполняется только при создании элемента
+	inline [inlineValueSize]byte
 }
 
-// isExpired проверяет истек ли элемент
-func (item *lruItem) isExpired() bool {
-	return !item.expiresAt.IsZero() && time.Now().After(item.expiresAt)
+// isExpired проверяет истек ли элемент на момент now
+func (item *lruItem) isExpired(now time.Time) bool {
+	return !item.expiresAt.IsZero() && now.After(item.expiresAt)
 }
 
 // LRUCache реализует Least Recently Used кэш
@@ -31,17 +45,83 @@ type LRUCache struct {
 	mu       sync.RWMutex
 	
 	// Конфигурация
-	maxSize    int
+	maxSize    int   // Максимальное количество элементов, 0 - без ограничения
+	maxBytes   int64 // Максимальный объем в байтах, 0 - без ограничения
 	defaultTTL time.Duration
+
+	// Значения длиннее порога сжимаются, 0 - без сжатия
+	compressionThreshold int
+
+	// Окно после истечения TTL, в течение которого элемент доступен через GetStale
+	staleWindow time.Duration
+
+	// Случайное отклонение TTL при записи и границы явно запрошенного TTL
+	ttlJitter ttlJitter
+	ttlBounds ttlBounds
+
+	// Get продлевает элемент на его исходный TTL
+	slidingTTL bool
+
+	// Предельный возраст элемента независимо от TTL, 0 - без ограничения
+	maxAge time.Duration
+
+	// Допустимое превышение лимитов SetEvictionHeadroom и сигнал фоновому вытеснению
+	headroom float64
+	trimCh   chan struct{}
+
+	// Максимальная длина значения, 0 - без ограничения.
+	// Изменяется атомарно, так как validate вызывается до захвата mu.
+	maxValueBytes int64
+
+	// Упреждающее обновление элементов перед истечением TTL
+	refreshAhead RefreshAhead
+	refreshes    refreshGroup
+
+	// Источник времени для TTL и времени обращения, изменяется под mu
+	clock Clock
+
+	// Текущий объем хранимых данных, объем до сжатия и число элементов в items.
+	// Изменяются под mu атомарно, чтобы Stats читал их без блокировки.
+	bytes    int64
+	rawBytes int64
+	keyCount int64
+
+	// Обратный индекс тегов SetWithTags, изменяется под mu
+	tags tagIndex
 	
 	// Управление жизненным циклом
-	stopCh chan struct{}
-	closed bool
+	stopCh      chan struct{}
+	cleanupStop chan struct{} // Останавливает текущую горутину очистки, nil - очистка не запущена
+	reaper      reaperState   // Состояние фоновой очистки для Health
+	closed      bool
+	onClose     func(items map[string][]byte) // Получает живые элементы при Close
+	
+	// Уведомления об удаленных элементах
+	evictQueue evictionQueue
+
+	// Подписчики на события изменений, общий для всех шардов ShardedCache
+	events *eventHub
+
+	// Получатель элементов, вытесненных по емкости, для TieredCache. Вызывается под mu.
+	spill func(key string, value []byte, expiresAt time.Time)
+
+	// Дедупликация одновременных загрузок в GetOrSet
+	loads internal.Group
 	
+	// Детальные метрики SetMetrics, nil - сбор отключен
+	metrics atomic.Pointer[cache.Metrics]
+
+	// Фильтр Блума SetBloomFilter перед картой, nil - отключен.
+	// Изменяется под mu, Get читает его без блокировки.
+	bloom atomic.Pointer[internal.CountingBloom]
+
 	// Статистика (atomic для производительности)
 	hits      int64
 	misses    int64
 	evictions int64
+
+	// Промахи по истекшим, но еще не удаленным элементам, входят в misses
+	expiredMisses int64
 }
 
 // NewLRU создает новый LRU кэш с указанным максимальным размером
@@ -51,15 +131,35 @@ func NewLRU(maxSize int) cache.Cache {
 
 // NewLRUWithTTL создает новый LRU кэш с максимальным размером и TTL по умолчанию
 func NewLRUWithTTL(maxSize int, defaultTTL time.Duration) cache.Cache {
-	if maxSize <= 0 {
+	return newLRU(maxSize, 0, defaultTTL)
+}
+
+// NewLRUWithBytes создает LRU кэш, ограниченный суммарным объемом ключей и значений в байтах.
+// Количество элементов не ограничивается.
+func NewLRUWithBytes(maxBytes int64) cache.Cache {
+	return newLRU(0, maxBytes, 0)
+}
+
+// newLRU создает LRU кэш и возвращает конкретный тип для внутреннего использования
+func newLRU(maxSize int, maxBytes int64, defaultTTL time.Duration) *LRUCache {
+	if maxSize <= 0 && maxBytes <= 0 {
 		maxSize = 1000
 	}
+	if maxSize < 0 {
+		maxSize = 0
+	}
+	if maxBytes < 0 {
+		maxBytes = 0
+	}
 	
 	c := &LRUCache{
 		items:      make(map[string]*lruItem, maxSize),
 		maxSize:    maxSize,
+		maxBytes:   maxBytes,
 		defaultTTL: defaultTTL,
 		stopCh:     make(chan struct{}),
+		clock:      realClock{},
+		events:     newEventHub(),
 	}
 
 	c.head = &lruItem{}
@@ -68,41 +168,77 @@ func NewLRUWithTTL(maxSize int, defaultTTL time.Duration) cache.Cache {
 	c.tail.prev = c.head
 	
 	if defaultTTL > 0 {
-		go c.cleanup()
+		c.SetCleanupInterval(defaultCleanupInterval)
 	}
 	
 	return c
 }
 
 // Get получает значение по ключу
-func (c *LRUCache) Get(key string) ([]byte, bool) {
-	if key == "" {
+func (c *LRUCache) Get(key string) (value []byte, ok bool) {
+	if m := c.metrics.Load(); m != nil {
+		timer := internal.NewTimer()
+		defer func() { recordGet(m, timer, ok) }()
+	}
+
+	if key == "" || c.bloomRejects(key) {
 		atomic.AddInt64(&c.misses, 1)
 		return nil, false
 	}
 	
 	c.mu.Lock()
-	defer c.mu.Unlock()
-	
+	defer c.unlock()
+
+	return c.getLocked(key)
+}
+
+// lookupLocked находит неистекший элемент и обновляет порядок и статистику.
+// Возвращает nil при промахе. Вызывается под mu.
+func (c *LRUCache) lookupLocked(key string) *lruItem {
 	item, exists := c.items[key]
-	if !exists {
+	if !exists || c.closed {
 		atomic.AddInt64(&c.misses, 1)
-		return nil, false
+		return nil
 	}
 
-	if item.isExpired() {
-		c.removeItem(item)
+	now := c.clock.Now()
+	if item.isExpired(now) {
+		// Элемент в окне устаревания остается доступным для GetStale
+		if hardExpired(now, item.expiresAt, c.staleWindow) {
+			c.removeItem(item, cache.ReasonExpired)
+		}
 		atomic.AddInt64(&c.misses, 1)
-		return nil, false
+		atomic.AddInt64(&c.expiredMisses, 1)
+		return nil
+	}
+
+	if item.negative {
+		atomic.AddInt64(&c.misses, 1)
+		return nil
 	}
 
 	c.moveToHead(item)
+	item.hits++
+	item.lastAccess = now
+	if c.slidingTTL && item.ttl > 0 {
+		item.expiresAt = capExpiry(expirationTime(now, item.ttl), item.createdAt, c.maxAge)
+	}
+	if c.refreshAhead.due(now, item.expiresAt, item.ttl) {
+		c.startRefresh(key, item.ttl)
+	}
 	
 	atomic.AddInt64(&c.hits, 1)
 
-	value := make([]byte, len(item.value))
-	copy(value, item.value)
-	return value, true
+	return item
+}
+
+// getLocked получает копию значения и обновляет статистику, вызывается под mu
+func (c *LRUCache) getLocked(key string) ([]byte, bool) {
+	item := c.lookupLocked(key)
+	if item == nil {
+		return nil, false
+	}
+	return decodeValue(item.value, item.compressed), true
 }
 
 // Set сохраняет значение с TTL по умолчанию
@@ -111,93 +247,293 @@ func (c *LRUCache) Set(key string, value []byte) error {
 }
 
 // SetWithTTL сохраняет значение с указанным TTL
-func (c *LRUCache) SetWithTTL(key string, value []byte, ttl time.Duration) error {
-	if key == "" {
-		return cache.ErrKeyEmpty
+func (c *LRUCache) SetWithTTL(key string, value []byte, ttl time.Duration) (err error) {
+	if m := c.metrics.Load(); m != nil {
+		timer := internal.NewTimer()
+		defer func() {
+			if err == nil {
+				m.RecordSet(timer.Duration())
+			}
+		}()
+	}
+
+	if err := c.validate(key, value); err != nil {
+		return err
 	}
 	
 	c.mu.Lock()
-	defer c.mu.Unlock()
+	defer c.unlock()
 	
 	if c.closed {
 		return cache.ErrCacheClosed
 	}
 
-	var expiresAt time.Time
-	if ttl > 0 {
-		expiresAt = time.Now().Add(ttl)
-	} else if c.defaultTTL > 0 {
-		expiresAt = time.Now().Add(c.defaultTTL)
+	c.setLocked(key, value, ttl)
+	return nil
+}
+
+// validate проверяет что ключ и значение могут быть сохранены
+func (c *LRUCache) validate(key string, value []byte) error {
+	if key == "" {
+		return cache.ErrKeyEmpty
+	}
+	if size := int64(len(key) + len(value)); c.maxBytes > 0 && size > c.maxBytes {
+		return fmt.Errorf("%w: элемент %d байт при объеме кэша %d", cache.ErrValueTooLarge, size, c.maxBytes)
+	}
+	if limit := atomic.LoadInt64(&c.maxValueBytes); limit > 0 && int64(len(value)) > limit {
+		return valueTooLarge(len(value), limit)
+	}
+	return nil
+}
+
+// setLocked сохраняет значение с TTL, отклоненным на настроенную долю, вызывается под mu
+func (c *LRUCache) setLocked(key string, value []byte, ttl time.Duration) {
+	if ttl <= 0 {
+		ttl = c.defaultTTL
+	} else {
+		ttl = c.ttlBounds.clamp(ttl)
+	}
+	c.setExactLocked(key, value, c.ttlJitter.apply(ttl))
+}
+
+// setExactLocked сохраняет значение с вытеснением при необходимости, вызывается под mu
+func (c *LRUCache) setExactLocked(key string, value []byte, ttl time.Duration) {
+	if ttl <= 0 {
+		ttl = c.defaultTTL
 	}
+	createdAt := c.clock.Now()
+	expiresAt := capExpiry(expirationTime(createdAt, ttl), createdAt, c.maxAge)
 
-	valueCopy := make([]byte, len(value))
-	copy(valueCopy, value)
+	rawSize := int64(len(key) + len(value))
 
 	if existingItem, exists := c.items[key]; exists {
-		existingItem.value = valueCopy
+		// Встроенный буфер не переписывается: на старое значение могут ссылаться срезы GetRef
+		data, compressed := encodeValue(value, c.compressionThreshold)
+		size := int64(len(key) + len(data))
+		atomic.AddInt64(&c.bytes, size-existingItem.size)
+		atomic.AddInt64(&c.rawBytes, rawSize-existingItem.rawSize)
+		existingItem.value = data
+		existingItem.compressed = compressed
+		existingItem.negative = false
+		existingItem.size = size
+		existingItem.rawSize = rawSize
 		existingItem.expiresAt = expiresAt
+		existingItem.createdAt = createdAt
+		existingItem.ttl = ttl
+		existingItem.lastAccess = createdAt
+		c.tags.remove(key, existingItem.tags)
+		existingItem.tags = nil
 		c.moveToHead(existingItem)
-		return nil
+		c.events.publish(cache.EventSet, key)
+		c.evictOverBytes()
+		c.signalTrim()
+		return
 	}
 
 	newItem := &lruItem{
-		key:       key,
-		value:     valueCopy,
-		expiresAt: expiresAt,
+		key:        key,
+		expiresAt:  expiresAt,
+		createdAt:  createdAt,
+		ttl:        ttl,
+		rawSize:    rawSize,
+		lastAccess: createdAt,
 	}
+	newItem.value, newItem.compressed = storeValue(value, c.compressionThreshold, &newItem.inline)
+	newItem.size = int64(len(key) + len(newItem.value))
 
-	if len(c.items) >= c.maxSize {
+	if c.maxSize > 0 && len(c.items) >= c.hardMaxSize() && c.reapExpiredSample() == 0 {
 		c.evictTail()
 	}
 
 	c.items[key] = newItem
+	atomic.AddInt64(&c.keyCount, 1)
+	c.bloomAdd(key)
 	c.addToHead(newItem)
-	
-	return nil
+	atomic.AddInt64(&c.bytes, newItem.size)
+	atomic.AddInt64(&c.rawBytes, rawSize)
+	c.events.publish(cache.EventSet, key)
+	c.evictOverBytes()
+	c.signalTrim()
+}
+
+// GetOrSet возвращает значение по ключу или загружает его через loader при промахе
+func (c *LRUCache) GetOrSet(key string, loader func() ([]byte, error), ttl time.Duration) ([]byte, error) {
+	if key == "" {
+		return nil, cache.ErrKeyEmpty
+	}
+
+	if value, exists := c.Get(key); exists {
+		return value, nil
+	}
+
+	shared, err := c.loads.Do(key, func() ([]byte, error) {
+		value, err := loader()
+		if err != nil {
+			return nil, err
+		}
+		if err := c.SetWithTTL(key, value, ttl); err != nil {
+			return nil, err
+		}
+		return value, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Результат общий для всех ожидающих, поэтому каждый получает свою копию
+	value := make([]byte, len(shared))
+	copy(value, shared)
+	return value, nil
+}
+
+// GetTTL возвращает оставшееся время жизни ключа без обновления статистики
+func (c *LRUCache) GetTTL(key string) (time.Duration, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	item, exists := c.items[key]
+	if !exists || item.isExpired(c.clock.Now()) {
+		return 0, false
+	}
+	return remainingTTL(c.clock.Now(), item.expiresAt)
+}
+
+// Expire устанавливает новое время жизни ключа.
+// Изменение TTL не считается обращением и не перемещает элемент в начало списка LRU.
+func (c *LRUCache) Expire(key string, ttl time.Duration) bool {
+	c.mu.Lock()
+	defer c.unlock()
+
+	item, exists := c.items[key]
+	if !exists || item.isExpired(c.clock.Now()) {
+		return false
+	}
+
+	item.expiresAt = capExpiry(expirationTime(c.clock.Now(), ttl), item.createdAt, c.maxAge)
+	item.ttl = max(ttl, 0)
+	return true
+}
+
+// Persist делает ключ бессрочным. Фоновая очистка не трогает элементы
+// с нулевым expiresAt, поэтому такой ключ может быть удален только явно или вытеснением.
+func (c *LRUCache) Persist(key string) bool {
+	return c.Expire(key, 0)
 }
 
 // Delete удаляет ключ из кэша
 func (c *LRUCache) Delete(key string) bool {
+	if m := c.metrics.Load(); m != nil {
+		timer := internal.NewTimer()
+		defer func() { m.RecordDelete(timer.Duration()) }()
+	}
+
 	if key == "" {
 		return false
 	}
 	
 	c.mu.Lock()
-	defer c.mu.Unlock()
-	
+	defer c.unlock()
+
+	if c.closed {
+		return false
+	}
+	return c.deleteLocked(key)
+}
+
+// deleteLocked удаляет ключ, вызывается под mu
+func (c *LRUCache) deleteLocked(key string) bool {
 	item, exists := c.items[key]
 	if !exists {
 		return false
 	}
 	
-	c.removeItem(item)
+	c.removeItem(item, cache.ReasonDeleted)
 	return true
 }
 
+// Keys возвращает все неистекшие ключи от недавно использованных к давно использованным
+func (c *LRUCache) Keys() []string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	keys := make([]string, 0, len(c.items))
+	for item := c.head.next; item != c.tail; item = item.next {
+		if !item.isExpired(c.clock.Now()) && !item.negative {
+			keys = append(keys, item.key)
+		}
+	}
+	return keys
+}
+
+// ForEach вызывает fn для каждого неистекшего элемента от недавно использованных
+// к давно использованным, пока fn возвращает true. fn получает копию значения.
+// Обход не меняет порядок LRU. Во время обхода удерживается блокировка на чтение,
+// поэтому fn не должна обращаться к этому же кэшу - это приведет к взаимоблокировке.
+func (c *LRUCache) ForEach(fn func(key string, value []byte) bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	for item := c.head.next; item != c.tail; item = item.next {
+		if item.isExpired(c.clock.Now()) || item.negative {
+			continue
+		}
+
+		if !fn(item.key, decodeValue(item.value, item.compressed)) {
+			return
+		}
+	}
+}
+
+// Len возвращает количество элементов без построения Stats.
+// Может учитывать истекшие элементы, которые еще не удалены.
+func (c *LRUCache) Len() int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return len(c.items)
+}
+
 // Clear очищает весь кэш
 func (c *LRUCache) Clear() {
+	c.clearItems(true)
+}
+
+// clearItems очищает кэш и при publish отправляет EventClear. ShardedCache очищает
+// шарды без события, потому что у шардов общий eventHub, и отправляет его сам.
+func (c *LRUCache) clearItems(publish bool) {
 	c.mu.Lock()
-	defer c.mu.Unlock()
+	defer c.unlock()
+
+	for item := c.head.next; item != c.tail; item = item.next {
+		c.evictQueue.pushEncoded(item.key, item.value, item.compressed, cache.ReasonCleared)
+	}
 	
 	c.items = make(map[string]*lruItem)
 	c.head.next = c.tail
 	c.tail.prev = c.head
+	atomic.StoreInt64(&c.bytes, 0)
+	atomic.StoreInt64(&c.rawBytes, 0)
+	atomic.StoreInt64(&c.keyCount, 0)
+	c.bloomReset()
+	c.tags = nil
+	if publish {
+		c.events.publish(cache.EventClear, "")
+	}
 
 	atomic.StoreInt64(&c.hits, 0)
 	atomic.StoreInt64(&c.misses, 0)
+	atomic.StoreInt64(&c.expiredMisses, 0)
 	atomic.StoreInt64(&c.evictions, 0)
 }
 
 func (c *LRUCache) Stats() cache.Stats {
-	c.mu.RLock()
-	keys := int64(len(c.items))
-	c.mu.RUnlock()
-	
 	stats := cache.Stats{
-		Hits:      atomic.LoadInt64(&c.hits),
-		Misses:    atomic.LoadInt64(&c.misses),
-		Keys:      keys,
-		Evictions: atomic.LoadInt64(&c.evictions),
+		Hits:          atomic.LoadInt64(&c.hits),
+		Misses:        atomic.LoadInt64(&c.misses),
+		ExpiredMisses: atomic.LoadInt64(&c.expiredMisses),
+		Keys:          atomic.LoadInt64(&c.keyCount),
+		Evictions:     atomic.LoadInt64(&c.evictions),
+		Bytes:         atomic.LoadInt64(&c.bytes),
+		RawBytes:      atomic.LoadInt64(&c.rawBytes),
 	}
 	
 	stats.CalculateHitRate()
@@ -205,18 +541,38 @@ func (c *LRUCache) Stats() cache.Stats {
 }
 
 func (c *LRUCache) Close() error {
-	c.mu.Lock()
-	defer c.mu.Unlock()
-	
-	if c.closed {
-		return nil
+	if items, onClose := c.shutdown(false); onClose != nil {
+		onClose(items)
 	}
-	
-	c.closed = true
-	close(c.stopCh)
 	return nil
 }
 
+// SetCompressionThreshold включает сжатие flate для значений длиннее threshold байт.
+// Действует на последующие записи, 0 отключает сжатие. Лимит NewLRUWithBytes
+// применяется к сжатому объему, поэтому сжатие увеличивает емкость кэша.
+func (c *LRUCache) SetCompressionThreshold(threshold int) {
+	c.mu.Lock()
+	c.compressionThreshold = threshold
+	c.mu.Unlock()
+}
+
+// SetOnEvict устанавливает колбэк, вызываемый при вытеснении, истечении,
+// удалении и очистке элементов. nil отключает уведомления.
+func (c *LRUCache) SetOnEvict(fn cache.EvictCallback) {
+	c.mu.Lock()
+	c.evictQueue.onEvict = fn
+	c.mu.Unlock()
+}
+
+// unlock снимает блокировку на запись и вызывает колбэк для элементов,
+// удаленных пока она удерживалась
+func (c *LRUCache) unlock() {
+	c.reportSizeLocked()
+	onEvict, evicted := c.evictQueue.take()
+	c.mu.Unlock()
+	notifyEvicted(onEvict, evicted)
+}
+
 // Приватные методы для управления двусвязным списком
 
 // addToHead добавляет элемент в начало списка
@@ -243,26 +599,59 @@ func (c *LRUCache) moveToHead(item *lruItem) {
 func (c *LRUCache) evictTail() {
 	lastItem := c.tail.prev
 	if lastItem != c.head {
-		c.removeItem(lastItem)
+		if c.spill != nil && !lastItem.isExpired(c.clock.Now()) && !lastItem.negative {
+			c.spill(lastItem.key, rawValue(lastItem.value, lastItem.compressed), lastItem.expiresAt)
+		}
+		c.removeItem(lastItem, cache.ReasonCapacity)
 		atomic.AddInt64(&c.evictions, 1)
+		if m := c.metrics.Load(); m != nil {
+			m.RecordEviction()
+		}
+	}
+}
+
+// evictOverBytes вытесняет элементы с конца списка, пока объем превышает maxBytes
+// с учетом допустимого превышения SetEvictionHeadroom.
+// Только что записанный элемент находится в начале списка и сам помещается в лимит,
+// поэтому он вытесняется последним и никогда не удаляется.
+func (c *LRUCache) evictOverBytes() {
+	if c.maxBytes <= 0 {
+		return
+	}
+	limit := c.hardMaxBytes()
+	if c.bytes > limit {
+		c.reapExpiredSample()
+	}
+	for c.bytes > limit && c.tail.prev != c.head {
+		c.evictTail()
 	}
 }
 
 // removeItem полностью удаляет элемент из кэша
-func (c *LRUCache) removeItem(item *lruItem) {
+func (c *LRUCache) removeItem(item *lruItem, reason cache.EvictionReason) {
 	delete(c.items, item.key)
+	atomic.AddInt64(&c.keyCount, -1)
+	c.bloomRemove(item.key)
+	c.evictQueue.pushEncoded(item.key, item.value, item.compressed, reason)
+	c.events.publishRemoved(item.key, reason)
 	c.removeFromList(item)
+	c.tags.remove(item.key, item.tags)
+	atomic.AddInt64(&c.bytes, -item.size)
+	atomic.AddInt64(&c.rawBytes, -item.rawSize)
 }
 
-// cleanup фоновая очистка истекших элементов
-func (c *LRUCache) cleanup() {
-	ticker := time.NewTicker(1 * time.Minute)
+// cleanup фоновая очистка истекших элементов с периодом interval до закрытия stop или кэша
+func (c *LRUCache) cleanup(interval time.Duration, stop <-chan struct{}) {
+	ticker := time.NewTicker(interval)
 	defer ticker.Stop()
 	
 	for {
 		select {
 		case <-ticker.C:
 			c.removeExpired()
+			c.reaper.done()
+		case <-stop:
+			return
 		case <-c.stopCh:
 			return
 		}
@@ -272,19 +661,19 @@ func (c *LRUCache) cleanup() {
 // removeExpired удаляет все истекшие элементы
 func (c *LRUCache) removeExpired() {
 	c.mu.Lock()
-	defer c.mu.Unlock()
+	defer c.unlock()
 	
 	var expiredKeys []string
 
 	for key, item := range c.items {
-		if item.isExpired() {
+		if hardExpired(c.clock.Now(), item.expiresAt, c.staleWindow) {
 			expiredKeys = append(expiredKeys, key)
 		}
 	}
 
 	for _, key := range expiredKeys {
 		if item, exists := c.items[key]; exists {
-			c.removeItem(item)
+			c.removeItem(item, cache.ReasonExpired)
 		}
 	}
 	
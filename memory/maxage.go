@@ -0,0 +1,51 @@
+package memory
+
+import "time"
+
+// SetMaxAge ограничивает возраст элемента: значение истекает не позже чем через
+// maxAge после записи, даже если TTL больше, ключ бессрочный, продлевается через
+// SetSlidingTTL, Touch или Expire. Перезапись ключа начинает отсчет заново.
+// Элементы, записанные до вызова, не пересчитываются. 0 отключает ограничение.
+// GetStale по-прежнему может вернуть элемент в пределах окна SetStaleWindow.
+func (c *LRUCache) SetMaxAge(maxAge time.Duration) {
+	c.mu.Lock()
+	c.maxAge = max(maxAge, 0)
+	c.mu.Unlock()
+}
+
+// SetMaxAge ограничивает возраст элемента: значение истекает не позже чем через
+// maxAge после записи независимо от TTL и его продлений
+func (c *LFUCache) SetMaxAge(maxAge time.Duration) {
+	c.mu.Lock()
+	c.maxAge = max(maxAge, 0)
+	c.mu.Unlock()
+}
+
+// SetMaxAge ограничивает возраст элемента: значение истекает не позже чем через
+// maxAge после записи независимо от TTL и его продлений
+func (c *SimpleCache) SetMaxAge(maxAge time.Duration) {
+	c.mu.Lock()
+	c.maxAge = max(maxAge, 0)
+	c.mu.Unlock()
+}
+
+// SetMaxAge ограничивает возраст элементов во всех шардах
+func (c *ShardedCache) SetMaxAge(maxAge time.Duration) {
+	for _, shard := range c.shards {
+		shard.SetMaxAge(maxAge)
+	}
+}
+
+// capExpiry ограничивает момент истечения сроком createdAt + maxAge.
+// Нулевой expiresAt (бессрочный элемент) тоже ограничивается. maxAge <= 0 - без ограничения.
+func capExpiry(expiresAt, createdAt time.Time, maxAge time.Duration) time.Time {
+	if maxAge <= 0 || createdAt.IsZero() {
+		return expiresAt
+	}
+
+	deadline := createdAt.Add(maxAge)
+	if expiresAt.IsZero() || expiresAt.After(deadline) {
+		return deadline
+	}
+	return expiresAt
+}
@@ -1,40 +1,104 @@
 package memory
 
 import (
+	"bytes"
+	"context"
+	"errors"
 	"fmt"
+	"io"
+	"math"
+	"math/rand/v2"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
 	cache "github.com/VsRnA/High-Performance-HTTP-Cache"
+	"github.com/VsRnA/High-Performance-HTTP-Cache/internal"
 )
 
 // Импортируем ошибки для удобства
 var (
-	ErrKeyEmpty    = cache.ErrKeyEmpty
-	ErrCacheClosed = cache.ErrCacheClosed
+	ErrKeyEmpty      = cache.ErrKeyEmpty
+	ErrCacheClosed   = cache.ErrCacheClosed
+	ErrValueTooLarge = cache.ErrValueTooLarge
 )
 
 // TestAllImplementations тестирует все реализации на одном наборе тестов
 func TestAllImplementations(t *testing.T) {
 	implementations := map[string]func() cache.Cache{
-		"Simple": func() cache.Cache { return NewSimpleWithTTL(1 * time.Minute) }, // Добавим TTL для тестирования
-		"LRU":    func() cache.Cache { return NewLRU(100) },
-		"LFU":    func() cache.Cache { return NewLFU(100) },
+		"Simple":   func() cache.Cache { return NewSimpleWithTTL(1 * time.Minute) }, // Добавим TTL для тестирования
+		"LRU":      func() cache.Cache { return NewLRU(100) },
+		"LFU":      func() cache.Cache { return NewLFU(100) },
+		"FIFO":     func() cache.Cache { return NewFIFO(100) },
+		"Random":   func() cache.Cache { return NewRandom(100) },
+		"Approx":   func() cache.Cache { return NewApproxLRU(100, 0) },
+		"Weighted": func() cache.Cache { return NewWeighted(100) },
+		"ARC":      func() cache.Cache { return NewARC(100) },
+		"TinyLFU":  func() cache.Cache { return NewTinyLFU(100) },
+		"Sharded":  func() cache.Cache { return NewSharded(4, 100) },
+		"Tiered":   func() cache.Cache { return NewTiered(100, t.TempDir()) },
 	}
 
 	for name, constructor := range implementations {
 		t.Run(name, func(t *testing.T) {
 			cache := constructor()
 			defer cache.Close()
-			
+			clock := withFakeClock(t, cache)
+
 			testBasicOperations(t, cache)
-			testTTL(t, cache)
+			testTTL(t, cache, clock)
 			testStats(t, cache)
+			testKeys(t, cache, clock)
+			testLen(t, cache)
+			testClosed(t, cache)
 		})
 	}
 }
 
+// testClosed проверяет, что закрытый кэш не отдает значения и отклоняет запись.
+// Должен вызываться последним: повторно открыть кэш нельзя.
+func testClosed(t *testing.T, c cache.Cache) {
+	c.Set("closed-key", []byte("value"))
+	if err := c.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	if _, exists := c.Get("closed-key"); exists {
+		t.Error("Get should miss after Close")
+	}
+	if err := c.Set("closed-key", []byte("value")); err != ErrCacheClosed {
+		t.Errorf("Expected ErrCacheClosed after Close, got %v", err)
+	}
+	if c.Delete("closed-key") {
+		t.Error("Delete should report false after Close")
+	}
+	if toucher, ok := c.(interface{ Touch(string, time.Duration) bool }); ok {
+		if toucher.Touch("closed-key", time.Minute) {
+			t.Error("Touch should report false after Close")
+		}
+	}
+	if getter, ok := c.(interface{ GetE(string) ([]byte, error) }); ok {
+		if _, err := getter.GetE("closed-key"); err != ErrCacheClosed {
+			t.Errorf("Expected GetE to return ErrCacheClosed after Close, got %v", err)
+		}
+	}
+
+	// Статистика остается доступной для итогового отчета
+	stats := c.Stats()
+	if stats.Keys != int64(c.Len()) {
+		t.Errorf("Stats after Close should match Len, got keys=%d len=%d", stats.Keys, c.Len())
+	}
+	if err := c.Close(); err != nil {
+		t.Errorf("Repeated Close failed: %v", err)
+	}
+}
+
 // testBasicOperations проверяет базовые операции кэша
 func testBasicOperations(t *testing.T, cache cache.Cache) {
 	// Тест Set/Get
@@ -74,7 +138,7 @@ func testBasicOperations(t *testing.T, cache cache.Cache) {
 }
 
 // testTTL проверяет функциональность TTL
-func testTTL(t *testing.T, cache cache.Cache) {
+func testTTL(t *testing.T, cache cache.Cache, clock *fakeClock) {
 	key := "ttl_key"
 	value := []byte("ttl_value")
 	ttl := 100 * time.Millisecond
@@ -91,22 +155,10 @@ func testTTL(t *testing.T, cache cache.Cache) {
 		t.Fatal("Key should exist immediately after SetWithTTL")
 	}
 
-	// Ждем истечения TTL
-	time.Sleep(150 * time.Millisecond)
-
-	// Проверяем истечение - может потребоваться несколько попыток для Simple кэша
-	// так как у него нет фоновой очистки, только lazy expiration
-	maxAttempts := 3
-	expired := false
-	for i := 0; i < maxAttempts; i++ {
-		if _, exists := cache.Get(key); !exists {
-			expired = true
-			break
-		}
-		time.Sleep(50 * time.Millisecond) // Небольшая задержка между попытками
-	}
+	// Сдвигаем время за TTL: истекший элемент не отдается даже без фоновой очистки
+	clock.Advance(150 * time.Millisecond)
 
-	if !expired {
+	if _, exists := cache.Get(key); exists {
 		t.Fatal("Key should expire after TTL")
 	}
 }
@@ -159,6 +211,47 @@ func testStats(t *testing.T, cache cache.Cache) {
 	}
 }
 
+// testKeys проверяет что Keys возвращает только живые ключи
+func testKeys(t *testing.T, cache cache.Cache, clock *fakeClock) {
+	cache.Clear()
+
+	cache.Set("a", []byte("1"))
+	cache.Set("b", []byte("2"))
+	cache.SetWithTTL("expired", []byte("3"), time.Millisecond)
+	clock.Advance(5 * time.Millisecond)
+
+	keys := cache.Keys()
+	sort.Strings(keys)
+	if fmt.Sprint(keys) != "[a b]" {
+		t.Fatalf("Expected [a b], got %v", keys)
+	}
+
+	cache.Clear()
+}
+
+// testLen проверяет количество элементов и его согласованность со Stats
+func testLen(t *testing.T, cache cache.Cache) {
+	cache.Clear()
+
+	if n := cache.Len(); n != 0 {
+		t.Fatalf("Expected empty cache, got Len %d", n)
+	}
+
+	cache.Set("a", []byte("1"))
+	cache.Set("b", []byte("2"))
+	cache.Set("a", []byte("3"))
+	if n := cache.Len(); n != 2 || int64(n) != cache.Stats().Keys {
+		t.Fatalf("Expected Len 2 matching Stats.Keys, got %d", n)
+	}
+
+	cache.Delete("a")
+	if n := cache.Len(); n != 1 {
+		t.Fatalf("Expected Len 1 after Delete, got %d", n)
+	}
+
+	cache.Clear()
+}
+
 // TestLRUEviction специально тестирует LRU политику
 func TestLRUEviction(t *testing.T) {
 	cache := NewLRU(3)
@@ -198,6 +291,18 @@ func TestLRUEviction(t *testing.T) {
 	if stats.Evictions == 0 {
 		t.Error("Should have evictions")
 	}
+
+	// Keys возвращает ключи от недавно использованных к давно использованным
+	if keys := cache.Keys(); fmt.Sprint(keys) != "[D C A]" {
+		t.Errorf("Expected MRU order [D C A], got %v", keys)
+	}
+
+	// GetTTL и Expire не меняют порядок вытеснения
+	cache.GetTTL("A")
+	cache.Expire("A", time.Minute)
+	if keys := cache.Keys(); fmt.Sprint(keys) != "[D C A]" {
+		t.Errorf("GetTTL/Expire should not update recency, got %v", keys)
+	}
 }
 
 // TestLFUEviction специально тестирует LFU политику
@@ -244,12 +349,298 @@ func TestLFUEviction(t *testing.T) {
 	}
 }
 
+// TestFIFOEviction специально тестирует FIFO политику
+func TestFIFOEviction(t *testing.T) {
+	cache := NewFIFO(3)
+	defer cache.Close()
+
+	cache.Set("A", []byte("valueA"))
+	cache.Set("B", []byte("valueB"))
+	cache.Set("C", []byte("valueC"))
+
+	// Обращения и перезапись не меняют порядок вытеснения
+	cache.Get("A")
+	cache.Get("A")
+	cache.Set("A", []byte("newA"))
+
+	// Добавляем D - должен вытеснить A (самый старый по времени добавления)
+	cache.Set("D", []byte("valueD"))
+
+	_, existsA := cache.Get("A")
+	_, existsB := cache.Get("B")
+	_, existsC := cache.Get("C")
+	_, existsD := cache.Get("D")
+
+	if existsA {
+		t.Error("A should be evicted (first in)")
+	}
+	if !existsB || !existsC {
+		t.Error("B and C should still exist")
+	}
+	if !existsD {
+		t.Error("D should exist (just added)")
+	}
+
+	if keys := cache.Keys(); fmt.Sprint(keys) != "[D C B]" {
+		t.Errorf("Expected insertion order [D C B], got %v", keys)
+	}
+}
+
+// TestRandomEviction проверяет что Random кэш соблюдает лимит и вытесняет разные ключи
+func TestRandomEviction(t *testing.T) {
+	c := NewRandom(10).(*RandomCache)
+	defer c.Close()
+
+	for i := 0; i < 1000; i++ {
+		c.Set(fmt.Sprintf("key%d", i), []byte("value"))
+	}
+
+	stats := c.Stats()
+	if stats.Keys != 10 {
+		t.Fatalf("Expected 10 keys, got %d", stats.Keys)
+	}
+	if stats.Evictions != 990 {
+		t.Fatalf("Expected 990 evictions, got %d", stats.Evictions)
+	}
+
+	// При равновероятном вытеснении среди выживших почти наверняка есть не только последние ключи
+	survivors := 0
+	for i := 0; i < 990; i++ {
+		if _, exists := c.Get(fmt.Sprintf("key%d", i)); exists {
+			survivors++
+		}
+	}
+	if survivors == 0 {
+		t.Error("Random eviction should not behave like FIFO")
+	}
+
+	// Индексы элементов остаются согласованными после удалений
+	for i, item := range c.order {
+		if item.index != i || c.items[item.key] != item {
+			t.Fatalf("Inconsistent order index for %s", item.key)
+		}
+	}
+}
+
+// TestARCScanResistance проверяет что ARC удерживает часто используемые ключи
+// при чередовании повторных обращений и однократных сканирований лучше, чем LRU
+func TestARCScanResistance(t *testing.T) {
+	hotHitRate := func(c cache.Cache) float64 {
+		defer c.Close()
+
+		hits, total := 0, 0
+		scanKey := 0
+		for round := 0; round < 50; round++ {
+			// Повторное использование горячего набора
+			for i := 0; i < 50; i++ {
+				key := fmt.Sprintf("hot%d", i)
+				total++
+				if _, exists := c.Get(key); exists {
+					hits++
+				} else {
+					c.Set(key, []byte("hot"))
+				}
+			}
+
+			// Сканирование ключей, которые больше не понадобятся
+			for i := 0; i < 100; i++ {
+				c.Set(fmt.Sprintf("scan%d", scanKey), []byte("scan"))
+				scanKey++
+			}
+		}
+		return float64(hits) / float64(total)
+	}
+
+	arcRate := hotHitRate(NewARC(100))
+	lruRate := hotHitRate(NewLRU(100))
+
+	if arcRate <= lruRate {
+		t.Fatalf("ARC should retain hot keys better than LRU: arc=%.2f lru=%.2f", arcRate, lruRate)
+	}
+}
+
+// TestARCGhostAdaptation проверяет адаптацию целевого размера T1 при попаданиях в призрачные списки
+func TestARCGhostAdaptation(t *testing.T) {
+	c := NewARC(2).(*ARCCache)
+	defer c.Close()
+
+	c.Set("A", []byte("A"))
+	c.Set("B", []byte("B"))
+	c.Set("C", []byte("C")) // A вытесняется из T1 в B1
+
+	if item := c.items["A"]; item == nil || item.list != &c.b1 {
+		t.Fatal("A should be tracked in B1 ghost list")
+	}
+	if _, exists := c.Get("A"); exists {
+		t.Fatal("Ghost entry should not be served")
+	}
+
+	// Повторная запись A - попадание в B1 увеличивает p и помещает A в T2
+	c.Set("A", []byte("A"))
+	if c.p != 1 {
+		t.Fatalf("Expected p to grow to 1, got %d", c.p)
+	}
+	if item := c.items["A"]; item.list != &c.t2 {
+		t.Fatal("A should move to T2 after ghost hit")
+	}
+	if keys := c.Stats().Keys; keys != 2 {
+		t.Fatalf("Expected 2 resident keys, got %d", keys)
+	}
+}
+
+// zipfHitRate прогоняет через кэш поток обращений с распределением Zipf
+// и возвращает долю попаданий
+func zipfHitRate(c cache.Cache, requests int) float64 {
+	defer c.Close()
+
+	zipf := rand.NewZipf(rand.New(rand.NewPCG(1, 2)), 1.01, 1, 10000)
+	hits := 0
+	for i := 0; i < requests; i++ {
+		key := strconv.FormatUint(zipf.Uint64(), 10)
+		if _, exists := c.Get(key); exists {
+			hits++
+		} else {
+			c.Set(key, []byte("value"))
+		}
+	}
+	return float64(hits) / float64(requests)
+}
+
+// TestTinyLFUZipfHitRate проверяет что фильтр допуска TinyLFU
+// дает больше попаданий, чем LRU, на неравномерной нагрузке
+func TestTinyLFUZipfHitRate(t *testing.T) {
+	tinyRate := zipfHitRate(NewTinyLFU(100), 50000)
+	lruRate := zipfHitRate(NewLRU(100), 50000)
+
+	if tinyRate <= lruRate {
+		t.Fatalf("TinyLFU should outperform LRU on Zipf workload: tinylfu=%.3f lru=%.3f", tinyRate, lruRate)
+	}
+}
+
+// TestWeightedCost проверяет, что дорогие элементы переживают вытеснение
+// многих дешевых, а бюджет стоимости соблюдается
+func TestWeightedCost(t *testing.T) {
+	c := NewWeighted(100).(*WeightedCache)
+	defer c.Close()
+
+	if err := c.SetWithCost("expensive", []byte("report"), 50, 0); err != nil {
+		t.Fatalf("SetWithCost failed: %v", err)
+	}
+	if stats := c.Stats(); stats.RemainingCost != 50 {
+		t.Fatalf("Expected remaining cost 50, got %d", stats.RemainingCost)
+	}
+
+	for i := 0; i < 200; i++ {
+		c.Set(fmt.Sprintf("cheap:%d", i), []byte("v"))
+	}
+
+	stats := c.Stats()
+	if _, ok := c.Peek("expensive"); !ok {
+		t.Fatal("Expensive item should survive pressure from cheap items")
+	}
+	if stats.Evictions != 150 || stats.Keys != 51 || stats.RemainingCost != 0 {
+		t.Fatalf("Expected 150 evictions, 51 keys and no budget left, got evictions=%d keys=%d remaining=%d",
+			stats.Evictions, stats.Keys, stats.RemainingCost)
+	}
+
+	// Без обращений приоритет дорогого элемента со временем догоняет инфляция
+	for i := 0; i < 5000; i++ {
+		c.Set(fmt.Sprintf("late:%d", i), []byte("v"))
+	}
+	if _, ok := c.Peek("expensive"); ok {
+		t.Error("Expensive item without hits should eventually age out")
+	}
+
+	if err := c.SetWithCost("huge", []byte("v"), 101, 0); !errors.Is(err, ErrValueTooLarge) {
+		t.Errorf("Expected ErrValueTooLarge for cost above budget, got %v", err)
+	}
+
+	// Перезапись меняет стоимость и не вытесняет сам элемент
+	c.Clear()
+	c.SetWithCost("a", []byte("1"), 60, 0)
+	c.SetWithCost("a", []byte("2"), 90, 0)
+	if value, ok := c.Get("a"); !ok || string(value) != "2" {
+		t.Fatalf("Expected overwritten value, got %q %v", value, ok)
+	}
+	if stats := c.Stats(); stats.RemainingCost != 10 || stats.Keys != 1 {
+		t.Fatalf("Expected remaining cost 10 with one key, got %d and %d keys", stats.RemainingCost, stats.Keys)
+	}
+}
+
+// TestApproxLRUQuality проверяет, что выборка из 5 кандидатов дает
+// долю попаданий, близкую к точному LRU, и сохраняет горячие ключи
+func TestApproxLRUQuality(t *testing.T) {
+	approxRate := zipfHitRate(NewApproxLRU(100, 5), 50000)
+	lruRate := zipfHitRate(NewLRU(100), 50000)
+
+	if approxRate < lruRate*0.9 {
+		t.Fatalf("Approximate LRU hit rate too low: approx=%.3f lru=%.3f", approxRate, lruRate)
+	}
+
+	// Горячие ключи, читаемые между записями, не должны вытесняться
+	c := NewApproxLRU(100, 5)
+	defer c.Close()
+
+	for i := 0; i < 100; i++ {
+		c.Set(fmt.Sprintf("hot%d", i%10), []byte("v"))
+		c.Set(fmt.Sprintf("cold%d", i), []byte("v"))
+	}
+	for i := 0; i < 1000; i++ {
+		for j := 0; j < 10; j++ {
+			c.Get(fmt.Sprintf("hot%d", j))
+		}
+		c.Set(fmt.Sprintf("new%d", i), []byte("v"))
+	}
+
+	survived := 0
+	for j := 0; j < 10; j++ {
+		if _, exists := c.Get(fmt.Sprintf("hot%d", j)); exists {
+			survived++
+		}
+	}
+	if survived < 9 {
+		t.Errorf("Expected hot keys to survive, only %d of 10 did", survived)
+	}
+	if c.Len() != 100 {
+		t.Errorf("Expected cache to stay at capacity, got %d", c.Len())
+	}
+}
+
+// TestTinyLFUAdmission проверяет что редкий кандидат не вытесняет популярный ключ
+func TestTinyLFUAdmission(t *testing.T) {
+	c := NewTinyLFU(2).(*TinyLFUCache)
+	defer c.Close()
+
+	c.Set("hot", []byte("hot"))
+	c.Set("warm", []byte("warm")) // hot переходит из окна в основную область
+	for i := 0; i < 5; i++ {
+		c.Get("hot")
+	}
+
+	// warm вытесняется из окна, но его частота ниже чем у hot
+	c.Set("cold", []byte("cold"))
+
+	if _, exists := c.Get("hot"); !exists {
+		t.Fatal("Frequent key should survive admission")
+	}
+	if _, exists := c.Get("warm"); exists {
+		t.Fatal("Rare candidate should be rejected")
+	}
+}
+
 // TestConcurrency проверяет потокобезопасность
 func TestConcurrency(t *testing.T) {
 	implementations := map[string]func() cache.Cache{
-		"Simple": func() cache.Cache { return NewSimple() },
-		"LRU":    func() cache.Cache { return NewLRU(1000) },
-		"LFU":    func() cache.Cache { return NewLFU(1000) },
+		"Simple":   func() cache.Cache { return NewSimple() },
+		"LRU":      func() cache.Cache { return NewLRU(1000) },
+		"LFU":      func() cache.Cache { return NewLFU(1000) },
+		"FIFO":     func() cache.Cache { return NewFIFO(1000) },
+		"Random":   func() cache.Cache { return NewRandom(1000) },
+		"Approx":   func() cache.Cache { return NewApproxLRU(1000, 0) },
+		"Weighted": func() cache.Cache { return NewWeighted(1000) },
+		"ARC":      func() cache.Cache { return NewARC(1000) },
+		"TinyLFU":  func() cache.Cache { return NewTinyLFU(1000) },
+		"Sharded":  func() cache.Cache { return NewSharded(16, 1000) },
 	}
 
 	for name, constructor := range implementations {
@@ -295,6 +686,88 @@ func TestConcurrency(t *testing.T) {
 	}
 }
 
+// TestGetOrSet проверяет дедупликацию загрузок и обработку ошибок loader
+func TestGetOrSet(t *testing.T) {
+	implementations := map[string]func() cache.Cache{
+		"Simple":   func() cache.Cache { return NewSimple() },
+		"LRU":      func() cache.Cache { return NewLRU(100) },
+		"LFU":      func() cache.Cache { return NewLFU(100) },
+		"FIFO":     func() cache.Cache { return NewFIFO(100) },
+		"Random":   func() cache.Cache { return NewRandom(100) },
+		"Approx":   func() cache.Cache { return NewApproxLRU(100, 0) },
+		"Weighted": func() cache.Cache { return NewWeighted(100) },
+		"ARC":      func() cache.Cache { return NewARC(100) },
+		"TinyLFU":  func() cache.Cache { return NewTinyLFU(100) },
+		"Sharded":  func() cache.Cache { return NewSharded(4, 100) },
+	}
+
+	for name, constructor := range implementations {
+		t.Run(name, func(t *testing.T) {
+			cache := constructor()
+			defer cache.Close()
+
+			// Одновременные промахи по одному ключу вызывают loader один раз
+			var calls int64
+			loader := func() ([]byte, error) {
+				atomic.AddInt64(&calls, 1)
+				time.Sleep(50 * time.Millisecond)
+				return []byte("loaded"), nil
+			}
+
+			var wg sync.WaitGroup
+			for i := 0; i < 20; i++ {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					value, err := cache.GetOrSet("shared", loader, 0)
+					if err != nil {
+						t.Errorf("GetOrSet failed: %v", err)
+						return
+					}
+					if string(value) != "loaded" {
+						t.Errorf("Wrong value: %s", value)
+					}
+				}()
+			}
+			wg.Wait()
+
+			if calls != 1 {
+				t.Fatalf("Loader should run once, ran %d times", calls)
+			}
+
+			// Ошибка loader не кэшируется
+			loadErr := errors.New("origin unavailable")
+			_, err := cache.GetOrSet("failing", func() ([]byte, error) { return nil, loadErr }, 0)
+			if !errors.Is(err, loadErr) {
+				t.Fatalf("Expected loader error, got %v", err)
+			}
+			if _, exists := cache.Get("failing"); exists {
+				t.Fatal("Failed load should not be cached")
+			}
+
+			// Загрузка одного ключа не блокирует другие ключи
+			release := make(chan struct{})
+			go cache.GetOrSet("slow", func() ([]byte, error) {
+				<-release
+				return []byte("slow"), nil
+			}, 0)
+
+			done := make(chan struct{})
+			go func() {
+				cache.GetOrSet("fast", func() ([]byte, error) { return []byte("fast"), nil }, 0)
+				close(done)
+			}()
+
+			select {
+			case <-done:
+			case <-time.After(time.Second):
+				t.Fatal("GetOrSet for a different key was blocked")
+			}
+			close(release)
+		})
+	}
+}
+
 // TestErrorCases проверяет обработку ошибок
 func TestErrorCases(t *testing.T) {
 	cache := NewSimple()
@@ -376,6 +849,26 @@ func BenchmarkLFUSet(b *testing.B) {
 	benchmarkSet(b, cache)
 }
 
+// BenchmarkLFUSetEvict измеряет запись в заполненный LFU кэш, где каждая запись вытесняет элемент
+func BenchmarkLFUSetEvict(b *testing.B) {
+	for _, size := range []int{1000, 10000, 100000} {
+		b.Run(fmt.Sprint(size), func(b *testing.B) {
+			cache := NewLFU(size)
+			defer cache.Close()
+
+			value := []byte("benchmark value")
+			for i := 0; i < size; i++ {
+				cache.Set(fmt.Sprintf("key%d", i), value)
+			}
+
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				cache.Set(fmt.Sprintf("new%d", i), value)
+			}
+		})
+	}
+}
+
 func benchmarkSet(b *testing.B, cache cache.Cache) {
 	value := []byte("benchmark value")
 	b.ResetTimer()
@@ -404,6 +897,140 @@ func BenchmarkLFUGet(b *testing.B) {
 	benchmarkGet(b, cache)
 }
 
+// BenchmarkLRUGetMiss сравнивает параллельные промахи по уникальным отсутствующим
+// ключам с фильтром Блума и без него
+func BenchmarkLRUGetMiss(b *testing.B) {
+	const size = 10000
+	absent := make([]string, 1<<16)
+	for i := range absent {
+		absent[i] = fmt.Sprintf("absent:%d", i)
+	}
+
+	for _, bloom := range []bool{false, true} {
+		b.Run(fmt.Sprintf("bloom=%t", bloom), func(b *testing.B) {
+			c := NewLRU(size).(*LRUCache)
+			defer c.Close()
+			for i := 0; i < size; i++ {
+				c.Set(fmt.Sprintf("key:%d", i), []byte("value"))
+			}
+			if bloom {
+				c.SetBloomFilter(size, 0.01)
+			}
+
+			var next atomic.Int64
+			b.ResetTimer()
+			b.RunParallel(func(pb *testing.PB) {
+				i := int(next.Add(1)) * 7919
+				for pb.Next() {
+					c.Get(absent[i&(len(absent)-1)])
+					i++
+				}
+			})
+		})
+	}
+}
+
+// BenchmarkGetRef сравнивает выделения памяти при чтении с копированием и без него
+func BenchmarkGetRef(b *testing.B) {
+	c := NewLRU(1000).(*LRUCache)
+	defer c.Close()
+
+	keys := make([]string, 1000)
+	value := make([]byte, 1024)
+	for i := range keys {
+		keys[i] = fmt.Sprintf("key%d", i)
+		c.Set(keys[i], value)
+	}
+
+	b.Run("Get", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			c.Get(keys[i%len(keys)])
+		}
+	})
+
+	b.Run("GetRef", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			c.GetRef(keys[i%len(keys)])
+		}
+	})
+}
+
+// BenchmarkSmallValueSet сравнивает выделения памяти при записи значений, которые
+// помещаются во встроенный буфер элемента, и более длинных
+func BenchmarkSmallValueSet(b *testing.B) {
+	keys := make([]string, 1000)
+	for i := range keys {
+		keys[i] = fmt.Sprintf("key%d", i)
+	}
+
+	for _, size := range []int{16, 64} {
+		value := make([]byte, size)
+
+		// Емкость вдвое меньше числа ключей, поэтому каждая запись создает новый элемент
+		b.Run(fmt.Sprintf("LRU/%d", size), func(b *testing.B) {
+			c := NewLRU(len(keys) / 2)
+			defer c.Close()
+
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				c.Set(keys[i%len(keys)], value)
+			}
+		})
+
+		b.Run(fmt.Sprintf("Simple/%d", size), func(b *testing.B) {
+			c := NewSimple()
+			defer c.Close()
+
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				c.Set(keys[i%len(keys)], value)
+			}
+		})
+	}
+}
+
+// BenchmarkApproxLRU сравнивает точный и приближенный LRU на параллельной
+// нагрузке из 90% чтений и 10% записей с вытеснением
+func BenchmarkApproxLRU(b *testing.B) {
+	implementations := map[string]func() cache.Cache{
+		"LRU":    func() cache.Cache { return NewLRU(10000) },
+		"Approx": func() cache.Cache { return NewApproxLRU(10000, 5) },
+	}
+
+	keys := make([]string, 20000)
+	for i := range keys {
+		keys[i] = fmt.Sprintf("key%d", i)
+	}
+	value := []byte("benchmark value")
+
+	for name, constructor := range implementations {
+		b.Run(name, func(b *testing.B) {
+			c := constructor()
+			defer c.Close()
+
+			for i := 0; i < 10000; i++ {
+				c.Set(keys[i], value)
+			}
+
+			b.ResetTimer()
+			b.RunParallel(func(pb *testing.PB) {
+				i := rand.IntN(len(keys))
+				for pb.Next() {
+					key := keys[i%len(keys)]
+					if i%10 == 0 {
+						c.Set(key, value)
+					} else {
+						c.Get(key)
+					}
+					i++
+				}
+			})
+		})
+	}
+}
+
 func benchmarkGet(b *testing.B, cache cache.Cache) {
 	// Предварительно заполняем кэш
 	value := []byte("benchmark value")
@@ -422,9 +1049,16 @@ func benchmarkGet(b *testing.B, cache cache.Cache) {
 // BenchmarkConcurrent тестирует производительность в многопоточном режиме
 func BenchmarkConcurrentAccess(b *testing.B) {
 	implementations := map[string]func() cache.Cache{
-		"Simple": func() cache.Cache { return NewSimple() },
-		"LRU":    func() cache.Cache { return NewLRU(10000) },
-		"LFU":    func() cache.Cache { return NewLFU(10000) },
+		"Simple":  func() cache.Cache { return NewSimple() },
+		"LRU":     func() cache.Cache { return NewLRU(10000) },
+		"LFU":     func() cache.Cache { return NewLFU(10000) },
+		"FIFO":    func() cache.Cache { return NewFIFO(10000) },
+		"Random":  func() cache.Cache { return NewRandom(10000) },
+		"Approx":  func() cache.Cache { return NewApproxLRU(10000, 0) },
+		"ARC":     func() cache.Cache { return NewARC(10000) },
+		"TinyLFU": func() cache.Cache { return NewTinyLFU(10000) },
+		// Тот же суммарный объем, что и у LRU, разделенный на 16 шардов
+		"Sharded": func() cache.Cache { return NewSharded(16, 10000/16) },
 	}
 
 	for name, constructor := range implementations {
@@ -451,4 +1085,3942 @@ func BenchmarkConcurrentAccess(b *testing.B) {
 			})
 		})
 	}
-}
\ No newline at end of file
+}
+
+// BenchmarkShardedClear сравнивает параллельную очистку 64 шардов с миллионом
+// ключей с последовательной очисткой шард за шардом
+func BenchmarkShardedClear(b *testing.B) {
+	const shards, keys = 64, 1_000_000
+	names := make([]string, keys)
+	for i := range names {
+		names[i] = strconv.Itoa(i)
+	}
+	value := []byte("value")
+
+	clears := map[string]func(c *ShardedCache){
+		"parallel": func(c *ShardedCache) { c.Clear() },
+		"sequential": func(c *ShardedCache) {
+			for _, shard := range c.shards {
+				shard.Clear()
+			}
+		},
+	}
+
+	for name, clear := range clears {
+		b.Run(name, func(b *testing.B) {
+			c := NewSharded(shards, keys/shards*2).(*ShardedCache)
+			defer c.Close()
+
+			for i := 0; i < b.N; i++ {
+				b.StopTimer()
+				for _, key := range names {
+					c.Set(key, value)
+				}
+				b.StartTimer()
+				clear(c)
+			}
+		})
+	}
+}
+
+// BenchmarkZipfHitRate сравнивает долю попаданий LRU и TinyLFU на нагрузке Zipf
+func BenchmarkZipfHitRate(b *testing.B) {
+	implementations := map[string]func() cache.Cache{
+		"LRU":     func() cache.Cache { return NewLRU(1000) },
+		"TinyLFU": func() cache.Cache { return NewTinyLFU(1000) },
+		"Approx":  func() cache.Cache { return NewApproxLRU(1000, 5) },
+	}
+
+	for name, constructor := range implementations {
+		b.Run(name, func(b *testing.B) {
+			hitRate := zipfHitRate(constructor(), b.N)
+			b.ReportMetric(hitRate*100, "hit%")
+		})
+	}
+}
+// TestShardedRouting проверяет округление числа шардов и агрегацию статистики
+func TestShardedRouting(t *testing.T) {
+	c := NewSharded(3, 10).(*ShardedCache)
+	defer c.Close()
+
+	if len(c.shards) != 4 {
+		t.Fatalf("Shard count should be rounded up to 4, got %d", len(c.shards))
+	}
+
+	for i := 0; i < 20; i++ {
+		c.Set(fmt.Sprintf("key%d", i), []byte("value"))
+	}
+
+	// Ключ всегда попадает в один и тот же шард
+	for i := 0; i < 20; i++ {
+		key := fmt.Sprintf("key%d", i)
+		if _, exists := c.shard(key).Get(key); !exists {
+			t.Fatalf("Key %s not found in its shard", key)
+		}
+	}
+
+	stats := c.Stats()
+	if stats.Keys != 20 || stats.Hits != 20 {
+		t.Fatalf("Expected 20 keys and 20 hits, got keys=%d hits=%d", stats.Keys, stats.Hits)
+	}
+
+	c.Clear()
+	if keys := c.Stats().Keys; keys != 0 {
+		t.Fatalf("Clear should empty every shard, got %d keys", keys)
+	}
+
+	c.Close()
+	for i, shard := range c.shards {
+		if err := shard.Set("key", []byte("value")); err != ErrCacheClosed {
+			t.Fatalf("Shard %d should be closed, got %v", i, err)
+		}
+	}
+}
+
+// TestLRUBytesLimit проверяет ограничение LRU кэша по объему
+func TestLRUBytesLimit(t *testing.T) {
+	// Каждый элемент занимает 1 байт ключа + 9 байт значения = 10 байт
+	cache := NewLRUWithBytes(30)
+	defer cache.Close()
+
+	cache.Set("A", []byte("123456789"))
+	cache.Set("B", []byte("123456789"))
+	cache.Set("C", []byte("123456789"))
+
+	if bytes := cache.Stats().Bytes; bytes != 30 {
+		t.Fatalf("Expected 30 bytes, got %d", bytes)
+	}
+
+	// Делаем A недавно использованным и добавляем D - вытесняется B
+	cache.Get("A")
+	cache.Set("D", []byte("123456789"))
+
+	if _, exists := cache.Get("B"); exists {
+		t.Error("B should be evicted to fit the byte limit")
+	}
+	if _, exists := cache.Get("A"); !exists {
+		t.Error("A should still exist (recently used)")
+	}
+
+	// Перезапись большим значением учитывает старый размер и вытесняет лишнее
+	cache.Set("A", []byte("1234567890123456789"))
+	stats := cache.Stats()
+	if stats.Bytes > 30 {
+		t.Fatalf("Byte usage %d exceeds limit", stats.Bytes)
+	}
+	if _, exists := cache.Get("A"); !exists {
+		t.Error("Overwritten key should stay in cache")
+	}
+
+	// Значение больше всего лимита отклоняется без вытеснения
+	before := cache.Stats().Keys
+	err := cache.Set("huge", make([]byte, 31))
+	if !errors.Is(err, ErrValueTooLarge) {
+		t.Fatalf("Expected ErrValueTooLarge, got %v", err)
+	}
+	if after := cache.Stats().Keys; after != before {
+		t.Fatalf("Rejected value should not evict anything: %d -> %d keys", before, after)
+	}
+
+	cache.Delete("A")
+	cache.Clear()
+	if bytes := cache.Stats().Bytes; bytes != 0 {
+		t.Fatalf("Expected 0 bytes after Clear, got %d", bytes)
+	}
+}
+
+// TestForEach проверяет обход элементов, раннюю остановку и копирование значений
+func TestForEach(t *testing.T) {
+	type iterable interface {
+		cache.Cache
+		ForEach(fn func(key string, value []byte) bool)
+	}
+
+	implementations := map[string]func() iterable{
+		"Simple":  func() iterable { return NewSimple().(*SimpleCache) },
+		"LRU":     func() iterable { return NewLRU(100).(*LRUCache) },
+		"LFU":     func() iterable { return NewLFU(100).(*LFUCache) },
+		"Sharded": func() iterable { return NewSharded(4, 100).(*ShardedCache) },
+	}
+
+	for name, constructor := range implementations {
+		t.Run(name, func(t *testing.T) {
+			c := constructor()
+			defer c.Close()
+			clock := withFakeClock(t, c)
+
+			for i := 0; i < 10; i++ {
+				c.Set(fmt.Sprintf("key%d", i), []byte("value"))
+			}
+			c.SetWithTTL("expired", []byte("value"), time.Millisecond)
+			clock.Advance(5 * time.Millisecond)
+
+			seen := 0
+			c.ForEach(func(key string, value []byte) bool {
+				if key == "expired" {
+					t.Error("Expired item should be skipped")
+				}
+				value[0] = 'X' // Изменение копии не должно затронуть кэш
+				seen++
+				return true
+			})
+			if seen != 10 {
+				t.Fatalf("Expected 10 items, got %d", seen)
+			}
+
+			if value, _ := c.Get("key0"); string(value) != "value" {
+				t.Fatal("Cache data was modified through ForEach")
+			}
+
+			// Возврат false останавливает обход
+			visited := 0
+			c.ForEach(func(key string, value []byte) bool {
+				visited++
+				return visited < 3
+			})
+			if visited != 3 {
+				t.Fatalf("Iteration should stop after 3 items, visited %d", visited)
+			}
+		})
+	}
+}
+
+// TestGetTTL проверяет получение оставшегося времени жизни
+func TestGetTTL(t *testing.T) {
+	implementations := map[string]func() cache.Cache{
+		"Simple":   func() cache.Cache { return NewSimple() },
+		"LRU":      func() cache.Cache { return NewLRU(100) },
+		"LFU":      func() cache.Cache { return NewLFU(100) },
+		"FIFO":     func() cache.Cache { return NewFIFO(100) },
+		"Random":   func() cache.Cache { return NewRandom(100) },
+		"Approx":   func() cache.Cache { return NewApproxLRU(100, 0) },
+		"Weighted": func() cache.Cache { return NewWeighted(100) },
+		"ARC":      func() cache.Cache { return NewARC(100) },
+		"TinyLFU":  func() cache.Cache { return NewTinyLFU(100) },
+		"Sharded":  func() cache.Cache { return NewSharded(4, 100) },
+	}
+
+	for name, constructor := range implementations {
+		t.Run(name, func(t *testing.T) {
+			c := constructor()
+			defer c.Close()
+			clock := withFakeClock(t, c)
+
+			c.SetWithTTL("temp", []byte("value"), time.Minute)
+			c.Set("persistent", []byte("value"))
+
+			ttl, exists := c.GetTTL("temp")
+			if !exists || ttl <= 0 || ttl > time.Minute {
+				t.Fatalf("Unexpected TTL for temp: %v, %v", ttl, exists)
+			}
+
+			ttl, exists = c.GetTTL("persistent")
+			if !exists || ttl != cache.NoExpiration {
+				t.Fatalf("Expected NoExpiration, got %v, %v", ttl, exists)
+			}
+
+			if _, exists := c.GetTTL("missing"); exists {
+				t.Fatal("Missing key should not exist")
+			}
+
+			c.SetWithTTL("expired", []byte("value"), time.Millisecond)
+			clock.Advance(5 * time.Millisecond)
+			if _, exists := c.GetTTL("expired"); exists {
+				t.Fatal("Expired key should not exist")
+			}
+
+			// GetTTL не влияет на статистику
+			if stats := c.Stats(); stats.Hits != 0 || stats.Misses != 0 {
+				t.Fatalf("GetTTL should not affect stats: hits=%d misses=%d", stats.Hits, stats.Misses)
+			}
+		})
+	}
+}
+
+// TestExpire проверяет изменение времени жизни без перезаписи значения
+func TestExpire(t *testing.T) {
+	implementations := map[string]func() cache.Cache{
+		"Simple":   func() cache.Cache { return NewSimple() },
+		"LRU":      func() cache.Cache { return NewLRU(100) },
+		"LFU":      func() cache.Cache { return NewLFU(100) },
+		"FIFO":     func() cache.Cache { return NewFIFO(100) },
+		"Random":   func() cache.Cache { return NewRandom(100) },
+		"Approx":   func() cache.Cache { return NewApproxLRU(100, 0) },
+		"Weighted": func() cache.Cache { return NewWeighted(100) },
+		"ARC":      func() cache.Cache { return NewARC(100) },
+		"TinyLFU":  func() cache.Cache { return NewTinyLFU(100) },
+		"Sharded":  func() cache.Cache { return NewSharded(4, 100) },
+	}
+
+	for name, constructor := range implementations {
+		t.Run(name, func(t *testing.T) {
+			c := constructor()
+			defer c.Close()
+			clock := withFakeClock(t, c)
+
+			c.Set("key", []byte("value"))
+
+			// Установка TTL постоянному ключу
+			if !c.Expire("key", 20*time.Millisecond) {
+				t.Fatal("Expire should succeed for existing key")
+			}
+			if ttl, _ := c.GetTTL("key"); ttl <= 0 || ttl > 20*time.Millisecond {
+				t.Fatalf("Unexpected TTL after Expire: %v", ttl)
+			}
+
+			// ttl <= 0 снимает ограничение
+			if !c.Expire("key", 0) {
+				t.Fatal("Expire(0) should succeed for existing key")
+			}
+			if ttl, _ := c.GetTTL("key"); ttl != cache.NoExpiration {
+				t.Fatalf("Expected NoExpiration after Expire(0), got %v", ttl)
+			}
+
+			// Значение не меняется
+			if value, _ := c.Get("key"); string(value) != "value" {
+				t.Fatalf("Value should be preserved, got %s", value)
+			}
+
+			c.Expire("key", time.Millisecond)
+			clock.Advance(5 * time.Millisecond)
+			if c.Expire("key", time.Minute) {
+				t.Fatal("Expire should fail for expired key")
+			}
+			if c.Expire("missing", time.Minute) {
+				t.Fatal("Expire should fail for missing key")
+			}
+		})
+	}
+}
+
+// TestPersist проверяет что бессрочный ключ переживает фоновую очистку
+func TestPersist(t *testing.T) {
+	type reaper interface {
+		cache.Cache
+		removeExpired()
+	}
+
+	implementations := map[string]func() reaper{
+		"Simple": func() reaper { return NewSimple().(*SimpleCache) },
+		"LRU":    func() reaper { return NewLRU(100).(*LRUCache) },
+		"LFU":    func() reaper { return NewLFU(100).(*LFUCache) },
+	}
+
+	for name, constructor := range implementations {
+		t.Run(name, func(t *testing.T) {
+			c := constructor()
+			defer c.Close()
+			clock := withFakeClock(t, c)
+
+			c.SetWithTTL("promoted", []byte("value"), 10*time.Millisecond)
+			c.SetWithTTL("temp", []byte("value"), 10*time.Millisecond)
+
+			if !c.Persist("promoted") {
+				t.Fatal("Persist should succeed for existing key")
+			}
+			if c.Persist("missing") {
+				t.Fatal("Persist should fail for missing key")
+			}
+
+			clock.Advance(20 * time.Millisecond)
+			c.removeExpired()
+
+			if _, exists := c.Get("promoted"); !exists {
+				t.Fatal("Persisted key should survive cleanup")
+			}
+			if _, exists := c.Get("temp"); exists {
+				t.Fatal("Temporary key should expire")
+			}
+			if c.Persist("temp") {
+				t.Fatal("Persist should fail for expired key")
+			}
+		})
+	}
+}
+
+// TestBulkOperations проверяет пакетные операции MGet/MSet/MDelete
+func TestBulkOperations(t *testing.T) {
+	type bulk interface {
+		cache.Cache
+		MGet(keys []string) map[string][]byte
+		MSet(items map[string][]byte, ttl time.Duration) error
+		MDelete(keys []string) int
+	}
+
+	implementations := map[string]func() bulk{
+		"Simple":  func() bulk { return NewSimple().(*SimpleCache) },
+		"LRU":     func() bulk { return NewLRU(100).(*LRUCache) },
+		"LFU":     func() bulk { return NewLFU(100).(*LFUCache) },
+		"Sharded": func() bulk { return NewSharded(4, 100).(*ShardedCache) },
+	}
+
+	for name, constructor := range implementations {
+		t.Run(name, func(t *testing.T) {
+			c := constructor()
+			defer c.Close()
+
+			err := c.MSet(map[string][]byte{
+				"a": []byte("1"),
+				"b": []byte("2"),
+				"c": []byte("3"),
+			}, 0)
+			if err != nil {
+				t.Fatalf("MSet failed: %v", err)
+			}
+
+			// Пустой ключ отклоняет весь пакет
+			err = c.MSet(map[string][]byte{"d": []byte("4"), "": []byte("5")}, 0)
+			if err != ErrKeyEmpty {
+				t.Fatalf("Expected ErrKeyEmpty, got %v", err)
+			}
+			if _, exists := c.Get("d"); exists {
+				t.Fatal("Invalid batch should not be partially applied")
+			}
+			c.Clear()
+			c.MSet(map[string][]byte{"a": []byte("1"), "b": []byte("2"), "c": []byte("3")}, 0)
+
+			found := c.MGet([]string{"a", "b", "missing"})
+			if len(found) != 2 || string(found["a"]) != "1" || string(found["b"]) != "2" {
+				t.Fatalf("Unexpected MGet result: %v", found)
+			}
+
+			stats := c.Stats()
+			if stats.Hits != 2 || stats.Misses != 1 {
+				t.Fatalf("Expected 2 hits and 1 miss, got hits=%d misses=%d", stats.Hits, stats.Misses)
+			}
+
+			if deleted := c.MDelete([]string{"a", "c", "missing"}); deleted != 2 {
+				t.Fatalf("Expected 2 deleted keys, got %d", deleted)
+			}
+			if keys := c.Keys(); len(keys) != 1 || keys[0] != "b" {
+				t.Fatalf("Expected only b to remain, got %v", keys)
+			}
+
+			c.Close()
+			if err := c.MSet(map[string][]byte{"x": []byte("1")}, 0); err != ErrCacheClosed {
+				t.Fatalf("Expected ErrCacheClosed, got %v", err)
+			}
+		})
+	}
+}
+
+// TestGetMulti проверяет разделение найденных ключей и промахов
+func TestGetMulti(t *testing.T) {
+	type multi interface {
+		cache.Cache
+		GetMulti(keys []string) (map[string][]byte, []string)
+	}
+
+	implementations := map[string]func() multi{
+		"Simple":  func() multi { return NewSimple().(*SimpleCache) },
+		"LRU":     func() multi { return NewLRU(100).(*LRUCache) },
+		"LFU":     func() multi { return NewLFU(100).(*LFUCache) },
+		"Sharded": func() multi { return NewSharded(4, 100).(*ShardedCache) },
+	}
+
+	for name, constructor := range implementations {
+		t.Run(name, func(t *testing.T) {
+			c := constructor()
+			defer c.Close()
+			clock := withFakeClock(t, c)
+
+			c.Set("a", []byte("1"))
+			c.Set("c", []byte("3"))
+			c.SetWithTTL("expired", []byte("x"), time.Millisecond)
+			clock.Advance(5 * time.Millisecond)
+
+			found, missing := c.GetMulti([]string{"z", "a", "expired", "b", "c", "z"})
+			if len(found) != 2 || string(found["a"]) != "1" || string(found["c"]) != "3" {
+				t.Fatalf("Unexpected found: %v", found)
+			}
+			want := []string{"z", "expired", "b"}
+			if len(missing) != len(want) {
+				t.Fatalf("Expected missing %v, got %v", want, missing)
+			}
+			for i := range want {
+				if missing[i] != want[i] {
+					t.Fatalf("Expected missing %v, got %v", want, missing)
+				}
+			}
+
+			// Найденные значения - копии
+			found["a"][0] = 'X'
+			if value, _ := c.Get("a"); string(value) != "1" {
+				t.Fatalf("GetMulti result must be a copy, got %q", value)
+			}
+
+			if found, missing := c.GetMulti(nil); len(found) != 0 || len(missing) != 0 {
+				t.Fatalf("Expected empty result for no keys, got %v %v", found, missing)
+			}
+		})
+	}
+}
+
+// TestOnClose проверяет, что колбэк закрытия вызывается один раз с живыми элементами
+func TestOnClose(t *testing.T) {
+	type closer interface {
+		cache.Cache
+		SetOnClose(fn func(items map[string][]byte))
+	}
+
+	implementations := map[string]func() closer{
+		"Simple":  func() closer { return NewSimple().(*SimpleCache) },
+		"LRU":     func() closer { return NewLRU(100).(*LRUCache) },
+		"LFU":     func() closer { return NewLFU(100).(*LFUCache) },
+		"Sharded": func() closer { return NewSharded(4, 100).(*ShardedCache) },
+	}
+
+	for name, constructor := range implementations {
+		t.Run(name, func(t *testing.T) {
+			c := constructor()
+			clock := withFakeClock(t, c)
+
+			var calls int
+			var got map[string][]byte
+			c.SetOnClose(func(items map[string][]byte) {
+				calls++
+				got = items
+			})
+
+			c.Set("a", []byte("1"))
+			c.Set("b", []byte("2"))
+			c.SetWithTTL("expired", []byte("x"), time.Millisecond)
+			clock.Advance(5 * time.Millisecond)
+
+			c.Close()
+			c.Close()
+
+			if calls != 1 {
+				t.Fatalf("Expected OnClose to be called once, got %d", calls)
+			}
+			if len(got) != 2 || string(got["a"]) != "1" || string(got["b"]) != "2" {
+				t.Fatalf("Unexpected items passed to OnClose: %v", got)
+			}
+			if err := c.Set("c", []byte("3")); err != ErrCacheClosed {
+				t.Fatalf("Expected ErrCacheClosed after Close, got %v", err)
+			}
+		})
+	}
+}
+
+// TestSimpleBounded проверяет, что ограниченный Simple кэш соблюдает лимит
+// при конкурентной записи и вытесняет только при вставке новых ключей
+func TestSimpleBounded(t *testing.T) {
+	const maxSize = 100
+	c := NewSimpleBounded(maxSize).(*SimpleCache)
+	defer c.Close()
+
+	var evicted atomic.Int64
+	c.SetOnEvict(func(key string, value []byte, reason cache.EvictionReason) {
+		if reason == cache.ReasonCapacity {
+			evicted.Add(1)
+		}
+	})
+
+	var wg sync.WaitGroup
+	for w := 0; w < 8; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < 500; i++ {
+				if err := c.Set(fmt.Sprintf("w%d:%d", w, i), []byte("v")); err != nil {
+					t.Errorf("Set failed: %v", err)
+					return
+				}
+				if n := c.Len(); n > maxSize {
+					t.Errorf("Cache grew to %d keys, limit is %d", n, maxSize)
+					return
+				}
+			}
+		}(w)
+	}
+	wg.Wait()
+
+	stats := c.Stats()
+	if stats.Keys != maxSize {
+		t.Fatalf("Expected %d keys, got %d", maxSize, stats.Keys)
+	}
+	if stats.Evictions != 8*500-maxSize || evicted.Load() != stats.Evictions {
+		t.Fatalf("Expected %d evictions, got stats=%d callbacks=%d", 8*500-maxSize, stats.Evictions, evicted.Load())
+	}
+
+	// Перезапись существующего ключа ничего не вытесняет
+	key := c.Keys()[0]
+	c.Set(key, []byte("updated"))
+	if after := c.Stats(); after.Evictions != stats.Evictions || after.Keys != maxSize {
+		t.Fatalf("Overwrite should not evict, got evictions=%d keys=%d", after.Evictions, after.Keys)
+	}
+
+	unbounded := NewSimpleBounded(0)
+	defer unbounded.Close()
+	for i := 0; i < 2*maxSize; i++ {
+		unbounded.Set(fmt.Sprint(i), []byte("v"))
+	}
+	if unbounded.Len() != 2*maxSize {
+		t.Fatalf("Expected unbounded cache for maxSize 0, got %d keys", unbounded.Len())
+	}
+}
+
+// TestGetAndRefresh проверяет продление TTL только вблизи истечения
+func TestGetAndRefresh(t *testing.T) {
+	type refresher interface {
+		cache.Cache
+		GetAndRefresh(key string, extendBy, onlyIfRemainingBelow time.Duration) ([]byte, bool)
+	}
+
+	implementations := map[string]func() refresher{
+		"Simple":  func() refresher { return NewSimple().(*SimpleCache) },
+		"LRU":     func() refresher { return NewLRU(100).(*LRUCache) },
+		"LFU":     func() refresher { return NewLFU(100).(*LFUCache) },
+		"Sharded": func() refresher { return NewSharded(4, 100).(*ShardedCache) },
+	}
+
+	for name, constructor := range implementations {
+		t.Run(name, func(t *testing.T) {
+			c := constructor()
+			defer c.Close()
+
+			// Еще не в окне продления: срок не меняется
+			c.SetWithTTL("far", []byte("1"), time.Hour)
+			if value, ok := c.GetAndRefresh("far", time.Hour, time.Minute); !ok || string(value) != "1" {
+				t.Fatalf("Expected hit, got %q %v", value, ok)
+			}
+			if ttl, _ := c.GetTTL("far"); ttl > time.Hour {
+				t.Errorf("TTL outside the window should not change, got %v", ttl)
+			}
+
+			// В окне: срок сдвигается на extendBy
+			c.SetWithTTL("near", []byte("2"), 30*time.Second)
+			if _, ok := c.GetAndRefresh("near", time.Hour, time.Minute); !ok {
+				t.Fatal("Expected hit for key in the window")
+			}
+			if ttl, _ := c.GetTTL("near"); ttl <= time.Hour {
+				t.Errorf("Expected TTL extended past an hour, got %v", ttl)
+			}
+
+			// Бессрочный ключ остается бессрочным
+			c.Set("forever", []byte("3"))
+			c.GetAndRefresh("forever", time.Hour, time.Minute)
+			if ttl, _ := c.GetTTL("forever"); ttl != cache.NoExpiration {
+				t.Errorf("Expected key without TTL to stay persistent, got %v", ttl)
+			}
+
+			if _, ok := c.GetAndRefresh("missing", time.Hour, time.Minute); ok {
+				t.Error("Expected miss for absent key")
+			}
+			if stats := c.Stats(); stats.Hits != 3 || stats.Misses != 1 {
+				t.Errorf("Expected 3 hits and 1 miss, got hits=%d misses=%d", stats.Hits, stats.Misses)
+			}
+		})
+	}
+}
+
+// TestSentinelErrors проверяет, что операции возвращают документированные
+// ошибки, распознаваемые через errors.Is даже с добавленным контекстом
+func TestSentinelErrors(t *testing.T) {
+	newLRU := func() *LRUCache {
+		c := NewLRUWithBytes(64).(*LRUCache)
+		c.SetMaxValueBytes(16)
+		c.Set("text", []byte("abc"))
+		return c
+	}
+	closed := newLRU()
+	closed.Close()
+
+	tests := []struct {
+		name string
+		op   func() error
+		want error
+	}{
+		{"Set empty key", func() error { return newLRU().Set("", []byte("v")) }, cache.ErrKeyEmpty},
+		{"Set over SetMaxValueBytes", func() error { return newLRU().Set("k", make([]byte, 17)) }, cache.ErrValueTooLarge},
+		{"Set over byte limit", func() error { return NewLRUWithBytes(8).Set("k", make([]byte, 8)) }, cache.ErrValueTooLarge},
+		{"MSet over SetMaxValueBytes", func() error {
+			return newLRU().MSet(map[string][]byte{"k": make([]byte, 17)}, 0)
+		}, cache.ErrValueTooLarge},
+		{"Set after Close", func() error { return closed.Set("k", []byte("v")) }, cache.ErrCacheClosed},
+		{"GetE after Close", func() error { _, err := closed.GetE("text"); return err }, cache.ErrCacheClosed},
+		{"GetE missing key", func() error { _, err := newLRU().GetE("missing"); return err }, cache.ErrNotFound},
+		{"Increment non-number", func() error { _, err := newLRU().Increment("text", 1); return err }, cache.ErrNotANumber},
+		{"LoadSnapshot garbage", func() error { return newLRU().LoadSnapshot(strings.NewReader("garbage")) }, cache.ErrInvalidSnapshot},
+		{"LoadSnapshot truncated", func() error { return newLRU().LoadSnapshot(strings.NewReader("HP")) }, cache.ErrInvalidSnapshot},
+		{"SetWithCost over budget", func() error {
+			return NewWeighted(10).(*WeightedCache).SetWithCost("k", []byte("v"), 11, 0)
+		}, cache.ErrValueTooLarge},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.op(); !errors.Is(err, tt.want) {
+				t.Fatalf("Expected %v, got %v", tt.want, err)
+			}
+		})
+	}
+
+	// Преждевременный конец снимка распознается и как исходная ошибка чтения
+	if err := newLRU().LoadSnapshot(strings.NewReader("HP")); !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Errorf("Expected truncated snapshot error to wrap io.ErrUnexpectedEOF, got %v", err)
+	}
+}
+
+// TestSimulate проверяет воспроизведение трассировки на разных политиках
+func TestSimulate(t *testing.T) {
+	// Трассировка: три горячих ключа, читаемых дважды за раунд, и сканирование
+	// уникальных ключей между ними. Сканирование вымывает горячие ключи из LRU,
+	// а LFU удерживает их по частоте.
+	var buf bytes.Buffer
+	traced := cache.NewTracing(NewSimple(), &buf)
+	for round := 0; round < 100; round++ {
+		for i := 0; i < 6; i++ {
+			traced.Get(fmt.Sprintf("hot%d", i/2))
+		}
+		for i := 0; i < 5; i++ {
+			traced.Get(fmt.Sprintf("scan%d-%d", round, i))
+		}
+	}
+	traced.Close()
+	if traced.Dropped() != 0 {
+		t.Fatalf("Expected complete trace, %d records dropped", traced.Dropped())
+	}
+
+	policies := []cache.EvictionPolicy{cache.LRU, cache.LFU, cache.FIFO}
+	results, err := Simulate(&buf, policies, 4)
+	if err != nil {
+		t.Fatalf("Simulate failed: %v", err)
+	}
+	if len(results) != len(policies) {
+		t.Fatalf("Expected %d results, got %d", len(policies), len(results))
+	}
+
+	lru, lfu := results[cache.LRU], results[cache.LFU]
+	if lru.Hits+lru.Misses != 1100 || lfu.Hits+lfu.Misses != 1100 {
+		t.Errorf("Expected 1100 lookups per policy, got LRU %d, LFU %d", lru.Hits+lru.Misses, lfu.Hits+lfu.Misses)
+	}
+	if lfu.HitRate <= lru.HitRate {
+		t.Errorf("Expected LFU to beat LRU on scan-heavy trace, got LFU %.2f%%, LRU %.2f%%", lfu.HitRate, lru.HitRate)
+	}
+
+	if _, err := Simulate(strings.NewReader(""), []cache.EvictionPolicy{cache.EvictionPolicy(42)}, 4); !errors.Is(err, cache.ErrInvalidConfig) {
+		t.Errorf("Expected ErrInvalidConfig for unknown policy, got %v", err)
+	}
+	if _, err := Simulate(strings.NewReader("timestamp,op\n"), policies, 4); err == nil {
+		t.Error("Expected error for malformed trace")
+	}
+}
+
+// TestStatsKeyCountConsistent проверяет, что атомарный счетчик ключей совпадает
+// с размером карты после смешанной конкурентной нагрузки с фоновой очисткой и Clear
+func TestStatsKeyCountConsistent(t *testing.T) {
+	implementations := map[string]func() cache.Cache{
+		"LRU":    func() cache.Cache { return NewLRUWithTTL(64, 5*time.Millisecond) },
+		"LFU":    func() cache.Cache { return NewLFUWithTTL(64, 5*time.Millisecond) },
+		"Simple": func() cache.Cache { return NewSimpleWithTTL(5 * time.Millisecond) },
+	}
+
+	for name, newCache := range implementations {
+		t.Run(name, func(t *testing.T) {
+			c := newCache()
+			defer c.Close()
+			c.(interface{ SetCleanupInterval(time.Duration) }).SetCleanupInterval(time.Millisecond)
+
+			var wg sync.WaitGroup
+			for w := 0; w < 8; w++ {
+				wg.Add(1)
+				go func(w int) {
+					defer wg.Done()
+					for i := 0; i < 2000; i++ {
+						key := strconv.Itoa((w*31 + i) % 100)
+						switch i % 10 {
+						case 0:
+							c.Delete(key)
+						case 1:
+							c.(interface {
+								Increment(string, int64) (int64, error)
+							}).Increment(key, 1)
+						case 2:
+							c.Stats()
+						case 3:
+							if i%500 == 3 {
+								c.Clear()
+							}
+						default:
+							c.SetWithTTL(key, []byte("value"), time.Duration(i%3)*time.Millisecond)
+						}
+					}
+				}(w)
+			}
+			wg.Wait()
+			time.Sleep(10 * time.Millisecond)
+
+			var keys int
+			switch c := c.(type) {
+			case *LRUCache:
+				c.mu.RLock()
+				keys = len(c.items)
+				c.mu.RUnlock()
+			case *LFUCache:
+				c.mu.RLock()
+				keys = len(c.items)
+				c.mu.RUnlock()
+			case *SimpleCache:
+				c.mu.RLock()
+				keys = len(c.items)
+				c.mu.RUnlock()
+			}
+			if stats := c.Stats(); stats.Keys != int64(keys) {
+				t.Errorf("Expected Stats.Keys to equal map size %d, got %d", keys, stats.Keys)
+			}
+		})
+	}
+}
+
+// TestBloomFilter проверяет, что фильтр Блума не дает ложных промахов
+// после записи, удаления, вытеснения и очистки
+func TestBloomFilter(t *testing.T) {
+	type bloomCache interface {
+		cache.Cache
+		SetBloomFilter(size int, falsePositiveRate float64)
+	}
+	implementations := map[string]func() bloomCache{
+		"LRU":     func() bloomCache { return NewLRU(50).(bloomCache) },
+		"LFU":     func() bloomCache { return NewLFU(50).(bloomCache) },
+		"Simple":  func() bloomCache { return NewSimple().(bloomCache) },
+		"Sharded": func() bloomCache { return NewSharded(4, 50).(bloomCache) },
+	}
+
+	for name, newCache := range implementations {
+		t.Run(name, func(t *testing.T) {
+			c := newCache()
+			defer c.Close()
+
+			c.Set("before", []byte("v"))
+			c.SetBloomFilter(100, 0.01)
+			if _, ok := c.Get("before"); !ok {
+				t.Error("Expected key stored before enabling the filter to be found")
+			}
+
+			for i := 0; i < 200; i++ {
+				c.Set(fmt.Sprintf("key%d", i), []byte("v"))
+			}
+			for _, key := range c.Keys() {
+				if _, ok := c.Get(key); !ok {
+					t.Fatalf("Expected stored key %s to pass the filter", key)
+				}
+			}
+
+			c.Set("deleted", []byte("v"))
+			c.Delete("deleted")
+			if _, ok := c.Get("deleted"); ok {
+				t.Error("Expected deleted key to miss")
+			}
+			c.Set("deleted", []byte("v"))
+			if _, ok := c.Get("deleted"); !ok {
+				t.Error("Expected key stored again after Delete to be found")
+			}
+
+			c.Clear()
+			if _, ok := c.Get("deleted"); ok {
+				t.Error("Expected miss after Clear")
+			}
+			c.Set("after", []byte("v"))
+			if _, ok := c.Get("after"); !ok {
+				t.Error("Expected key stored after Clear to be found")
+			}
+
+			misses := c.Stats().Misses
+			c.Get("absent")
+			if got := c.Stats().Misses; got != misses+1 {
+				t.Errorf("Expected filtered miss to be counted, misses %d -> %d", misses, got)
+			}
+
+			c.SetBloomFilter(0, 0)
+			if _, ok := c.Get("after"); !ok {
+				t.Error("Expected key to be found with the filter disabled")
+			}
+		})
+	}
+}
+
+// TestTopKeys проверяет отбор самых популярных ключей и их порядок
+func TestTopKeys(t *testing.T) {
+	type topKeysCache interface {
+		cache.Cache
+		TopKeys(n int) []cache.KeyStat
+	}
+
+	implementations := map[string]func() topKeysCache{
+		"Simple":  func() topKeysCache { return NewSimple().(*SimpleCache) },
+		"LRU":     func() topKeysCache { return NewLRU(100).(*LRUCache) },
+		"LFU":     func() topKeysCache { return NewLFU(100).(*LFUCache) },
+		"Sharded": func() topKeysCache { return NewSharded(4, 100).(*ShardedCache) },
+	}
+
+	for name, constructor := range implementations {
+		t.Run(name, func(t *testing.T) {
+			c := constructor()
+			defer c.Close()
+			clock := withFakeClock(t, c)
+
+			// LFU считает запись ключа обращением
+			offset := int64(0)
+			if name == "LFU" {
+				offset = 1
+			}
+
+			before := clock.Now()
+			for key, reads := range map[string]int{"hot": 5, "warm": 3, "cold": 1, "idle": 0} {
+				c.Set(key, []byte("v"))
+				for i := 0; i < reads; i++ {
+					c.Get(key)
+				}
+			}
+			c.SetWithTTL("expired", []byte("v"), time.Millisecond)
+			for i := 0; i < 10; i++ {
+				c.Get("expired")
+			}
+			clock.Advance(5 * time.Millisecond)
+
+			top := c.TopKeys(2)
+			if len(top) != 2 || top[0].Key != "hot" || top[1].Key != "warm" {
+				t.Fatalf("Expected [hot warm], got %+v", top)
+			}
+			if top[0].Hits != 5+offset || top[1].Hits != 3+offset {
+				t.Errorf("Expected hits %d and %d, got %d and %d", 5+offset, 3+offset, top[0].Hits, top[1].Hits)
+			}
+			if top[0].LastAccess.Before(before) {
+				t.Errorf("Expected last access after %v, got %v", before, top[0].LastAccess)
+			}
+
+			all := c.TopKeys(10)
+			if len(all) != 4 || all[3].Key != "idle" {
+				t.Errorf("Expected 4 live keys ending with idle, got %+v", all)
+			}
+			if len(c.TopKeys(0)) != 0 {
+				t.Error("Expected no keys for n = 0")
+			}
+
+			// Перезапись сохраняет попадания, удаление обнуляет
+			c.Set("hot", []byte("v2"))
+			if top := c.TopKeys(1); top[0].Key != "hot" {
+				t.Errorf("Expected hot to stay on top after overwrite, got %+v", top)
+			}
+			c.Delete("hot")
+			c.Set("hot", []byte("v"))
+			if top := c.TopKeys(1); top[0].Key != "warm" {
+				t.Errorf("Expected warm on top after hot was deleted, got %+v", top)
+			}
+		})
+	}
+}
+
+// TestLRUBulkEviction проверяет вытеснение при пакетной вставке в LRU
+func TestLRUBulkEviction(t *testing.T) {
+	c := NewLRU(3).(*LRUCache)
+	defer c.Close()
+
+	items := make(map[string][]byte)
+	for i := 0; i < 5; i++ {
+		items[fmt.Sprintf("key%d", i)] = []byte("value")
+	}
+	c.MSet(items, 0)
+
+	stats := c.Stats()
+	if stats.Keys != 3 || stats.Evictions != 2 {
+		t.Fatalf("Expected 3 keys and 2 evictions, got keys=%d evictions=%d", stats.Keys, stats.Evictions)
+	}
+}
+
+// TestIncrement проверяет атомарные счетчики
+func TestIncrement(t *testing.T) {
+	type counter interface {
+		cache.Cache
+		Increment(key string, delta int64) (int64, error)
+		Decrement(key string, delta int64) (int64, error)
+	}
+
+	implementations := map[string]func() counter{
+		"Simple":  func() counter { return NewSimple().(*SimpleCache) },
+		"LRU":     func() counter { return NewLRU(100).(*LRUCache) },
+		"LFU":     func() counter { return NewLFU(100).(*LFUCache) },
+		"Sharded": func() counter { return NewSharded(4, 100).(*ShardedCache) },
+	}
+
+	for name, constructor := range implementations {
+		t.Run(name, func(t *testing.T) {
+			c := constructor()
+			defer c.Close()
+
+			// Отсутствующий ключ считается нулем
+			if n, err := c.Increment("hits", 5); err != nil || n != 5 {
+				t.Fatalf("Expected 5, got %d (%v)", n, err)
+			}
+			if n, err := c.Decrement("hits", 2); err != nil || n != 3 {
+				t.Fatalf("Expected 3, got %d (%v)", n, err)
+			}
+
+			// Конкурентные инкременты не теряются
+			var wg sync.WaitGroup
+			for i := 0; i < 10; i++ {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					for j := 0; j < 100; j++ {
+						c.Increment("concurrent", 1)
+					}
+				}()
+			}
+			wg.Wait()
+
+			if value, _ := c.Get("concurrent"); string(value) != "1000" {
+				t.Fatalf("Expected 1000, got %s", value)
+			}
+
+			// TTL существующего ключа сохраняется
+			c.SetWithTTL("limited", []byte("1"), time.Minute)
+			c.Increment("limited", 1)
+			if ttl, _ := c.GetTTL("limited"); ttl == cache.NoExpiration {
+				t.Fatal("Increment should preserve TTL")
+			}
+
+			c.Set("text", []byte("abc"))
+			if _, err := c.Increment("text", 1); !errors.Is(err, cache.ErrNotANumber) {
+				t.Fatalf("Expected ErrNotANumber, got %v", err)
+			}
+
+			// Переполнение int64 отклоняется, значение не меняется
+			c.Set("max", []byte(strconv.FormatInt(math.MaxInt64-1, 10)))
+			if n, err := c.Increment("max", 1); err != nil || n != math.MaxInt64 {
+				t.Fatalf("Expected MaxInt64, got %d (%v)", n, err)
+			}
+			if _, err := c.Increment("max", 1); !errors.Is(err, cache.ErrOverflow) {
+				t.Errorf("Expected ErrOverflow, got %v", err)
+			}
+			if value, _ := c.Get("max"); string(value) != strconv.FormatInt(math.MaxInt64, 10) {
+				t.Errorf("Overflow should keep the value, got %s", value)
+			}
+
+			c.Set("min", []byte(strconv.FormatInt(math.MinInt64+1, 10)))
+			if n, err := c.Decrement("min", 1); err != nil || n != math.MinInt64 {
+				t.Fatalf("Expected MinInt64, got %d (%v)", n, err)
+			}
+			if _, err := c.Decrement("min", 1); !errors.Is(err, cache.ErrOverflow) {
+				t.Errorf("Expected ErrOverflow, got %v", err)
+			}
+			if _, err := c.Increment("min", math.MinInt64); !errors.Is(err, cache.ErrOverflow) {
+				t.Errorf("Expected ErrOverflow, got %v", err)
+			}
+
+			// Декремент на MinInt64 не может быть выражен инкрементом на -delta
+			if _, err := c.Decrement("zero", math.MinInt64); !errors.Is(err, cache.ErrOverflow) {
+				t.Errorf("Expected ErrOverflow for MinInt64 decrement, got %v", err)
+			}
+			if _, exists := c.Get("zero"); exists {
+				t.Error("Rejected decrement should not create the key")
+			}
+		})
+	}
+}
+
+// TestOnEvict проверяет вызов колбэка удаления с корректными причинами
+func TestOnEvict(t *testing.T) {
+	type evictable interface {
+		cache.Cache
+		SetOnEvict(fn cache.EvictCallback)
+	}
+
+	implementations := map[string]func() evictable{
+		"Simple":   func() evictable { return NewSimple().(*SimpleCache) },
+		"LRU":      func() evictable { return NewLRU(2).(*LRUCache) },
+		"LFU":      func() evictable { return NewLFU(2).(*LFUCache) },
+		"FIFO":     func() evictable { return NewFIFO(2).(*FIFOCache) },
+		"Random":   func() evictable { return NewRandom(2).(*RandomCache) },
+		"Approx":   func() evictable { return NewApproxLRU(2, 0).(*ApproxLRUCache) },
+		"Weighted": func() evictable { return NewWeighted(2).(*WeightedCache) },
+		"ARC":      func() evictable { return NewARC(2).(*ARCCache) },
+		"TinyLFU":  func() evictable { return NewTinyLFU(2).(*TinyLFUCache) },
+		"Sharded":  func() evictable { return NewSharded(1, 2).(*ShardedCache) },
+	}
+
+	for name, constructor := range implementations {
+		t.Run(name, func(t *testing.T) {
+			c := constructor()
+			defer c.Close()
+			clock := withFakeClock(t, c)
+
+			var mu sync.Mutex
+			reasons := make(map[string]cache.EvictionReason)
+			c.SetOnEvict(func(key string, value []byte, reason cache.EvictionReason) {
+				// Обращение к кэшу из колбэка не должно блокироваться
+				c.Get(key)
+
+				mu.Lock()
+				reasons[key] = reason
+				mu.Unlock()
+			})
+
+			c.SetWithTTL("expired", []byte("value"), time.Millisecond)
+			clock.Advance(5 * time.Millisecond)
+			c.Get("expired")
+
+			c.Set("deleted", []byte("value"))
+			c.Delete("deleted")
+
+			c.Set("cleared", []byte("value"))
+			c.Clear()
+
+			mu.Lock()
+			defer mu.Unlock()
+
+			expected := map[string]cache.EvictionReason{
+				"expired": cache.ReasonExpired,
+				"deleted": cache.ReasonDeleted,
+				"cleared": cache.ReasonCleared,
+			}
+			for key, reason := range expected {
+				if reasons[key] != reason {
+					t.Errorf("Key %s: expected reason %v, got %v", key, reason, reasons[key])
+				}
+			}
+		})
+	}
+}
+
+// TestOnEvictReentrant проверяет, что колбэк удаления может обращаться к кэшу:
+// он вызывается после снятия блокировки при вытеснении, истечении и Clear
+func TestOnEvictReentrant(t *testing.T) {
+	type evictingCache interface {
+		cache.Cache
+		SetOnEvict(fn cache.EvictCallback)
+		SetCleanupInterval(interval time.Duration)
+	}
+
+	// Удаление parentN удаляет связанный childN
+	cascade := func(c evictingCache) {
+		c.SetOnEvict(func(key string, _ []byte, reason cache.EvictionReason) {
+			if suffix, ok := strings.CutPrefix(key, "parent"); ok {
+				c.Get("child" + suffix)
+				c.Delete("child" + suffix)
+			}
+		})
+	}
+
+	implementations := map[string]struct {
+		constructor func() evictingCache
+		bounded     bool
+	}{
+		"Simple":  {func() evictingCache { return NewSimple().(*SimpleCache) }, false},
+		"LRU":     {func() evictingCache { return NewLRU(2).(*LRUCache) }, true},
+		"LFU":     {func() evictingCache { return NewLFU(2).(*LFUCache) }, true},
+		"Sharded": {func() evictingCache { return NewSharded(1, 2).(*ShardedCache) }, true},
+	}
+
+	for name, impl := range implementations {
+		t.Run(name, func(t *testing.T) {
+			done := make(chan struct{})
+			go func() {
+				defer close(done)
+
+				if impl.bounded {
+					c := impl.constructor()
+					defer c.Close()
+					cascade(c)
+
+					c.Set("parent1", []byte("v"))
+					c.Set("child1", []byte("v"))
+					c.Set("filler", []byte("v")) // Вытесняет parent1
+					if _, ok := c.Get("child1"); ok || c.Len() != 1 {
+						t.Errorf("Expected only filler after capacity eviction, got keys %v", c.Keys())
+					}
+				}
+
+				c := impl.constructor()
+				defer c.Close()
+				cascade(c)
+
+				c.SetWithTTL("parent2", []byte("v"), time.Millisecond)
+				c.Set("child2", []byte("v"))
+				c.SetCleanupInterval(time.Millisecond)
+				deadline := time.Now().Add(time.Second)
+				for c.Len() > 0 && time.Now().Before(deadline) {
+					time.Sleep(time.Millisecond)
+				}
+				if c.Len() != 0 {
+					t.Errorf("Expected cleanup to remove parent2 and its child, got keys %v", c.Keys())
+				}
+
+				c.Set("parent3", []byte("v"))
+				c.Set("child3", []byte("v"))
+				c.SetOnEvict(func(key string, _ []byte, reason cache.EvictionReason) {
+					c.Get(key)
+					c.Delete(key)
+				})
+				c.Clear()
+				if c.Len() != 0 {
+					t.Errorf("Expected empty cache after Clear, got keys %v", c.Keys())
+				}
+			}()
+
+			select {
+			case <-done:
+			case <-time.After(5 * time.Second):
+				t.Fatal("Evict callback re-entering the cache deadlocked")
+			}
+		})
+	}
+}
+
+// TestOnEvictCapacity проверяет уведомление о вытеснении при переполнении
+func TestOnEvictCapacity(t *testing.T) {
+	c := NewLRU(2).(*LRUCache)
+	defer c.Close()
+
+	var evicted []string
+	c.SetOnEvict(func(key string, value []byte, reason cache.EvictionReason) {
+		if reason != cache.ReasonCapacity {
+			t.Errorf("Expected capacity reason, got %v", reason)
+		}
+		if string(value) != "value"+key {
+			t.Errorf("Unexpected evicted value %s", value)
+		}
+		evicted = append(evicted, key)
+	})
+
+	c.Set("A", []byte("valueA"))
+	c.Set("B", []byte("valueB"))
+	c.Set("C", []byte("valueC"))
+
+	if fmt.Sprint(evicted) != "[A]" {
+		t.Fatalf("Expected A to be evicted, got %v", evicted)
+	}
+}
+
+// TestSnapshot проверяет сохранение и восстановление кэша через снимок
+func TestSnapshot(t *testing.T) {
+	type snapshotter interface {
+		cache.Cache
+		SaveSnapshot(w io.Writer) error
+		LoadSnapshot(r io.Reader) error
+	}
+
+	implementations := map[string]func() snapshotter{
+		"Simple":  func() snapshotter { return NewSimpleWithTTL(time.Hour).(*SimpleCache) },
+		"LRU":     func() snapshotter { return NewLRUWithTTL(100, time.Hour).(*LRUCache) },
+		"LFU":     func() snapshotter { return NewLFUWithTTL(100, time.Hour).(*LFUCache) },
+		"Sharded": func() snapshotter { return NewShardedWithTTL(4, 100, time.Hour).(*ShardedCache) },
+	}
+
+	for name, constructor := range implementations {
+		t.Run(name, func(t *testing.T) {
+			c := constructor()
+			defer c.Close()
+			clock := withFakeClock(t, c)
+
+			c.SetWithTTL("short", []byte("short"), 30*time.Millisecond)
+			c.SetWithTTL("long", []byte("long"), time.Minute)
+			c.Set("persistent", []byte("persistent"))
+			c.Persist("persistent")
+			c.SetWithTTL("expired", []byte("expired"), time.Millisecond)
+			clock.Advance(5 * time.Millisecond)
+
+			var buf bytes.Buffer
+			if err := c.SaveSnapshot(&buf); err != nil {
+				t.Fatalf("SaveSnapshot failed: %v", err)
+			}
+			data := buf.Bytes()
+
+			c.Clear()
+			if err := c.LoadSnapshot(bytes.NewReader(data)); err != nil {
+				t.Fatalf("LoadSnapshot failed: %v", err)
+			}
+
+			keys := c.Keys()
+			sort.Strings(keys)
+			if fmt.Sprint(keys) != "[long persistent short]" {
+				t.Fatalf("Expected [long persistent short], got %v", keys)
+			}
+			if value, _ := c.Get("long"); string(value) != "long" {
+				t.Fatalf("Expected value long, got %s", value)
+			}
+			if ttl, _ := c.GetTTL("long"); ttl <= 55*time.Second || ttl > time.Minute {
+				t.Fatalf("Expected TTL close to 1m, got %v", ttl)
+			}
+			if ttl, _ := c.GetTTL("persistent"); ttl != cache.NoExpiration {
+				t.Fatalf("Persistent key should stay persistent, got %v", ttl)
+			}
+
+			// Элемент, истекший между сохранением и загрузкой, пропускается
+			c.Clear()
+			clock.Advance(40 * time.Millisecond)
+			if err := c.LoadSnapshot(bytes.NewReader(data)); err != nil {
+				t.Fatalf("LoadSnapshot failed: %v", err)
+			}
+			if _, exists := c.GetTTL("short"); exists {
+				t.Fatal("Key expired before load should be skipped")
+			}
+
+			if err := c.LoadSnapshot(bytes.NewReader(data[:len(data)-3])); !errors.Is(err, cache.ErrInvalidSnapshot) {
+				t.Fatalf("Expected ErrInvalidSnapshot for truncated data, got %v", err)
+			}
+			if err := c.LoadSnapshot(strings.NewReader("garbage")); !errors.Is(err, cache.ErrInvalidSnapshot) {
+				t.Fatalf("Expected ErrInvalidSnapshot for garbage, got %v", err)
+			}
+		})
+	}
+}
+
+// TestWAL проверяет восстановление кэша из журнала после перезапуска
+func TestWAL(t *testing.T) {
+	config := WALConfig{Path: filepath.Join(t.TempDir(), "cache.wal"), SyncEvery: 1}
+	clock := newFakeClock()
+
+	open := func() *WALCache {
+		w, err := NewWAL(NewLRUWithOptions(WithMaxSize(100), WithClock(clock)).(*LRUCache), config)
+		if err != nil {
+			t.Fatalf("NewWAL failed: %v", err)
+		}
+		return w
+	}
+
+	w := open()
+	w.Set("a", []byte("1"))
+	w.SetWithTTL("b", []byte("2"), time.Minute)
+	w.Set("c", []byte("3"))
+	w.Delete("c")
+	w.SetWithTTL("d", []byte("4"), time.Minute)
+	w.Persist("d")
+	w.SetWithTTL("short", []byte("5"), 20*time.Millisecond)
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	clock.Advance(30 * time.Millisecond)
+
+	w = open()
+	keys := w.Keys()
+	sort.Strings(keys)
+	if fmt.Sprint(keys) != "[a b d]" {
+		t.Fatalf("Expected [a b d] after replay, got %v", keys)
+	}
+	if ttl, _ := w.GetTTL("b"); ttl != time.Minute-30*time.Millisecond {
+		t.Fatalf("Expected TTL reduced by the elapsed time, got %v", ttl)
+	}
+	if ttl, _ := w.GetTTL("d"); ttl != cache.NoExpiration {
+		t.Fatalf("Persisted key should stay persistent, got %v", ttl)
+	}
+
+	// После компакции журнал пуст, а состояние восстанавливается из снимка
+	if err := w.Compact(); err != nil {
+		t.Fatalf("Compact failed: %v", err)
+	}
+	w.Set("e", []byte("5"))
+	w.Close()
+
+	if info, err := os.Stat(config.Path); err != nil || info.Size() == 0 || info.Size() > 32 {
+		t.Fatalf("Expected log with a single record after compaction, got %v (%v)", info.Size(), err)
+	}
+
+	// Недописанная запись в конце журнала отбрасывается
+	f, err := os.OpenFile(config.Path, os.O_APPEND|os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.Write([]byte{40, walOpSet, 1})
+	f.Close()
+
+	w = open()
+	defer w.Close()
+
+	keys = w.Keys()
+	sort.Strings(keys)
+	if fmt.Sprint(keys) != "[a b d e]" {
+		t.Fatalf("Expected [a b d e] after compaction and torn write, got %v", keys)
+	}
+	w.Set("f", []byte("6"))
+}
+
+// TestWALAutoCompact проверяет компакцию при превышении размера журнала
+func TestWALAutoCompact(t *testing.T) {
+	config := WALConfig{Path: filepath.Join(t.TempDir(), "cache.wal"), CompactSize: 1024}
+
+	w, err := NewWAL(NewLRU(10).(*LRUCache), config)
+	if err != nil {
+		t.Fatalf("NewWAL failed: %v", err)
+	}
+	for i := 0; i < 1000; i++ {
+		w.Set(fmt.Sprintf("key%d", i%10), []byte("value"))
+	}
+	w.Close()
+
+	if info, _ := os.Stat(config.Path); info.Size() >= config.CompactSize {
+		t.Fatalf("Log should be compacted below %d bytes, got %d", config.CompactSize, info.Size())
+	}
+
+	w, err = NewWAL(NewLRU(10).(*LRUCache), config)
+	if err != nil {
+		t.Fatalf("NewWAL failed: %v", err)
+	}
+	defer w.Close()
+
+	if keys := w.Stats().Keys; keys != 10 {
+		t.Fatalf("Expected 10 keys after replay, got %d", keys)
+	}
+}
+
+// TestCompression проверяет прозрачное сжатие значений длиннее порога
+func TestCompression(t *testing.T) {
+	type compressible interface {
+		cache.Cache
+		SetCompressionThreshold(threshold int)
+		ForEach(fn func(key string, value []byte) bool)
+		SetOnEvict(fn cache.EvictCallback)
+	}
+
+	implementations := map[string]func() compressible{
+		"Simple":  func() compressible { return NewSimple().(*SimpleCache) },
+		"LRU":     func() compressible { return NewLRU(100).(*LRUCache) },
+		"LFU":     func() compressible { return NewLFU(100).(*LFUCache) },
+		"Sharded": func() compressible { return NewSharded(4, 100).(*ShardedCache) },
+	}
+
+	const threshold = 64
+	values := map[string][]byte{
+		"below": bytes.Repeat([]byte("a"), threshold-1),
+		"at":    bytes.Repeat([]byte("b"), threshold),
+		"above": bytes.Repeat([]byte(`{"field":"value"}`), 100),
+	}
+
+	for name, constructor := range implementations {
+		t.Run(name, func(t *testing.T) {
+			c := constructor()
+			defer c.Close()
+
+			c.SetCompressionThreshold(threshold)
+			for key, value := range values {
+				c.Set(key, value)
+			}
+
+			for key, value := range values {
+				got, exists := c.Get(key)
+				if !exists || !bytes.Equal(got, value) {
+					t.Fatalf("Key %s: value did not survive compression round-trip", key)
+				}
+			}
+
+			c.ForEach(func(key string, value []byte) bool {
+				if !bytes.Equal(value, values[key]) {
+					t.Errorf("ForEach returned corrupted value for %s", key)
+				}
+				return true
+			})
+
+			var raw int64
+			for key, value := range values {
+				raw += int64(len(key) + len(value))
+			}
+			stats := c.Stats()
+			if stats.RawBytes != raw {
+				t.Fatalf("Expected %d raw bytes, got %d", raw, stats.RawBytes)
+			}
+			if stats.Bytes >= stats.RawBytes {
+				t.Fatalf("Compressed bytes %d should be less than raw bytes %d", stats.Bytes, stats.RawBytes)
+			}
+
+			var evicted []byte
+			c.SetOnEvict(func(key string, value []byte, reason cache.EvictionReason) {
+				if key == "above" {
+					evicted = value
+				}
+			})
+			c.Delete("above")
+			if !bytes.Equal(evicted, values["above"]) {
+				t.Fatal("Evict callback should receive the decompressed value")
+			}
+
+			c.Clear()
+			if stats := c.Stats(); stats.Bytes != 0 || stats.RawBytes != 0 {
+				t.Fatalf("Byte totals should be reset after Clear, got %d/%d", stats.Bytes, stats.RawBytes)
+			}
+		})
+	}
+}
+
+// TestStreamKeys проверяет потоковую выдачу всех ключей по партициям
+// и завершение горутины обхода при отмене контекста
+func TestStreamKeys(t *testing.T) {
+	type streamingCache interface {
+		cache.Cache
+		StreamKeys(ctx context.Context) <-chan string
+	}
+
+	// Больше streamBatchSize, чтобы обход шел несколькими партициями
+	const count = 40000
+	implementations := map[string]func() streamingCache{
+		"Simple":  func() streamingCache { return NewSimple().(*SimpleCache) },
+		"LRU":     func() streamingCache { return NewLRU(2 * count).(*LRUCache) },
+		"LFU":     func() streamingCache { return NewLFU(2 * count).(*LFUCache) },
+		"Sharded": func() streamingCache { return NewSharded(4, count).(*ShardedCache) },
+	}
+
+	for name, constructor := range implementations {
+		t.Run(name, func(t *testing.T) {
+			c := constructor()
+			defer c.Close()
+			clock := withFakeClock(t, c)
+
+			for i := 0; i < count; i++ {
+				c.Set(strconv.Itoa(i), []byte("v"))
+			}
+			c.SetWithTTL("expired", []byte("v"), time.Millisecond)
+			clock.Advance(5 * time.Millisecond)
+
+			seen := make(map[string]bool, count)
+			for key := range c.StreamKeys(context.Background()) {
+				if seen[key] {
+					t.Fatalf("Key %s streamed twice", key)
+				}
+				seen[key] = true
+			}
+			if len(seen) != count || seen["expired"] {
+				t.Fatalf("Expected %d live keys, got %d (expired included: %v)", count, len(seen), seen["expired"])
+			}
+
+			ctx, cancel := context.WithCancel(context.Background())
+			keys := c.StreamKeys(ctx)
+			for i := 0; i < 10; i++ {
+				<-keys
+			}
+			cancel()
+
+			// Канал закрывается горутиной обхода при выходе, после отмены остается
+			// не больше буфера уже отправленных ключей
+			drained := 0
+			timeout := time.After(time.Second)
+			for open := true; open; {
+				select {
+				case _, open = <-keys:
+					drained++
+				case <-timeout:
+					t.Fatal("Stream goroutine did not exit after cancel")
+				}
+			}
+			if drained > 2*streamBufferSize+1 {
+				t.Errorf("Expected stream to stop after cancel, drained %d more keys", drained)
+			}
+
+			// Запись не ждет остановленный обход
+			if err := c.Set("after", []byte("v")); err != nil {
+				t.Fatalf("Set after cancelled stream failed: %v", err)
+			}
+		})
+	}
+}
+
+// TestGetOrSetContext проверяет прекращение ожидания загрузки при отмене контекста
+func TestGetOrSetContext(t *testing.T) {
+	type contextCache interface {
+		cache.Cache
+		GetContext(ctx context.Context, key string) ([]byte, bool)
+		SetContext(ctx context.Context, key string, value []byte, ttl time.Duration) error
+		GetOrSetContext(ctx context.Context, key string, loader func() ([]byte, error), ttl time.Duration) ([]byte, error)
+	}
+
+	implementations := map[string]func() contextCache{
+		"Simple":  func() contextCache { return NewSimple().(*SimpleCache) },
+		"LRU":     func() contextCache { return NewLRU(100).(*LRUCache) },
+		"LFU":     func() contextCache { return NewLFU(100).(*LFUCache) },
+		"Sharded": func() contextCache { return NewSharded(4, 100).(*ShardedCache) },
+	}
+
+	for name, constructor := range implementations {
+		t.Run(name, func(t *testing.T) {
+			c := constructor()
+			defer c.Close()
+
+			release := make(chan struct{})
+			loaded := make(chan struct{})
+			slowLoader := func() ([]byte, error) {
+				<-release
+				defer close(loaded)
+				return []byte("value"), nil
+			}
+
+			// Ведущий и ожидающий вызовы прекращают ждать по таймауту
+			var wg sync.WaitGroup
+			for i := 0; i < 2; i++ {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+					defer cancel()
+					if _, err := c.GetOrSetContext(ctx, "slow", slowLoader, 0); err != context.DeadlineExceeded {
+						t.Errorf("Expected DeadlineExceeded, got %v", err)
+					}
+				}()
+			}
+			wg.Wait()
+
+			// Загрузка завершается в фоне и сохраняет значение
+			close(release)
+			<-loaded
+			time.Sleep(5 * time.Millisecond)
+			if value, err := c.GetOrSetContext(context.Background(), "slow", slowLoader, 0); err != nil || string(value) != "value" {
+				t.Fatalf("Expected background load to populate cache, got %s (%v)", value, err)
+			}
+
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+
+			if err := c.SetContext(ctx, "key", []byte("value"), 0); err != context.Canceled {
+				t.Fatalf("Expected Canceled from SetContext, got %v", err)
+			}
+			c.Set("key", []byte("value"))
+			if _, exists := c.GetContext(ctx, "key"); exists {
+				t.Fatal("GetContext with cancelled context should miss")
+			}
+			if value, exists := c.GetContext(context.Background(), "key"); !exists || string(value) != "value" {
+				t.Fatal("GetContext should return stored value")
+			}
+		})
+	}
+}
+
+// TestDeletePrefix проверяет удаление по префиксу и по шаблону
+func TestDeletePrefix(t *testing.T) {
+	type prefixCache interface {
+		cache.Cache
+		DeletePrefix(prefix string) int
+		DeleteMatch(pattern string) int
+	}
+
+	implementations := map[string]func() prefixCache{
+		"Simple":  func() prefixCache { return NewSimple().(*SimpleCache) },
+		"LRU":     func() prefixCache { return NewLRU(100).(*LRUCache) },
+		"LFU":     func() prefixCache { return NewLFU(100).(*LFUCache) },
+		"Sharded": func() prefixCache { return NewSharded(4, 100).(*ShardedCache) },
+	}
+
+	for name, constructor := range implementations {
+		t.Run(name, func(t *testing.T) {
+			c := constructor()
+			defer c.Close()
+
+			for _, key := range []string{"user:1:profile", "user:1:settings", "user:2:profile", "session:1"} {
+				c.Set(key, []byte("value"))
+			}
+
+			if deleted := c.DeletePrefix("user:1:"); deleted != 2 {
+				t.Fatalf("Expected 2 keys deleted by prefix, got %d", deleted)
+			}
+			if _, exists := c.Get("user:1:profile"); exists {
+				t.Fatal("Key with deleted prefix should be gone")
+			}
+			if keys := c.Keys(); len(keys) != 2 {
+				t.Fatalf("Expected 2 remaining keys, got %v", keys)
+			}
+
+			if deleted := c.DeleteMatch("*:profile"); deleted != 1 {
+				t.Fatalf("Expected 1 key deleted by pattern, got %d", deleted)
+			}
+			if deleted := c.DeleteMatch("user:*"); deleted != 0 {
+				t.Fatalf("Expected no keys to match, got %d", deleted)
+			}
+			if _, exists := c.Get("session:1"); !exists {
+				t.Fatal("Unrelated key should remain")
+			}
+			if stats := c.Stats(); stats.Keys != 1 {
+				t.Fatalf("Expected 1 key in stats, got %d", stats.Keys)
+			}
+		})
+	}
+}
+
+// TestMatchGlob проверяет сопоставление ключей с шаблоном
+func TestMatchGlob(t *testing.T) {
+	tests := []struct {
+		pattern string
+		key     string
+		match   bool
+	}{
+		{"user:1", "user:1", true},
+		{"user:1", "user:10", false},
+		{"user:*", "user:", true},
+		{"user:*:profile", "user:42:profile", true},
+		{"user:*:profile", "user:42:settings", false},
+		{"*", "", true},
+		{"a*b*c", "axxbyyc", true},
+		{"a*a", "a", false},
+		{"*:1:*", "user:1:profile", true},
+	}
+
+	for _, tt := range tests {
+		if got := matchGlob(tt.pattern, tt.key); got != tt.match {
+			t.Errorf("matchGlob(%q, %q) = %v, expected %v", tt.pattern, tt.key, got, tt.match)
+		}
+	}
+}
+
+// TestTags проверяет инвалидацию по тегам и очистку обратного индекса
+func TestTags(t *testing.T) {
+	type taggedCache interface {
+		cache.Cache
+		SetWithTags(key string, value []byte, ttl time.Duration, tags ...string) error
+		InvalidateTag(tag string) int
+	}
+
+	implementations := map[string]func() taggedCache{
+		"Simple":  func() taggedCache { return NewSimple().(*SimpleCache) },
+		"LRU":     func() taggedCache { return NewLRU(100).(*LRUCache) },
+		"LFU":     func() taggedCache { return NewLFU(100).(*LFUCache) },
+		"Sharded": func() taggedCache { return NewSharded(4, 100).(*ShardedCache) },
+	}
+
+	for name, constructor := range implementations {
+		t.Run(name, func(t *testing.T) {
+			c := constructor()
+			defer c.Close()
+
+			c.SetWithTags("page:1", []byte("a"), 0, "pages", "user:1")
+			c.SetWithTags("page:2", []byte("b"), 0, "pages", "pages")
+			c.SetWithTags("avatar:1", []byte("c"), 0, "user:1")
+			c.Set("other", []byte("d"))
+
+			if deleted := c.InvalidateTag("pages"); deleted != 2 {
+				t.Fatalf("Expected 2 keys invalidated, got %d", deleted)
+			}
+			if _, exists := c.Get("page:1"); exists {
+				t.Fatal("Invalidated key should be gone")
+			}
+			if deleted := c.InvalidateTag("user:1"); deleted != 1 {
+				t.Fatalf("Expected only avatar:1 left under user:1, got %d", deleted)
+			}
+			if _, exists := c.Get("other"); !exists {
+				t.Fatal("Untagged key should remain")
+			}
+
+			// Перезапись без тегов снимает связь ключа с тегом
+			c.SetWithTags("page:3", []byte("e"), 0, "pages")
+			c.Set("page:3", []byte("f"))
+			if deleted := c.InvalidateTag("pages"); deleted != 0 {
+				t.Fatalf("Overwritten key should lose its tags, got %d deleted", deleted)
+			}
+
+			// Удаленный ключ не должен всплыть при инвалидации
+			c.SetWithTags("page:4", []byte("g"), 0, "pages")
+			c.Delete("page:4")
+			c.Set("page:4", []byte("h"))
+			if deleted := c.InvalidateTag("pages"); deleted != 0 {
+				t.Fatalf("Deleted key should leave the tag index, got %d deleted", deleted)
+			}
+		})
+	}
+}
+
+// TestTagsEviction проверяет, что вытесненные ключи не остаются в индексе тегов
+func TestTagsEviction(t *testing.T) {
+	lru := NewLRU(2).(*LRUCache)
+	defer lru.Close()
+
+	for i := 0; i < 10; i++ {
+		lru.SetWithTags(fmt.Sprintf("key%d", i), []byte("value"), 0, fmt.Sprintf("tag%d", i), "all")
+	}
+
+	lru.mu.RLock()
+	tags, all := len(lru.tags), len(lru.tags["all"])
+	lru.mu.RUnlock()
+	if tags != 3 || all != 2 {
+		t.Fatalf("Expected index for 2 live keys, got %d tags and %d keys under 'all'", tags, all)
+	}
+
+	lfu := NewLFU(2).(*LFUCache)
+	defer lfu.Close()
+
+	for i := 0; i < 10; i++ {
+		lfu.SetWithTags(fmt.Sprintf("key%d", i), []byte("value"), 0, "all")
+	}
+	if deleted := lfu.InvalidateTag("all"); deleted != 2 {
+		t.Fatalf("Expected 2 live keys invalidated, got %d", deleted)
+	}
+	if len(lfu.tags) != 0 {
+		t.Fatalf("Expected empty tag index, got %d tags", len(lfu.tags))
+	}
+}
+
+// TestGetStale проверяет выдачу устаревших значений в окне после истечения TTL
+func TestGetStale(t *testing.T) {
+	type staleCache interface {
+		cache.Cache
+		SetStaleWindow(window time.Duration)
+		GetStale(key string) ([]byte, bool, bool)
+	}
+
+	implementations := map[string]func() staleCache{
+		"Simple":  func() staleCache { return NewSimple().(*SimpleCache) },
+		"LRU":     func() staleCache { return NewLRU(100).(*LRUCache) },
+		"LFU":     func() staleCache { return NewLFU(100).(*LFUCache) },
+		"Sharded": func() staleCache { return NewSharded(4, 100).(*ShardedCache) },
+	}
+
+	for name, constructor := range implementations {
+		t.Run(name, func(t *testing.T) {
+			c := constructor()
+			defer c.Close()
+			clock := withFakeClock(t, c)
+			c.SetStaleWindow(100 * time.Millisecond)
+
+			c.SetWithTTL("key", []byte("value"), 20*time.Millisecond)
+			if value, fresh, exists := c.GetStale("key"); !exists || !fresh || string(value) != "value" {
+				t.Fatalf("Expected fresh value, got %s fresh=%v exists=%v", value, fresh, exists)
+			}
+
+			clock.Advance(40 * time.Millisecond)
+
+			if _, exists := c.Get("key"); exists {
+				t.Fatal("Get should miss on a stale item")
+			}
+			if value, fresh, exists := c.GetStale("key"); !exists || fresh || string(value) != "value" {
+				t.Fatalf("Expected stale value after Get, got %s fresh=%v exists=%v", value, fresh, exists)
+			}
+
+			clock.Advance(100 * time.Millisecond)
+
+			if _, _, exists := c.GetStale("key"); exists {
+				t.Fatal("Item past the stale window should be gone")
+			}
+			if stats := c.Stats(); stats.Keys != 0 {
+				t.Fatalf("Expected hard-expired item to be removed, got %d keys", stats.Keys)
+			}
+		})
+	}
+}
+
+// TestRefreshAhead проверяет фоновое обновление значения перед истечением TTL без промахов
+func TestRefreshAhead(t *testing.T) {
+	type refreshCache interface {
+		cache.Cache
+		SetRefreshAhead(config RefreshAhead)
+	}
+
+	implementations := map[string]func() refreshCache{
+		"Simple":  func() refreshCache { return NewSimple().(*SimpleCache) },
+		"LRU":     func() refreshCache { return NewLRU(100).(*LRUCache) },
+		"LFU":     func() refreshCache { return NewLFU(100).(*LFUCache) },
+		"Sharded": func() refreshCache { return NewSharded(4, 100).(*ShardedCache) },
+	}
+
+	for name, constructor := range implementations {
+		t.Run(name, func(t *testing.T) {
+			c := constructor()
+			defer c.Close()
+			clock := withFakeClock(t, c)
+
+			var calls int64
+			release := make(chan struct{})
+			c.SetRefreshAhead(RefreshAhead{
+				Loader: func(key string) ([]byte, error) {
+					atomic.AddInt64(&calls, 1)
+					<-release
+					return []byte("fresh"), nil
+				},
+				Threshold: 0.5,
+			})
+
+			c.SetWithTTL("key", []byte("old"), 200*time.Millisecond)
+			if value, _ := c.Get("key"); string(value) != "old" {
+				t.Fatalf("Expected old value before refresh window, got %s", value)
+			}
+
+			clock.Advance(120 * time.Millisecond)
+
+			// Все обращения в окне обновления получают текущее значение и запускают одну загрузку
+			for i := 0; i < 10; i++ {
+				if value, exists := c.Get("key"); !exists || string(value) != "old" {
+					t.Fatalf("Expected old value during refresh, got %s", value)
+				}
+			}
+			close(release)
+
+			deadline := time.Now().Add(time.Second)
+			for {
+				if value, _ := c.Get("key"); string(value) == "fresh" {
+					break
+				}
+				if time.Now().After(deadline) {
+					t.Fatal("Value was not refreshed in background")
+				}
+				time.Sleep(5 * time.Millisecond)
+			}
+
+			if n := atomic.LoadInt64(&calls); n != 1 {
+				t.Fatalf("Expected a single refresh, got %d", n)
+			}
+			if stats := c.Stats(); stats.Misses != 0 {
+				t.Fatalf("Refresh should not cause misses, got %d", stats.Misses)
+			}
+			if ttl, _ := c.GetTTL("key"); ttl <= 100*time.Millisecond {
+				t.Fatalf("Refreshed value should get a full TTL, got %v", ttl)
+			}
+		})
+	}
+}
+
+// TestTTLJitter проверяет детерминированное отклонение TTL и запись без отклонения
+func TestTTLJitter(t *testing.T) {
+	type jitterCache interface {
+		cache.Cache
+		SetTTLJitter(fraction float64, random func() float64)
+		SetWithExactTTL(key string, value []byte, ttl time.Duration) error
+	}
+
+	implementations := map[string]func() jitterCache{
+		"Simple":  func() jitterCache { return NewSimple().(*SimpleCache) },
+		"LRU":     func() jitterCache { return NewLRU(100).(*LRUCache) },
+		"LFU":     func() jitterCache { return NewLFU(100).(*LFUCache) },
+		"Sharded": func() jitterCache { return NewSharded(4, 100).(*ShardedCache) },
+	}
+
+	for name, constructor := range implementations {
+		t.Run(name, func(t *testing.T) {
+			c := constructor()
+			defer c.Close()
+
+			var next float64
+			c.SetTTLJitter(0.1, func() float64 { return next })
+
+			next = 0
+			c.SetWithTTL("short", []byte("value"), time.Hour)
+			next = 0.999
+			c.SetWithTTL("long", []byte("value"), time.Hour)
+			c.SetWithExactTTL("exact", []byte("value"), time.Hour)
+
+			if ttl, _ := c.GetTTL("short"); ttl > 54*time.Minute {
+				t.Fatalf("Expected TTL reduced by 10%%, got %v", ttl)
+			}
+			if ttl, _ := c.GetTTL("long"); ttl < 65*time.Minute {
+				t.Fatalf("Expected TTL increased by 10%%, got %v", ttl)
+			}
+			if ttl, _ := c.GetTTL("exact"); ttl > time.Hour || ttl < 59*time.Minute {
+				t.Fatalf("Expected exact TTL, got %v", ttl)
+			}
+
+			c.Set("persistent", []byte("value"))
+			if ttl, _ := c.GetTTL("persistent"); ttl != cache.NoExpiration {
+				t.Fatalf("Jitter should not apply to keys without TTL, got %v", ttl)
+			}
+		})
+	}
+}
+
+// TestTTLBounds проверяет ограничение явно запрошенного TTL снизу и сверху
+// и то, что TTL 0 по-прежнему означает TTL по умолчанию
+func TestTTLBounds(t *testing.T) {
+	type boundedCache interface {
+		cache.Cache
+		SetTTLBounds(minTTL, maxTTL time.Duration)
+		SetWithExactTTL(key string, value []byte, ttl time.Duration) error
+	}
+
+	// TTL по умолчанию специально ниже нижней границы
+	const defaultTTL = 10 * time.Second
+	bounded := func(c boundedCache) boundedCache {
+		c.SetTTLBounds(time.Minute, time.Hour)
+		return c
+	}
+	implementations := map[string]func() boundedCache{
+		"Simple":  func() boundedCache { return bounded(NewSimpleWithTTL(defaultTTL).(*SimpleCache)) },
+		"LRU":     func() boundedCache { return bounded(NewLRUWithTTL(100, defaultTTL).(*LRUCache)) },
+		"LFU":     func() boundedCache { return bounded(NewLFUWithTTL(100, defaultTTL).(*LFUCache)) },
+		"Sharded": func() boundedCache { return bounded(NewShardedWithTTL(4, 100, defaultTTL).(*ShardedCache)) },
+		// Границы задаются только опцией, без вызова SetTTLBounds
+		"ShardedOptions": func() boundedCache {
+			return NewShardedWithOptions(4, WithMaxSize(100), WithDefaultTTL(defaultTTL), WithTTLBounds(time.Minute, time.Hour)).(*ShardedCache)
+		},
+		"LRUOptions": func() boundedCache {
+			return NewLRUWithOptions(WithMaxSize(100), WithDefaultTTL(defaultTTL), WithTTLBounds(time.Minute, time.Hour)).(*LRUCache)
+		},
+	}
+
+	for name, constructor := range implementations {
+		t.Run(name, func(t *testing.T) {
+			c := constructor()
+			defer c.Close()
+
+			c.SetWithTTL("below", []byte("v"), time.Millisecond)
+			c.SetWithTTL("within", []byte("v"), 30*time.Minute)
+			c.SetWithTTL("above", []byte("v"), 24*time.Hour)
+			c.SetWithExactTTL("exact", []byte("v"), time.Millisecond)
+			c.SetWithTTL("default", []byte("v"), 0)
+
+			tests := []struct {
+				key      string
+				min, max time.Duration
+			}{
+				{"below", 59 * time.Second, time.Minute},
+				{"within", 29 * time.Minute, 30 * time.Minute},
+				{"above", 59 * time.Minute, time.Hour},
+				{"exact", 59 * time.Second, time.Minute},
+				{"default", 9 * time.Second, defaultTTL},
+			}
+			for _, tt := range tests {
+				if ttl, ok := c.GetTTL(tt.key); !ok || ttl < tt.min || ttl > tt.max {
+					t.Errorf("Key %s: expected TTL in [%v, %v], got %v", tt.key, tt.min, tt.max, ttl)
+				}
+			}
+
+			c.SetTTLBounds(0, 0)
+			c.SetWithTTL("unbounded", []byte("v"), 24*time.Hour)
+			if ttl, _ := c.GetTTL("unbounded"); ttl < 23*time.Hour {
+				t.Errorf("Expected bounds to be removed, got %v", ttl)
+			}
+		})
+	}
+}
+
+// TestTouch проверяет продление TTL и обновление порядка без учета в статистике
+func TestTouch(t *testing.T) {
+	type touchCache interface {
+		cache.Cache
+		Touch(key string, extend time.Duration) bool
+	}
+
+	implementations := map[string]func() touchCache{
+		"Simple":  func() touchCache { return NewSimple().(*SimpleCache) },
+		"LRU":     func() touchCache { return NewLRU(100).(*LRUCache) },
+		"LFU":     func() touchCache { return NewLFU(100).(*LFUCache) },
+		"Sharded": func() touchCache { return NewSharded(4, 100).(*ShardedCache) },
+	}
+
+	for name, constructor := range implementations {
+		t.Run(name, func(t *testing.T) {
+			c := constructor()
+			defer c.Close()
+			clock := withFakeClock(t, c)
+
+			c.SetWithTTL("key", []byte("value"), time.Minute)
+			c.Set("persistent", []byte("value"))
+			c.SetWithTTL("expired", []byte("value"), time.Millisecond)
+			clock.Advance(5 * time.Millisecond)
+
+			if !c.Touch("key", time.Hour) {
+				t.Fatal("Touch should succeed for existing key")
+			}
+			if ttl, _ := c.GetTTL("key"); ttl < 59*time.Minute {
+				t.Fatalf("Expected TTL extended to an hour, got %v", ttl)
+			}
+			if !c.Touch("key", time.Second) {
+				t.Fatal("Touch should succeed for existing key")
+			}
+			if ttl, _ := c.GetTTL("key"); ttl < 59*time.Minute {
+				t.Fatalf("Touch should not shorten TTL, got %v", ttl)
+			}
+
+			if !c.Touch("persistent", time.Hour) {
+				t.Fatal("Touch should succeed for persistent key")
+			}
+			if ttl, _ := c.GetTTL("persistent"); ttl != cache.NoExpiration {
+				t.Fatalf("Touch should keep persistent key without TTL, got %v", ttl)
+			}
+
+			if c.Touch("missing", 0) || c.Touch("expired", time.Hour) {
+				t.Fatal("Touch should fail for missing and expired keys")
+			}
+
+			if stats := c.Stats(); stats.Hits != 0 || stats.Misses != 0 {
+				t.Fatalf("Touch should not affect hit statistics, got %d hits and %d misses", stats.Hits, stats.Misses)
+			}
+		})
+	}
+
+	lru := NewLRU(2).(*LRUCache)
+	defer lru.Close()
+
+	lru.Set("a", []byte("1"))
+	lru.Set("b", []byte("2"))
+	lru.Touch("a", 0)
+	lru.Set("c", []byte("3"))
+
+	if _, exists := lru.Get("a"); !exists {
+		t.Fatal("Touched key should not be evicted")
+	}
+	if _, exists := lru.Get("b"); exists {
+		t.Fatal("Least recently used key should be evicted")
+	}
+}
+
+// TestMaxValueBytes проверяет отказ в записи значений длиннее лимита
+func TestMaxValueBytes(t *testing.T) {
+	type limitedCache interface {
+		cache.Cache
+		SetMaxValueBytes(limit int)
+	}
+
+	implementations := map[string]func() limitedCache{
+		"Simple":  func() limitedCache { return NewSimple().(*SimpleCache) },
+		"LRU":     func() limitedCache { return NewLRU(100).(*LRUCache) },
+		"LFU":     func() limitedCache { return NewLFU(100).(*LFUCache) },
+		"Sharded": func() limitedCache { return NewSharded(4, 100).(*ShardedCache) },
+	}
+
+	for name, constructor := range implementations {
+		t.Run(name, func(t *testing.T) {
+			c := constructor()
+			defer c.Close()
+			c.SetMaxValueBytes(8)
+
+			if err := c.Set("boundary", make([]byte, 8)); err != nil {
+				t.Fatalf("Value at the limit should be accepted, got %v", err)
+			}
+			if err := c.SetWithTTL("over", make([]byte, 9), time.Minute); !errors.Is(err, cache.ErrValueTooLarge) {
+				t.Fatalf("Expected ErrValueTooLarge for value over the limit, got %v", err)
+			}
+			if _, exists := c.Get("over"); exists {
+				t.Fatal("Rejected value should not be stored")
+			}
+
+			c.SetMaxValueBytes(0)
+			if err := c.Set("over", make([]byte, 9)); err != nil {
+				t.Fatalf("Zero limit should accept any value, got %v", err)
+			}
+		})
+	}
+}
+
+// TestNegativeCaching проверяет отрицательные записи и GetWithState
+func TestNegativeCaching(t *testing.T) {
+	type negativeCache interface {
+		cache.Cache
+		SetNegative(key string, ttl time.Duration) error
+		GetWithState(key string) ([]byte, cache.EntryState)
+	}
+
+	implementations := map[string]func() negativeCache{
+		"Simple":  func() negativeCache { return NewSimple().(*SimpleCache) },
+		"LRU":     func() negativeCache { return NewLRU(100).(*LRUCache) },
+		"LFU":     func() negativeCache { return NewLFU(100).(*LFUCache) },
+		"Sharded": func() negativeCache { return NewSharded(4, 100).(*ShardedCache) },
+	}
+
+	for name, constructor := range implementations {
+		t.Run(name, func(t *testing.T) {
+			c := constructor()
+			defer c.Close()
+			clock := withFakeClock(t, c)
+
+			c.Set("present", []byte("value"))
+			c.SetNegative("absent", 30*time.Millisecond)
+
+			if value, state := c.GetWithState("present"); state != cache.StateHit || string(value) != "value" {
+				t.Fatalf("Expected hit, got %v with %s", state, value)
+			}
+			if value, state := c.GetWithState("absent"); state != cache.StateNegative || value != nil {
+				t.Fatalf("Expected negative entry, got %v with %s", state, value)
+			}
+			if _, state := c.GetWithState("unknown"); state != cache.StateMiss {
+				t.Fatalf("Expected miss, got %v", state)
+			}
+			if _, exists := c.Get("absent"); exists {
+				t.Fatal("Get should treat negative entry as a miss")
+			}
+			for _, key := range c.Keys() {
+				if key == "absent" {
+					t.Fatal("Keys should not list negative entries")
+				}
+			}
+
+			clock.Advance(50 * time.Millisecond)
+			if _, state := c.GetWithState("absent"); state != cache.StateMiss {
+				t.Fatalf("Expected negative entry to expire, got %v", state)
+			}
+
+			// Запись значения заменяет отрицательную запись
+			c.SetNegative("later", time.Minute)
+			c.Set("later", []byte("found"))
+			if value, state := c.GetWithState("later"); state != cache.StateHit || string(value) != "found" {
+				t.Fatalf("Expected value to replace negative entry, got %v with %s", state, value)
+			}
+		})
+	}
+}
+
+// TestPeek проверяет чтение без влияния на статистику, порядок вытеснения и удаление истекших
+func TestPeek(t *testing.T) {
+	type peekCache interface {
+		cache.Cache
+		Peek(key string) ([]byte, bool)
+	}
+
+	implementations := map[string]func() peekCache{
+		"Simple":  func() peekCache { return NewSimple().(*SimpleCache) },
+		"LRU":     func() peekCache { return NewLRU(100).(*LRUCache) },
+		"LFU":     func() peekCache { return NewLFU(100).(*LFUCache) },
+		"Sharded": func() peekCache { return NewSharded(4, 100).(*ShardedCache) },
+	}
+
+	for name, constructor := range implementations {
+		t.Run(name, func(t *testing.T) {
+			c := constructor()
+			defer c.Close()
+			clock := withFakeClock(t, c)
+
+			c.Set("key", []byte("value"))
+			c.SetWithTTL("expired", []byte("value"), time.Millisecond)
+			clock.Advance(5 * time.Millisecond)
+
+			if value, exists := c.Peek("key"); !exists || string(value) != "value" {
+				t.Fatalf("Expected value from Peek, got %s", value)
+			}
+			if _, exists := c.Peek("expired"); exists {
+				t.Fatal("Peek should not return expired key")
+			}
+			if _, exists := c.Peek("missing"); exists {
+				t.Fatal("Peek should not return missing key")
+			}
+
+			stats := c.Stats()
+			if stats.Hits != 0 || stats.Misses != 0 {
+				t.Fatalf("Peek should not affect statistics, got %d hits and %d misses", stats.Hits, stats.Misses)
+			}
+			if stats.Keys != 2 {
+				t.Fatalf("Peek should not remove expired key, got %d keys", stats.Keys)
+			}
+		})
+	}
+
+	lru := NewLRU(2).(*LRUCache)
+	defer lru.Close()
+
+	lru.Set("a", []byte("1"))
+	lru.Set("b", []byte("2"))
+	lru.Peek("a")
+	lru.Set("c", []byte("3"))
+
+	if _, exists := lru.Peek("a"); exists {
+		t.Fatal("Peek should not protect key from LRU eviction")
+	}
+
+	lfu := NewLFU(2).(*LFUCache)
+	defer lfu.Close()
+
+	lfu.Set("a", []byte("1"))
+	lfu.Set("b", []byte("2"))
+	lfu.Get("b")
+	for i := 0; i < 5; i++ {
+		lfu.Peek("a")
+	}
+	lfu.Set("c", []byte("3"))
+
+	if _, exists := lfu.Peek("a"); exists {
+		t.Fatal("Peek should not increase LFU frequency")
+	}
+}
+
+// TestTiered проверяет вытеснение на диск, перенос обратно в память и очистку файлов
+func TestTiered(t *testing.T) {
+	dir := t.TempDir()
+	c := NewTiered(2, dir).(*TieredCache)
+
+	files := func() int {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			t.Fatalf("ReadDir failed: %v", err)
+		}
+		return len(entries)
+	}
+
+	c.SetWithTTL("a", []byte("1"), time.Hour)
+	c.Set("b", []byte("2"))
+	c.Set("c", []byte("3"))
+
+	if n := files(); n != 1 {
+		t.Fatalf("Expected evicted key on disk, got %d files", n)
+	}
+	if ttl, exists := c.GetTTL("a"); !exists || ttl < 59*time.Minute {
+		t.Fatalf("Expected TTL preserved on disk, got %v", ttl)
+	}
+
+	// Попадание на диске переносит a в память и вытесняет b на диск
+	if value, exists := c.Get("a"); !exists || string(value) != "1" {
+		t.Fatalf("Expected disk hit for a, got %s", value)
+	}
+	if value, exists := c.Get("c"); !exists || string(value) != "3" {
+		t.Fatalf("Expected memory hit for c, got %s", value)
+	}
+	if _, exists := c.Get("missing"); exists {
+		t.Fatal("Missing key should miss")
+	}
+	if ttl, _ := c.GetTTL("a"); ttl < 59*time.Minute {
+		t.Fatalf("Expected TTL preserved after promotion, got %v", ttl)
+	}
+
+	stats := c.TieredStats()
+	if stats.MemoryHits != 1 || stats.DiskHits != 1 || stats.Misses != 1 {
+		t.Fatalf("Expected 1 memory hit, 1 disk hit and 1 miss, got %+v", stats)
+	}
+	if stats.Keys != 3 || stats.DiskKeys != 1 || c.Len() != 3 {
+		t.Fatalf("Expected 3 keys with 1 on disk, got %+v", stats)
+	}
+
+	// Запись ключа, лежащего на диске, заменяет дисковую копию
+	c.Set("b", []byte("new"))
+	if value, _ := c.Get("b"); string(value) != "new" {
+		t.Fatalf("Expected overwritten value, got %s", value)
+	}
+
+	keys := c.Keys()
+	sort.Strings(keys)
+	if fmt.Sprint(keys) != "[a b c]" {
+		t.Fatalf("Expected keys from both tiers, got %v", keys)
+	}
+
+	if !c.Delete("a") || !c.Delete("c") {
+		t.Fatal("Delete should remove key from either tier")
+	}
+
+	c.Clear()
+	if n := files(); n != 0 {
+		t.Fatalf("Clear should remove disk files, got %d", n)
+	}
+
+	c.Set("x", []byte("1"))
+	c.Set("y", []byte("2"))
+	c.Set("z", []byte("3"))
+	c.Close()
+	if n := files(); n != 0 {
+		t.Fatalf("Close should remove disk files, got %d", n)
+	}
+	if err := c.Set("key", []byte("value")); err != cache.ErrCacheClosed {
+		t.Fatalf("Expected ErrCacheClosed after Close, got %v", err)
+	}
+}
+
+// TestSetNX проверяет запись только отсутствующих ключей и атомарность при конкуренции
+func TestSetNX(t *testing.T) {
+	type nxCache interface {
+		cache.Cache
+		SetNX(key string, value []byte, ttl time.Duration) (bool, error)
+	}
+
+	implementations := map[string]func() nxCache{
+		"Simple":  func() nxCache { return NewSimple().(*SimpleCache) },
+		"LRU":     func() nxCache { return NewLRU(100).(*LRUCache) },
+		"LFU":     func() nxCache { return NewLFU(100).(*LFUCache) },
+		"Sharded": func() nxCache { return NewSharded(4, 100).(*ShardedCache) },
+	}
+
+	for name, constructor := range implementations {
+		t.Run(name, func(t *testing.T) {
+			c := constructor()
+			defer c.Close()
+			clock := withFakeClock(t, c)
+
+			if ok, err := c.SetNX("lock", []byte("owner-1"), time.Minute); !ok || err != nil {
+				t.Fatalf("First SetNX should succeed, got %v (%v)", ok, err)
+			}
+			if ok, _ := c.SetNX("lock", []byte("owner-2"), time.Minute); ok {
+				t.Fatal("SetNX should fail for existing key")
+			}
+			if value, _ := c.Get("lock"); string(value) != "owner-1" {
+				t.Fatalf("SetNX should not overwrite, got %s", value)
+			}
+
+			c.SetWithTTL("expired", []byte("old"), time.Millisecond)
+			clock.Advance(5 * time.Millisecond)
+			if ok, _ := c.SetNX("expired", []byte("new"), 0); !ok {
+				t.Fatal("SetNX should succeed for expired key")
+			}
+
+			c.Delete("lock")
+			var wg sync.WaitGroup
+			var winners int64
+			for i := 0; i < 50; i++ {
+				wg.Add(1)
+				go func(i int) {
+					defer wg.Done()
+					if ok, _ := c.SetNX("lock", []byte(fmt.Sprint(i)), time.Minute); ok {
+						atomic.AddInt64(&winners, 1)
+					}
+				}(i)
+			}
+			wg.Wait()
+
+			if winners != 1 {
+				t.Fatalf("Expected exactly one concurrent SetNX to win, got %d", winners)
+			}
+		})
+	}
+}
+
+// TestLoadOrStore проверяет возврат существующего значения, запись отсутствующего
+// и истекшего ключа и единое победившее значение при конкуренции
+func TestLoadOrStore(t *testing.T) {
+	type loadOrStoreCache interface {
+		cache.Cache
+		LoadOrStore(key string, value []byte, ttl time.Duration) ([]byte, bool, error)
+	}
+
+	implementations := map[string]func() loadOrStoreCache{
+		"Simple":  func() loadOrStoreCache { return NewSimple().(*SimpleCache) },
+		"LRU":     func() loadOrStoreCache { return NewLRU(100).(*LRUCache) },
+		"LFU":     func() loadOrStoreCache { return NewLFU(100).(*LFUCache) },
+		"Sharded": func() loadOrStoreCache { return NewSharded(4, 100).(*ShardedCache) },
+	}
+
+	for name, constructor := range implementations {
+		t.Run(name, func(t *testing.T) {
+			c := constructor()
+			defer c.Close()
+			clock := withFakeClock(t, c)
+
+			actual, loaded, err := c.LoadOrStore("key", []byte("first"), time.Minute)
+			if err != nil || loaded || string(actual) != "first" {
+				t.Fatalf("Expected store of first, got %q loaded=%v (%v)", actual, loaded, err)
+			}
+			actual, loaded, _ = c.LoadOrStore("key", []byte("second"), time.Minute)
+			if !loaded || string(actual) != "first" {
+				t.Fatalf("Expected existing value first, got %q loaded=%v", actual, loaded)
+			}
+			if value, _ := c.Get("key"); string(value) != "first" {
+				t.Fatalf("LoadOrStore should not overwrite, got %s", value)
+			}
+
+			c.SetWithTTL("expired", []byte("old"), time.Millisecond)
+			clock.Advance(5 * time.Millisecond)
+			actual, loaded, _ = c.LoadOrStore("expired", []byte("new"), 0)
+			if loaded || string(actual) != "new" {
+				t.Fatalf("Expected expired key to be replaced, got %q loaded=%v", actual, loaded)
+			}
+			if value, _ := c.Get("expired"); string(value) != "new" {
+				t.Fatalf("Expected new value stored, got %s", value)
+			}
+
+			if _, _, err := c.LoadOrStore("", []byte("v"), 0); !errors.Is(err, cache.ErrKeyEmpty) {
+				t.Errorf("Expected ErrKeyEmpty, got %v", err)
+			}
+
+			var wg sync.WaitGroup
+			var stores int64
+			results := make([]string, 50)
+			for i := range results {
+				wg.Add(1)
+				go func(i int) {
+					defer wg.Done()
+					actual, loaded, _ := c.LoadOrStore("race", []byte(fmt.Sprint(i)), time.Minute)
+					if !loaded {
+						atomic.AddInt64(&stores, 1)
+					}
+					results[i] = string(actual)
+				}(i)
+			}
+			wg.Wait()
+
+			if stores != 1 {
+				t.Fatalf("Expected exactly one concurrent LoadOrStore to store, got %d", stores)
+			}
+			for i, result := range results {
+				if result != results[0] {
+					t.Fatalf("Expected all callers to see the winning value %s, caller %d got %s", results[0], i, result)
+				}
+			}
+		})
+	}
+}
+
+// TestSetIf проверяет условную запись по версии, вложенной в значение
+func TestSetIf(t *testing.T) {
+	type setIfCache interface {
+		cache.Cache
+		SetIf(key string, value []byte, ttl time.Duration, cond func(old []byte) bool) (bool, error)
+	}
+
+	// Значение - "версия:данные", запись проходит только для более новой версии
+	versioned := func(version int, data string) []byte {
+		return []byte(fmt.Sprintf("%d:%s", version, data))
+	}
+	version := func(value []byte) int {
+		v, _, _ := strings.Cut(string(value), ":")
+		n, _ := strconv.Atoi(v)
+		return n
+	}
+	newer := func(value []byte) func(old []byte) bool {
+		return func(old []byte) bool { return old == nil || version(value) > version(old) }
+	}
+
+	implementations := map[string]func() setIfCache{
+		"Simple":  func() setIfCache { return NewSimple().(*SimpleCache) },
+		"LRU":     func() setIfCache { return NewLRU(100).(*LRUCache) },
+		"LFU":     func() setIfCache { return NewLFU(100).(*LFUCache) },
+		"Sharded": func() setIfCache { return NewSharded(4, 100).(*ShardedCache) },
+	}
+
+	for name, constructor := range implementations {
+		t.Run(name, func(t *testing.T) {
+			c := constructor()
+			defer c.Close()
+			clock := withFakeClock(t, c)
+
+			var seen []byte
+			ok, err := c.SetIf("doc", versioned(2, "b"), 0, func(old []byte) bool {
+				seen = old
+				return true
+			})
+			if !ok || err != nil || seen != nil {
+				t.Fatalf("Expected write of absent key with nil old value, got %v (%v), old %q", ok, err, seen)
+			}
+
+			if ok, _ := c.SetIf("doc", versioned(1, "a"), 0, newer(versioned(1, "a"))); ok {
+				t.Fatal("Expected older version to be rejected")
+			}
+			if value, _ := c.Get("doc"); version(value) != 2 {
+				t.Fatalf("Expected version 2 to remain, got %s", value)
+			}
+			if ok, _ := c.SetIf("doc", versioned(3, "c"), 0, newer(versioned(3, "c"))); !ok {
+				t.Fatal("Expected newer version to be stored")
+			}
+			if value, _ := c.Get("doc"); string(value) != "3:c" {
+				t.Fatalf("Expected 3:c, got %s", value)
+			}
+
+			c.SetWithTTL("expired", versioned(9, "old"), time.Millisecond)
+			clock.Advance(5 * time.Millisecond)
+			if ok, _ := c.SetIf("expired", versioned(1, "new"), 0, newer(versioned(1, "new"))); !ok {
+				t.Fatal("Expected expired key to be treated as absent")
+			}
+
+			if _, err := c.SetIf("", []byte("v"), 0, func([]byte) bool { return true }); !errors.Is(err, cache.ErrKeyEmpty) {
+				t.Errorf("Expected ErrKeyEmpty, got %v", err)
+			}
+
+			// Конкурентные записи разных версий оставляют самую новую
+			var wg sync.WaitGroup
+			for i := 1; i <= 50; i++ {
+				wg.Add(1)
+				go func(i int) {
+					defer wg.Done()
+					value := versioned(i, "race")
+					c.SetIf("race", value, 0, newer(value))
+				}(i)
+			}
+			wg.Wait()
+
+			if value, _ := c.Get("race"); version(value) != 50 {
+				t.Fatalf("Expected newest version 50 to win, got %s", value)
+			}
+		})
+	}
+}
+
+// TestGetDetailed проверяет различение истекших и отсутствующих ключей при промахе
+func TestGetDetailed(t *testing.T) {
+	type detailedCache interface {
+		cache.Cache
+		GetDetailed(key string) ([]byte, cache.Outcome)
+	}
+
+	implementations := map[string]func() detailedCache{
+		"Simple":  func() detailedCache { return NewSimple().(*SimpleCache) },
+		"LRU":     func() detailedCache { return NewLRU(10).(*LRUCache) },
+		"LFU":     func() detailedCache { return NewLFU(10).(*LFUCache) },
+		"Sharded": func() detailedCache { return NewSharded(4, 10).(*ShardedCache) },
+	}
+
+	for name, constructor := range implementations {
+		t.Run(name, func(t *testing.T) {
+			c := constructor()
+			defer c.Close()
+			clock := withFakeClock(t, c)
+
+			c.Set("live", []byte("v"))
+			c.SetWithTTL("expired", []byte("v"), time.Millisecond)
+			clock.Advance(5 * time.Millisecond)
+
+			if value, outcome := c.GetDetailed("live"); outcome != cache.OutcomeHit || string(value) != "v" {
+				t.Errorf("Expected hit for live key, got %v %q", outcome, value)
+			}
+			if _, outcome := c.GetDetailed("expired"); outcome != cache.OutcomeExpiredMiss {
+				t.Errorf("Expected expired_miss, got %v", outcome)
+			}
+			// Истекший элемент удален первым обращением, дальше ключ отсутствует
+			if _, outcome := c.GetDetailed("expired"); outcome != cache.OutcomeAbsentMiss {
+				t.Errorf("Expected absent_miss after removal, got %v", outcome)
+			}
+			if _, outcome := c.GetDetailed("missing"); outcome != cache.OutcomeAbsentMiss {
+				t.Errorf("Expected absent_miss, got %v", outcome)
+			}
+
+			// Обычный Get тоже учитывает истекшие промахи
+			c.SetWithTTL("expired2", []byte("v"), time.Millisecond)
+			clock.Advance(5 * time.Millisecond)
+			c.Get("expired2")
+
+			stats := c.Stats()
+			if stats.Hits != 1 || stats.Misses != 4 || stats.ExpiredMisses != 2 {
+				t.Errorf("Expected 1 hit, 4 misses, 2 expired misses, got %+v", stats)
+			}
+
+			c.Clear()
+			if stats := c.Stats(); stats.ExpiredMisses != 0 {
+				t.Errorf("Expected expired misses reset by Clear, got %d", stats.ExpiredMisses)
+			}
+		})
+	}
+}
+
+// TestInlineValues проверяет значения на границе встроенного буфера элемента
+func TestInlineValues(t *testing.T) {
+	type inlineCache interface {
+		cache.Cache
+		GetRef(key string) ([]byte, bool)
+		SetCompressionThreshold(threshold int)
+	}
+
+	implementations := map[string]func() inlineCache{
+		"Simple": func() inlineCache { return NewSimple().(*SimpleCache) },
+		"LRU":    func() inlineCache { return NewLRU(100).(*LRUCache) },
+	}
+
+	for name, constructor := range implementations {
+		t.Run(name, func(t *testing.T) {
+			c := constructor()
+			defer c.Close()
+
+			for _, size := range []int{0, 1, inlineValueSize, inlineValueSize + 1} {
+				value := bytes.Repeat([]byte("x"), size)
+				key := fmt.Sprint("key", size)
+				c.Set(key, value)
+				// Кэш хранит копию, изменение исходного среза ее не затрагивает
+				if size > 0 {
+					value[0] = 'z'
+				}
+				if got, ok := c.Get(key); !ok || !bytes.Equal(got, bytes.Repeat([]byte("x"), size)) {
+					t.Errorf("Expected %d bytes, got %q, %v", size, got, ok)
+				}
+			}
+
+			// append к полученному срезу не должен затирать соседние данные элемента
+			ref, _ := c.GetRef(fmt.Sprint("key", 1))
+			_ = append(ref, 'y')
+			if got, _ := c.Get(fmt.Sprint("key", 1)); string(got) != "x" {
+				t.Errorf("Append to GetRef result changed the value: %q", got)
+			}
+
+			// Короткое сжимаемое значение хранится сжатым, а не во встроенном буфере
+			c.SetCompressionThreshold(4)
+			zeros := make([]byte, inlineValueSize)
+			c.Set("zeros", zeros)
+			if got, ok := c.Get("zeros"); !ok || !bytes.Equal(got, zeros) {
+				t.Errorf("Expected compressed zeros to round-trip, got %q, %v", got, ok)
+			}
+		})
+	}
+}
+
+// TestHealth проверяет состояние кэша для проверок готовности до и после Close
+func TestHealth(t *testing.T) {
+	type healthCache interface {
+		cache.Cache
+		Health() cache.HealthStatus
+		SetCleanupInterval(interval time.Duration)
+	}
+
+	implementations := map[string]func() healthCache{
+		"Simple":  func() healthCache { return NewSimpleBounded(10).(*SimpleCache) },
+		"LRU":     func() healthCache { return NewLRU(10).(*LRUCache) },
+		"LFU":     func() healthCache { return NewLFU(10).(*LFUCache) },
+		"Sharded": func() healthCache { return NewSharded(2, 10).(*ShardedCache) },
+	}
+
+	for name, constructor := range implementations {
+		t.Run(name, func(t *testing.T) {
+			c := constructor()
+			defer c.Close()
+
+			for i := 0; i < 4; i++ {
+				c.Set(fmt.Sprint("key", i), []byte("v"))
+			}
+
+			health := c.Health()
+			if !health.Healthy || health.Closed || !health.ReaperAlive {
+				t.Errorf("Expected healthy open cache, got %+v", health)
+			}
+			if !health.LastCleanup.IsZero() {
+				t.Errorf("Expected no cleanup yet, got %v", health.LastCleanup)
+			}
+			if health.Saturation <= 0 || health.Saturation > 1 {
+				t.Errorf("Expected saturation in (0, 1], got %v", health.Saturation)
+			}
+
+			c.SetCleanupInterval(5 * time.Millisecond)
+			time.Sleep(30 * time.Millisecond)
+			if health := c.Health(); !health.Healthy || health.LastCleanup.IsZero() {
+				t.Errorf("Expected cleanup to be tracked, got %+v", health)
+			}
+
+			c.Close()
+			health = c.Health()
+			if health.Healthy || !health.Closed || health.ReaperAlive {
+				t.Errorf("Expected unhealthy closed cache, got %+v", health)
+			}
+		})
+	}
+
+	// Очистка, не проходившая дольше двух периодов, считается зависшей
+	stalled := reaperState{interval: time.Millisecond, started: time.Now().Add(-time.Second)}
+	if stalled.alive(false) {
+		t.Error("Expected stalled reaper to be reported as not alive")
+	}
+	stalled.done()
+	if !stalled.alive(false) {
+		t.Error("Expected reaper to be alive right after a cleanup pass")
+	}
+}
+
+// fakeClock - управляемый источник времени для проверки истечения без ожидания
+type fakeClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (f *fakeClock) Now() time.Time {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.now
+}
+
+// Advance сдвигает время вперед на d
+func (f *fakeClock) Advance(d time.Duration) {
+	f.mu.Lock()
+	f.now = f.now.Add(d)
+	f.mu.Unlock()
+}
+
+// newFakeClock создает управляемые часы с фиксированным начальным временем
+func newFakeClock() *fakeClock {
+	return &fakeClock{now: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)}
+}
+
+// withFakeClock подменяет время созданного кэша управляемыми часами и возвращает их
+func withFakeClock(t *testing.T, c any) *fakeClock {
+	t.Helper()
+	clocked, ok := c.(interface{ setClock(clock Clock) })
+	if !ok {
+		t.Fatalf("Cache %T does not support clock replacement", c)
+	}
+	clock := newFakeClock()
+	clocked.setClock(clock)
+	return clock
+}
+
+// TestClock проверяет истечение элементов по подмененному времени без ожидания
+func TestClock(t *testing.T) {
+	implementations := map[string]func(opts ...Option) cache.Cache{
+		"Simple":  NewSimpleWithOptions,
+		"LRU":     NewLRUWithOptions,
+		"LFU":     NewLFUWithOptions,
+		"Sharded": func(opts ...Option) cache.Cache { return NewShardedWithOptions(4, opts...) },
+		// New принимает из опций только часы, лимит задается конфигурацией
+		"NewFIFO": func(opts ...Option) cache.Cache {
+			c, err := New(cache.Config{EvictionPolicy: cache.FIFO, MaxSize: 10}, opts...)
+			if err != nil {
+				t.Fatalf("New failed: %v", err)
+			}
+			return c
+		},
+	}
+
+	for name, constructor := range implementations {
+		t.Run(name, func(t *testing.T) {
+			clock := newFakeClock()
+			c := constructor(WithMaxSize(10), WithClock(clock))
+			defer c.Close()
+
+			c.SetWithTTL("short", []byte("v"), time.Minute)
+			c.SetWithTTL("long", []byte("v"), time.Hour)
+			c.Set("forever", []byte("v"))
+
+			clock.Advance(30 * time.Second)
+			if ttl, ok := c.GetTTL("short"); !ok || ttl != 30*time.Second {
+				t.Errorf("Expected exactly 30s left, got %v, %v", ttl, ok)
+			}
+
+			clock.Advance(31 * time.Second)
+			if _, ok := c.Get("short"); ok {
+				t.Error("Expected short key to expire after advancing the clock")
+			}
+			if _, ok := c.Get("long"); !ok {
+				t.Error("Expected long key to be alive")
+			}
+
+			clock.Advance(24 * time.Hour)
+			keys := c.Keys()
+			if len(keys) != 1 || keys[0] != "forever" {
+				t.Errorf("Expected only the key without TTL, got %v", keys)
+			}
+
+			// Новая запись отсчитывает TTL от подмененного времени
+			c.SetWithTTL("fresh", []byte("v"), time.Minute)
+			if ttl, ok := c.GetTTL("fresh"); !ok || ttl != time.Minute {
+				t.Errorf("Expected full minute for fresh key, got %v, %v", ttl, ok)
+			}
+		})
+	}
+}
+
+// TestNewFromConfig проверяет выбор реализации и применение настроек из cache.Config
+func TestNewFromConfig(t *testing.T) {
+	// После записи a, b, чтения a и записи c каждая политика вытесняет свой ключ
+	tests := []struct {
+		policy  cache.EvictionPolicy
+		evicted string
+	}{
+		{cache.LRU, "b"},
+		{cache.LFU, "b"},
+		{cache.FIFO, "a"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.policy.String(), func(t *testing.T) {
+			c, err := New(cache.Config{
+				EvictionPolicy:  tt.policy,
+				MaxSize:         2,
+				DefaultTTL:      time.Hour,
+				CleanupInterval: 10 * time.Second,
+				MinTTL:          time.Minute,
+			})
+			if err != nil {
+				t.Fatalf("New failed: %v", err)
+			}
+			defer c.Close()
+
+			c.Set("a", []byte("1"))
+			c.Set("b", []byte("2"))
+			c.Get("a")
+			c.Set("c", []byte("3"))
+
+			for _, key := range []string{"a", "b", "c"} {
+				if _, ok := c.Get(key); ok == (key == tt.evicted) {
+					t.Errorf("Key %s: present=%v, expected %s to be evicted", key, ok, tt.evicted)
+				}
+			}
+
+			// MinTTL поднимает явно запрошенный TTL
+			c.SetWithTTL("short", []byte("v"), time.Millisecond)
+			if ttl, ok := c.GetTTL("short"); !ok || ttl < 59*time.Second {
+				t.Errorf("Expected TTL raised to MinTTL, got %v, %v", ttl, ok)
+			}
+		})
+	}
+
+	lru, err := New(cache.Config{EvictionPolicy: cache.LRU, CleanupInterval: 10 * time.Second})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	defer lru.Close()
+	if interval := lru.(*LRUCache).reaper.interval; interval != 10*time.Second {
+		t.Errorf("Expected cleanup interval 10s, got %v", interval)
+	}
+
+	invalid := []cache.Config{
+		{EvictionPolicy: cache.EvictionPolicy(42)},
+		{EvictionPolicy: cache.LRU, MaxSize: -1},
+		{EvictionPolicy: cache.LFU, MinTTL: time.Hour, MaxTTL: time.Minute},
+	}
+	for _, config := range invalid {
+		if _, err := New(config); !errors.Is(err, cache.ErrInvalidConfig) {
+			t.Errorf("Expected ErrInvalidConfig for %+v, got %v", config, err)
+		}
+	}
+}
+
+// TestGetWithAge проверяет рост возраста значения и его сброс при перезаписи
+func TestGetWithAge(t *testing.T) {
+	type ageCache interface {
+		cache.Cache
+		GetWithAge(key string) ([]byte, time.Duration, bool)
+	}
+
+	implementations := map[string]func(opts ...Option) cache.Cache{
+		"Simple":  NewSimpleWithOptions,
+		"LRU":     NewLRUWithOptions,
+		"LFU":     NewLFUWithOptions,
+		"Sharded": func(opts ...Option) cache.Cache { return NewShardedWithOptions(4, opts...) },
+	}
+
+	for name, constructor := range implementations {
+		t.Run(name, func(t *testing.T) {
+			clock := newFakeClock()
+			c := constructor(WithMaxSize(10), WithClock(clock)).(ageCache)
+			defer c.Close()
+
+			c.Set("key", []byte("v"))
+			if value, age, ok := c.GetWithAge("key"); !ok || string(value) != "v" || age != 0 {
+				t.Errorf("Expected fresh value, got %q, %v, %v", value, age, ok)
+			}
+
+			clock.Advance(time.Minute)
+			if _, age, _ := c.GetWithAge("key"); age != time.Minute {
+				t.Errorf("Expected age 1m, got %v", age)
+			}
+
+			// Продление TTL не обнуляет возраст, перезапись - обнуляет
+			c.Expire("key", time.Hour)
+			clock.Advance(time.Minute)
+			if _, age, _ := c.GetWithAge("key"); age != 2*time.Minute {
+				t.Errorf("Expected age 2m after Expire, got %v", age)
+			}
+			c.Set("key", []byte("v2"))
+			if _, age, _ := c.GetWithAge("key"); age != 0 {
+				t.Errorf("Expected age reset by overwrite, got %v", age)
+			}
+
+			if _, _, ok := c.GetWithAge("missing"); ok {
+				t.Error("Expected miss for missing key")
+			}
+			if stats := c.Stats(); stats.Hits != 4 || stats.Misses != 1 {
+				t.Errorf("Expected 4 hits and 1 miss, got %+v", stats)
+			}
+		})
+	}
+}
+
+// TestSimpleConcurrentTTLChange проверяет под -race, что изменение TTL не гоняется
+// с Get, который читает элемент SimpleCache после снятия блокировки
+func TestSimpleConcurrentTTLChange(t *testing.T) {
+	changes := map[string]func(c *SimpleCache){
+		"Expire": func(c *SimpleCache) { c.Expire("key", time.Hour) },
+		"Touch":  func(c *SimpleCache) { c.Touch("key", time.Second) },
+		"GetAndRefresh": func(c *SimpleCache) {
+			c.GetAndRefresh("key", time.Second, 2*time.Hour)
+		},
+	}
+
+	for name, change := range changes {
+		t.Run(name, func(t *testing.T) {
+			c := NewSimpleWithTTL(time.Hour).(*SimpleCache)
+			defer c.Close()
+			c.Set("key", []byte("value"))
+
+			var wg sync.WaitGroup
+			wg.Add(2)
+			go func() {
+				defer wg.Done()
+				for i := 0; i < 1000; i++ {
+					if value, ok := c.Get("key"); !ok || string(value) != "value" {
+						t.Errorf("Expected value during TTL change, got %q, %v", value, ok)
+						return
+					}
+				}
+			}()
+			go func() {
+				defer wg.Done()
+				for i := 0; i < 1000; i++ {
+					change(c)
+				}
+			}()
+			wg.Wait()
+
+			if ttl, ok := c.GetTTL("key"); !ok || ttl <= 0 {
+				t.Errorf("Expected key with TTL after changes, got %v, %v", ttl, ok)
+			}
+		})
+	}
+}
+
+// TestSnapshotSlidingTTL проверяет, что восстановленный из снимка элемент продлевается
+// скользящим истечением на свой TTL, а не на TTL по умолчанию
+func TestSnapshotSlidingTTL(t *testing.T) {
+	type slidingSnapshotCache interface {
+		SnapshotCache
+		SetSlidingTTL(enabled bool)
+		setClock(clock Clock)
+	}
+
+	implementations := map[string]func() slidingSnapshotCache{
+		"Simple":  func() slidingSnapshotCache { return NewSimpleWithTTL(time.Hour).(*SimpleCache) },
+		"LRU":     func() slidingSnapshotCache { return NewLRUWithTTL(100, time.Hour).(*LRUCache) },
+		"LFU":     func() slidingSnapshotCache { return NewLFUWithTTL(100, time.Hour).(*LFUCache) },
+		"Sharded": func() slidingSnapshotCache { return NewShardedWithTTL(4, 100, time.Hour).(*ShardedCache) },
+	}
+
+	for name, constructor := range implementations {
+		t.Run(name, func(t *testing.T) {
+			source := constructor()
+			defer source.Close()
+			clock := withFakeClock(t, source)
+
+			source.SetWithTTL("session", []byte("v"), time.Minute)
+			var buf bytes.Buffer
+			if err := source.SaveSnapshot(&buf); err != nil {
+				t.Fatalf("SaveSnapshot failed: %v", err)
+			}
+
+			restored := constructor()
+			defer restored.Close()
+			restored.setClock(clock)
+			restored.SetSlidingTTL(true)
+			if err := restored.LoadSnapshot(&buf); err != nil {
+				t.Fatalf("LoadSnapshot failed: %v", err)
+			}
+
+			clock.Advance(20 * time.Second)
+			if _, ok := restored.Get("session"); !ok {
+				t.Fatal("Expected restored key to be alive")
+			}
+			if ttl, _ := restored.GetTTL("session"); ttl != time.Minute {
+				t.Errorf("Expected read to slide expiry by the original 1m TTL, got %v", ttl)
+			}
+		})
+	}
+}
+
+// TestGetSet проверяет атомарную замену значения с возвратом предыдущего
+func TestGetSet(t *testing.T) {
+	type getSetCache interface {
+		cache.Cache
+		GetSet(key string, value []byte, ttl time.Duration) ([]byte, bool, error)
+	}
+
+	implementations := map[string]func() getSetCache{
+		"Simple":  func() getSetCache { return NewSimple().(*SimpleCache) },
+		"LRU":     func() getSetCache { return NewLRU(100).(*LRUCache) },
+		"LFU":     func() getSetCache { return NewLFU(100).(*LFUCache) },
+		"Sharded": func() getSetCache { return NewSharded(4, 100).(*ShardedCache) },
+	}
+
+	for name, constructor := range implementations {
+		t.Run(name, func(t *testing.T) {
+			c := constructor()
+			defer c.Close()
+
+			if old, exists, err := c.GetSet("token", []byte("v1"), 0); exists || old != nil || err != nil {
+				t.Fatalf("Expected no previous value, got %s (%v, %v)", old, exists, err)
+			}
+			if old, exists, _ := c.GetSet("token", []byte("v2"), time.Minute); !exists || string(old) != "v1" {
+				t.Fatalf("Expected previous value v1, got %s", old)
+			}
+			if value, _ := c.Get("token"); string(value) != "v2" {
+				t.Fatalf("Expected new value v2, got %s", value)
+			}
+			if ttl, _ := c.GetTTL("token"); ttl <= 0 {
+				t.Fatalf("Expected new TTL to apply, got %v", ttl)
+			}
+
+			// Каждая замена видит результат предыдущей, поэтому ни одно значение не теряется
+			c.Set("counter", []byte("start"))
+			var wg sync.WaitGroup
+			seen := make(chan string, 100)
+			for i := 0; i < 100; i++ {
+				wg.Add(1)
+				go func(i int) {
+					defer wg.Done()
+					old, _, _ := c.GetSet("counter", []byte(fmt.Sprint(i)), 0)
+					seen <- string(old)
+				}(i)
+			}
+			wg.Wait()
+			close(seen)
+
+			unique := make(map[string]bool)
+			for old := range seen {
+				if unique[old] {
+					t.Fatalf("Value %s returned twice", old)
+				}
+				unique[old] = true
+			}
+		})
+	}
+}
+
+// TestDetailedStats проверяет сбор детальных метрик в реализациях кэша
+func TestDetailedStats(t *testing.T) {
+	type metricsCache interface {
+		cache.Cache
+		SetMetrics(m *cache.Metrics)
+		DetailedStats() cache.MetricsSnapshot
+	}
+
+	implementations := map[string]func() metricsCache{
+		"Simple": func() metricsCache { return NewSimple().(*SimpleCache) },
+		"LRU":    func() metricsCache { return NewLRU(2).(*LRUCache) },
+		"LFU":    func() metricsCache { return NewLFU(2).(*LFUCache) },
+	}
+
+	for name, constructor := range implementations {
+		t.Run(name, func(t *testing.T) {
+			c := constructor()
+			defer c.Close()
+
+			if snap := c.DetailedStats(); snap.Sets != 0 {
+				t.Fatalf("Expected zero snapshot without metrics, got %+v", snap)
+			}
+
+			c.SetMetrics(cache.NewMetrics())
+			c.Set("a", []byte("1"))
+			c.Set("b", []byte("22"))
+			c.Get("a")
+			c.Get("missing")
+			c.Delete("b")
+			c.Set("", []byte("invalid"))
+
+			snap := c.DetailedStats()
+			if snap.Sets != 2 || snap.Deletes != 1 {
+				t.Errorf("Expected 2 sets and 1 delete, got %d and %d", snap.Sets, snap.Deletes)
+			}
+			if snap.Hits != 1 || snap.Misses != 1 {
+				t.Errorf("Expected 1 hit and 1 miss, got %d and %d", snap.Hits, snap.Misses)
+			}
+			if snap.KeyCount != 1 {
+				t.Errorf("Expected 1 key, got %d", snap.KeyCount)
+			}
+			if want := internal.EstimateMemory("a", []byte("1")); snap.Memory != want {
+				t.Errorf("Expected memory %d, got %d", want, snap.Memory)
+			}
+			if snap.GetP99 <= 0 || snap.AvgSetTime <= 0 {
+				t.Errorf("Expected recorded timings, got p99=%v avg_set=%v", snap.GetP99, snap.AvgSetTime)
+			}
+
+			c.SetMetrics(nil)
+			c.Set("c", []byte("3"))
+			if snap := c.DetailedStats(); snap.Sets != 0 {
+				t.Errorf("Expected metrics to be disabled, got %d sets", snap.Sets)
+			}
+		})
+	}
+
+	// Вытеснения учитываются в метриках LRU и LFU
+	for name, c := range map[string]metricsCache{
+		"LRU": NewLRU(2).(*LRUCache),
+		"LFU": NewLFU(2).(*LFUCache),
+	} {
+		m := cache.NewMetrics()
+		c.SetMetrics(m)
+		for i := 0; i < 5; i++ {
+			c.Set(fmt.Sprint(i), []byte("v"))
+		}
+		if snap := m.GetSnapshot(); snap.Evictions != 3 || snap.KeyCount != 2 {
+			t.Errorf("%s: expected 3 evictions and 2 keys, got %d and %d", name, snap.Evictions, snap.KeyCount)
+		}
+		c.Close()
+	}
+}
+
+// TestStatsBytes проверяет, что объем данных в Stats следует за записью, перезаписью,
+// удалением, истечением и вытеснением во всех реализациях
+func TestStatsBytes(t *testing.T) {
+	implementations := map[string]func(size int) cache.Cache{
+		"Simple":   func(int) cache.Cache { return NewSimple() },
+		"LRU":      func(size int) cache.Cache { return NewLRU(size) },
+		"LFU":      func(size int) cache.Cache { return NewLFU(size) },
+		"FIFO":     func(size int) cache.Cache { return NewFIFO(size) },
+		"Random":   func(size int) cache.Cache { return NewRandom(size) },
+		"Approx":   func(size int) cache.Cache { return NewApproxLRU(size, 0) },
+		"Weighted": func(size int) cache.Cache { return NewWeighted(int64(size)) },
+		"TinyLFU":  func(size int) cache.Cache { return NewTinyLFU(size) },
+		"ARC":      func(size int) cache.Cache { return NewARC(size) },
+		"Sharded":  func(size int) cache.Cache { return NewSharded(1, size) },
+	}
+
+	for name, constructor := range implementations {
+		t.Run(name, func(t *testing.T) {
+			c := constructor(100)
+			defer c.Close()
+			clock := withFakeClock(t, c)
+
+			expectBytes := func(want int64) {
+				t.Helper()
+				if got := c.Stats().Bytes; got != want {
+					t.Fatalf("Expected %d bytes, got %d", want, got)
+				}
+			}
+
+			c.Set("a", []byte("12345"))
+			expectBytes(6)
+
+			// Перезапись вычитает старое значение и добавляет новое
+			c.Set("a", []byte("1"))
+			expectBytes(2)
+
+			c.Set("bb", []byte("xyz"))
+			expectBytes(7)
+
+			c.Delete("a")
+			expectBytes(5)
+
+			c.SetWithTTL("e", []byte("v"), time.Millisecond)
+			expectBytes(7)
+			clock.Advance(5 * time.Millisecond)
+			c.Get("e")
+			expectBytes(5)
+
+			c.Clear()
+			expectBytes(0)
+		})
+
+		t.Run(name+"/Eviction", func(t *testing.T) {
+			if name == "Simple" {
+				t.Skip("Simple cache does not evict")
+			}
+
+			c := constructor(4)
+			defer c.Close()
+
+			for i := 0; i < 20; i++ {
+				c.Set(fmt.Sprintf("k%d", i%10), []byte("v"))
+			}
+			if stats := c.Stats(); stats.Bytes != stats.Keys*3 {
+				t.Fatalf("Expected %d bytes for %d keys, got %d", stats.Keys*3, stats.Keys, stats.Bytes)
+			}
+		})
+	}
+}
+
+// TestCleanupInterval проверяет фоновую очистку без TTL по умолчанию и ее отключение
+func TestCleanupInterval(t *testing.T) {
+	type cleanupCache interface {
+		cache.Cache
+		SetCleanupInterval(interval time.Duration)
+	}
+
+	implementations := map[string]func() cleanupCache{
+		"Simple":  func() cleanupCache { return NewSimple().(*SimpleCache) },
+		"LRU":     func() cleanupCache { return NewLRU(100).(*LRUCache) },
+		"LFU":     func() cleanupCache { return NewLFU(100).(*LFUCache) },
+		"Sharded": func() cleanupCache { return NewSharded(4, 100).(*ShardedCache) },
+	}
+
+	for name, constructor := range implementations {
+		t.Run(name, func(t *testing.T) {
+			c := constructor()
+			defer c.Close()
+
+			c.SetCleanupInterval(5 * time.Millisecond)
+			c.SetWithTTL("short", []byte("value"), 10*time.Millisecond)
+			c.Set("persistent", []byte("value"))
+
+			deadline := time.Now().Add(time.Second)
+			for c.Len() != 1 && time.Now().Before(deadline) {
+				time.Sleep(5 * time.Millisecond)
+			}
+			if c.Len() != 1 {
+				t.Fatalf("Expected expired key to be removed by cleanup, got %d keys", c.Len())
+			}
+
+			// После отключения истекшие элементы остаются до обращения к ним
+			c.SetCleanupInterval(0)
+			c.SetWithTTL("short", []byte("value"), time.Millisecond)
+			time.Sleep(30 * time.Millisecond)
+			if c.Len() != 2 {
+				t.Fatalf("Expected cleanup to be disabled, got %d keys", c.Len())
+			}
+		})
+	}
+}
+
+// TestOptions проверяет создание кэшей через функциональные опции
+func TestOptions(t *testing.T) {
+	constructors := map[string]func(opts ...Option) cache.Cache{
+		"Simple": NewSimpleWithOptions,
+		"LRU":    NewLRUWithOptions,
+		"LFU":    NewLFUWithOptions,
+	}
+
+	for name, constructor := range constructors {
+		t.Run(name, func(t *testing.T) {
+			var evicted atomic.Int64
+			metrics := cache.NewMetrics()
+
+			c := constructor(
+				WithMaxSize(2),
+				WithDefaultTTL(time.Hour),
+				WithCleanupInterval(5*time.Millisecond),
+				WithOnEvict(func(key string, value []byte, reason cache.EvictionReason) {
+					evicted.Add(1)
+				}),
+				WithMetrics(metrics),
+			)
+			defer c.Close()
+
+			c.Set("a", []byte("1"))
+			if ttl, ok := c.GetTTL("a"); !ok || ttl <= 59*time.Minute {
+				t.Fatalf("Expected default TTL of an hour, got %v", ttl)
+			}
+
+			c.SetWithTTL("short", []byte("2"), time.Millisecond)
+			deadline := time.Now().Add(time.Second)
+			for evicted.Load() == 0 && time.Now().Before(deadline) {
+				time.Sleep(5 * time.Millisecond)
+			}
+			if evicted.Load() == 0 {
+				t.Fatal("Expected expired key to be removed by cleanup and reported to OnEvict")
+			}
+
+			if snap := metrics.GetSnapshot(); snap.Sets != 2 {
+				t.Errorf("Expected 2 sets in metrics, got %d", snap.Sets)
+			}
+
+			for i := 0; i < 5; i++ {
+				c.Set(fmt.Sprint(i), []byte("v"))
+			}
+			if name != "Simple" && c.Len() != 2 {
+				t.Errorf("Expected size limit of 2, got %d keys", c.Len())
+			}
+		})
+	}
+
+	// Поздняя опция перекрывает раннюю, WithMaxBytes ограничивает LRU по объему
+	c := NewLRUWithOptions(WithMaxSize(1), WithMaxSize(0), WithMaxBytes(10))
+	defer c.Close()
+	for i := 0; i < 10; i++ {
+		c.Set(fmt.Sprint(i), []byte("1234"))
+	}
+	if stats := c.Stats(); stats.Keys != 2 || stats.Bytes > 10 {
+		t.Errorf("Expected byte limit to keep 2 keys, got %d keys and %d bytes", stats.Keys, stats.Bytes)
+	}
+}
+
+// TestShardedWithOptions проверяет, что хеш-функция из WithHashFunc выбирает шард
+func TestShardedWithOptions(t *testing.T) {
+	var calls atomic.Int64
+	c := NewShardedWithOptions(4,
+		WithMaxSize(2),
+		WithHashFunc(func(string) uint64 {
+			calls.Add(1)
+			return 0
+		}),
+	)
+	defer c.Close()
+
+	// Все ключи попадают в первый шард, поэтому лимит шарда ограничивает весь кэш
+	for i := 0; i < 10; i++ {
+		c.Set(fmt.Sprint(i), []byte("v"))
+	}
+	if c.Len() != 2 {
+		t.Errorf("Expected all keys in one shard of size 2, got %d keys", c.Len())
+	}
+	if calls.Load() == 0 {
+		t.Error("Expected custom hash to be used")
+	}
+	if value, ok := c.Get("9"); !ok || string(value) != "v" {
+		t.Errorf("Expected latest key to be found, got %q %v", value, ok)
+	}
+}
+
+// TestLFUFrequencyDecay проверяет, что после старения частот давно популярный,
+// но неиспользуемый ключ вытесняется, а активный остается
+func TestLFUFrequencyDecay(t *testing.T) {
+	fill := func() *LFUCache {
+		c := NewLFU(3).(*LFUCache)
+		c.Set("old", []byte("1"))
+		for i := 0; i < 100; i++ {
+			c.Get("old")
+		}
+		c.Set("active", []byte("2"))
+		c.Set("idle", []byte("3"))
+		return c
+	}
+
+	// Без старения прошлая популярность защищает ключ от вытеснения
+	c := fill()
+	c.Set("new", []byte("4"))
+	if _, ok := c.Get("old"); !ok {
+		t.Fatal("Expected hot key to survive without decay")
+	}
+	c.Close()
+
+	c = fill()
+	defer c.Close()
+	for i := 0; i < 8; i++ {
+		c.decayFrequencies()
+		for j := 0; j < 3; j++ {
+			c.Get("active")
+		}
+	}
+
+	c.Set("new", []byte("4"))
+	if _, ok := c.Get("old"); ok {
+		t.Error("Expected previously hot idle key to be evicted after decay")
+	}
+	if _, ok := c.Get("active"); !ok {
+		t.Error("Expected active key to survive")
+	}
+
+	// Многократное старение не опускает частоту ниже 1
+	for i := 0; i < 10; i++ {
+		c.decayFrequencies()
+	}
+	c.mu.RLock()
+	for key, item := range c.items {
+		if item.frequency != 1 {
+			t.Errorf("Expected frequency of %q to stop at 1, got %d", key, item.frequency)
+		}
+	}
+	c.mu.RUnlock()
+
+	// Фоновое старение уменьшает частоты без ручного вызова
+	for i := 0; i < 3; i++ {
+		c.Get("active")
+	}
+	c.SetFrequencyDecay(time.Millisecond)
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) {
+		c.mu.RLock()
+		frequency := c.items["active"].frequency
+		c.mu.RUnlock()
+		if frequency == 1 {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Error("Expected background decay to reduce frequency to one")
+}
+
+// TestLFUBucketOrder сравнивает кандидата на вытеснение из корзин частот с полным перебором:
+// наименьшая частота, а среди равных - давнее всего использованный элемент
+func TestLFUBucketOrder(t *testing.T) {
+	c := NewLFU(50).(*LFUCache)
+	defer c.Close()
+
+	rng := rand.New(rand.NewPCG(1, 2))
+	for i := 0; i < 5000; i++ {
+		key := fmt.Sprint(rng.IntN(80))
+		switch op := rng.IntN(10); {
+		case op < 5:
+			c.Get(key)
+		case op < 8:
+			c.Set(key, []byte("v"))
+		case op < 9:
+			c.Delete(key)
+		default:
+			c.decayFrequencies()
+		}
+
+		c.mu.RLock()
+		var want *lfuItem
+		for _, item := range c.items {
+			if want == nil || item.frequency < want.frequency ||
+				(item.frequency == want.frequency && item.lastAccess.Before(want.lastAccess)) {
+				want = item
+			}
+		}
+		got := c.buckets.back()
+		buckets := 0
+		for b := c.buckets.root.next; b != &c.buckets.root; b = b.next {
+			buckets++
+			if b.root.next == &b.root {
+				t.Fatalf("Step %d: empty bucket %d left in list", i, b.frequency)
+			}
+			if b.frequency <= b.prev.frequency {
+				t.Fatalf("Step %d: bucket %d follows bucket %d", i, b.frequency, b.prev.frequency)
+			}
+		}
+		c.mu.RUnlock()
+
+		if got != want {
+			t.Fatalf("Step %d: eviction candidate %v, want %v", i, got, want)
+		}
+		if buckets > len(c.items) {
+			t.Fatalf("Step %d: %d buckets for %d items", i, buckets, len(c.items))
+		}
+	}
+}
+
+// TestSimpleGetExpired проверяет, что Simple кэш не возвращает значение истекшего элемента
+func TestSimpleGetExpired(t *testing.T) {
+	c := NewSimple().(*SimpleCache)
+	defer c.Close()
+	clock := withFakeClock(t, c)
+
+	var reasons []cache.EvictionReason
+	c.SetOnEvict(func(key string, value []byte, reason cache.EvictionReason) {
+		reasons = append(reasons, reason)
+	})
+
+	for i := 0; i < 100; i++ {
+		key := fmt.Sprint(i)
+		c.SetWithTTL(key, []byte("value"), time.Millisecond)
+		clock.Advance(2 * time.Millisecond)
+
+		if value, ok := c.Get(key); ok || value != nil {
+			t.Fatalf("Expected (nil, false) for expired key, got (%q, %v)", value, ok)
+		}
+	}
+
+	if c.Len() != 0 {
+		t.Errorf("Expected expired keys to be removed, got %d", c.Len())
+	}
+	if stats := c.Stats(); stats.Hits != 0 || stats.Misses != 100 {
+		t.Errorf("Expected 0 hits and 100 misses, got %d and %d", stats.Hits, stats.Misses)
+	}
+	if len(reasons) != 100 || reasons[0] != cache.ReasonExpired {
+		t.Errorf("Expected 100 expiry notifications, got %v", reasons)
+	}
+}
+
+// TestEvictionPrefersExpired проверяет, что при нехватке места сначала удаляются
+// истекшие элементы, а не живые
+func TestEvictionPrefersExpired(t *testing.T) {
+	implementations := map[string]func() cache.Cache{
+		"LRU":      func() cache.Cache { return NewLRU(3) },
+		"LRUBytes": func() cache.Cache { return NewLRUWithBytes(12) },
+		"LFU":      func() cache.Cache { return NewLFU(3) },
+	}
+
+	for name, constructor := range implementations {
+		t.Run(name, func(t *testing.T) {
+			c := constructor()
+			defer c.Close()
+			clock := withFakeClock(t, c)
+
+			// Живой ключ записан первым и является первым кандидатом на вытеснение
+			c.Set("live", []byte("v"))
+			c.SetWithTTL("a", []byte("v"), time.Millisecond)
+			c.SetWithTTL("b", []byte("v"), time.Millisecond)
+			for i := 0; i < 3; i++ {
+				c.Get("a")
+				c.Get("b")
+			}
+			clock.Advance(5 * time.Millisecond)
+
+			c.Set("new", []byte("v"))
+
+			if _, ok := c.Get("live"); !ok {
+				t.Error("Expected live key to survive while expired keys occupy the cache")
+			}
+			if _, ok := c.Get("new"); !ok {
+				t.Error("Expected new key to be stored")
+			}
+		})
+	}
+}
+
+// TestCopy проверяет копию живых элементов и ее независимость от кэша
+func TestCopy(t *testing.T) {
+	type copyCache interface {
+		cache.Cache
+		Copy() map[string][]byte
+	}
+
+	implementations := map[string]func() copyCache{
+		"Simple":  func() copyCache { return NewSimple().(*SimpleCache) },
+		"LRU":     func() copyCache { return NewLRU(100).(*LRUCache) },
+		"LFU":     func() copyCache { return NewLFU(100).(*LFUCache) },
+		"Sharded": func() copyCache { return NewSharded(4, 100).(*ShardedCache) },
+	}
+
+	for name, constructor := range implementations {
+		t.Run(name, func(t *testing.T) {
+			c := constructor()
+			defer c.Close()
+			clock := withFakeClock(t, c)
+
+			c.Set("a", []byte("1"))
+			c.Set("b", []byte("2"))
+			c.SetWithTTL("expired", []byte("3"), time.Millisecond)
+			clock.Advance(5 * time.Millisecond)
+
+			items := c.Copy()
+			if len(items) != 2 || string(items["a"]) != "1" || string(items["b"]) != "2" {
+				t.Fatalf("Expected copy of live items a and b, got %v", items)
+			}
+
+			items["a"][0] = 'x'
+			items["c"] = []byte("new")
+			if value, _ := c.Get("a"); string(value) != "1" {
+				t.Errorf("Modifying copy changed cache value to %s", value)
+			}
+			if _, ok := c.Get("c"); ok {
+				t.Error("Adding to copy should not add to cache")
+			}
+
+			c.Set("b", []byte("changed"))
+			if string(items["b"]) != "2" {
+				t.Errorf("Copy should not follow later writes, got %s", items["b"])
+			}
+		})
+	}
+}
+
+// TestSubscribe проверяет события изменений, несколько подписчиков и отписку
+func TestSubscribe(t *testing.T) {
+	type subscribeCache interface {
+		cache.Cache
+		Subscribe() (<-chan cache.Event, func())
+	}
+
+	implementations := map[string]func() subscribeCache{
+		"Simple":  func() subscribeCache { return NewSimple().(*SimpleCache) },
+		"LRU":     func() subscribeCache { return NewLRU(2).(*LRUCache) },
+		"LFU":     func() subscribeCache { return NewLFU(2).(*LFUCache) },
+		// Несколько шардов: Clear должен отправить одно событие, а не по событию на шард
+		"Sharded": func() subscribeCache { return NewSharded(4, 2).(*ShardedCache) },
+	}
+
+	for name, constructor := range implementations {
+		t.Run(name, func(t *testing.T) {
+			c := constructor()
+			defer c.Close()
+			clock := withFakeClock(t, c)
+
+			first, unsubscribeFirst := c.Subscribe()
+			second, unsubscribeSecond := c.Subscribe()
+			defer unsubscribeSecond()
+
+			c.Set("a", []byte("1"))
+			c.Delete("a")
+			c.SetWithTTL("b", []byte("2"), time.Millisecond)
+			clock.Advance(5 * time.Millisecond)
+			c.Get("b")
+			c.Clear()
+
+			want := []cache.Event{
+				{Type: cache.EventSet, Key: "a"},
+				{Type: cache.EventDelete, Key: "a"},
+				{Type: cache.EventSet, Key: "b"},
+				{Type: cache.EventExpire, Key: "b"},
+				{Type: cache.EventClear},
+			}
+			for _, events := range []<-chan cache.Event{first, second} {
+				for _, expected := range want {
+					if event := <-events; event != expected {
+						t.Fatalf("Expected event %v %q, got %v %q", expected.Type, expected.Key, event.Type, event.Key)
+					}
+				}
+			}
+
+			// После отписки канал закрывается, а события получает только оставшийся подписчик
+			unsubscribeFirst()
+			unsubscribeFirst()
+			if _, ok := <-first; ok {
+				t.Fatal("Expected channel to be closed after unsubscribe")
+			}
+			c.Set("c", []byte("3"))
+			if event := <-second; event.Type != cache.EventSet || event.Key != "c" {
+				t.Fatalf("Expected set event for c, got %v %q", event.Type, event.Key)
+			}
+		})
+	}
+
+	// Вытеснение и отсутствие блокировки при переполненном буфере подписчика
+	c := NewLRU(2).(*LRUCache)
+	defer c.Close()
+	events, unsubscribe := c.Subscribe()
+	defer unsubscribe()
+
+	for i := 0; i < eventBufferSize*2; i++ {
+		c.Set(fmt.Sprint(i), []byte("v"))
+	}
+	if len(events) != eventBufferSize {
+		t.Fatalf("Expected full buffer of %d events, got %d", eventBufferSize, len(events))
+	}
+
+	<-events // set 0
+	<-events // set 1
+	if event := <-events; event.Type != cache.EventEvict || event.Key != "0" {
+		t.Fatalf("Expected evict event for 0, got %v %q", event.Type, event.Key)
+	}
+	if event := <-events; event.Type != cache.EventSet || event.Key != "2" {
+		t.Fatalf("Expected set event for 2, got %v %q", event.Type, event.Key)
+	}
+}
+
+// TestSlidingTTL проверяет, что чтение продлевает элемент на исходный TTL,
+// а непрочитанный элемент истекает и удаляется фоновой очисткой
+func TestSlidingTTL(t *testing.T) {
+	type slidingCache interface {
+		cache.Cache
+		SetSlidingTTL(enabled bool)
+		SetCleanupInterval(interval time.Duration)
+	}
+
+	implementations := map[string]func() slidingCache{
+		"Simple":  func() slidingCache { return NewSimple().(*SimpleCache) },
+		"LRU":     func() slidingCache { return NewLRU(100).(*LRUCache) },
+		"LFU":     func() slidingCache { return NewLFU(100).(*LFUCache) },
+		"Sharded": func() slidingCache { return NewSharded(4, 100).(*ShardedCache) },
+	}
+
+	for name, constructor := range implementations {
+		t.Run(name, func(t *testing.T) {
+			c := constructor()
+			defer c.Close()
+			clock := withFakeClock(t, c)
+
+			c.SetSlidingTTL(true)
+			c.SetCleanupInterval(5 * time.Millisecond)
+			c.SetWithTTL("session", []byte("active"), 50*time.Millisecond)
+			c.SetWithTTL("idle", []byte("idle"), 50*time.Millisecond)
+			c.Set("persistent", []byte("value"))
+
+			// Чтения чаще TTL удерживают ключ дольше нескольких TTL
+			for i := 0; i < 10; i++ {
+				clock.Advance(20 * time.Millisecond)
+				if _, ok := c.Get("session"); !ok {
+					t.Fatalf("Session expired after %d reads despite sliding TTL", i)
+				}
+			}
+
+			if ttl, ok := c.GetTTL("session"); !ok || ttl <= 30*time.Millisecond {
+				t.Errorf("Expected TTL to be reset by the last read, got %v", ttl)
+			}
+			if ttl, ok := c.GetTTL("persistent"); !ok || ttl != cache.NoExpiration {
+				t.Errorf("Persistent key should stay persistent, got %v", ttl)
+			}
+			// Очистка запускается по реальному таймеру и удаляет idle, истекший по часам кэша
+			deadline := time.Now().Add(time.Second)
+			for c.Len() != 2 && time.Now().Before(deadline) {
+				time.Sleep(5 * time.Millisecond)
+			}
+			if c.Len() != 2 {
+				t.Errorf("Expected idle key to be removed by cleanup, got %d keys", c.Len())
+			}
+
+			// После отключения чтение не продлевает ключ
+			c.SetSlidingTTL(false)
+			c.SetWithTTL("fixed", []byte("v"), 40*time.Millisecond)
+			for i := 0; i < 3; i++ {
+				clock.Advance(20 * time.Millisecond)
+				c.Get("fixed")
+			}
+			if _, ok := c.Get("fixed"); ok {
+				t.Error("Expected key to expire without sliding TTL")
+			}
+		})
+	}
+}
+
+func TestMaxAge(t *testing.T) {
+	type maxAgeCache interface {
+		cache.Cache
+		SetMaxAge(maxAge time.Duration)
+		SetSlidingTTL(enabled bool)
+	}
+
+	implementations := map[string]func() maxAgeCache{
+		"Simple":  func() maxAgeCache { return NewSimple().(*SimpleCache) },
+		"LRU":     func() maxAgeCache { return NewLRU(100).(*LRUCache) },
+		"LFU":     func() maxAgeCache { return NewLFU(100).(*LFUCache) },
+		"Sharded": func() maxAgeCache { return NewSharded(4, 100).(*ShardedCache) },
+	}
+
+	for name, constructor := range implementations {
+		t.Run(name, func(t *testing.T) {
+			c := constructor()
+			defer c.Close()
+			clock := withFakeClock(t, c)
+
+			c.SetMaxAge(60 * time.Millisecond)
+			c.SetSlidingTTL(true)
+			c.SetWithTTL("hot", []byte("v"), 30*time.Millisecond)
+			c.Set("persistent", []byte("v"))
+			c.SetWithTTL("short", []byte("v"), 10*time.Millisecond)
+
+			if ttl, ok := c.GetTTL("persistent"); !ok || ttl == cache.NoExpiration || ttl > 60*time.Millisecond {
+				t.Errorf("Expected persistent key to be capped by max age, got %v", ttl)
+			}
+			if ttl, ok := c.GetTTL("short"); !ok || ttl > 10*time.Millisecond {
+				t.Errorf("Shorter TTL should be kept, got %v", ttl)
+			}
+
+			// Persist и Expire не продлевают элемент дальше предельного возраста
+			c.Persist("hot")
+			c.Expire("hot", time.Hour)
+			if ttl, ok := c.GetTTL("hot"); !ok || ttl > 60*time.Millisecond {
+				t.Errorf("Expected TTL capped by max age after Expire, got %v", ttl)
+			}
+
+			// Скользящее истечение удерживает ключ только до предельного возраста
+			for i := 0; i < 40; i++ {
+				clock.Advance(5 * time.Millisecond)
+				if _, ok := c.Get("hot"); !ok {
+					break
+				}
+			}
+			if _, ok := c.Get("hot"); ok {
+				t.Error("Expected frequently read key to expire by max age")
+			}
+			if _, ok := c.Get("persistent"); ok {
+				t.Error("Expected persistent key to expire by max age")
+			}
+
+			// Перезапись начинает отсчет заново
+			c.Set("persistent", []byte("v2"))
+			if value, ok := c.Get("persistent"); !ok || string(value) != "v2" {
+				t.Errorf("Expected rewritten key to be available, got %q", value)
+			}
+
+			c.SetMaxAge(0)
+			c.Set("free", []byte("v"))
+			if ttl, ok := c.GetTTL("free"); !ok || ttl != cache.NoExpiration {
+				t.Errorf("Expected no limit after SetMaxAge(0), got %v", ttl)
+			}
+		})
+	}
+}
+
+func TestGetE(t *testing.T) {
+	type getECache interface {
+		cache.Cache
+		GetE(key string) ([]byte, error)
+	}
+
+	implementations := map[string]func() getECache{
+		"Simple":  func() getECache { return NewSimple().(*SimpleCache) },
+		"LRU":     func() getECache { return NewLRU(100).(*LRUCache) },
+		"LFU":     func() getECache { return NewLFU(100).(*LFUCache) },
+		"Sharded": func() getECache { return NewSharded(4, 100).(*ShardedCache) },
+	}
+
+	for name, constructor := range implementations {
+		t.Run(name, func(t *testing.T) {
+			c := constructor()
+			clock := withFakeClock(t, c)
+
+			c.Set("key", []byte("value"))
+			if value, err := c.GetE("key"); err != nil || string(value) != "value" {
+				t.Errorf("Expected value, got %q, %v", value, err)
+			}
+			if _, err := c.GetE("missing"); !errors.Is(err, cache.ErrNotFound) {
+				t.Errorf("Expected ErrNotFound, got %v", err)
+			}
+			if _, err := c.GetE(""); !errors.Is(err, cache.ErrKeyEmpty) {
+				t.Errorf("Expected ErrKeyEmpty, got %v", err)
+			}
+
+			c.SetWithTTL("short", []byte("v"), 10*time.Millisecond)
+			clock.Advance(20 * time.Millisecond)
+			if _, err := c.GetE("short"); !errors.Is(err, cache.ErrNotFound) {
+				t.Errorf("Expected ErrNotFound for expired key, got %v", err)
+			}
+
+			stats := c.Stats()
+			if stats.Hits != 1 || stats.Misses != 2 {
+				t.Errorf("Expected 1 hit and 2 misses, got %d and %d", stats.Hits, stats.Misses)
+			}
+
+			c.Close()
+			if _, err := c.GetE("key"); !errors.Is(err, cache.ErrCacheClosed) {
+				t.Errorf("Expected ErrCacheClosed, got %v", err)
+			}
+		})
+	}
+}
+
+func TestGetRef(t *testing.T) {
+	type refCache interface {
+		cache.Cache
+		GetRef(key string) ([]byte, bool)
+		SetCompressionThreshold(threshold int)
+	}
+
+	implementations := map[string]func() refCache{
+		"Simple":  func() refCache { return NewSimple().(*SimpleCache) },
+		"LRU":     func() refCache { return NewLRU(100).(*LRUCache) },
+		"LFU":     func() refCache { return NewLFU(100).(*LFUCache) },
+		"Sharded": func() refCache { return NewSharded(4, 100).(*ShardedCache) },
+	}
+
+	for name, constructor := range implementations {
+		t.Run(name, func(t *testing.T) {
+			c := constructor()
+			defer c.Close()
+
+			c.Set("key", []byte("value"))
+			first, ok := c.GetRef("key")
+			if !ok || string(first) != "value" {
+				t.Fatalf("Expected value, got %q, %v", first, ok)
+			}
+
+			// Повторное чтение возвращает тот же срез без копирования
+			second, _ := c.GetRef("key")
+			if &first[0] != &second[0] {
+				t.Error("Expected GetRef to return the stored slice")
+			}
+
+			// Перезапись не затрагивает уже полученный срез
+			c.Set("key", []byte("other"))
+			if string(first) != "value" {
+				t.Errorf("Overwrite changed a returned slice: %q", first)
+			}
+
+			if _, ok := c.GetRef("missing"); ok {
+				t.Error("Expected miss for missing key")
+			}
+			if stats := c.Stats(); stats.Hits != 2 || stats.Misses != 1 {
+				t.Errorf("Expected 2 hits and 1 miss, got %d and %d", stats.Hits, stats.Misses)
+			}
+
+			c.SetCompressionThreshold(16)
+			large := bytes.Repeat([]byte("a"), 1024)
+			c.Set("large", large)
+			if value, ok := c.GetRef("large"); !ok || !bytes.Equal(value, large) {
+				t.Error("Expected compressed value to be decompressed")
+			}
+		})
+	}
+}
+
+func TestEvictionHeadroom(t *testing.T) {
+	waitForLimit := func(t *testing.T, within func() bool) {
+		t.Helper()
+		deadline := time.Now().Add(time.Second)
+		for !within() {
+			if time.Now().After(deadline) {
+				t.Fatal("Cache did not return to its limit")
+			}
+			time.Sleep(time.Millisecond)
+		}
+	}
+
+	t.Run("Count", func(t *testing.T) {
+		c := NewLRU(100).(*LRUCache)
+		defer c.Close()
+		c.SetEvictionHeadroom(0.2)
+
+		for i := 0; i < 1000; i++ {
+			c.Set(fmt.Sprintf("key%d", i), []byte("value"))
+			if n := c.Len(); n > 120 {
+				t.Fatalf("Cache exceeded the overshoot bound: %d items", n)
+			}
+		}
+		waitForLimit(t, func() bool { return c.Len() == 100 })
+
+		// Вытесняются давно использованные элементы
+		if _, exists := c.Get("key999"); !exists {
+			t.Error("Most recent key should survive background eviction")
+		}
+		if stats := c.Stats(); stats.Evictions != 900 {
+			t.Errorf("Expected 900 evictions, got %d", stats.Evictions)
+		}
+	})
+
+	t.Run("Bytes", func(t *testing.T) {
+		c := NewLRUWithBytes(1000).(*LRUCache)
+		defer c.Close()
+		c.SetEvictionHeadroom(0.5)
+
+		for i := 0; i < 500; i++ {
+			c.Set(fmt.Sprintf("k%03d", i), []byte("123456"))
+			if bytes := c.Stats().Bytes; bytes > 1500 {
+				t.Fatalf("Cache exceeded the overshoot bound: %d bytes", bytes)
+			}
+		}
+		waitForLimit(t, func() bool { return c.Stats().Bytes <= 1000 })
+	})
+
+	t.Run("Disable", func(t *testing.T) {
+		c := NewLRU(10).(*LRUCache)
+		defer c.Close()
+		c.SetEvictionHeadroom(1)
+
+		for i := 0; i < 15; i++ {
+			c.Set(fmt.Sprintf("key%d", i), []byte("value"))
+		}
+		c.SetEvictionHeadroom(0)
+		waitForLimit(t, func() bool { return c.Len() == 10 })
+
+		for i := 0; i < 5; i++ {
+			c.Set(fmt.Sprintf("new%d", i), []byte("value"))
+			if c.Len() != 10 {
+				t.Fatalf("Expected synchronous eviction without headroom, got %d items", c.Len())
+			}
+		}
+	})
+}
@@ -0,0 +1,101 @@
+package memory
+
+import (
+	cache "github.com/VsRnA/High-Performance-HTTP-Cache"
+	"github.com/VsRnA/High-Performance-HTTP-Cache/internal"
+)
+
+// SetMetrics подключает детальные метрики: время и перцентили Get, Set и Delete,
+// попадания, промахи, вытеснения, количество ключей и оценку занятой памяти.
+// nil отключает сбор. Один экземпляр Metrics должен использоваться одним кэшем,
+// иначе количество ключей и объем памяти будут перезаписывать друг друга.
+func (c *LRUCache) SetMetrics(m *cache.Metrics) {
+	c.mu.Lock()
+	c.metrics.Store(m)
+	c.reportSizeLocked()
+	c.mu.Unlock()
+}
+
+// DetailedStats возвращает снимок подключенных метрик. Без SetMetrics возвращает нулевой снимок.
+func (c *LRUCache) DetailedStats() cache.MetricsSnapshot {
+	return detailedStats(c.metrics.Load())
+}
+
+// reportSizeLocked обновляет количество ключей и объем памяти в метриках, вызывается под mu
+func (c *LRUCache) reportSizeLocked() {
+	if m := c.metrics.Load(); m != nil {
+		m.SetKeyCount(int64(len(c.items)))
+		m.SetMemoryUsage(estimateMemory(c.bytes, len(c.items)))
+	}
+}
+
+// SetMetrics подключает детальные метрики: время и перцентили Get, Set и Delete,
+// попадания, промахи, вытеснения, количество ключей и оценку занятой памяти.
+// nil отключает сбор. Один экземпляр Metrics должен использоваться одним кэшем.
+func (c *LFUCache) SetMetrics(m *cache.Metrics) {
+	c.mu.Lock()
+	c.metrics.Store(m)
+	c.reportSizeLocked()
+	c.mu.Unlock()
+}
+
+// DetailedStats возвращает снимок подключенных метрик. Без SetMetrics возвращает нулевой снимок.
+func (c *LFUCache) DetailedStats() cache.MetricsSnapshot {
+	return detailedStats(c.metrics.Load())
+}
+
+// reportSizeLocked обновляет количество ключей и объем памяти в метриках, вызывается под mu
+func (c *LFUCache) reportSizeLocked() {
+	if m := c.metrics.Load(); m != nil {
+		m.SetKeyCount(int64(len(c.items)))
+		m.SetMemoryUsage(estimateMemory(c.bytes, len(c.items)))
+	}
+}
+
+// SetMetrics подключает детальные метрики: время и перцентили Get, Set и Delete,
+// попадания, промахи, количество ключей и оценку занятой памяти.
+// nil отключает сбор. Один экземпляр Metrics должен использоваться одним кэшем.
+func (c *SimpleCache) SetMetrics(m *cache.Metrics) {
+	c.mu.Lock()
+	c.metrics.Store(m)
+	c.reportSizeLocked()
+	c.mu.Unlock()
+}
+
+// DetailedStats возвращает снимок подключенных метрик. Без SetMetrics возвращает нулевой снимок.
+func (c *SimpleCache) DetailedStats() cache.MetricsSnapshot {
+	return detailedStats(c.metrics.Load())
+}
+
+// reportSizeLocked обновляет количество ключей и объем памяти в метриках, вызывается под mu
+func (c *SimpleCache) reportSizeLocked() {
+	if m := c.metrics.Load(); m != nil {
+		m.SetKeyCount(int64(len(c.items)))
+		m.SetMemoryUsage(estimateMemory(c.bytes, len(c.items)))
+	}
+}
+
+// detailedStats возвращает снимок метрик или нулевой снимок, если метрики не подключены
+func detailedStats(m *cache.Metrics) cache.MetricsSnapshot {
+	if m == nil {
+		return cache.MetricsSnapshot{}
+	}
+	return m.GetSnapshot()
+}
+
+// estimateMemory оценивает занятую память так же, как сумма internal.EstimateMemory
+// по всем элементам. bytes уже содержит длины ключей и хранимых значений,
+// поэтому добавляются только накладные расходы на каждый элемент.
+func estimateMemory(bytes int64, items int) int64 {
+	return bytes + int64(items)*internal.EstimateMemory("", nil)
+}
+
+// recordGet учитывает чтение в метриках. timer запускается до начала операции.
+func recordGet(m *cache.Metrics, timer *internal.Timer, hit bool) {
+	if hit {
+		m.RecordHit()
+	} else {
+		m.RecordMiss()
+	}
+	m.RecordGet(timer.Duration())
+}
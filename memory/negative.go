@@ -0,0 +1,133 @@
+package memory
+
+import (
+	"sync/atomic"
+	"time"
+
+	cache "github.com/VsRnA/High-Performance-HTTP-Cache"
+)
+
+// SetNegative запоминает, что значения для ключа нет в источнике. Отрицательная запись
+// не содержит значения, Get считает ее промахом, а GetWithState возвращает StateNegative,
+// пока не истечет ttl. Обычно ttl делают короче, чем для значений.
+func (c *LRUCache) SetNegative(key string, ttl time.Duration) error {
+	if err := c.validate(key, nil); err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	defer c.unlock()
+
+	if c.closed {
+		return cache.ErrCacheClosed
+	}
+
+	c.setLocked(key, nil, ttl)
+	if item, exists := c.items[key]; exists {
+		item.negative = true
+	}
+	return nil
+}
+
+// GetWithState получает значение и отличает закэшированное отсутствие значения от промаха.
+// Отрицательные записи учитываются в статистике как промахи.
+func (c *LRUCache) GetWithState(key string) ([]byte, cache.EntryState) {
+	c.mu.Lock()
+	defer c.unlock()
+
+	if item, exists := c.items[key]; exists && item.negative && !item.isExpired(c.clock.Now()) {
+		atomic.AddInt64(&c.misses, 1)
+		return nil, cache.StateNegative
+	}
+
+	if value, exists := c.getLocked(key); exists {
+		return value, cache.StateHit
+	}
+	return nil, cache.StateMiss
+}
+
+// SetNegative запоминает, что значения для ключа нет в источнике. Отрицательная запись
+// не содержит значения, Get считает ее промахом, а GetWithState возвращает StateNegative,
+// пока не истечет ttl. Обычно ttl делают короче, чем для значений.
+func (c *LFUCache) SetNegative(key string, ttl time.Duration) error {
+	if err := c.validate(key, nil); err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	defer c.unlock()
+
+	if c.closed {
+		return cache.ErrCacheClosed
+	}
+
+	c.setLocked(key, nil, ttl)
+	if item, exists := c.items[key]; exists {
+		item.negative = true
+	}
+	return nil
+}
+
+// GetWithState получает значение и отличает закэшированное отсутствие значения от промаха.
+// Отрицательные записи учитываются в статистике как промахи.
+func (c *LFUCache) GetWithState(key string) ([]byte, cache.EntryState) {
+	c.mu.Lock()
+	defer c.unlock()
+
+	if item, exists := c.items[key]; exists && item.negative && !item.isExpired(c.clock.Now()) {
+		atomic.AddInt64(&c.misses, 1)
+		return nil, cache.StateNegative
+	}
+
+	if value, exists := c.getLocked(key); exists {
+		return value, cache.StateHit
+	}
+	return nil, cache.StateMiss
+}
+
+// SetNegative запоминает, что значения для ключа нет в источнике. Отрицательная запись
+// не содержит значения, Get считает ее промахом, а GetWithState возвращает StateNegative,
+// пока не истечет ttl. Обычно ttl делают короче, чем для значений.
+func (c *SimpleCache) SetNegative(key string, ttl time.Duration) error {
+	if err := c.validate(key, nil); err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	defer c.unlock()
+
+	if c.closed {
+		return cache.ErrCacheClosed
+	}
+
+	c.setLocked(key, nil, ttl)
+	c.items[key].negative = true
+	return nil
+}
+
+// GetWithState получает значение и отличает закэшированное отсутствие значения от промаха.
+// Отрицательные записи учитываются в статистике как промахи.
+func (c *SimpleCache) GetWithState(key string) ([]byte, cache.EntryState) {
+	c.mu.Lock()
+	defer c.unlock()
+
+	if item, exists := c.items[key]; exists && item.negative && !item.isExpired(c.clock.Now()) {
+		atomic.AddInt64(&c.misses, 1)
+		return nil, cache.StateNegative
+	}
+
+	if value, exists := c.getLocked(key); exists {
+		return value, cache.StateHit
+	}
+	return nil, cache.StateMiss
+}
+
+// SetNegative запоминает отсутствие значения для ключа в его шарде
+func (c *ShardedCache) SetNegative(key string, ttl time.Duration) error {
+	return c.shard(key).SetNegative(key, ttl)
+}
+
+// GetWithState получает значение и отличает закэшированное отсутствие значения от промаха
+func (c *ShardedCache) GetWithState(key string) ([]byte, cache.EntryState) {
+	return c.shard(key).GetWithState(key)
+}
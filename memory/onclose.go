@@ -0,0 +1,89 @@
+package memory
+
+// SetOnClose устанавливает колбэк, который Close вызывает один раз с копией
+// неистекших элементов, например, чтобы сохранить их перед остановкой процесса.
+// Копия снимается под той же блокировкой, что и закрытие, поэтому после нее запись
+// в кэш уже невозможна. Колбэк вызывается после снятия блокировки. nil отключает вызов.
+func (c *LRUCache) SetOnClose(fn func(items map[string][]byte)) {
+	c.mu.Lock()
+	c.onClose = fn
+	c.mu.Unlock()
+}
+
+// SetOnClose устанавливает колбэк, который Close вызывает один раз с копией
+// неистекших элементов. nil отключает вызов.
+func (c *LFUCache) SetOnClose(fn func(items map[string][]byte)) {
+	c.mu.Lock()
+	c.onClose = fn
+	c.mu.Unlock()
+}
+
+// SetOnClose устанавливает колбэк, который Close вызывает один раз с копией
+// неистекших элементов. nil отключает вызов.
+func (c *SimpleCache) SetOnClose(fn func(items map[string][]byte)) {
+	c.mu.Lock()
+	c.onClose = fn
+	c.mu.Unlock()
+}
+
+// SetOnClose устанавливает колбэк, который Close вызывает один раз с элементами
+// всех шардов. Шарды закрываются по очереди, поэтому копия согласована
+// в пределах шарда, но не между шардами. nil отключает вызов.
+func (c *ShardedCache) SetOnClose(fn func(items map[string][]byte)) {
+	c.closeMu.Lock()
+	c.onClose = fn
+	c.closeMu.Unlock()
+}
+
+// shutdown закрывает кэш и возвращает колбэк OnClose с копией элементов для него.
+// Копия снимается и при collect, даже без колбэка, - так ее собирает ShardedCache.
+// Для уже закрытого кэша возвращает nil, nil, поэтому колбэк вызывается один раз.
+func (c *LRUCache) shutdown(collect bool) (map[string][]byte, func(map[string][]byte)) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.closed {
+		return nil, nil
+	}
+
+	c.closed = true
+	close(c.stopCh)
+	if c.onClose == nil && !collect {
+		return nil, nil
+	}
+	return c.copyLocked(), c.onClose
+}
+
+// shutdown закрывает кэш и возвращает колбэк OnClose с копией элементов для него
+func (c *LFUCache) shutdown() (map[string][]byte, func(map[string][]byte)) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.closed {
+		return nil, nil
+	}
+
+	c.closed = true
+	close(c.stopCh)
+	if c.onClose == nil {
+		return nil, nil
+	}
+	return c.copyLocked(), c.onClose
+}
+
+// shutdown закрывает кэш и возвращает колбэк OnClose с копией элементов для него
+func (c *SimpleCache) shutdown() (map[string][]byte, func(map[string][]byte)) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.closed {
+		return nil, nil
+	}
+
+	c.closed = true
+	close(c.stopCh)
+	if c.onClose == nil {
+		return nil, nil
+	}
+	return c.copyLocked(), c.onClose
+}
@@ -0,0 +1,169 @@
+package memory
+
+import (
+	"time"
+
+	cache "github.com/VsRnA/High-Performance-HTTP-Cache"
+	"github.com/VsRnA/High-Performance-HTTP-Cache/internal"
+)
+
+// Option настраивает кэш, создаваемый через NewLRUWithOptions, NewLFUWithOptions,
+// NewSimpleWithOptions или NewShardedWithOptions. Опции применяются по порядку, поздняя перекрывает раннюю.
+type Option func(*options)
+
+// options - собранные настройки конструктора
+type options struct {
+	maxSize         int
+	maxBytes        int64
+	defaultTTL      time.Duration
+	cleanupInterval time.Duration
+	cleanupSet      bool // Период очистки задан явно, в том числе 0
+	onEvict         cache.EvictCallback
+	metrics         *cache.Metrics
+	hash            internal.HashFunc
+	minTTL, maxTTL  time.Duration
+	clock           Clock
+}
+
+// WithMaxSize ограничивает количество элементов. Simple кэш не ограничивается.
+func WithMaxSize(maxSize int) Option {
+	return func(o *options) {
+		o.maxSize = maxSize
+	}
+}
+
+// WithMaxBytes ограничивает суммарный объем ключей и значений. Поддерживается только LRU кэшем.
+func WithMaxBytes(maxBytes int64) Option {
+	return func(o *options) {
+		o.maxBytes = maxBytes
+	}
+}
+
+// WithDefaultTTL задает TTL для Set и включает фоновую очистку раз в минуту
+func WithDefaultTTL(ttl time.Duration) Option {
+	return func(o *options) {
+		o.defaultTTL = ttl
+	}
+}
+
+// WithCleanupInterval задает период фоновой очистки независимо от TTL по умолчанию, 0 отключает ее
+func WithCleanupInterval(interval time.Duration) Option {
+	return func(o *options) {
+		o.cleanupInterval = interval
+		o.cleanupSet = true
+	}
+}
+
+// WithOnEvict устанавливает колбэк для удаленных элементов, как SetOnEvict
+func WithOnEvict(fn cache.EvictCallback) Option {
+	return func(o *options) {
+		o.onEvict = fn
+	}
+}
+
+// WithMetrics подключает детальные метрики, как SetMetrics
+func WithMetrics(m *cache.Metrics) Option {
+	return func(o *options) {
+		o.metrics = m
+	}
+}
+
+// WithHashFunc задает хеш-функцию распределения ключей по шардам вместо internal.Hash64.
+// Учитывается только NewShardedWithOptions.
+func WithHashFunc(hash internal.HashFunc) Option {
+	return func(o *options) {
+		o.hash = hash
+	}
+}
+
+// WithTTLBounds ограничивает явно запрошенный TTL, как SetTTLBounds
+func WithTTLBounds(minTTL, maxTTL time.Duration) Option {
+	return func(o *options) {
+		o.minTTL = minTTL
+		o.maxTTL = maxTTL
+	}
+}
+
+// newOptions собирает опции в настройки
+func newOptions(opts []Option) options {
+	var o options
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// configurable - настройки, общие для кэшей с конструкторами на опциях
+type configurable interface {
+	SetOnEvict(fn cache.EvictCallback)
+	SetMetrics(m *cache.Metrics)
+	SetCleanupInterval(interval time.Duration)
+	SetTTLBounds(minTTL, maxTTL time.Duration)
+	setClock(clock Clock)
+}
+
+// apply применяет к созданному кэшу настройки, для которых есть сеттеры
+func (o options) apply(c configurable) {
+	if o.clock != nil {
+		c.setClock(o.clock)
+	}
+	if o.onEvict != nil {
+		c.SetOnEvict(o.onEvict)
+	}
+	if o.metrics != nil {
+		c.SetMetrics(o.metrics)
+	}
+	if o.cleanupSet {
+		c.SetCleanupInterval(o.cleanupInterval)
+	}
+	if o.minTTL > 0 || o.maxTTL > 0 {
+		c.SetTTLBounds(o.minTTL, o.maxTTL)
+	}
+}
+
+// NewLRUWithOptions создает LRU кэш с настройками из опций.
+// Без WithMaxSize и WithMaxBytes кэш ограничивается 1000 элементами, как NewLRU(0).
+func NewLRUWithOptions(opts ...Option) cache.Cache {
+	o := newOptions(opts)
+	c := newLRU(o.maxSize, o.maxBytes, o.defaultTTL)
+	o.apply(c)
+	return c
+}
+
+// NewLFUWithOptions создает LFU кэш с настройками из опций.
+// Без WithMaxSize кэш ограничивается 1000 элементами.
+func NewLFUWithOptions(opts ...Option) cache.Cache {
+	o := newOptions(opts)
+	c := NewLFUWithTTL(o.maxSize, o.defaultTTL).(*LFUCache)
+	o.apply(c)
+	return c
+}
+
+// NewSimpleWithOptions создает Simple кэш с настройками из опций. Ограничения размера игнорируются.
+func NewSimpleWithOptions(opts ...Option) cache.Cache {
+	o := newOptions(opts)
+	c := NewSimpleWithTTL(o.defaultTTL).(*SimpleCache)
+	o.apply(c)
+	return c
+}
+
+// NewShardedWithOptions создает шардированный кэш из shards LRU шардов с настройками из опций.
+// WithMaxSize и WithMaxBytes ограничивают каждый шард, WithHashFunc задает распределение ключей.
+// WithMetrics не поддерживается: метрики подключаются к отдельному кэшу, а не к набору шардов.
+func NewShardedWithOptions(shards int, opts ...Option) cache.Cache {
+	o := newOptions(opts)
+	c := newSharded(shards, o.maxSize, o.maxBytes, o.defaultTTL, o.hash)
+	if o.clock != nil {
+		c.setClock(o.clock)
+	}
+	if o.onEvict != nil {
+		c.SetOnEvict(o.onEvict)
+	}
+	if o.cleanupSet {
+		c.SetCleanupInterval(o.cleanupInterval)
+	}
+	if o.minTTL > 0 || o.maxTTL > 0 {
+		c.SetTTLBounds(o.minTTL, o.maxTTL)
+	}
+	return c
+}
@@ -0,0 +1,59 @@
+package memory
+
+// Peek получает значение без перемещения элемента в начало списка и без учета в статистике.
+// Истекший элемент не возвращается, но и не удаляется - это остается фоновой очистке и Get.
+func (c *LRUCache) Peek(key string) ([]byte, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	item, exists := c.items[key]
+	if !exists || c.closed || item.isExpired(c.clock.Now()) || item.negative {
+		return nil, false
+	}
+	return decodeValue(item.value, item.compressed), true
+}
+
+// Peek получает значение без увеличения частоты использования и без учета в статистике.
+// Истекший элемент не возвращается, но и не удаляется - это остается фоновой очистке и Get.
+func (c *LFUCache) Peek(key string) ([]byte, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	item, exists := c.items[key]
+	if !exists || c.closed || item.isExpired(c.clock.Now()) || item.negative {
+		return nil, false
+	}
+	return decodeValue(item.value, item.compressed), true
+}
+
+// Peek получает значение без учета в статистике.
+// Истекший элемент не возвращается, но и не удаляется - это остается фоновой очистке и Get.
+func (c *SimpleCache) Peek(key string) ([]byte, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	item, exists := c.items[key]
+	if !exists || c.closed || item.isExpired(c.clock.Now()) || item.negative {
+		return nil, false
+	}
+	return decodeValue(item.value, item.compressed), true
+}
+
+// Peek получает значение из шарда ключа без влияния на вытеснение и статистику
+func (c *ShardedCache) Peek(key string) ([]byte, bool) {
+	return c.shard(key).Peek(key)
+}
+
+// Peek получает значение без изменения приоритета вытеснения и без учета в статистике
+func (c *WeightedCache) Peek(key string) ([]byte, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	item, exists := c.items[key]
+	if !exists || c.closed || item.isExpired(c.clock.Now()) {
+		return nil, false
+	}
+	value := make([]byte, len(item.value))
+	copy(value, item.value)
+	return value, true
+}
@@ -0,0 +1,160 @@
+package memory
+
+import (
+	"strings"
+
+	cache "github.com/VsRnA/High-Performance-HTTP-Cache"
+)
+
+// matchGlob проверяет соответствие ключа шаблону, где * означает любую,
+// в том числе пустую, последовательность символов. Остальные символы сравниваются как есть.
+func matchGlob(pattern, key string) bool {
+	parts := strings.Split(pattern, "*")
+	if len(parts) == 1 {
+		return pattern == key
+	}
+
+	// Первая часть должна быть префиксом, последняя - суффиксом,
+	// средние ищутся жадно слева направо
+	if !strings.HasPrefix(key, parts[0]) {
+		return false
+	}
+	key = key[len(parts[0]):]
+
+	last := parts[len(parts)-1]
+	for _, part := range parts[1 : len(parts)-1] {
+		i := strings.Index(key, part)
+		if i < 0 {
+			return false
+		}
+		key = key[i+len(part):]
+	}
+	return strings.HasSuffix(key, last)
+}
+
+// DeletePrefix удаляет все ключи с указанным префиксом за одну блокировку
+// и возвращает количество удаленных. Истекшие ключи удаляются, но не учитываются.
+func (c *LRUCache) DeletePrefix(prefix string) int {
+	return c.deleteMatching(func(key string) bool {
+		return strings.HasPrefix(key, prefix)
+	})
+}
+
+// DeleteMatch удаляет все ключи, подходящие под шаблон с *, за одну блокировку
+// и возвращает количество удаленных
+func (c *LRUCache) DeleteMatch(pattern string) int {
+	return c.deleteMatching(func(key string) bool {
+		return matchGlob(pattern, key)
+	})
+}
+
+// deleteMatching удаляет элементы, для ключей которых match возвращает true
+func (c *LRUCache) deleteMatching(match func(key string) bool) int {
+	c.mu.Lock()
+	defer c.unlock()
+
+	deleted := 0
+	for key, item := range c.items {
+		if !match(key) {
+			continue
+		}
+		if item.isExpired(c.clock.Now()) {
+			c.removeItem(item, cache.ReasonExpired)
+			continue
+		}
+		c.removeItem(item, cache.ReasonDeleted)
+		deleted++
+	}
+	return deleted
+}
+
+// DeletePrefix удаляет все ключи с указанным префиксом за одну блокировку
+// и возвращает количество удаленных. Истекшие ключи удаляются, но не учитываются.
+func (c *LFUCache) DeletePrefix(prefix string) int {
+	return c.deleteMatching(func(key string) bool {
+		return strings.HasPrefix(key, prefix)
+	})
+}
+
+// DeleteMatch удаляет все ключи, подходящие под шаблон с *, за одну блокировку
+// и возвращает количество удаленных
+func (c *LFUCache) DeleteMatch(pattern string) int {
+	return c.deleteMatching(func(key string) bool {
+		return matchGlob(pattern, key)
+	})
+}
+
+// deleteMatching удаляет элементы, для ключей которых match возвращает true
+func (c *LFUCache) deleteMatching(match func(key string) bool) int {
+	c.mu.Lock()
+	defer c.unlock()
+
+	deleted := 0
+	for key, item := range c.items {
+		if !match(key) {
+			continue
+		}
+		if item.isExpired(c.clock.Now()) {
+			c.removeItem(item, cache.ReasonExpired)
+			continue
+		}
+		c.removeItem(item, cache.ReasonDeleted)
+		deleted++
+	}
+	return deleted
+}
+
+// DeletePrefix удаляет все ключи с указанным префиксом за одну блокировку
+// и возвращает количество удаленных. Истекшие ключи удаляются, но не учитываются.
+func (c *SimpleCache) DeletePrefix(prefix string) int {
+	return c.deleteMatching(func(key string) bool {
+		return strings.HasPrefix(key, prefix)
+	})
+}
+
+// DeleteMatch удаляет все ключи, подходящие под шаблон с *, за одну блокировку
+// и возвращает количество удаленных
+func (c *SimpleCache) DeleteMatch(pattern string) int {
+	return c.deleteMatching(func(key string) bool {
+		return matchGlob(pattern, key)
+	})
+}
+
+// deleteMatching удаляет элементы, для ключей которых match возвращает true
+func (c *SimpleCache) deleteMatching(match func(key string) bool) int {
+	c.mu.Lock()
+	defer c.unlock()
+
+	deleted := 0
+	for key, item := range c.items {
+		if !match(key) {
+			continue
+		}
+		if item.isExpired(c.clock.Now()) {
+			c.removeItem(item, cache.ReasonExpired)
+			continue
+		}
+		c.removeItem(item, cache.ReasonDeleted)
+		deleted++
+	}
+	return deleted
+}
+
+// DeletePrefix удаляет ключи с указанным префиксом во всех шардах.
+// Каждый шард блокируется отдельно, поэтому удаление не атомарно для кэша целиком.
+func (c *ShardedCache) DeletePrefix(prefix string) int {
+	deleted := 0
+	for _, shard := range c.shards {
+		deleted += shard.DeletePrefix(prefix)
+	}
+	return deleted
+}
+
+// DeleteMatch удаляет ключи, подходящие под шаблон с *, во всех шардах
+func (c *ShardedCache) DeleteMatch(pattern string) int {
+	deleted := 0
+	for _, shard := range c.shards {
+		deleted += shard.DeleteMatch(pattern)
+	}
+	return deleted
+}
@@ -0,0 +1,400 @@
+package memory
+
+import (
+	"math/rand/v2"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	cache "github.com/VsRnA/High-Performance-HTTP-Cache"
+	"github.com/VsRnA/High-Performance-HTTP-Cache/internal"
+)
+
+// randomItem представляет элемент в Random кэше
+type randomItem struct {
+	key       string
+	value     []byte
+	expiresAt time.Time
+	index     int // Позиция в срезе order для выбора случайного элемента за O(1)
+}
+
+// size возвращает объем ключа и значения в байтах
+func (item *randomItem) size() int64 {
+	return int64(len(item.key) + len(item.value))
+}
+
+// isExpired проверяет истек ли элемент на момент now
+func (item *randomItem) isExpired(now time.Time) bool {
+	return !item.expiresAt.IsZero() && now.After(item.expiresAt)
+}
+
+// RandomCache реализует кэш со случайным вытеснением
+// При переполнении удаляет равновероятно выбранный элемент
+type RandomCache struct {
+	// Основные данные
+	items map[string]*randomItem
+	order []*randomItem // Все элементы для равновероятного выбора жертвы
+	mu    sync.RWMutex
+
+	// Конфигурация
+	maxSize    int
+	defaultTTL time.Duration
+
+	// Объем хранимых ключей и значений в байтах, изменяется под mu
+	bytes int64
+
+	// Источник времени для TTL, изменяется под mu
+	clock Clock
+
+	// Управление жизненным циклом
+	stopCh chan struct{}
+	closed bool
+
+	// Уведомления об удаленных элементах
+	evictQueue evictionQueue
+
+	// Дедупликация одновременных загрузок в GetOrSet
+	loads internal.Group
+
+	// Статистика
+	hits      int64
+	misses    int64
+	evictions int64
+}
+
+// NewRandom создает новый Random кэш с указанным максимальным размером
+func NewRandom(maxSize int) cache.Cache {
+	return NewRandomWithTTL(maxSize, 0)
+}
+
+// NewRandomWithTTL создает новый Random кэш с максимальным размером и TTL по умолчанию
+func NewRandomWithTTL(maxSize int, defaultTTL time.Duration) cache.Cache {
+	if maxSize <= 0 {
+		maxSize = 1000
+	}
+
+	c := &RandomCache{
+		items:      make(map[string]*randomItem, maxSize),
+		order:      make([]*randomItem, 0, maxSize),
+		maxSize:    maxSize,
+		defaultTTL: defaultTTL,
+		stopCh:     make(chan struct{}),
+		clock:      realClock{},
+	}
+
+	if defaultTTL > 0 {
+		go c.cleanup()
+	}
+
+	return c
+}
+
+// Get получает значение по ключу
+func (c *RandomCache) Get(key string) ([]byte, bool) {
+	if key == "" {
+		atomic.AddInt64(&c.misses, 1)
+		return nil, false
+	}
+
+	c.mu.Lock()
+	defer c.unlock()
+
+	item, exists := c.items[key]
+	if !exists || c.closed {
+		atomic.AddInt64(&c.misses, 1)
+		return nil, false
+	}
+
+	if item.isExpired(c.clock.Now()) {
+		c.removeItem(item, cache.ReasonExpired)
+		atomic.AddInt64(&c.misses, 1)
+		return nil, false
+	}
+
+	atomic.AddInt64(&c.hits, 1)
+
+	value := make([]byte, len(item.value))
+	copy(value, item.value)
+	return value, true
+}
+
+// Set сохраняет значение с TTL по умолчанию
+func (c *RandomCache) Set(key string, value []byte) error {
+	return c.SetWithTTL(key, value, c.defaultTTL)
+}
+
+// SetWithTTL сохраняет значение с указанным TTL
+func (c *RandomCache) SetWithTTL(key string, value []byte, ttl time.Duration) error {
+	if key == "" {
+		return cache.ErrKeyEmpty
+	}
+
+	c.mu.Lock()
+	defer c.unlock()
+
+	if c.closed {
+		return cache.ErrCacheClosed
+	}
+
+	var expiresAt time.Time
+	if ttl > 0 {
+		expiresAt = c.clock.Now().Add(ttl)
+	} else if c.defaultTTL > 0 {
+		expiresAt = c.clock.Now().Add(c.defaultTTL)
+	}
+
+	valueCopy := make([]byte, len(value))
+	copy(valueCopy, value)
+
+	if existingItem, exists := c.items[key]; exists {
+		c.bytes += int64(len(valueCopy) - len(existingItem.value))
+		existingItem.value = valueCopy
+		existingItem.expiresAt = expiresAt
+		return nil
+	}
+
+	if len(c.items) >= c.maxSize {
+		c.evictRandom()
+	}
+
+	newItem := &randomItem{
+		key:       key,
+		value:     valueCopy,
+		expiresAt: expiresAt,
+		index:     len(c.order),
+	}
+
+	c.items[key] = newItem
+	c.order = append(c.order, newItem)
+	c.bytes += newItem.size()
+	return nil
+}
+
+// GetOrSet возвращает значение по ключу или загружает его через loader при промахе
+func (c *RandomCache) GetOrSet(key string, loader func() ([]byte, error), ttl time.Duration) ([]byte, error) {
+	if key == "" {
+		return nil, cache.ErrKeyEmpty
+	}
+
+	if value, exists := c.Get(key); exists {
+		return value, nil
+	}
+
+	shared, err := c.loads.Do(key, func() ([]byte, error) {
+		value, err := loader()
+		if err != nil {
+			return nil, err
+		}
+		if err := c.SetWithTTL(key, value, ttl); err != nil {
+			return nil, err
+		}
+		return value, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Результат общий для всех ожидающих, поэтому каждый получает свою копию
+	value := make([]byte, len(shared))
+	copy(value, shared)
+	return value, nil
+}
+
+// GetTTL возвращает оставшееся время жизни ключа без обновления статистики
+func (c *RandomCache) GetTTL(key string) (time.Duration, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	item, exists := c.items[key]
+	if !exists || item.isExpired(c.clock.Now()) {
+		return 0, false
+	}
+	return remainingTTL(c.clock.Now(), item.expiresAt)
+}
+
+// Expire устанавливает новое время жизни ключа
+func (c *RandomCache) Expire(key string, ttl time.Duration) bool {
+	c.mu.Lock()
+	defer c.unlock()
+
+	item, exists := c.items[key]
+	if !exists || item.isExpired(c.clock.Now()) {
+		return false
+	}
+
+	item.expiresAt = expirationTime(c.clock.Now(), ttl)
+	return true
+}
+
+// Persist делает ключ бессрочным
+func (c *RandomCache) Persist(key string) bool {
+	return c.Expire(key, 0)
+}
+
+// Delete удаляет ключ из кэша
+func (c *RandomCache) Delete(key string) bool {
+	if key == "" {
+		return false
+	}
+
+	c.mu.Lock()
+	defer c.unlock()
+
+	item, exists := c.items[key]
+	if !exists || c.closed {
+		return false
+	}
+
+	c.removeItem(item, cache.ReasonDeleted)
+	return true
+}
+
+// Keys возвращает все неистекшие ключи в неопределенном порядке
+func (c *RandomCache) Keys() []string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	keys := make([]string, 0, len(c.items))
+	for key, item := range c.items {
+		if !item.isExpired(c.clock.Now()) {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// Len возвращает количество элементов без построения Stats.
+// Может учитывать истекшие элементы, которые еще не удалены.
+func (c *RandomCache) Len() int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return len(c.items)
+}
+
+// Clear очищает весь кэш
+func (c *RandomCache) Clear() {
+	c.mu.Lock()
+	defer c.unlock()
+
+	for key, item := range c.items {
+		c.evictQueue.push(key, item.value, cache.ReasonCleared)
+	}
+
+	c.items = make(map[string]*randomItem)
+	c.order = nil
+	c.bytes = 0
+
+	atomic.StoreInt64(&c.hits, 0)
+	atomic.StoreInt64(&c.misses, 0)
+	atomic.StoreInt64(&c.evictions, 0)
+}
+
+// Stats возвращает статистику кэша
+func (c *RandomCache) Stats() cache.Stats {
+	c.mu.RLock()
+	keys := int64(len(c.items))
+	bytes := c.bytes
+	c.mu.RUnlock()
+
+	stats := cache.Stats{
+		Hits:      atomic.LoadInt64(&c.hits),
+		Misses:    atomic.LoadInt64(&c.misses),
+		Keys:      keys,
+		Evictions: atomic.LoadInt64(&c.evictions),
+		Bytes:     bytes,
+		RawBytes:  bytes, // Значения хранятся без сжатия
+	}
+
+	stats.CalculateHitRate()
+	return stats
+}
+
+// Close корректно завершает работу кэша
+func (c *RandomCache) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.closed {
+		return nil
+	}
+
+	c.closed = true
+	close(c.stopCh)
+	return nil
+}
+
+// SetOnEvict устанавливает колбэк, вызываемый при вытеснении, истечении,
+// удалении и очистке элементов. nil отключает уведомления.
+func (c *RandomCache) SetOnEvict(fn cache.EvictCallback) {
+	c.mu.Lock()
+	c.evictQueue.onEvict = fn
+	c.mu.Unlock()
+}
+
+// unlock снимает блокировку на запись и вызывает колбэк для элементов,
+// удаленных пока она удерживалась
+func (c *RandomCache) unlock() {
+	onEvict, evicted := c.evictQueue.take()
+	c.mu.Unlock()
+	notifyEvicted(onEvict, evicted)
+}
+
+// evictRandom удаляет случайно выбранный элемент
+func (c *RandomCache) evictRandom() {
+	if len(c.order) == 0 {
+		return
+	}
+	c.removeItem(c.order[rand.IntN(len(c.order))], cache.ReasonCapacity)
+	atomic.AddInt64(&c.evictions, 1)
+}
+
+// removeItem полностью удаляет элемент из кэша.
+// На место удаленного элемента в order переносится последний, чтобы удаление было O(1).
+func (c *RandomCache) removeItem(item *randomItem, reason cache.EvictionReason) {
+	delete(c.items, item.key)
+
+	last := c.order[len(c.order)-1]
+	c.order[item.index] = last
+	last.index = item.index
+	c.order[len(c.order)-1] = nil
+	c.order = c.order[:len(c.order)-1]
+
+	c.bytes -= item.size()
+	c.evictQueue.push(item.key, item.value, reason)
+}
+
+// cleanup фоновая очистка истекших элементов
+func (c *RandomCache) cleanup() {
+	ticker := time.NewTicker(1 * time.Minute)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			c.removeExpired()
+		case <-c.stopCh:
+			return
+		}
+	}
+}
+
+// removeExpired удаляет все истекшие элементы
+func (c *RandomCache) removeExpired() {
+	c.mu.Lock()
+	defer c.unlock()
+
+	var expired []*randomItem
+	for _, item := range c.items {
+		if item.isExpired(c.clock.Now()) {
+			expired = append(expired, item)
+		}
+	}
+
+	for _, item := range expired {
+		c.removeItem(item, cache.ReasonExpired)
+	}
+
+	if len(expired) > 0 {
+		atomic.AddInt64(&c.evictions, int64(len(expired)))
+	}
+}
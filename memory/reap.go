@@ -0,0 +1,50 @@
+package memory
+
+import (
+	"sync/atomic"
+
+	cache "github.com/VsRnA/High-Performance-HTTP-Cache"
+)
+
+// reapSampleSize - сколько элементов проверяется на истечение перед вытеснением живого элемента.
+// Полный перебор сделал бы каждую запись в заполненный кэш O(n), поэтому проверяется
+// выборка: порядок обхода map случаен, и при многих истекших элементах хотя бы один
+// почти всегда попадает в нее.
+const reapSampleSize = 20
+
+// reapExpiredSample удаляет истекшие элементы среди reapSampleSize проверенных
+// и возвращает их количество. Вызывается под mu перед вытеснением по емкости,
+// чтобы место освобождали мертвые элементы, а не живые.
+func (c *LRUCache) reapExpiredSample() int {
+	reaped, checked := 0, 0
+	for _, item := range c.items {
+		if checked++; checked > reapSampleSize {
+			break
+		}
+		if hardExpired(c.clock.Now(), item.expiresAt, c.staleWindow) {
+			c.removeItem(item, cache.ReasonExpired)
+			reaped++
+		}
+	}
+
+	atomic.AddInt64(&c.evictions, int64(reaped))
+	return reaped
+}
+
+// reapExpiredSample удаляет истекшие элементы среди reapSampleSize проверенных
+// и возвращает их количество. Вызывается под mu перед вытеснением по емкости.
+func (c *LFUCache) reapExpiredSample() int {
+	reaped, checked := 0, 0
+	for _, item := range c.items {
+		if checked++; checked > reapSampleSize {
+			break
+		}
+		if hardExpired(c.clock.Now(), item.expiresAt, c.staleWindow) {
+			c.removeItem(item, cache.ReasonExpired)
+			reaped++
+		}
+	}
+
+	atomic.AddInt64(&c.evictions, int64(reaped))
+	return reaped
+}
@@ -0,0 +1,76 @@
+package memory
+
+import (
+	"sync/atomic"
+
+	"github.com/VsRnA/High-Performance-HTTP-Cache/internal"
+)
+
+// GetRef получает значение по ключу без защитного копирования: возвращается срез,
+// которым владеет кэш. Вызывающий не должен изменять его содержимое, иначе изменятся
+// данные в кэше и у других читателей. Перезапись или удаление ключа не затрагивает
+// уже полученный срез: кэш заменяет значение, а не изменяет его.
+// Сжатые значения по-прежнему распаковываются в новый срез.
+func (c *LRUCache) GetRef(key string) (value []byte, ok bool) {
+	if m := c.metrics.Load(); m != nil {
+		timer := internal.NewTimer()
+		defer func() { recordGet(m, timer, ok) }()
+	}
+
+	if key == "" {
+		atomic.AddInt64(&c.misses, 1)
+		return nil, false
+	}
+
+	c.mu.Lock()
+	defer c.unlock()
+
+	item := c.lookupLocked(key)
+	if item == nil {
+		return nil, false
+	}
+	return rawValue(item.value, item.compressed), true
+}
+
+// GetRef получает значение по ключу без защитного копирования.
+// Возвращенный срез принадлежит кэшу и не должен изменяться.
+func (c *LFUCache) GetRef(key string) (value []byte, ok bool) {
+	if m := c.metrics.Load(); m != nil {
+		timer := internal.NewTimer()
+		defer func() { recordGet(m, timer, ok) }()
+	}
+
+	if key == "" {
+		atomic.AddInt64(&c.misses, 1)
+		return nil, false
+	}
+
+	c.mu.Lock()
+	defer c.unlock()
+
+	item := c.lookupLocked(key)
+	if item == nil {
+		return nil, false
+	}
+	return rawValue(item.value, item.compressed), true
+}
+
+// GetRef получает значение по ключу без защитного копирования.
+// Возвращенный срез принадлежит кэшу и не должен изменяться.
+func (c *SimpleCache) GetRef(key string) (value []byte, ok bool) {
+	if m := c.metrics.Load(); m != nil {
+		timer := internal.NewTimer()
+		defer func() { recordGet(m, timer, ok) }()
+	}
+
+	item := c.lookup(key)
+	if item == nil {
+		return nil, false
+	}
+	return rawValue(item.value, item.compressed), true
+}
+
+// GetRef получает значение из шарда ключа без защитного копирования
+func (c *ShardedCache) GetRef(key string) ([]byte, bool) {
+	return c.shard(key).GetRef(key)
+}
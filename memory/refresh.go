@@ -0,0 +1,167 @@
+package memory
+
+import (
+	"sync"
+	"time"
+)
+
+// RefreshAhead настраивает упреждающее обновление элементов. Когда при обращении
+// у элемента остается меньше Threshold от исходного TTL, Get возвращает текущее
+// значение и запускает Loader в фоне. Новое значение сохраняется с тем же TTL.
+type RefreshAhead struct {
+	Loader    func(key string) ([]byte, error)
+	Threshold float64 // Доля оставшегося TTL, например 0.2 - обновление за последние 20%
+}
+
+// due проверяет, попал ли элемент с исходным ttl в окно обновления на момент now
+func (r RefreshAhead) due(now, expiresAt time.Time, ttl time.Duration) bool {
+	if r.Loader == nil || ttl <= 0 || expiresAt.IsZero() {
+		return false
+	}
+	return expiresAt.Sub(now) < time.Duration(float64(ttl)*r.Threshold)
+}
+
+// refreshGroup запускает фоновые обновления, не более одного на ключ
+type refreshGroup struct {
+	running sync.Map
+}
+
+// start запускает fn в отдельной горутине, если для key обновление еще не идет
+func (g *refreshGroup) start(key string, fn func()) {
+	if _, running := g.running.LoadOrStore(key, struct{}{}); running {
+		return
+	}
+	go func() {
+		defer g.running.Delete(key)
+		fn()
+	}()
+}
+
+// SetRefreshAhead включает упреждающее обновление. Нулевое значение отключает его.
+func (c *LRUCache) SetRefreshAhead(config RefreshAhead) {
+	c.mu.Lock()
+	c.refreshAhead = config
+	c.mu.Unlock()
+}
+
+// startRefresh запускает фоновую загрузку ключа, вызывается под mu
+func (c *LRUCache) startRefresh(key string, ttl time.Duration) {
+	loader := c.refreshAhead.Loader
+	c.refreshes.start(key, func() {
+		// При ошибке текущее значение остается в кэше до истечения TTL
+		if value, err := loader(key); err == nil {
+			c.refresh(key, value, ttl)
+		}
+	})
+}
+
+// refresh заменяет значение обновленного ключа, сохраняя его теги.
+// Ключ, удаленный во время загрузки, не восстанавливается.
+func (c *LRUCache) refresh(key string, value []byte, ttl time.Duration) {
+	if c.validate(key, value) != nil {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.unlock()
+
+	item, exists := c.items[key]
+	if c.closed || !exists {
+		return
+	}
+
+	tags := item.tags
+	c.setLocked(key, value, ttl)
+	if item, exists := c.items[key]; exists {
+		item.tags = tags
+		c.tags.add(key, tags)
+	}
+}
+
+// SetRefreshAhead включает упреждающее обновление. Нулевое значение отключает его.
+func (c *LFUCache) SetRefreshAhead(config RefreshAhead) {
+	c.mu.Lock()
+	c.refreshAhead = config
+	c.mu.Unlock()
+}
+
+// startRefresh запускает фоновую загрузку ключа, вызывается под mu
+func (c *LFUCache) startRefresh(key string, ttl time.Duration) {
+	loader := c.refreshAhead.Loader
+	c.refreshes.start(key, func() {
+		// При ошибке текущее значение остается в кэше до истечения TTL
+		if value, err := loader(key); err == nil {
+			c.refresh(key, value, ttl)
+		}
+	})
+}
+
+// refresh заменяет значение обновленного ключа, сохраняя его теги.
+// Ключ, удаленный во время загрузки, не восстанавливается.
+func (c *LFUCache) refresh(key string, value []byte, ttl time.Duration) {
+	if c.validate(key, value) != nil {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.unlock()
+
+	item, exists := c.items[key]
+	if c.closed || !exists {
+		return
+	}
+
+	tags := item.tags
+	c.setLocked(key, value, ttl)
+	if item, exists := c.items[key]; exists {
+		item.tags = tags
+		c.tags.add(key, tags)
+	}
+}
+
+// SetRefreshAhead включает упреждающее обновление. Нулевое значение отключает его.
+func (c *SimpleCache) SetRefreshAhead(config RefreshAhead) {
+	c.mu.Lock()
+	c.refreshAhead = config
+	c.mu.Unlock()
+}
+
+// startRefresh запускает фоновую загрузку ключа, вызывается под mu на чтение или запись
+func (c *SimpleCache) startRefresh(key string, ttl time.Duration) {
+	loader := c.refreshAhead.Loader
+	c.refreshes.start(key, func() {
+		// При ошибке текущее значение остается в кэше до истечения TTL
+		if value, err := loader(key); err == nil {
+			c.refresh(key, value, ttl)
+		}
+	})
+}
+
+// refresh заменяет значение обновленного ключа, сохраняя его теги.
+// Ключ, удаленный во время загрузки, не восстанавливается.
+func (c *SimpleCache) refresh(key string, value []byte, ttl time.Duration) {
+	if c.validate(key, value) != nil {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.unlock()
+
+	item, exists := c.items[key]
+	if c.closed || !exists {
+		return
+	}
+
+	tags := item.tags
+	c.setLocked(key, value, ttl)
+	item = c.items[key]
+	item.tags = tags
+	c.tags.add(key, tags)
+}
+
+// SetRefreshAhead включает упреждающее обновление во всех шардах
+func (c *ShardedCache) SetRefreshAhead(config RefreshAhead) {
+	for _, shard := range c.shards {
+		shard.SetRefreshAhead(config)
+	}
+}
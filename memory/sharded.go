@@ -0,0 +1,215 @@
+package memory
+
+import (
+	"maps"
+	"sync"
+	"time"
+
+	cache "github.com/VsRnA/High-Performance-HTTP-Cache"
+	"github.com/VsRnA/High-Performance-HTTP-Cache/internal"
+)
+
+// ShardedCache распределяет ключи по нескольким независимым LRU кэшам.
+// Каждый шард имеет собственную блокировку, что снижает contention при конкурентной записи.
+type ShardedCache struct {
+	shards []*LRUCache
+	hash   internal.HashFunc
+
+	closeMu sync.Mutex
+	closed  bool
+	onClose func(items map[string][]byte)
+}
+
+// NewSharded создает шардированный кэш из shards независимых LRU кэшей по perShardSize элементов.
+// Количество шардов округляется вверх до степени двойки.
+func NewSharded(shards int, perShardSize int) cache.Cache {
+	return NewShardedWithTTL(shards, perShardSize, 0)
+}
+
+// NewShardedWithTTL создает шардированный кэш с TTL по умолчанию
+func NewShardedWithTTL(shards int, perShardSize int, defaultTTL time.Duration) cache.Cache {
+	return newSharded(shards, perShardSize, 0, defaultTTL, internal.Hash64)
+}
+
+// newSharded создает шардированный кэш с лимитами на каждый шард и хеш-функцией hash
+func newSharded(shards int, perShardSize int, perShardBytes int64, defaultTTL time.Duration, hash internal.HashFunc) *ShardedCache {
+	count := internal.NextPowerOfTwo(shards)
+
+	c := &ShardedCache{
+		shards: make([]*LRUCache, count),
+		hash:   hash,
+	}
+	events := newEventHub()
+	for i := range c.shards {
+		c.shards[i] = newLRU(perShardSize, perShardBytes, defaultTTL)
+		c.shards[i].events = events
+	}
+
+	return c
+}
+
+// shard возвращает шард, отвечающий за ключ
+func (c *ShardedCache) shard(key string) *LRUCache {
+	return c.shards[internal.ShardIndexFunc(key, len(c.shards), c.hash)]
+}
+
+// Get получает значение по ключу
+func (c *ShardedCache) Get(key string) ([]byte, bool) {
+	return c.shard(key).Get(key)
+}
+
+// Set сохраняет значение с TTL по умолчанию
+func (c *ShardedCache) Set(key string, value []byte) error {
+	return c.shard(key).Set(key, value)
+}
+
+// SetWithTTL сохраняет значение с указанным TTL
+func (c *ShardedCache) SetWithTTL(key string, value []byte, ttl time.Duration) error {
+	return c.shard(key).SetWithTTL(key, value, ttl)
+}
+
+// GetOrSet возвращает значение по ключу или загружает его через loader при промахе
+func (c *ShardedCache) GetOrSet(key string, loader func() ([]byte, error), ttl time.Duration) ([]byte, error) {
+	return c.shard(key).GetOrSet(key, loader, ttl)
+}
+
+// GetTTL возвращает оставшееся время жизни ключа
+func (c *ShardedCache) GetTTL(key string) (time.Duration, bool) {
+	return c.shard(key).GetTTL(key)
+}
+
+// Expire устанавливает новое время жизни ключа
+func (c *ShardedCache) Expire(key string, ttl time.Duration) bool {
+	return c.shard(key).Expire(key, ttl)
+}
+
+// Persist делает ключ бессрочным. Фоновая очистка не трогает элементы
+// с нулевым expiresAt, поэтому такой ключ может быть удален только явно или вытеснением.
+func (c *ShardedCache) Persist(key string) bool {
+	return c.Expire(key, 0)
+}
+
+// Delete удаляет ключ из кэша
+func (c *ShardedCache) Delete(key string) bool {
+	return c.shard(key).Delete(key)
+}
+
+// Keys возвращает неистекшие ключи всех шардов.
+// Порядок LRU сохраняется только в пределах одного шарда.
+func (c *ShardedCache) Keys() []string {
+	var keys []string
+	for _, shard := range c.shards {
+		keys = append(keys, shard.Keys()...)
+	}
+	return keys
+}
+
+// ForEach последовательно обходит шарды, вызывая fn для каждого неистекшего элемента,
+// пока fn возвращает true. Ограничения на fn те же, что и у LRUCache.ForEach.
+func (c *ShardedCache) ForEach(fn func(key string, value []byte) bool) {
+	stopped := false
+	for _, shard := range c.shards {
+		shard.ForEach(func(key string, value []byte) bool {
+			if !fn(key, value) {
+				stopped = true
+			}
+			return !stopped
+		})
+		if stopped {
+			return
+		}
+	}
+}
+
+// SetCompressionThreshold включает сжатие значений длиннее threshold байт во всех шардах
+func (c *ShardedCache) SetCompressionThreshold(threshold int) {
+	for _, shard := range c.shards {
+		shard.SetCompressionThreshold(threshold)
+	}
+}
+
+// SetOnEvict устанавливает колбэк удаления элементов для всех шардов
+func (c *ShardedCache) SetOnEvict(fn cache.EvictCallback) {
+	for _, shard := range c.shards {
+		shard.SetOnEvict(fn)
+	}
+}
+
+// Len возвращает суммарное количество элементов во всех шардах
+func (c *ShardedCache) Len() int {
+	n := 0
+	for _, shard := range c.shards {
+		n += shard.Len()
+	}
+	return n
+}
+
+// Clear очищает все шарды параллельно и возвращается, когда очищены все,
+// вместе со сброшенной статистикой. Stats, вызванный одновременно с Clear,
+// может застать часть шардов еще не очищенной. Колбэк OnEvict при этом
+// вызывается из нескольких горутин одновременно. Подписчики получают одно
+// событие EventClear после очистки всех шардов.
+func (c *ShardedCache) Clear() {
+	c.parallel(func(_ int, shard *LRUCache) {
+		shard.clearItems(false)
+	})
+	c.shards[0].events.publish(cache.EventClear, "")
+}
+
+// parallel вызывает fn для каждого шарда в отдельной горутине и ждет завершения всех.
+// Шарды не зависят друг от друга, поэтому их блокировки берутся одновременно.
+func (c *ShardedCache) parallel(fn func(i int, shard *LRUCache)) {
+	var wg sync.WaitGroup
+	for i, shard := range c.shards {
+		wg.Add(1)
+		go func(i int, shard *LRUCache) {
+			defer wg.Done()
+			fn(i, shard)
+		}(i, shard)
+	}
+	wg.Wait()
+}
+
+// Stats возвращает суммарную статистику по всем шардам
+func (c *ShardedCache) Stats() cache.Stats {
+	var stats cache.Stats
+	for _, shard := range c.shards {
+		s := shard.Stats()
+		stats.Hits += s.Hits
+		stats.Misses += s.Misses
+		stats.ExpiredMisses += s.ExpiredMisses
+		stats.Keys += s.Keys
+		stats.Evictions += s.Evictions
+		stats.Bytes += s.Bytes
+		stats.RawBytes += s.RawBytes
+	}
+
+	stats.CalculateHitRate()
+	return stats
+}
+
+// Close параллельно завершает работу всех шардов
+func (c *ShardedCache) Close() error {
+	c.closeMu.Lock()
+	onClose := c.onClose
+	first := !c.closed
+	c.closed = true
+	c.closeMu.Unlock()
+
+	// Каждый шард копируется под той же блокировкой, под которой закрывается
+	collect := first && onClose != nil
+	shardItems := make([]map[string][]byte, len(c.shards))
+	c.parallel(func(i int, shard *LRUCache) {
+		shardItems[i], _ = shard.shutdown(collect)
+	})
+
+	items := make(map[string][]byte)
+	for _, part := range shardItems {
+		maps.Copy(items, part)
+	}
+
+	if collect {
+		onClose(items)
+	}
+	return nil
+}
@@ -6,17 +6,44 @@ import (
 	"time"
 
 	cache "github.com/VsRnA/High-Performance-HTTP-Cache"
+	"github.com/VsRnA/High-Performance-HTTP-Cache/internal"
 )
 
 // simpleItem представляет элемент в простом кэше
 type simpleItem struct {
-	value     []byte
-	expiresAt time.Time
+	key        string
+	value      []byte
+	expiresAt  time.Time
+	createdAt  time.Time // Момент записи текущего значения для SetMaxAge
+	ttl        time.Duration
+	rawSize    int64 // Длина ключа плюс длина значения до сжатия
+	compressed bool  // value хранится сжатым flate
+	negative   bool  // Отрицательная запись SetNegative без значения
+	tags       []string
+
+	// Попадания для TopKeys и момент последнего попадания или записи в UnixNano.
+	// Изменяются атомарно, так как Get учитывает попадание под блокировкой на чтение.
+	hits       int64
+	lastAccess int64
+
+	// Хранилище коротких значений, заполняется только при создании элемента
+	inline [inlineValueSize]byte
+}
+
+// size возвращает занимаемый объем: длина ключа плюс длина хранимого значения
+func (item *simpleItem) size() int64 {
+	return int64(len(item.key) + len(item.value))
+}
+
+// touch учитывает попадание в момент now. Вызывается под mu, в том числе на чтение.
+func (item *simpleItem) touch(now time.Time) {
+	atomic.AddInt64(&item.hits, 1)
+	atomic.StoreInt64(&item.lastAccess, now.UnixNano())
 }
 
-// isExpired проверяет истек ли элемент
-func (item *simpleItem) isExpired() bool {
-	return !item.expiresAt.IsZero() && time.Now().After(item.expiresAt)
+// isExpired проверяет истек ли элемент на момент now
+func (item *simpleItem) isExpired(now time.Time) bool {
+	return !item.expiresAt.IsZero() && now.After(item.expiresAt)
 }
 
 // SimpleCache - простейшая реализация кэша без политик вытеснения
@@ -27,15 +54,60 @@ type SimpleCache struct {
 	mu    sync.RWMutex
 	
 	// Конфигурация
-	defaultTTL time.Duration
+	defaultTTL           time.Duration
+	compressionThreshold int           // Значения длиннее порога сжимаются, 0 - без сжатия
+	staleWindow          time.Duration // Окно после истечения TTL для GetStale
+	ttlJitter            ttlJitter     // Случайное отклонение TTL при записи
+	ttlBounds            ttlBounds     // Границы явно запрошенного TTL
+	slidingTTL           bool          // Get продлевает элемент на его исходный TTL
+	maxAge               time.Duration // Предельный возраст элемента независимо от TTL
+	maxValueBytes        int64         // Максимальная длина значения, изменяется атомарно
+	clock                Clock         // Источник времени для TTL и времени обращения
+	maxSize              int           // Максимальное количество элементов NewSimpleBounded, 0 - без ограничения
+
+	// Упреждающее обновление элементов перед истечением TTL
+	refreshAhead RefreshAhead
+	refreshes    refreshGroup
+
+	// Текущий объем хранимых данных, объем до сжатия и число элементов в items.
+	// Изменяются под mu атомарно, чтобы Stats читал их без блокировки.
+	bytes    int64
+	rawBytes int64
+	keyCount int64
+
+	// Обратный индекс тегов SetWithTags, изменяется под mu
+	tags tagIndex
 	
 	// Управление жизненным циклом
-	stopCh chan struct{}
-	closed bool
+	stopCh      chan struct{}
+	cleanupStop chan struct{} // Останавливает текущую горутину очистки, nil - очистка не запущена
+	reaper      reaperState   // Состояние фоновой очистки для Health
+	closed      bool
+	onClose     func(items map[string][]byte) // Получает живые элементы при Close
 	
+	// Дедупликация одновременных загрузок в GetOrSet
+	loads internal.Group
+	
+	// Уведомления об удаленных элементах
+	evictQueue evictionQueue
+
+	// Подписчики на события изменений
+	events *eventHub
+
+	// Детальные метрики SetMetrics, nil - сбор отключен
+	metrics atomic.Pointer[cache.Metrics]
+
+	// Фильтр Блума SetBloomFilter перед картой, nil - отключен.
+	// Изменяется под mu, Get читает его без блокировки.
+	bloom atomic.Pointer[internal.CountingBloom]
+
 	// Статистика
-	hits   int64
-	misses int64
+	hits      int64
+	misses    int64
+	evictions int64
+
+	// Промахи по истекшим, но еще не удаленным элементам, входят в misses
+	expiredMisses int64
 }
 
 // NewSimple создает новый простой кэш без ограничений размера
@@ -49,50 +121,137 @@ func NewSimpleWithTTL(defaultTTL time.Duration) cache.Cache {
 		items:      make(map[string]*simpleItem),
 		defaultTTL: defaultTTL,
 		stopCh:     make(chan struct{}),
+		clock:      realClock{},
+		events:     newEventHub(),
 	}
 
 	if defaultTTL > 0 {
-		go c.cleanup()
+		c.SetCleanupInterval(defaultCleanupInterval)
 	}
 	
 	return c
 }
 
 // Get получает значение по ключу
-func (c *SimpleCache) Get(key string) ([]byte, bool) {
-	if key == "" {
+func (c *SimpleCache) Get(key string) (value []byte, ok bool) {
+	if m := c.metrics.Load(); m != nil {
+		timer := internal.NewTimer()
+		defer func() { recordGet(m, timer, ok) }()
+	}
+
+	if c.bloomRejects(key) {
 		atomic.AddInt64(&c.misses, 1)
 		return nil, false
 	}
+
+	item := c.lookup(key)
+	if item == nil {
+		return nil, false
+	}
+	return decodeValue(item.value, item.compressed), true
+}
+
+// lookup находит неистекший элемент и обновляет статистику.
+// Поиск выполняется под блокировкой на чтение, блокировка на запись берется
+// только для удаления истекшего элемента и скользящего продления. Возвращает nil при промахе.
+func (c *SimpleCache) lookup(key string) *simpleItem {
+	if key == "" {
+		atomic.AddInt64(&c.misses, 1)
+		return nil
+	}
 	
 	c.mu.RLock()
 	item, exists := c.items[key]
+	exists = exists && !c.closed
+	staleWindow := c.staleWindow
+	sliding := c.slidingTTL
+	now := c.clock.Now()
+	if exists && !item.isExpired(now) && !item.negative {
+		item.touch(now)
+		if c.refreshAhead.due(now, item.expiresAt, item.ttl) {
+			c.startRefresh(key, item.ttl)
+		}
+	}
 	c.mu.RUnlock()
 	
-	if !exists {
+	if !exists || item.negative {
 		atomic.AddInt64(&c.misses, 1)
-		return nil, false
+		return nil
 	}
 
-	if item.isExpired() {
+	if item.isExpired(now) {
+		// Элемент в окне устаревания остается доступным для GetStale.
+		// Удаляется только тот же элемент: его могли перезаписать после RUnlock.
+		if hardExpired(now, item.expiresAt, staleWindow) {
+			c.mu.Lock()
+			if current, exists := c.items[key]; exists && current == item {
+				c.removeItem(item, cache.ReasonExpired)
+			}
+			c.unlock()
+		}
+
+		atomic.AddInt64(&c.misses, 1)
+		atomic.AddInt64(&c.expiredMisses, 1)
+		return nil
+	}
+
+	if sliding && item.ttl > 0 {
 		c.mu.Lock()
-		if item, exists := c.items[key]; exists && item.isExpired() {
-			delete(c.items, key)
-			exists = false
+		if current, exists := c.items[key]; exists && current == item {
+			c.slideLocked(item)
 		}
 		c.mu.Unlock()
-		
-		if !exists {
-			atomic.AddInt64(&c.misses, 1)
-			return nil, false
-		}
 	}
 	
 	atomic.AddInt64(&c.hits, 1)
 
-	value := make([]byte, len(item.value))
-	copy(value, item.value)
-	return value, true
+	return item
+}
+
+// lookupLocked находит неистекший элемент под блокировкой на запись, удаляя истекший.
+// Возвращает nil при промахе. Вызывается под mu.
+func (c *SimpleCache) lookupLocked(key string) *simpleItem {
+	item, exists := c.items[key]
+	if !exists || c.closed {
+		atomic.AddInt64(&c.misses, 1)
+		return nil
+	}
+
+	now := c.clock.Now()
+	if item.isExpired(now) {
+		// Элемент в окне устаревания остается доступным для GetStale
+		if hardExpired(now, item.expiresAt, c.staleWindow) {
+			c.removeItem(item, cache.ReasonExpired)
+		}
+		atomic.AddInt64(&c.misses, 1)
+		atomic.AddInt64(&c.expiredMisses, 1)
+		return nil
+	}
+
+	if item.negative {
+		atomic.AddInt64(&c.misses, 1)
+		return nil
+	}
+
+	item.touch(now)
+	if c.slidingTTL && item.ttl > 0 {
+		c.slideLocked(item)
+	}
+	if c.refreshAhead.due(now, item.expiresAt, item.ttl) {
+		c.startRefresh(key, item.ttl)
+	}
+	atomic.AddInt64(&c.hits, 1)
+
+	return item
+}
+
+// getLocked получает копию значения и обновляет статистику, вызывается под mu
+func (c *SimpleCache) getLocked(key string) ([]byte, bool) {
+	item := c.lookupLocked(key)
+	if item == nil {
+		return nil, false
+	}
+	return decodeValue(item.value, item.compressed), true
 }
 
 // Set сохраняет значение с TTL по умолчанию
@@ -101,76 +260,259 @@ func (c *SimpleCache) Set(key string, value []byte) error {
 }
 
 // SetWithTTL сохраняет значение с указанным TTL
-func (c *SimpleCache) SetWithTTL(key string, value []byte, ttl time.Duration) error {
-	if key == "" {
-		return cache.ErrKeyEmpty
+func (c *SimpleCache) SetWithTTL(key string, value []byte, ttl time.Duration) (err error) {
+	if m := c.metrics.Load(); m != nil {
+		timer := internal.NewTimer()
+		defer func() {
+			if err == nil {
+				m.RecordSet(timer.Duration())
+			}
+		}()
+	}
+
+	if err := c.validate(key, value); err != nil {
+		return err
 	}
 	
 	c.mu.Lock()
-	defer c.mu.Unlock()
+	defer c.unlock()
 	
 	if c.closed {
 		return cache.ErrCacheClosed
 	}
 
-	var expiresAt time.Time
-	if ttl > 0 {
-		expiresAt = time.Now().Add(ttl)
-	} else if c.defaultTTL > 0 {
-		expiresAt = time.Now().Add(c.defaultTTL)
+	c.setLocked(key, value, ttl)
+	return nil
+}
+
+// validate проверяет что ключ и значение могут быть сохранены
+func (c *SimpleCache) validate(key string, value []byte) error {
+	if key == "" {
+		return cache.ErrKeyEmpty
+	}
+	if limit := atomic.LoadInt64(&c.maxValueBytes); limit > 0 && int64(len(value)) > limit {
+		return valueTooLarge(len(value), limit)
+	}
+	return nil
+}
+
+// setLocked сохраняет значение с TTL, отклоненным на настроенную долю, вызывается под mu
+func (c *SimpleCache) setLocked(key string, value []byte, ttl time.Duration) {
+	if ttl <= 0 {
+		ttl = c.defaultTTL
+	} else {
+		ttl = c.ttlBounds.clamp(ttl)
 	}
+	c.setExactLocked(key, value, c.ttlJitter.apply(ttl))
+}
 
-	valueCopy := make([]byte, len(value))
-	copy(valueCopy, value)
+// setExactLocked сохраняет значение, вызывается под mu
+func (c *SimpleCache) setExactLocked(key string, value []byte, ttl time.Duration) {
+	if ttl <= 0 {
+		ttl = c.defaultTTL
+	}
+	createdAt := c.clock.Now()
+	expiresAt := capExpiry(expirationTime(createdAt, ttl), createdAt, c.maxAge)
 
-	c.items[key] = &simpleItem{
-		value:     valueCopy,
-		expiresAt: expiresAt,
+	var hits int64
+	if existingItem, exists := c.items[key]; exists {
+		hits = existingItem.hits
+		atomic.AddInt64(&c.bytes, -existingItem.size())
+		atomic.AddInt64(&c.rawBytes, -existingItem.rawSize)
+		c.tags.remove(key, existingItem.tags)
+	} else {
+		c.evictIfFull()
+		atomic.AddInt64(&c.keyCount, 1)
+		c.bloomAdd(key)
 	}
-	
-	return nil
+
+	item := &simpleItem{
+		key:        key,
+		expiresAt:  expiresAt,
+		createdAt:  createdAt,
+		ttl:        ttl,
+		rawSize:    int64(len(key) + len(value)),
+		hits:       hits,
+		lastAccess: createdAt.UnixNano(),
+	}
+	item.value, item.compressed = storeValue(value, c.compressionThreshold, &item.inline)
+
+	c.items[key] = item
+	atomic.AddInt64(&c.bytes, item.size())
+	atomic.AddInt64(&c.rawBytes, item.rawSize)
+	c.events.publish(cache.EventSet, key)
+}
+
+// GetOrSet возвращает значение по ключу или загружает его через loader при промахе
+func (c *SimpleCache) GetOrSet(key string, loader func() ([]byte, error), ttl time.Duration) ([]byte, error) {
+	if key == "" {
+		return nil, cache.ErrKeyEmpty
+	}
+
+	if value, exists := c.Get(key); exists {
+		return value, nil
+	}
+
+	shared, err := c.loads.Do(key, func() ([]byte, error) {
+		value, err := loader()
+		if err != nil {
+			return nil, err
+		}
+		if err := c.SetWithTTL(key, value, ttl); err != nil {
+			return nil, err
+		}
+		return value, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Результат общий для всех ожидающих, поэтому каждый получает свою копию
+	value := make([]byte, len(shared))
+	copy(value, shared)
+	return value, nil
+}
+
+// GetTTL возвращает оставшееся время жизни ключа без обновления статистики
+func (c *SimpleCache) GetTTL(key string) (time.Duration, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	item, exists := c.items[key]
+	if !exists || item.isExpired(c.clock.Now()) {
+		return 0, false
+	}
+	return remainingTTL(c.clock.Now(), item.expiresAt)
+}
+
+// Expire устанавливает новое время жизни ключа
+func (c *SimpleCache) Expire(key string, ttl time.Duration) bool {
+	c.mu.Lock()
+	defer c.unlock()
+
+	item, exists := c.items[key]
+	if !exists || item.isExpired(c.clock.Now()) {
+		return false
+	}
+
+	// Get читает элемент после снятия блокировки, поэтому элемент заменяется, а не изменяется
+	expired := *item
+	expired.expiresAt = capExpiry(expirationTime(c.clock.Now(), ttl), item.createdAt, c.maxAge)
+	expired.ttl = max(ttl, 0)
+	c.items[key] = &expired
+	return true
+}
+
+// Persist делает ключ бессрочным. Фоновая очистка не трогает элементы
+// с нулевым expiresAt, поэтому такой ключ может быть удален только явно или вытеснением.
+func (c *SimpleCache) Persist(key string) bool {
+	return c.Expire(key, 0)
 }
 
 // Delete удаляет ключ из кэша
 func (c *SimpleCache) Delete(key string) bool {
+	if m := c.metrics.Load(); m != nil {
+		timer := internal.NewTimer()
+		defer func() { m.RecordDelete(timer.Duration()) }()
+	}
+
 	if key == "" {
 		return false
 	}
 	
 	c.mu.Lock()
-	defer c.mu.Unlock()
-	
-	_, exists := c.items[key]
+	defer c.unlock()
+
+	if c.closed {
+		return false
+	}
+	return c.deleteLocked(key)
+}
+
+// deleteLocked удаляет ключ, вызывается под mu
+func (c *SimpleCache) deleteLocked(key string) bool {
+	item, exists := c.items[key]
 	if exists {
-		delete(c.items, key)
+		c.removeItem(item, cache.ReasonDeleted)
 		return true
 	}
 	
 	return false
 }
 
+// Keys возвращает все неистекшие ключи в неопределенном порядке
+func (c *SimpleCache) Keys() []string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	keys := make([]string, 0, len(c.items))
+	for key, item := range c.items {
+		if !item.isExpired(c.clock.Now()) && !item.negative {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// ForEach вызывает fn для каждого неистекшего элемента, пока fn возвращает true.
+// fn получает копию значения. Во время обхода удерживается блокировка на чтение,
+// поэтому fn не должна обращаться к этому же кэшу - это приведет к взаимоблокировке.
+func (c *SimpleCache) ForEach(fn func(key string, value []byte) bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	for key, item := range c.items {
+		if item.isExpired(c.clock.Now()) || item.negative {
+			continue
+		}
+
+		if !fn(key, decodeValue(item.value, item.compressed)) {
+			return
+		}
+	}
+}
+
+// Len возвращает количество элементов без построения Stats.
+// Может учитывать истекшие элементы, которые еще не удалены.
+func (c *SimpleCache) Len() int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return len(c.items)
+}
+
 // Clear очищает весь кэш
 func (c *SimpleCache) Clear() {
 	c.mu.Lock()
-	defer c.mu.Unlock()
+	defer c.unlock()
+
+	for key, item := range c.items {
+		c.evictQueue.pushEncoded(key, item.value, item.compressed, cache.ReasonCleared)
+	}
 	
 	c.items = make(map[string]*simpleItem)
+	atomic.StoreInt64(&c.bytes, 0)
+	atomic.StoreInt64(&c.rawBytes, 0)
+	atomic.StoreInt64(&c.keyCount, 0)
+	c.bloomReset()
+	c.tags = nil
+	c.events.publish(cache.EventClear, "")
 
 	atomic.StoreInt64(&c.hits, 0)
 	atomic.StoreInt64(&c.misses, 0)
+	atomic.StoreInt64(&c.expiredMisses, 0)
+	atomic.StoreInt64(&c.evictions, 0)
 }
 
 // Stats возвращает статистику кэша
 func (c *SimpleCache) Stats() cache.Stats {
-	c.mu.RLock()
-	keys := int64(len(c.items))
-	c.mu.RUnlock()
-	
 	stats := cache.Stats{
-		Hits:      atomic.LoadInt64(&c.hits),
-		Misses:    atomic.LoadInt64(&c.misses),
-		Keys:      keys,
-		Evictions: 0, // Простой кэш не делает eviction
+		Hits:          atomic.LoadInt64(&c.hits),
+		Misses:        atomic.LoadInt64(&c.misses),
+		ExpiredMisses: atomic.LoadInt64(&c.expiredMisses),
+		Keys:          atomic.LoadInt64(&c.keyCount),
+		Evictions:     atomic.LoadInt64(&c.evictions), // Ненулевые только у NewSimpleBounded
+		Bytes:         atomic.LoadInt64(&c.bytes),
+		RawBytes:      atomic.LoadInt64(&c.rawBytes),
 	}
 	
 	stats.CalculateHitRate()
@@ -179,27 +521,61 @@ func (c *SimpleCache) Stats() cache.Stats {
 
 // Close корректно завершает работу кэша
 func (c *SimpleCache) Close() error {
-	c.mu.Lock()
-	defer c.mu.Unlock()
-	
-	if c.closed {
-		return nil
+	if items, onClose := c.shutdown(); onClose != nil {
+		onClose(items)
 	}
-	
-	c.closed = true
-	close(c.stopCh)
 	return nil
 }
 
-// cleanup фоновая очистка истекших элементов
-func (c *SimpleCache) cleanup() {
-	ticker := time.NewTicker(1 * time.Minute)
+// removeItem удаляет элемент из кэша
+func (c *SimpleCache) removeItem(item *simpleItem, reason cache.EvictionReason) {
+	delete(c.items, item.key)
+	atomic.AddInt64(&c.keyCount, -1)
+	c.bloomRemove(item.key)
+	c.evictQueue.pushEncoded(item.key, item.value, item.compressed, reason)
+	c.events.publishRemoved(item.key, reason)
+	c.tags.remove(item.key, item.tags)
+	atomic.AddInt64(&c.bytes, -item.size())
+	atomic.AddInt64(&c.rawBytes, -item.rawSize)
+}
+
+// SetCompressionThreshold включает сжатие flate для значений длиннее threshold байт.
+// Действует на последующие записи, 0 отключает сжатие.
+func (c *SimpleCache) SetCompressionThreshold(threshold int) {
+	c.mu.Lock()
+	c.compressionThreshold = threshold
+	c.mu.Unlock()
+}
+
+// SetOnEvict устанавливает колбэк, вызываемый при вытеснении, истечении,
+// удалении и очистке элементов. nil отключает уведомления.
+func (c *SimpleCache) SetOnEvict(fn cache.EvictCallback) {
+	c.mu.Lock()
+	c.evictQueue.onEvict = fn
+	c.mu.Unlock()
+}
+
+// unlock снимает блокировку на запись и вызывает колбэк для элементов,
+// удаленных пока она удерживалась
+func (c *SimpleCache) unlock() {
+	c.reportSizeLocked()
+	onEvict, evicted := c.evictQueue.take()
+	c.mu.Unlock()
+	notifyEvicted(onEvict, evicted)
+}
+
+// cleanup фоновая очистка истекших элементов с периодом interval до закрытия stop или кэша
+func (c *SimpleCache) cleanup(interval time.Duration, stop <-chan struct{}) {
+	ticker := time.NewTicker(interval)
 	defer ticker.Stop()
 	
 	for {
 		select {
 		case <-ticker.C:
 			c.removeExpired()
+			c.reaper.done()
+		case <-stop:
+			return
 		case <-c.stopCh:
 			return
 		}
@@ -209,17 +585,17 @@ func (c *SimpleCache) cleanup() {
 // removeExpired удаляет все истекшие элементы
 func (c *SimpleCache) removeExpired() {
 	c.mu.Lock()
-	defer c.mu.Unlock()
+	defer c.unlock()
 	
 	var expiredKeys []string
 
 	for key, item := range c.items {
-		if item.isExpired() {
+		if hardExpired(c.clock.Now(), item.expiresAt, c.staleWindow) {
 			expiredKeys = append(expiredKeys, key)
 		}
 	}
 
 	for _, key := range expiredKeys {
-		delete(c.items, key)
+		c.removeItem(c.items[key], cache.ReasonExpired)
 	}
 }
@@ -0,0 +1,80 @@
+package memory
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+
+	cache "github.com/VsRnA/High-Performance-HTTP-Cache"
+)
+
+// traceOp - операция трассировки, значимая для воспроизведения
+type traceOp struct {
+	op  string
+	key string
+}
+
+// Simulate воспроизводит трассировку в формате cache.NewTracing на новых кэшах
+// каждой политики размером maxSize и возвращает их итоговую статистику.
+// Чтение (get, get_or_set) при промахе сохраняет ключ, как сделало бы приложение
+// после загрузки из источника, поэтому доля попаданий не зависит от того,
+// какие промахи были у кэша, записавшего трассировку. set, delete и clear
+// воспроизводятся как есть, expire и persist пропускаются: TTL в трассировке не сохраняется.
+func Simulate(trace io.Reader, policies []cache.EvictionPolicy, maxSize int) (map[cache.EvictionPolicy]cache.Stats, error) {
+	ops, err := readTrace(trace)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make(map[cache.EvictionPolicy]cache.Stats, len(policies))
+	for _, policy := range policies {
+		c, err := New(cache.Config{EvictionPolicy: policy, MaxSize: maxSize})
+		if err != nil {
+			return nil, err
+		}
+		replay(c, ops)
+		results[policy] = c.Stats()
+		c.Close()
+	}
+	return results, nil
+}
+
+// readTrace разбирает CSV трассировки, пропуская заголовок
+func readTrace(trace io.Reader) ([]traceOp, error) {
+	r := csv.NewReader(trace)
+	r.FieldsPerRecord = 4
+	r.ReuseRecord = true
+
+	var ops []traceOp
+	for line := 1; ; line++ {
+		record, err := r.Read()
+		if err == io.EOF {
+			return ops, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("трассировка: %w", err)
+		}
+		if line == 1 && record[0] == "timestamp" {
+			continue
+		}
+		ops = append(ops, traceOp{op: record[1], key: record[2]})
+	}
+}
+
+// replay выполняет операции трассировки на кэше c
+func replay(c cache.Cache, ops []traceOp) {
+	for _, op := range ops {
+		switch op.op {
+		case "get", "get_or_set":
+			if _, ok := c.Get(op.key); !ok {
+				c.Set(op.key, nil)
+			}
+		case "set":
+			c.Set(op.key, nil)
+		case "delete":
+			c.Delete(op.key)
+		case "clear":
+			c.Clear()
+		}
+	}
+}
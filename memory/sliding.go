@@ -0,0 +1,42 @@
+package memory
+
+// SetSlidingTTL включает скользящее истечение: каждое успешное чтение через Get
+// и GetOrSet переносит истечение элемента на now + TTL, с которым он был записан.
+// Элемент, который читают чаще его TTL, не истекает. Бессрочные элементы не меняются.
+// Peek, GetTTL и GetStale истечение не продлевают.
+func (c *LRUCache) SetSlidingTTL(enabled bool) {
+	c.mu.Lock()
+	c.slidingTTL = enabled
+	c.mu.Unlock()
+}
+
+// SetSlidingTTL включает скользящее истечение: каждое успешное чтение
+// переносит истечение элемента на now + TTL, с которым он был записан
+func (c *LFUCache) SetSlidingTTL(enabled bool) {
+	c.mu.Lock()
+	c.slidingTTL = enabled
+	c.mu.Unlock()
+}
+
+// SetSlidingTTL включает скользящее истечение: каждое успешное чтение
+// переносит истечение элемента на now + TTL, с которым он был записан
+func (c *SimpleCache) SetSlidingTTL(enabled bool) {
+	c.mu.Lock()
+	c.slidingTTL = enabled
+	c.mu.Unlock()
+}
+
+// SetSlidingTTL включает скользящее истечение во всех шардах
+func (c *ShardedCache) SetSlidingTTL(enabled bool) {
+	for _, shard := range c.shards {
+		shard.SetSlidingTTL(enabled)
+	}
+}
+
+// slideLocked продлевает элемент на его TTL. Get читает элементы после снятия
+// блокировки, поэтому элемент заменяется копией, а не изменяется. Вызывается под mu.
+func (c *SimpleCache) slideLocked(item *simpleItem) {
+	slid := *item
+	slid.expiresAt = capExpiry(expirationTime(c.clock.Now(), item.ttl), item.createdAt, c.maxAge)
+	c.items[item.key] = &slid
+}
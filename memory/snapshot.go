@@ -0,0 +1,344 @@
+package memory
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	cache "github.com/VsRnA/High-Performance-HTTP-Cache"
+)
+
+// Формат снимка: сигнатура, версия, количество записей и записи вида
+// uvarint(len(key)) key uvarint(len(value)) value varint(expiresAt в UnixNano, 0 - бессрочно)
+// varint(ttl в наносекундах). Сохраняется абсолютный момент истечения, поэтому время
+// простоя между сохранением и загрузкой тоже учитывается в TTL. ttl - TTL, с которым
+// элемент был записан: на него скользящее истечение продлевает восстановленный элемент.
+const (
+	snapshotMagic   = "HPCS"
+	snapshotVersion = 1
+)
+
+// snapshotEntry - элемент кэша в снимке
+type snapshotEntry struct {
+	key       string
+	value     []byte
+	expiresAt time.Time
+	ttl       time.Duration // TTL записи элемента для скользящего истечения
+}
+
+// writeSnapshot записывает элементы в w
+func writeSnapshot(w io.Writer, entries []snapshotEntry) error {
+	bw := bufio.NewWriter(w)
+	bw.WriteString(snapshotMagic)
+	bw.WriteByte(snapshotVersion)
+
+	buf := make([]byte, binary.MaxVarintLen64)
+	bw.Write(binary.AppendUvarint(buf[:0], uint64(len(entries))))
+
+	for _, entry := range entries {
+		var expiresAt int64
+		if !entry.expiresAt.IsZero() {
+			expiresAt = entry.expiresAt.UnixNano()
+		}
+
+		bw.Write(binary.AppendUvarint(buf[:0], uint64(len(entry.key))))
+		bw.WriteString(entry.key)
+		bw.Write(binary.AppendUvarint(buf[:0], uint64(len(entry.value))))
+		bw.Write(entry.value)
+		bw.Write(binary.AppendVarint(buf[:0], expiresAt))
+		bw.Write(binary.AppendVarint(buf[:0], int64(entry.ttl)))
+	}
+
+	// bufio.Writer запоминает первую ошибку записи и возвращает ее из Flush
+	return bw.Flush()
+}
+
+// readSnapshot читает элементы из r, пропуская истекшие к моменту now
+func readSnapshot(r io.Reader, now time.Time) ([]snapshotEntry, error) {
+	br := bufio.NewReader(r)
+
+	header := make([]byte, len(snapshotMagic)+1)
+	if _, err := io.ReadFull(br, header); err != nil {
+		return nil, snapshotError(err)
+	}
+	if string(header[:len(snapshotMagic)]) != snapshotMagic || header[len(snapshotMagic)] != snapshotVersion {
+		return nil, fmt.Errorf("%w: неизвестный заголовок %q", cache.ErrInvalidSnapshot, header)
+	}
+
+	count, err := binary.ReadUvarint(br)
+	if err != nil {
+		return nil, snapshotError(err)
+	}
+
+	var entries []snapshotEntry
+	for i := uint64(0); i < count; i++ {
+		key, err := readSnapshotBytes(br)
+		if err != nil {
+			return nil, err
+		}
+		value, err := readSnapshotBytes(br)
+		if err != nil {
+			return nil, err
+		}
+		expiresAt, err := binary.ReadVarint(br)
+		if err != nil {
+			return nil, snapshotError(err)
+		}
+		ttl, err := binary.ReadVarint(br)
+		if err != nil {
+			return nil, snapshotError(err)
+		}
+
+		entry := snapshotEntry{key: string(key), value: value, ttl: time.Duration(ttl)}
+		if expiresAt != 0 {
+			entry.expiresAt = time.Unix(0, expiresAt)
+			if !entry.expiresAt.After(now) {
+				continue
+			}
+		}
+		entries = append(entries, entry)
+	}
+
+	return entries, nil
+}
+
+// readSnapshotBytes читает последовательность байт с префиксом длины
+func readSnapshotBytes(br *bufio.Reader) ([]byte, error) {
+	n, err := binary.ReadUvarint(br)
+	if err != nil {
+		return nil, snapshotError(err)
+	}
+
+	// Память выделяется по мере чтения, чтобы испорченная длина
+	// не приводила к огромной аллокации
+	data, err := io.ReadAll(io.LimitReader(br, int64(n)))
+	if err != nil {
+		return nil, err
+	}
+	if uint64(len(data)) != n {
+		return nil, fmt.Errorf("%w: ожидалось %d байт, прочитано %d", cache.ErrInvalidSnapshot, n, len(data))
+	}
+	return data, nil
+}
+
+// snapshotError оборачивает преждевременный конец данных в ErrInvalidSnapshot.
+// errors.Is находит в результате и ErrInvalidSnapshot, и исходную ошибку.
+func snapshotError(err error) error {
+	if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return fmt.Errorf("%w: %w", cache.ErrInvalidSnapshot, err)
+	}
+	return err
+}
+
+// SaveSnapshot записывает все неистекшие элементы в w.
+// Элементы сохраняются от давно использованных к недавно использованным,
+// поэтому LoadSnapshot восстанавливает порядок LRU.
+func (c *LRUCache) SaveSnapshot(w io.Writer) error {
+	return writeSnapshot(w, c.snapshotEntries())
+}
+
+// snapshotEntries собирает неистекшие элементы под блокировкой на чтение.
+// Несжатые значения не копируются: сохраненные срезы никогда не изменяются на месте.
+func (c *LRUCache) snapshotEntries() []snapshotEntry {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	entries := make([]snapshotEntry, 0, len(c.items))
+	for item := c.tail.prev; item != c.head; item = item.prev {
+		if !item.isExpired(c.clock.Now()) && !item.negative {
+			entries = append(entries, snapshotEntry{
+				key:       item.key,
+				value:     rawValue(item.value, item.compressed),
+				expiresAt: item.expiresAt,
+				ttl:       item.ttl,
+			})
+		}
+	}
+	return entries
+}
+
+// LoadSnapshot загружает элементы из снимка, сохраненного SaveSnapshot.
+// Истекшие к моменту загрузки элементы пропускаются, существующие ключи перезаписываются.
+// При ошибке чтения или валидации кэш не изменяется.
+func (c *LRUCache) LoadSnapshot(r io.Reader) error {
+	entries, err := readSnapshot(r, c.clock.Now())
+	if err != nil {
+		return err
+	}
+	return c.loadEntries(entries)
+}
+
+// loadEntries сохраняет элементы снимка с их исходным моментом истечения и TTL
+func (c *LRUCache) loadEntries(entries []snapshotEntry) error {
+	for _, entry := range entries {
+		if err := c.validate(entry.key, entry.value); err != nil {
+			return err
+		}
+	}
+
+	c.mu.Lock()
+	defer c.unlock()
+
+	if c.closed {
+		return cache.ErrCacheClosed
+	}
+
+	for _, entry := range entries {
+		c.setLocked(entry.key, entry.value, 0)
+		if item, exists := c.items[entry.key]; exists {
+			item.expiresAt = capExpiry(entry.expiresAt, item.createdAt, c.maxAge)
+			item.ttl = entry.ttl
+		}
+	}
+	return nil
+}
+
+// SaveSnapshot записывает все неистекшие элементы в w.
+// Частоты обращений не сохраняются.
+func (c *LFUCache) SaveSnapshot(w io.Writer) error {
+	return writeSnapshot(w, c.snapshotEntries())
+}
+
+// snapshotEntries собирает неистекшие элементы под блокировкой на чтение
+func (c *LFUCache) snapshotEntries() []snapshotEntry {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	entries := make([]snapshotEntry, 0, len(c.items))
+	for _, item := range c.items {
+		if !item.isExpired(c.clock.Now()) && !item.negative {
+			entries = append(entries, snapshotEntry{
+				key:       item.key,
+				value:     rawValue(item.value, item.compressed),
+				expiresAt: item.expiresAt,
+				ttl:       item.ttl,
+			})
+		}
+	}
+	return entries
+}
+
+// LoadSnapshot загружает элементы из снимка, сохраненного SaveSnapshot.
+// Истекшие к моменту загрузки элементы пропускаются, существующие ключи перезаписываются.
+// При ошибке чтения или валидации кэш не изменяется.
+func (c *LFUCache) LoadSnapshot(r io.Reader) error {
+	entries, err := readSnapshot(r, c.clock.Now())
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		if err := c.validate(entry.key, entry.value); err != nil {
+			return err
+		}
+	}
+
+	c.mu.Lock()
+	defer c.unlock()
+
+	if c.closed {
+		return cache.ErrCacheClosed
+	}
+
+	for _, entry := range entries {
+		c.setLocked(entry.key, entry.value, 0)
+		if item, exists := c.items[entry.key]; exists {
+			item.expiresAt = capExpiry(entry.expiresAt, item.createdAt, c.maxAge)
+			item.ttl = entry.ttl
+		}
+	}
+	return nil
+}
+
+// SaveSnapshot записывает все неистекшие элементы в w
+func (c *SimpleCache) SaveSnapshot(w io.Writer) error {
+	return writeSnapshot(w, c.snapshotEntries())
+}
+
+// snapshotEntries собирает неистекшие элементы под блокировкой на чтение
+func (c *SimpleCache) snapshotEntries() []snapshotEntry {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	entries := make([]snapshotEntry, 0, len(c.items))
+	for _, item := range c.items {
+		if !item.isExpired(c.clock.Now()) && !item.negative {
+			entries = append(entries, snapshotEntry{
+				key:       item.key,
+				value:     rawValue(item.value, item.compressed),
+				expiresAt: item.expiresAt,
+				ttl:       item.ttl,
+			})
+		}
+	}
+	return entries
+}
+
+// LoadSnapshot загружает элементы из снимка, сохраненного SaveSnapshot.
+// Истекшие к моменту загрузки элементы пропускаются, существующие ключи перезаписываются.
+// При ошибке чтения или валидации кэш не изменяется.
+func (c *SimpleCache) LoadSnapshot(r io.Reader) error {
+	entries, err := readSnapshot(r, c.clock.Now())
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		if err := c.validate(entry.key, entry.value); err != nil {
+			return err
+		}
+	}
+
+	c.mu.Lock()
+	defer c.unlock()
+
+	if c.closed {
+		return cache.ErrCacheClosed
+	}
+
+	for _, entry := range entries {
+		c.setLocked(entry.key, entry.value, 0)
+		item := c.items[entry.key]
+		item.expiresAt = capExpiry(entry.expiresAt, item.createdAt, c.maxAge)
+		item.ttl = entry.ttl
+	}
+	return nil
+}
+
+// SaveSnapshot записывает неистекшие элементы всех шардов в один снимок
+func (c *ShardedCache) SaveSnapshot(w io.Writer) error {
+	var entries []snapshotEntry
+	for _, shard := range c.shards {
+		entries = append(entries, shard.snapshotEntries()...)
+	}
+	return writeSnapshot(w, entries)
+}
+
+// LoadSnapshot загружает снимок, распределяя элементы по шардам.
+// Снимок не зависит от количества шардов, поэтому его можно загрузить
+// в кэш с другой конфигурацией.
+func (c *ShardedCache) LoadSnapshot(r io.Reader) error {
+	entries, err := readSnapshot(r, c.shards[0].clock.Now())
+	if err != nil {
+		return err
+	}
+
+	batches := make(map[*LRUCache][]snapshotEntry)
+	for _, entry := range entries {
+		shard := c.shard(entry.key)
+		if err := shard.validate(entry.key, entry.value); err != nil {
+			return err
+		}
+		batches[shard] = append(batches[shard], entry)
+	}
+
+	for shard, batch := range batches {
+		if err := shard.loadEntries(batch); err != nil {
+			return err
+		}
+	}
+	return nil
+}
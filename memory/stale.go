@@ -0,0 +1,121 @@
+package memory
+
+import (
+	"sync/atomic"
+	"time"
+
+	cache "github.com/VsRnA/High-Performance-HTTP-Cache"
+)
+
+// SetStaleWindow задает окно после истечения TTL, в течение которого элемент
+// не удаляется и возвращается через GetStale с признаком fresh = false.
+// Get считает такой элемент промахом. 0 отключает окно.
+func (c *LRUCache) SetStaleWindow(window time.Duration) {
+	c.mu.Lock()
+	c.staleWindow = window
+	c.mu.Unlock()
+}
+
+// GetStale получает значение по ключу, включая истекшие элементы в окне устаревания.
+// fresh = false означает, что TTL истек и значение стоит обновить.
+// Устаревшее значение считается попаданием, так как оно возвращается вызывающему.
+func (c *LRUCache) GetStale(key string) (value []byte, fresh bool, exists bool) {
+	c.mu.Lock()
+	defer c.unlock()
+
+	item, exists := c.items[key]
+	if !exists || item.negative {
+		atomic.AddInt64(&c.misses, 1)
+		return nil, false, false
+	}
+
+	if hardExpired(c.clock.Now(), item.expiresAt, c.staleWindow) {
+		c.removeItem(item, cache.ReasonExpired)
+		atomic.AddInt64(&c.misses, 1)
+		return nil, false, false
+	}
+
+	c.moveToHead(item)
+	atomic.AddInt64(&c.hits, 1)
+
+	return decodeValue(item.value, item.compressed), !item.isExpired(c.clock.Now()), true
+}
+
+// SetStaleWindow задает окно после истечения TTL, в течение которого элемент
+// не удаляется и возвращается через GetStale с признаком fresh = false.
+// Get считает такой элемент промахом. 0 отключает окно.
+func (c *LFUCache) SetStaleWindow(window time.Duration) {
+	c.mu.Lock()
+	c.staleWindow = window
+	c.mu.Unlock()
+}
+
+// GetStale получает значение по ключу, включая истекшие элементы в окне устаревания.
+// fresh = false означает, что TTL истек и значение стоит обновить.
+// Устаревшее значение считается попаданием, так как оно возвращается вызывающему.
+func (c *LFUCache) GetStale(key string) (value []byte, fresh bool, exists bool) {
+	c.mu.Lock()
+	defer c.unlock()
+
+	item, exists := c.items[key]
+	if !exists || item.negative {
+		atomic.AddInt64(&c.misses, 1)
+		return nil, false, false
+	}
+
+	if hardExpired(c.clock.Now(), item.expiresAt, c.staleWindow) {
+		c.removeItem(item, cache.ReasonExpired)
+		atomic.AddInt64(&c.misses, 1)
+		return nil, false, false
+	}
+
+	c.touch(item)
+	atomic.AddInt64(&c.hits, 1)
+
+	return decodeValue(item.value, item.compressed), !item.isExpired(c.clock.Now()), true
+}
+
+// SetStaleWindow задает окно после истечения TTL, в течение которого элемент
+// не удаляется и возвращается через GetStale с признаком fresh = false.
+// Get считает такой элемент промахом. 0 отключает окно.
+func (c *SimpleCache) SetStaleWindow(window time.Duration) {
+	c.mu.Lock()
+	c.staleWindow = window
+	c.mu.Unlock()
+}
+
+// GetStale получает значение по ключу, включая истекшие элементы в окне устаревания.
+// fresh = false означает, что TTL истек и значение стоит обновить.
+// Устаревшее значение считается попаданием, так как оно возвращается вызывающему.
+func (c *SimpleCache) GetStale(key string) (value []byte, fresh bool, exists bool) {
+	c.mu.Lock()
+	defer c.unlock()
+
+	item, exists := c.items[key]
+	if !exists || item.negative {
+		atomic.AddInt64(&c.misses, 1)
+		return nil, false, false
+	}
+
+	if hardExpired(c.clock.Now(), item.expiresAt, c.staleWindow) {
+		c.removeItem(item, cache.ReasonExpired)
+		atomic.AddInt64(&c.misses, 1)
+		return nil, false, false
+	}
+
+	atomic.AddInt64(&c.hits, 1)
+
+	return decodeValue(item.value, item.compressed), !item.isExpired(c.clock.Now()), true
+}
+
+// SetStaleWindow задает окно устаревания для всех шардов
+func (c *ShardedCache) SetStaleWindow(window time.Duration) {
+	for _, shard := range c.shards {
+		shard.SetStaleWindow(window)
+	}
+}
+
+// GetStale получает значение по ключу, включая истекшие элементы в окне устаревания
+func (c *ShardedCache) GetStale(key string) (value []byte, fresh bool, exists bool) {
+	return c.shard(key).GetStale(key)
+}
@@ -0,0 +1,126 @@
+package memory
+
+import (
+	"context"
+
+	"github.com/VsRnA/High-Performance-HTTP-Cache/internal"
+)
+
+const (
+	// streamBatchSize - примерное количество ключей в одной партиции StreamKeys
+	streamBatchSize = 1 << 14
+
+	// streamBufferSize - емкость канала StreamKeys
+	streamBufferSize = 256
+)
+
+// keyPartition добавляет к keys неистекшие ключи партиции partition из partitions
+// и возвращает результат. Выполняется под блокировкой кэша на чтение.
+type keyPartition func(partition, partitions uint64, keys []string) []string
+
+// inPartition сообщает, относится ли ключ к партиции. Партиция ключа определяется
+// хешем, поэтому каждый ключ попадает ровно в одну партицию.
+func inPartition(key string, partition, partitions uint64) bool {
+	return partitions == 1 || internal.Hash64(key)%partitions == partition
+}
+
+// streamKeys отправляет ключи в канал по партициям, пока они не кончатся или ctx не будет отменен.
+// Число партиций выбирается по размеру кэша size на момент вызова так, чтобы
+// в партиции было около streamBatchSize ключей. Блокировка удерживается только
+// на время сбора партиции, но не во время отправки в канал.
+func streamKeys(ctx context.Context, size int, collect keyPartition) <-chan string {
+	out := make(chan string, streamBufferSize)
+	partitions := uint64(max((size+streamBatchSize-1)/streamBatchSize, 1))
+
+	go func() {
+		defer close(out)
+
+		var batch []string
+		for partition := uint64(0); partition < partitions; partition++ {
+			if ctx.Err() != nil {
+				return
+			}
+			batch = collect(partition, partitions, batch[:0])
+			for _, key := range batch {
+				select {
+				case out <- key:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+	return out
+}
+
+// StreamKeys отправляет неистекшие ключи в буферизованный канал, не собирая их
+// в один срез. Ключи собираются партициями примерно по streamBatchSize: на каждую
+// партицию кэш обходится под блокировкой на чтение, которая снимается перед отправкой.
+// Поэтому результат - нечеткий снимок: ключи, удаленные во время обхода, могут
+// попасть в канал, а добавленные - не попасть, но каждый ключ отправляется не больше одного раза.
+// Канал закрывается после последнего ключа или отмены ctx. Если чтение прекращается
+// раньше, ctx нужно отменить, иначе горутина обхода останется ждать.
+func (c *LRUCache) StreamKeys(ctx context.Context) <-chan string {
+	return streamKeys(ctx, c.Len(), func(partition, partitions uint64, keys []string) []string {
+		c.mu.RLock()
+		defer c.mu.RUnlock()
+
+		for key, item := range c.items {
+			if !item.isExpired(c.clock.Now()) && !item.negative && inPartition(key, partition, partitions) {
+				keys = append(keys, key)
+			}
+		}
+		return keys
+	})
+}
+
+// StreamKeys отправляет неистекшие ключи в канал партициями, см. LRUCache.StreamKeys
+func (c *LFUCache) StreamKeys(ctx context.Context) <-chan string {
+	return streamKeys(ctx, c.Len(), func(partition, partitions uint64, keys []string) []string {
+		c.mu.RLock()
+		defer c.mu.RUnlock()
+
+		for key, item := range c.items {
+			if !item.isExpired(c.clock.Now()) && !item.negative && inPartition(key, partition, partitions) {
+				keys = append(keys, key)
+			}
+		}
+		return keys
+	})
+}
+
+// StreamKeys отправляет неистекшие ключи в канал партициями, см. LRUCache.StreamKeys
+func (c *SimpleCache) StreamKeys(ctx context.Context) <-chan string {
+	return streamKeys(ctx, c.Len(), func(partition, partitions uint64, keys []string) []string {
+		c.mu.RLock()
+		defer c.mu.RUnlock()
+
+		for key, item := range c.items {
+			if !item.isExpired(c.clock.Now()) && !item.negative && inPartition(key, partition, partitions) {
+				keys = append(keys, key)
+			}
+		}
+		return keys
+	})
+}
+
+// StreamKeys последовательно отправляет ключи всех шардов в один канал
+func (c *ShardedCache) StreamKeys(ctx context.Context) <-chan string {
+	out := make(chan string, streamBufferSize)
+
+	go func() {
+		defer close(out)
+
+		for _, shard := range c.shards {
+			// При отмене ctx горутина шарда завершится сама, не дожидаясь чтения
+			for key := range shard.StreamKeys(ctx) {
+				select {
+				case out <- key:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+	return out
+}
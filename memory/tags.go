@@ -0,0 +1,201 @@
+package memory
+
+import (
+	"time"
+
+	cache "github.com/VsRnA/High-Performance-HTTP-Cache"
+)
+
+// tagIndex - обратный индекс от тега к множеству ключей с этим тегом.
+// Изменяется под mu кэша: ключи добавляются в SetWithTags и удаляются
+// в removeItem, поэтому удаление, истечение и вытеснение не оставляют записей.
+type tagIndex map[string]map[string]struct{}
+
+// add связывает ключ с тегами
+func (idx *tagIndex) add(key string, tags []string) {
+	if len(tags) == 0 {
+		return
+	}
+	if *idx == nil {
+		*idx = make(tagIndex)
+	}
+	for _, tag := range tags {
+		keys := (*idx)[tag]
+		if keys == nil {
+			keys = make(map[string]struct{})
+			(*idx)[tag] = keys
+		}
+		keys[key] = struct{}{}
+	}
+}
+
+// remove удаляет связи ключа с тегами вместе с опустевшими тегами
+func (idx tagIndex) remove(key string, tags []string) {
+	for _, tag := range tags {
+		keys := idx[tag]
+		delete(keys, key)
+		if len(keys) == 0 {
+			delete(idx, tag)
+		}
+	}
+}
+
+// normalizeTags убирает пустые и повторяющиеся теги
+func normalizeTags(tags []string) []string {
+	var result []string
+	for _, tag := range tags {
+		if tag == "" || containsTag(result, tag) {
+			continue
+		}
+		result = append(result, tag)
+	}
+	return result
+}
+
+// containsTag проверяет наличие тега в списке. Тегов у элемента обычно немного,
+// поэтому линейный поиск дешевле отдельного множества.
+func containsTag(tags []string, tag string) bool {
+	for _, t := range tags {
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
+
+// SetWithTags сохраняет значение с указанным TTL и связывает ключ с тегами.
+// Повторная запись ключа заменяет его теги, запись через Set снимает их.
+func (c *LRUCache) SetWithTags(key string, value []byte, ttl time.Duration, tags ...string) error {
+	if err := c.validate(key, value); err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	defer c.unlock()
+
+	if c.closed {
+		return cache.ErrCacheClosed
+	}
+
+	c.setLocked(key, value, ttl)
+	if item, exists := c.items[key]; exists {
+		item.tags = normalizeTags(tags)
+		c.tags.add(key, item.tags)
+	}
+	return nil
+}
+
+// InvalidateTag удаляет все ключи с указанным тегом за одну блокировку
+// и возвращает количество удаленных. Истекшие ключи удаляются, но не учитываются.
+func (c *LRUCache) InvalidateTag(tag string) int {
+	c.mu.Lock()
+	defer c.unlock()
+
+	deleted := 0
+	for key := range c.tags[tag] {
+		item := c.items[key]
+		if item.isExpired(c.clock.Now()) {
+			c.removeItem(item, cache.ReasonExpired)
+			continue
+		}
+		c.removeItem(item, cache.ReasonDeleted)
+		deleted++
+	}
+	return deleted
+}
+
+// SetWithTags сохраняет значение с указанным TTL и связывает ключ с тегами.
+// Повторная запись ключа заменяет его теги, запись через Set снимает их.
+func (c *LFUCache) SetWithTags(key string, value []byte, ttl time.Duration, tags ...string) error {
+	if err := c.validate(key, value); err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	defer c.unlock()
+
+	if c.closed {
+		return cache.ErrCacheClosed
+	}
+
+	c.setLocked(key, value, ttl)
+	if item, exists := c.items[key]; exists {
+		item.tags = normalizeTags(tags)
+		c.tags.add(key, item.tags)
+	}
+	return nil
+}
+
+// InvalidateTag удаляет все ключи с указанным тегом за одну блокировку
+// и возвращает количество удаленных. Истекшие ключи удаляются, но не учитываются.
+func (c *LFUCache) InvalidateTag(tag string) int {
+	c.mu.Lock()
+	defer c.unlock()
+
+	deleted := 0
+	for key := range c.tags[tag] {
+		item := c.items[key]
+		if item.isExpired(c.clock.Now()) {
+			c.removeItem(item, cache.ReasonExpired)
+			continue
+		}
+		c.removeItem(item, cache.ReasonDeleted)
+		deleted++
+	}
+	return deleted
+}
+
+// SetWithTags сохраняет значение с указанным TTL и связывает ключ с тегами.
+// Повторная запись ключа заменяет его теги, запись через Set снимает их.
+func (c *SimpleCache) SetWithTags(key string, value []byte, ttl time.Duration, tags ...string) error {
+	if err := c.validate(key, value); err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	defer c.unlock()
+
+	if c.closed {
+		return cache.ErrCacheClosed
+	}
+
+	c.setLocked(key, value, ttl)
+	item := c.items[key]
+	item.tags = normalizeTags(tags)
+	c.tags.add(key, item.tags)
+	return nil
+}
+
+// InvalidateTag удаляет все ключи с указанным тегом за одну блокировку
+// и возвращает количество удаленных. Истекшие ключи удаляются, но не учитываются.
+func (c *SimpleCache) InvalidateTag(tag string) int {
+	c.mu.Lock()
+	defer c.unlock()
+
+	deleted := 0
+	for key := range c.tags[tag] {
+		item := c.items[key]
+		if item.isExpired(c.clock.Now()) {
+			c.removeItem(item, cache.ReasonExpired)
+			continue
+		}
+		c.removeItem(item, cache.ReasonDeleted)
+		deleted++
+	}
+	return deleted
+}
+
+// SetWithTags сохраняет значение с тегами в шарде ключа
+func (c *ShardedCache) SetWithTags(key string, value []byte, ttl time.Duration, tags ...string) error {
+	return c.shard(key).SetWithTags(key, value, ttl, tags...)
+}
+
+// InvalidateTag удаляет ключи с указанным тегом во всех шардах.
+// Каждый шард блокируется отдельно, поэтому удаление не атомарно для кэша целиком.
+func (c *ShardedCache) InvalidateTag(tag string) int {
+	deleted := 0
+	for _, shard := range c.shards {
+		deleted += shard.InvalidateTag(tag)
+	}
+	return deleted
+}
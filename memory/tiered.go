@@ -0,0 +1,365 @@
+package memory
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	cache "github.com/VsRnA/High-Performance-HTTP-Cache"
+	"github.com/VsRnA/High-Performance-HTTP-Cache/internal"
+)
+
+// diskEntry описывает значение, вытесненное на диск
+type diskEntry struct {
+	expiresAt time.Time
+	size      int64 // Длина ключа плюс длина значения
+}
+
+// TieredStats дополняет статистику кэша разбивкой попаданий по уровням
+type TieredStats struct {
+	cache.Stats
+	MemoryHits int64 `json:"memory_hits"` // Попадания в памяти
+	DiskHits   int64 `json:"disk_hits"`   // Попадания на диске
+	DiskKeys   int64 `json:"disk_keys"`   // Ключей на диске
+}
+
+// TieredCache - двухуровневый кэш: LRU в памяти и файлы на диске.
+// Элементы, вытесненные из памяти по емкости, записываются в каталог в файлы
+// с именем по internal.Hash64 ключа. Промах в памяти проверяет диск и при попадании
+// переносит значение обратно в память.
+//
+// Индекс диска хранится в памяти, поэтому файлы не переживают перезапуск процесса
+// и удаляются при Clear и Close. При совпадении хешей двух ключей на диске остается
+// последний записанный. Все операции выполняются под одной блокировкой.
+type TieredCache struct {
+	mu  sync.Mutex
+	hot *LRUCache
+	dir string
+
+	// Индекс диска: ключ -> описание и хеш -> ключ для обнаружения коллизий
+	disk      map[string]diskEntry
+	files     map[uint64]string
+	diskBytes int64
+
+	closed bool
+
+	// Источник времени для TTL дискового уровня, изменяется под mu
+	clock Clock
+
+	// Дедупликация одновременных загрузок в GetOrSet
+	loads internal.Group
+
+	// Статистика (atomic для производительности)
+	memoryHits int64
+	diskHits   int64
+	misses     int64
+	lost       int64 // Элементы, потерянные из-за ошибок диска и коллизий хешей
+}
+
+// NewTiered создает двухуровневый кэш с hotSize элементами в памяти
+// и неограниченным дисковым уровнем в каталоге diskDir
+func NewTiered(hotSize int, diskDir string) cache.Cache {
+	t := &TieredCache{
+		hot:   newLRU(hotSize, 0, 0),
+		dir:   diskDir,
+		disk:  make(map[string]diskEntry),
+		files: make(map[uint64]string),
+		clock: realClock{},
+	}
+	// Ошибка создания каталога проявится при записи и будет учтена как потеря элемента
+	os.MkdirAll(diskDir, 0o755)
+
+	// Вытеснение по емкости происходит только внутри записей в hot,
+	// которые выполняются под t.mu, поэтому spill не захватывает блокировку
+	t.hot.spill = t.spillLocked
+	return t
+}
+
+// Get получает значение из памяти, а при промахе - с диска с переносом в память
+func (t *TieredCache) Get(key string) ([]byte, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return t.getLocked(key)
+}
+
+// getLocked получает значение и обновляет статистику, вызывается под mu
+func (t *TieredCache) getLocked(key string) ([]byte, bool) {
+	if value, exists := t.hot.Peek(key); exists {
+		t.hot.Touch(key, 0)
+		atomic.AddInt64(&t.memoryHits, 1)
+		return value, true
+	}
+
+	entry, exists := t.disk[key]
+	if !exists || t.closed {
+		atomic.AddInt64(&t.misses, 1)
+		return nil, false
+	}
+	if hardExpired(t.clock.Now(), entry.expiresAt, 0) {
+		t.removeDiskLocked(key)
+		atomic.AddInt64(&t.misses, 1)
+		return nil, false
+	}
+
+	value, err := os.ReadFile(t.path(key))
+	t.removeDiskLocked(key)
+	if err != nil {
+		atomic.AddInt64(&t.lost, 1)
+		atomic.AddInt64(&t.misses, 1)
+		return nil, false
+	}
+
+	// Перенос в память может вытеснить на диск другой элемент
+	var ttl time.Duration
+	if !entry.expiresAt.IsZero() {
+		ttl = max(entry.expiresAt.Sub(t.clock.Now()), time.Nanosecond)
+	}
+	t.hot.SetWithExactTTL(key, value, ttl)
+
+	atomic.AddInt64(&t.diskHits, 1)
+	return value, true
+}
+
+// Set сохраняет значение без ограничения времени жизни
+func (t *TieredCache) Set(key string, value []byte) error {
+	return t.SetWithTTL(key, value, 0)
+}
+
+// SetWithTTL сохраняет значение в памяти, удаляя устаревшую копию с диска
+func (t *TieredCache) SetWithTTL(key string, value []byte, ttl time.Duration) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.closed {
+		return cache.ErrCacheClosed
+	}
+	if err := t.hot.SetWithTTL(key, value, ttl); err != nil {
+		return err
+	}
+	t.removeDiskLocked(key)
+	return nil
+}
+
+// GetOrSet возвращает значение по ключу или загружает его через loader при промахе
+func (t *TieredCache) GetOrSet(key string, loader func() ([]byte, error), ttl time.Duration) ([]byte, error) {
+	if key == "" {
+		return nil, cache.ErrKeyEmpty
+	}
+
+	if value, exists := t.Get(key); exists {
+		return value, nil
+	}
+
+	shared, err := t.loads.Do(key, func() ([]byte, error) {
+		value, err := loader()
+		if err != nil {
+			return nil, err
+		}
+		if err := t.SetWithTTL(key, value, ttl); err != nil {
+			return nil, err
+		}
+		return value, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Результат общий для всех ожидающих, поэтому каждый получает свою копию
+	value := make([]byte, len(shared))
+	copy(value, shared)
+	return value, nil
+}
+
+// GetTTL возвращает оставшееся время жизни ключа на любом уровне
+func (t *TieredCache) GetTTL(key string) (time.Duration, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if ttl, exists := t.hot.GetTTL(key); exists {
+		return ttl, true
+	}
+	if entry, exists := t.disk[key]; exists {
+		return remainingTTL(t.clock.Now(), entry.expiresAt)
+	}
+	return 0, false
+}
+
+// Expire устанавливает новое время жизни ключа на любом уровне
+func (t *TieredCache) Expire(key string, ttl time.Duration) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.hot.Expire(key, ttl) {
+		return true
+	}
+
+	entry, exists := t.disk[key]
+	if !exists || hardExpired(t.clock.Now(), entry.expiresAt, 0) {
+		return false
+	}
+	entry.expiresAt = expirationTime(t.clock.Now(), ttl)
+	t.disk[key] = entry
+	return true
+}
+
+// Persist снимает ограничение времени жизни с ключа
+func (t *TieredCache) Persist(key string) bool {
+	return t.Expire(key, 0)
+}
+
+// Delete удаляет ключ с обоих уровней
+func (t *TieredCache) Delete(key string) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.closed {
+		return false
+	}
+	deleted := t.hot.Delete(key)
+	if _, exists := t.disk[key]; exists {
+		t.removeDiskLocked(key)
+		deleted = true
+	}
+	return deleted
+}
+
+// Keys возвращает неистекшие ключи обоих уровней: сначала из памяти, затем с диска
+func (t *TieredCache) Keys() []string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	keys := t.hot.Keys()
+	for key, entry := range t.disk {
+		if !hardExpired(t.clock.Now(), entry.expiresAt, 0) {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// Len возвращает количество элементов на обоих уровнях.
+// Истекшие элементы на диске удаляются лениво и могут учитываться.
+func (t *TieredCache) Len() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return t.hot.Len() + len(t.disk)
+}
+
+// Clear очищает память и удаляет файлы дискового уровня
+func (t *TieredCache) Clear() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.hot.Clear()
+	t.clearDiskLocked()
+
+	atomic.StoreInt64(&t.memoryHits, 0)
+	atomic.StoreInt64(&t.diskHits, 0)
+	atomic.StoreInt64(&t.misses, 0)
+	atomic.StoreInt64(&t.lost, 0)
+}
+
+// Stats возвращает суммарную статистику обоих уровней.
+// Вытеснение из памяти на диск не считается, Evictions учитывает только
+// элементы, потерянные из-за ошибок диска и коллизий хешей.
+func (t *TieredCache) Stats() cache.Stats {
+	return t.TieredStats().Stats
+}
+
+// TieredStats возвращает статистику с разбивкой попаданий по уровням
+func (t *TieredCache) TieredStats() TieredStats {
+	t.mu.Lock()
+	hot := t.hot.Stats()
+	diskKeys := int64(len(t.disk))
+	diskBytes := t.diskBytes
+	t.mu.Unlock()
+
+	stats := TieredStats{
+		Stats: cache.Stats{
+			Hits:      atomic.LoadInt64(&t.memoryHits) + atomic.LoadInt64(&t.diskHits),
+			Misses:    atomic.LoadInt64(&t.misses),
+			Keys:      hot.Keys + diskKeys,
+			Evictions: atomic.LoadInt64(&t.lost),
+			Bytes:     hot.Bytes + diskBytes,
+			RawBytes:  hot.RawBytes + diskBytes,
+		},
+		MemoryHits: atomic.LoadInt64(&t.memoryHits),
+		DiskHits:   atomic.LoadInt64(&t.diskHits),
+		DiskKeys:   diskKeys,
+	}
+
+	stats.CalculateHitRate()
+	return stats
+}
+
+// Close завершает работу кэша и удаляет файлы дискового уровня
+func (t *TieredCache) Close() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.closed {
+		return nil
+	}
+	t.closed = true
+	t.clearDiskLocked()
+	return t.hot.Close()
+}
+
+// spillLocked записывает вытесненный из памяти элемент на диск, вызывается под mu
+func (t *TieredCache) spillLocked(key string, value []byte, expiresAt time.Time) {
+	if t.closed {
+		return
+	}
+
+	hash := internal.Hash64(key)
+	if other, exists := t.files[hash]; exists && other != key {
+		t.removeDiskLocked(other)
+		atomic.AddInt64(&t.lost, 1)
+	}
+
+	if err := os.WriteFile(t.path(key), value, 0o644); err != nil {
+		t.removeDiskLocked(key)
+		atomic.AddInt64(&t.lost, 1)
+		return
+	}
+
+	size := int64(len(key) + len(value))
+	if entry, exists := t.disk[key]; exists {
+		t.diskBytes -= entry.size
+	}
+	t.disk[key] = diskEntry{expiresAt: expiresAt, size: size}
+	t.files[hash] = key
+	t.diskBytes += size
+}
+
+// removeDiskLocked удаляет ключ из индекса и его файл, вызывается под mu
+func (t *TieredCache) removeDiskLocked(key string) {
+	entry, exists := t.disk[key]
+	if !exists {
+		return
+	}
+	delete(t.disk, key)
+	delete(t.files, internal.Hash64(key))
+	t.diskBytes -= entry.size
+	os.Remove(t.path(key))
+}
+
+// clearDiskLocked удаляет все файлы дискового уровня, вызывается под mu
+func (t *TieredCache) clearDiskLocked() {
+	for key := range t.disk {
+		os.Remove(t.path(key))
+	}
+	t.disk = make(map[string]diskEntry)
+	t.files = make(map[uint64]string)
+	t.diskBytes = 0
+}
+
+// path возвращает путь к файлу значения ключа
+func (t *TieredCache) path(key string) string {
+	return filepath.Join(t.dir, strconv.FormatUint(internal.Hash64(key), 16))
+}
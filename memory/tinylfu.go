@@ -0,0 +1,437 @@
+package memory
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+
+	cache "github.com/VsRnA/High-Performance-HTTP-Cache"
+	"github.com/VsRnA/High-Performance-HTTP-Cache/internal"
+)
+
+// tinyLFUItem представляет элемент в окне или основной области TinyLFU кэша
+type tinyLFUItem struct {
+	key        string
+	value      []byte
+	expiresAt  time.Time
+	list       *tinyLFUList
+	prev, next *tinyLFUItem
+}
+
+// size возвращает объем ключа и значения в байтах
+func (item *tinyLFUItem) size() int64 {
+	return int64(len(item.key) + len(item.value))
+}
+
+// isExpired проверяет истек ли элемент на момент now
+func (item *tinyLFUItem) isExpired(now time.Time) bool {
+	return !item.expiresAt.IsZero() && now.After(item.expiresAt)
+}
+
+// tinyLFUList - LRU список с ограничителем, начало списка - самый недавний элемент
+type tinyLFUList struct {
+	root tinyLFUItem
+	len  int
+}
+
+// init подготавливает пустой список
+func (l *tinyLFUList) init() {
+	l.root.next = &l.root
+	l.root.prev = &l.root
+	l.len = 0
+}
+
+// pushFront добавляет элемент в начало списка
+func (l *tinyLFUList) pushFront(item *tinyLFUItem) {
+	item.list = l
+	item.prev = &l.root
+	item.next = l.root.next
+	l.root.next.prev = item
+	l.root.next = item
+	l.len++
+}
+
+// remove удаляет элемент из списка
+func (l *tinyLFUList) remove(item *tinyLFUItem) {
+	item.prev.next = item.next
+	item.next.prev = item.prev
+	item.prev, item.next, item.list = nil, nil, nil
+	l.len--
+}
+
+// back возвращает самый давний элемент или nil для пустого списка
+func (l *tinyLFUList) back() *tinyLFUItem {
+	if l.len == 0 {
+		return nil
+	}
+	return l.root.prev
+}
+
+// TinyLFUCache реализует W-TinyLFU кэш.
+// Новые элементы попадают в небольшое LRU окно. Вытесненный из окна кандидат
+// допускается в основную LRU область только если оценка его частоты
+// в count-min sketch выше, чем у элемента, который пришлось бы вытеснить.
+type TinyLFUCache struct {
+	// Основные данные
+	items  map[string]*tinyLFUItem
+	window tinyLFUList // Окно для новых элементов (~1% емкости)
+	main   tinyLFUList // Основная область
+	sketch *internal.CountMinSketch
+	mu     sync.RWMutex
+
+	// Конфигурация
+	maxSize    int
+	windowSize int
+	defaultTTL time.Duration
+
+	// Объем хранимых ключей и значений в байтах, изменяется под mu
+	bytes int64
+
+	// Источник времени для TTL, изменяется под mu
+	clock Clock
+
+	// Управление жизненным циклом
+	stopCh chan struct{}
+	closed bool
+
+	// Уведомления об удаленных элементах
+	evictQueue evictionQueue
+
+	// Дедупликация одновременных загрузок в GetOrSet
+	loads internal.Group
+
+	// Статистика
+	hits      int64
+	misses    int64
+	evictions int64
+}
+
+// NewTinyLFU создает новый W-TinyLFU кэш с указанным максимальным размером.
+// Счетчики частоты стареют каждые 10*maxSize обращений.
+func NewTinyLFU(maxSize int) cache.Cache {
+	return NewTinyLFUWithResetInterval(maxSize, 0)
+}
+
+// NewTinyLFUWithResetInterval создает W-TinyLFU кэш, у которого счетчики частоты
+// делятся пополам каждые resetInterval обращений. 0 означает 10*maxSize.
+func NewTinyLFUWithResetInterval(maxSize int, resetInterval int) cache.Cache {
+	if maxSize <= 0 {
+		maxSize = 1000
+	}
+	if resetInterval <= 0 {
+		resetInterval = 10 * maxSize
+	}
+
+	c := &TinyLFUCache{
+		items:      make(map[string]*tinyLFUItem, maxSize),
+		sketch:     internal.NewCountMinSketch(maxSize, resetInterval),
+		maxSize:    maxSize,
+		windowSize: max(1, maxSize/100),
+		stopCh:     make(chan struct{}),
+		clock:      realClock{},
+	}
+	c.window.init()
+	c.main.init()
+
+	return c
+}
+
+// Get получает значение по ключу. Каждое обращение учитывается в sketch.
+func (c *TinyLFUCache) Get(key string) ([]byte, bool) {
+	if key == "" {
+		atomic.AddInt64(&c.misses, 1)
+		return nil, false
+	}
+
+	c.mu.Lock()
+	defer c.unlock()
+
+	c.sketch.Increment(key)
+
+	item, exists := c.items[key]
+	if !exists || c.closed {
+		atomic.AddInt64(&c.misses, 1)
+		return nil, false
+	}
+
+	if item.isExpired(c.clock.Now()) {
+		c.removeItem(item, cache.ReasonExpired)
+		atomic.AddInt64(&c.misses, 1)
+		return nil, false
+	}
+
+	list := item.list
+	list.remove(item)
+	list.pushFront(item)
+
+	atomic.AddInt64(&c.hits, 1)
+
+	value := make([]byte, len(item.value))
+	copy(value, item.value)
+	return value, true
+}
+
+// Set сохраняет значение с TTL по умолчанию
+func (c *TinyLFUCache) Set(key string, value []byte) error {
+	return c.SetWithTTL(key, value, c.defaultTTL)
+}
+
+// SetWithTTL сохраняет значение с указанным TTL
+func (c *TinyLFUCache) SetWithTTL(key string, value []byte, ttl time.Duration) error {
+	if key == "" {
+		return cache.ErrKeyEmpty
+	}
+
+	c.mu.Lock()
+	defer c.unlock()
+
+	if c.closed {
+		return cache.ErrCacheClosed
+	}
+
+	var expiresAt time.Time
+	if ttl > 0 {
+		expiresAt = c.clock.Now().Add(ttl)
+	} else if c.defaultTTL > 0 {
+		expiresAt = c.clock.Now().Add(c.defaultTTL)
+	}
+
+	valueCopy := make([]byte, len(value))
+	copy(valueCopy, value)
+
+	if existingItem, exists := c.items[key]; exists {
+		c.bytes += int64(len(valueCopy) - len(existingItem.value))
+		existingItem.value = valueCopy
+		existingItem.expiresAt = expiresAt
+		list := existingItem.list
+		list.remove(existingItem)
+		list.pushFront(existingItem)
+		return nil
+	}
+
+	c.sketch.Increment(key)
+
+	newItem := &tinyLFUItem{
+		key:       key,
+		value:     valueCopy,
+		expiresAt: expiresAt,
+	}
+	c.items[key] = newItem
+	c.window.pushFront(newItem)
+	c.bytes += newItem.size()
+
+	if c.window.len > c.windowSize {
+		candidate := c.window.back()
+		c.window.remove(candidate)
+		c.admit(candidate)
+	}
+
+	return nil
+}
+
+// admit решает, попадет ли вытесненный из окна кандидат в основную область
+func (c *TinyLFUCache) admit(candidate *tinyLFUItem) {
+	mainSize := c.maxSize - c.windowSize
+	if c.main.len < mainSize {
+		c.main.pushFront(candidate)
+		return
+	}
+
+	victim := c.main.back()
+	if victim != nil && c.sketch.Estimate(candidate.key) > c.sketch.Estimate(victim.key) {
+		c.evict(victim)
+		c.main.pushFront(candidate)
+		return
+	}
+
+	// Кандидат не прошел фильтр допуска
+	delete(c.items, candidate.key)
+	c.bytes -= candidate.size()
+	c.evictQueue.push(candidate.key, candidate.value, cache.ReasonCapacity)
+	atomic.AddInt64(&c.evictions, 1)
+}
+
+// GetOrSet возвращает значение по ключу или загружает его через loader при промахе
+func (c *TinyLFUCache) GetOrSet(key string, loader func() ([]byte, error), ttl time.Duration) ([]byte, error) {
+	if key == "" {
+		return nil, cache.ErrKeyEmpty
+	}
+
+	if value, exists := c.Get(key); exists {
+		return value, nil
+	}
+
+	shared, err := c.loads.Do(key, func() ([]byte, error) {
+		value, err := loader()
+		if err != nil {
+			return nil, err
+		}
+		if err := c.SetWithTTL(key, value, ttl); err != nil {
+			return nil, err
+		}
+		return value, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Результат общий для всех ожидающих, поэтому каждый получает свою копию
+	value := make([]byte, len(shared))
+	copy(value, shared)
+	return value, nil
+}
+
+// GetTTL возвращает оставшееся время жизни ключа без обновления статистики
+func (c *TinyLFUCache) GetTTL(key string) (time.Duration, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	item, exists := c.items[key]
+	if !exists || item.isExpired(c.clock.Now()) {
+		return 0, false
+	}
+	return remainingTTL(c.clock.Now(), item.expiresAt)
+}
+
+// Expire устанавливает новое время жизни ключа
+func (c *TinyLFUCache) Expire(key string, ttl time.Duration) bool {
+	c.mu.Lock()
+	defer c.unlock()
+
+	item, exists := c.items[key]
+	if !exists || item.isExpired(c.clock.Now()) {
+		return false
+	}
+
+	item.expiresAt = expirationTime(c.clock.Now(), ttl)
+	return true
+}
+
+// Persist делает ключ бессрочным
+func (c *TinyLFUCache) Persist(key string) bool {
+	return c.Expire(key, 0)
+}
+
+// Delete удаляет ключ из кэша
+func (c *TinyLFUCache) Delete(key string) bool {
+	if key == "" {
+		return false
+	}
+
+	c.mu.Lock()
+	defer c.unlock()
+
+	item, exists := c.items[key]
+	if !exists || c.closed {
+		return false
+	}
+
+	c.removeItem(item, cache.ReasonDeleted)
+	return true
+}
+
+// Keys возвращает все неистекшие ключи в неопределенном порядке
+func (c *TinyLFUCache) Keys() []string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	keys := make([]string, 0, len(c.items))
+	for key, item := range c.items {
+		if !item.isExpired(c.clock.Now()) {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// Len возвращает количество элементов без построения Stats.
+// Может учитывать истекшие элементы, которые еще не удалены.
+func (c *TinyLFUCache) Len() int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return len(c.items)
+}
+
+// Clear очищает весь кэш и историю частот
+func (c *TinyLFUCache) Clear() {
+	c.mu.Lock()
+	defer c.unlock()
+
+	for key, item := range c.items {
+		c.evictQueue.push(key, item.value, cache.ReasonCleared)
+	}
+
+	c.items = make(map[string]*tinyLFUItem, c.maxSize)
+	c.window.init()
+	c.main.init()
+	c.sketch.Clear()
+	c.bytes = 0
+
+	atomic.StoreInt64(&c.hits, 0)
+	atomic.StoreInt64(&c.misses, 0)
+	atomic.StoreInt64(&c.evictions, 0)
+}
+
+// Stats возвращает статистику кэша
+func (c *TinyLFUCache) Stats() cache.Stats {
+	c.mu.RLock()
+	keys := int64(len(c.items))
+	bytes := c.bytes
+	c.mu.RUnlock()
+
+	stats := cache.Stats{
+		Hits:      atomic.LoadInt64(&c.hits),
+		Misses:    atomic.LoadInt64(&c.misses),
+		Keys:      keys,
+		Evictions: atomic.LoadInt64(&c.evictions),
+		Bytes:     bytes,
+		RawBytes:  bytes, // Значения хранятся без сжатия
+	}
+
+	stats.CalculateHitRate()
+	return stats
+}
+
+// Close корректно завершает работу кэша
+func (c *TinyLFUCache) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.closed {
+		return nil
+	}
+
+	c.closed = true
+	close(c.stopCh)
+	return nil
+}
+
+// SetOnEvict устанавливает колбэк, вызываемый при вытеснении, истечении,
+// удалении и очистке элементов. nil отключает уведомления.
+func (c *TinyLFUCache) SetOnEvict(fn cache.EvictCallback) {
+	c.mu.Lock()
+	c.evictQueue.onEvict = fn
+	c.mu.Unlock()
+}
+
+// unlock снимает блокировку на запись и вызывает колбэк для элементов,
+// удаленных пока она удерживалась
+func (c *TinyLFUCache) unlock() {
+	onEvict, evicted := c.evictQueue.take()
+	c.mu.Unlock()
+	notifyEvicted(onEvict, evicted)
+}
+
+// evict вытесняет элемент из-за переполнения
+func (c *TinyLFUCache) evict(item *tinyLFUItem) {
+	c.removeItem(item, cache.ReasonCapacity)
+	atomic.AddInt64(&c.evictions, 1)
+}
+
+// removeItem полностью удаляет элемент из кэша
+func (c *TinyLFUCache) removeItem(item *tinyLFUItem, reason cache.EvictionReason) {
+	item.list.remove(item)
+	delete(c.items, item.key)
+	c.bytes -= item.size()
+	c.evictQueue.push(item.key, item.value, reason)
+}
@@ -0,0 +1,131 @@
+package memory
+
+import (
+	"container/heap"
+	"sort"
+	"sync/atomic"
+	"time"
+
+	cache "github.com/VsRnA/High-Performance-HTTP-Cache"
+)
+
+// hotterKey сообщает, что a популярнее b. При равенстве попаданий выше ключ,
+// меньший лексикографически, чтобы порядок был детерминированным.
+func hotterKey(a, b cache.KeyStat) bool {
+	if a.Hits != b.Hits {
+		return a.Hits > b.Hits
+	}
+	return a.Key < b.Key
+}
+
+// keyStatHeap - куча с наименее популярным из отобранных ключей в вершине
+type keyStatHeap []cache.KeyStat
+
+func (h keyStatHeap) Len() int           { return len(h) }
+func (h keyStatHeap) Less(i, j int) bool { return hotterKey(h[j], h[i]) }
+func (h keyStatHeap) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
+func (h *keyStatHeap) Push(x any)        { *h = append(*h, x.(cache.KeyStat)) }
+func (h *keyStatHeap) Pop() any {
+	old := *h
+	stat := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return stat
+}
+
+// topKeys отбирает n самых популярных ключей за O(m log n) без сортировки всех m ключей
+type topKeys struct {
+	n    int
+	heap keyStatHeap
+}
+
+// newTopKeys создает отбор n ключей
+func newTopKeys(n int) *topKeys {
+	return &topKeys{n: n, heap: make(keyStatHeap, 0, max(n, 0))}
+}
+
+// offer учитывает ключ, вытесняя наименее популярный из отобранных
+func (t *topKeys) offer(stat cache.KeyStat) {
+	if t.n <= 0 {
+		return
+	}
+	if len(t.heap) < t.n {
+		heap.Push(&t.heap, stat)
+		return
+	}
+	if hotterKey(stat, t.heap[0]) {
+		t.heap[0] = stat
+		heap.Fix(&t.heap, 0)
+	}
+}
+
+// result возвращает отобранные ключи по убыванию популярности
+func (t *topKeys) result() []cache.KeyStat {
+	stats := []cache.KeyStat(t.heap)
+	sort.Slice(stats, func(i, j int) bool { return hotterKey(stats[i], stats[j]) })
+	return stats
+}
+
+// TopKeys возвращает до n неистекших ключей с наибольшим числом попаданий Get
+// по убыванию. Попадания ключа сохраняются при перезаписи и обнуляются при удалении.
+// Выполняется под блокировкой на чтение с частичной сортировкой.
+func (c *LRUCache) TopKeys(n int) []cache.KeyStat {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	top := newTopKeys(n)
+	for key, item := range c.items {
+		if item.isExpired(c.clock.Now()) || item.negative {
+			continue
+		}
+		top.offer(cache.KeyStat{Key: key, Hits: item.hits, LastAccess: item.lastAccess})
+	}
+	return top.result()
+}
+
+// TopKeys возвращает до n неистекших ключей с наибольшей частотой по убыванию.
+// Hits - частота, по которой кэш вытесняет: она учитывает запись ключа
+// и уменьшается вдвое при затухании SetFrequencyDecay.
+func (c *LFUCache) TopKeys(n int) []cache.KeyStat {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	top := newTopKeys(n)
+	for key, item := range c.items {
+		if item.isExpired(c.clock.Now()) || item.negative {
+			continue
+		}
+		top.offer(cache.KeyStat{Key: key, Hits: item.frequency, LastAccess: item.lastAccess})
+	}
+	return top.result()
+}
+
+// TopKeys возвращает до n неистекших ключей с наибольшим числом попаданий Get
+// по убыванию, см. LRUCache.TopKeys
+func (c *SimpleCache) TopKeys(n int) []cache.KeyStat {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	top := newTopKeys(n)
+	for key, item := range c.items {
+		if item.isExpired(c.clock.Now()) || item.negative {
+			continue
+		}
+		top.offer(cache.KeyStat{
+			Key:        key,
+			Hits:       atomic.LoadInt64(&item.hits),
+			LastAccess: time.Unix(0, atomic.LoadInt64(&item.lastAccess)),
+		})
+	}
+	return top.result()
+}
+
+// TopKeys возвращает до n самых популярных ключей всех шардов
+func (c *ShardedCache) TopKeys(n int) []cache.KeyStat {
+	top := newTopKeys(n)
+	for _, shard := range c.shards {
+		for _, stat := range shard.TopKeys(n) {
+			top.offer(stat)
+		}
+	}
+	return top.result()
+}
@@ -0,0 +1,73 @@
+package memory
+
+import "time"
+
+// extendExpiry сдвигает момент истечения так, чтобы элемент прожил после now еще не меньше extend.
+// Бессрочные элементы и extend <= 0 не изменяют срок жизни, более поздний срок не сокращается.
+func extendExpiry(now, expiresAt time.Time, extend time.Duration) time.Time {
+	if expiresAt.IsZero() || extend <= 0 {
+		return expiresAt
+	}
+	if extended := now.Add(extend); extended.After(expiresAt) {
+		return extended
+	}
+	return expiresAt
+}
+
+// Touch отмечает ключ как недавно использованный и при extend > 0 продлевает его TTL,
+// не копируя значение. Не считается попаданием в статистике.
+// Возвращает false если ключ отсутствует, уже истек или кэш закрыт.
+func (c *LRUCache) Touch(key string, extend time.Duration) bool {
+	c.mu.Lock()
+	defer c.unlock()
+
+	item, exists := c.items[key]
+	if !exists || c.closed || item.isExpired(c.clock.Now()) {
+		return false
+	}
+
+	c.moveToHead(item)
+	item.expiresAt = capExpiry(extendExpiry(c.clock.Now(), item.expiresAt, extend), item.createdAt, c.maxAge)
+	return true
+}
+
+// Touch увеличивает частоту использования ключа и при extend > 0 продлевает его TTL,
+// не копируя значение. Не считается попаданием в статистике.
+// Возвращает false если ключ отсутствует, уже истек или кэш закрыт.
+func (c *LFUCache) Touch(key string, extend time.Duration) bool {
+	c.mu.Lock()
+	defer c.unlock()
+
+	item, exists := c.items[key]
+	if !exists || c.closed || item.isExpired(c.clock.Now()) {
+		return false
+	}
+
+	c.touch(item)
+	item.expiresAt = capExpiry(extendExpiry(c.clock.Now(), item.expiresAt, extend), item.createdAt, c.maxAge)
+	return true
+}
+
+// Touch при extend > 0 продлевает TTL ключа, не копируя значение.
+// Порядок использования в SimpleCache не отслеживается.
+// Возвращает false если ключ отсутствует, уже истек или кэш закрыт.
+func (c *SimpleCache) Touch(key string, extend time.Duration) bool {
+	c.mu.Lock()
+	defer c.unlock()
+
+	item, exists := c.items[key]
+	if !exists || c.closed || item.isExpired(c.clock.Now()) {
+		return false
+	}
+
+	// Get читает элемент после снятия блокировки, поэтому элемент заменяется, а не изменяется
+	touched := *item
+	touched.expiresAt = capExpiry(extendExpiry(c.clock.Now(), item.expiresAt, extend), item.createdAt, c.maxAge)
+	c.items[key] = &touched
+	return true
+}
+
+// Touch отмечает ключ как недавно использованный в его шарде
+func (c *ShardedCache) Touch(key string, extend time.Duration) bool {
+	return c.shard(key).Touch(key, extend)
+}
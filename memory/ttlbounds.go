@@ -0,0 +1,64 @@
+package memory
+
+import "time"
+
+// ttlBounds ограничивает явно запрошенный TTL, чтобы слишком короткие TTL
+// не гоняли элементы через кэш, а слишком длинные не держали устаревшие данные
+type ttlBounds struct {
+	min time.Duration // 0 - без нижней границы
+	max time.Duration // 0 - без верхней границы
+}
+
+// clamp возвращает ttl в пределах границ. 0 и отрицательные значения
+// означают TTL по умолчанию и не изменяются.
+func (b ttlBounds) clamp(ttl time.Duration) time.Duration {
+	if ttl <= 0 {
+		return ttl
+	}
+	if b.min > 0 && ttl < b.min {
+		ttl = b.min
+	}
+	if b.max > 0 && ttl > b.max {
+		ttl = b.max
+	}
+	return ttl
+}
+
+// SetTTLBounds ограничивает TTL, переданный в SetWithTTL и другие записи:
+// меньший поднимается до minTTL, больший опускается до maxTTL. 0 снимает границу.
+// TTL 0 по-прежнему означает TTL по умолчанию, который не ограничивается.
+// Отклонение SetTTLJitter применяется после ограничения. Если minTTL больше
+// maxTTL, побеждает maxTTL - проверку выполняет cache.Config.Validate.
+func (c *LRUCache) SetTTLBounds(minTTL, maxTTL time.Duration) {
+	c.mu.Lock()
+	c.ttlBounds = ttlBounds{min: max(minTTL, 0), max: max(maxTTL, 0)}
+	c.mu.Unlock()
+}
+
+// SetTTLBounds ограничивает явно запрошенный TTL, см. LRUCache.SetTTLBounds
+func (c *LFUCache) SetTTLBounds(minTTL, maxTTL time.Duration) {
+	c.mu.Lock()
+	c.ttlBounds = ttlBounds{min: max(minTTL, 0), max: max(maxTTL, 0)}
+	c.mu.Unlock()
+}
+
+// SetTTLBounds ограничивает явно запрошенный TTL, см. LRUCache.SetTTLBounds
+func (c *SimpleCache) SetTTLBounds(minTTL, maxTTL time.Duration) {
+	c.mu.Lock()
+	c.ttlBounds = ttlBounds{min: max(minTTL, 0), max: max(maxTTL, 0)}
+	c.mu.Unlock()
+}
+
+// SetTTLBounds ограничивает явно запрошенный TTL, см. LRUCache.SetTTLBounds
+func (c *FIFOCache) SetTTLBounds(minTTL, maxTTL time.Duration) {
+	c.mu.Lock()
+	c.ttlBounds = ttlBounds{min: max(minTTL, 0), max: max(maxTTL, 0)}
+	c.mu.Unlock()
+}
+
+// SetTTLBounds ограничивает явно запрошенный TTL во всех шардах
+func (c *ShardedCache) SetTTLBounds(minTTL, maxTTL time.Duration) {
+	for _, shard := range c.shards {
+		shard.SetTTLBounds(minTTL, maxTTL)
+	}
+}
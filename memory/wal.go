@@ -0,0 +1,577 @@
+package memory
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	cache "github.com/VsRnA/High-Performance-HTTP-Cache"
+	"github.com/VsRnA/High-Performance-HTTP-Cache/internal"
+)
+
+// Типы записей журнала
+const (
+	walOpSet byte = iota + 1
+	walOpDelete
+	walOpExpire
+	walOpClear
+)
+
+// SnapshotCache - кэш, который умеет сохранять и загружать снимки
+type SnapshotCache interface {
+	cache.Cache
+	SaveSnapshot(w io.Writer) error
+	LoadSnapshot(r io.Reader) error
+}
+
+// WALConfig настраивает журнал упреждающей записи
+type WALConfig struct {
+	// Path - путь к файлу журнала. Снимок хранится рядом в Path + ".snapshot".
+	Path string
+
+	// SyncEvery - количество записей между вызовами fsync.
+	// 1 - fsync после каждой записи, 0 - fsync только при Compact и Close.
+	SyncEvery int
+
+	// CompactSize - размер журнала в байтах, после которого выполняется компакция.
+	// 0 отключает автоматическую компакцию.
+	CompactSize int64
+}
+
+// WALCache записывает каждое изменение кэша в журнал на диске
+// и восстанавливает состояние из снимка и журнала при создании.
+// Вытеснения не журналируются, поэтому после восстановления кэш
+// с ограниченным размером может вытеснить другие элементы, чем до сбоя.
+type WALCache struct {
+	cache  SnapshotCache
+	config WALConfig
+
+	// mu упорядочивает изменения кэша и записи журнала
+	mu      sync.Mutex
+	file    *os.File
+	size    int64
+	pending int   // Записи после последнего fsync
+	err     error // Первая ошибка записи, после нее журнал не пишется
+	buf     []byte
+
+	// Дедупликация одновременных загрузок в GetOrSet
+	loads internal.Group
+}
+
+// NewWAL оборачивает кэш журналом по пути config.Path.
+// Если снимок или журнал уже существуют, их содержимое загружается в c.
+// Поврежденный хвост журнала (например, после сбоя посреди записи) отбрасывается.
+func NewWAL(c SnapshotCache, config WALConfig) (*WALCache, error) {
+	w := &WALCache{
+		cache:  c,
+		config: config,
+	}
+
+	if err := w.loadSnapshot(); err != nil {
+		return nil, err
+	}
+
+	file, err := os.OpenFile(config.Path, os.O_RDWR|os.O_CREATE, 0o644)
+	if err != nil {
+		return nil, err
+	}
+
+	size, err := w.replay(file)
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	// Отбрасываем недописанную запись, чтобы новые записи шли после последней целой
+	if err := file.Truncate(size); err != nil {
+		file.Close()
+		return nil, err
+	}
+	if _, err := file.Seek(size, io.SeekStart); err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	w.file = file
+	w.size = size
+	return w, nil
+}
+
+// snapshotPath возвращает путь к файлу снимка
+func (w *WALCache) snapshotPath() string {
+	return w.config.Path + ".snapshot"
+}
+
+// loadSnapshot загружает снимок, оставленный последней компакцией
+func (w *WALCache) loadSnapshot() error {
+	file, err := os.Open(w.snapshotPath())
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	return w.cache.LoadSnapshot(file)
+}
+
+// replay применяет записи журнала к кэшу и возвращает размер целой части журнала
+func (w *WALCache) replay(file *os.File) (int64, error) {
+	br := bufio.NewReader(file)
+
+	var offset int64
+	for {
+		payload, n, err := readWALRecord(br)
+		if err == io.EOF || err == errWALCorrupt {
+			return offset, nil
+		}
+		if err != nil {
+			return 0, err
+		}
+
+		if err := w.apply(payload); err != nil {
+			if err == errWALCorrupt {
+				return offset, nil
+			}
+			return 0, err
+		}
+		offset += n
+	}
+}
+
+// errWALCorrupt означает поврежденную или недописанную запись журнала
+var errWALCorrupt = errors.New("поврежденная запись журнала")
+
+// readWALRecord читает одну запись: uvarint(len) payload crc32(payload).
+// Возвращает io.EOF, если журнал закончился ровно на границе записи.
+func readWALRecord(br *bufio.Reader) ([]byte, int64, error) {
+	length, err := binary.ReadUvarint(br)
+	if err == io.EOF {
+		return nil, 0, io.EOF
+	}
+	if err != nil {
+		return nil, 0, errWALCorrupt
+	}
+
+	payload, err := io.ReadAll(io.LimitReader(br, int64(length)))
+	if err != nil {
+		return nil, 0, err
+	}
+	if uint64(len(payload)) != length {
+		return nil, 0, errWALCorrupt
+	}
+
+	var checksum [4]byte
+	if _, err := io.ReadFull(br, checksum[:]); err != nil {
+		return nil, 0, errWALCorrupt
+	}
+	if binary.LittleEndian.Uint32(checksum[:]) != crc32.ChecksumIEEE(payload) {
+		return nil, 0, errWALCorrupt
+	}
+
+	n := int64(uvarintLen(length)) + int64(length) + 4
+	return payload, n, nil
+}
+
+// uvarintLen возвращает длину uvarint кодировки x
+func uvarintLen(x uint64) int {
+	var buf [binary.MaxVarintLen64]byte
+	return binary.PutUvarint(buf[:], x)
+}
+
+// apply применяет запись журнала к кэшу без повторной записи в журнал
+func (w *WALCache) apply(payload []byte) error {
+	if len(payload) == 0 {
+		return errWALCorrupt
+	}
+
+	op, rest := payload[0], payload[1:]
+	switch op {
+	case walOpSet:
+		key, rest, ok := readWALBytes(rest)
+		if !ok {
+			return errWALCorrupt
+		}
+		value, rest, ok := readWALBytes(rest)
+		if !ok {
+			return errWALCorrupt
+		}
+		expiresAt, ok := readWALTime(rest)
+		if !ok {
+			return errWALCorrupt
+		}
+		return w.restore(string(key), value, expiresAt)
+
+	case walOpDelete:
+		key, _, ok := readWALBytes(rest)
+		if !ok {
+			return errWALCorrupt
+		}
+		w.cache.Delete(string(key))
+		return nil
+
+	case walOpExpire:
+		key, rest, ok := readWALBytes(rest)
+		if !ok {
+			return errWALCorrupt
+		}
+		expiresAt, ok := readWALTime(rest)
+		if !ok {
+			return errWALCorrupt
+		}
+		if expiresAt.IsZero() {
+			w.cache.Persist(string(key))
+		} else if ttl := expiresAt.Sub(w.now()); ttl > 0 {
+			w.cache.Expire(string(key), ttl)
+		} else {
+			w.cache.Delete(string(key))
+		}
+		return nil
+
+	case walOpClear:
+		w.cache.Clear()
+		return nil
+	}
+
+	return errWALCorrupt
+}
+
+// restore сохраняет элемент с исходным моментом истечения.
+// Истекший к моменту восстановления элемент удаляется.
+func (w *WALCache) restore(key string, value []byte, expiresAt time.Time) error {
+	if expiresAt.IsZero() {
+		if err := w.cache.Set(key, value); err != nil {
+			return err
+		}
+		w.cache.Persist(key)
+		return nil
+	}
+
+	ttl := expiresAt.Sub(w.now())
+	if ttl <= 0 {
+		w.cache.Delete(key)
+		return nil
+	}
+	return w.cache.SetWithTTL(key, value, ttl)
+}
+
+// readWALBytes читает последовательность байт с префиксом длины
+func readWALBytes(data []byte) ([]byte, []byte, bool) {
+	length, n := binary.Uvarint(data)
+	if n <= 0 || uint64(len(data)-n) < length {
+		return nil, nil, false
+	}
+	data = data[n:]
+	return data[:length], data[length:], true
+}
+
+// readWALTime читает момент истечения в UnixNano, 0 - бессрочно
+func readWALTime(data []byte) (time.Time, bool) {
+	nanos, n := binary.Varint(data)
+	if n <= 0 {
+		return time.Time{}, false
+	}
+	if nanos == 0 {
+		return time.Time{}, true
+	}
+	return time.Unix(0, nanos), true
+}
+
+// expiresAt вычисляет момент истечения ключа по его текущему TTL в кэше
+func (w *WALCache) expiresAt(key string) time.Time {
+	ttl, exists := w.cache.GetTTL(key)
+	if !exists || ttl == cache.NoExpiration {
+		return time.Time{}
+	}
+	return w.now().Add(ttl)
+}
+
+// now возвращает текущее время по источнику времени обернутого кэша, см. WithClock.
+// Моменты истечения в журнале должны совпадать с TTL, которые видит кэш.
+func (w *WALCache) now() time.Time {
+	if c, ok := w.cache.(interface{ now() time.Time }); ok {
+		return c.now()
+	}
+	return time.Now()
+}
+
+// appendWALTime добавляет момент истечения в запись
+func appendWALTime(buf []byte, expiresAt time.Time) []byte {
+	var nanos int64
+	if !expiresAt.IsZero() {
+		nanos = expiresAt.UnixNano()
+	}
+	return binary.AppendVarint(buf, nanos)
+}
+
+// appendWALBytes добавляет последовательность байт с префиксом длины
+func appendWALBytes(buf []byte, data []byte) []byte {
+	buf = binary.AppendUvarint(buf, uint64(len(data)))
+	return append(buf, data...)
+}
+
+// write дописывает запись в журнал, вызывается под mu.
+// Запись уходит в файл одним вызовом Write, чтобы после сбоя процесса
+// в журнале не оставалось частично записанных буферов.
+func (w *WALCache) write(payload []byte) error {
+	if w.err != nil {
+		return w.err
+	}
+	if w.file == nil {
+		return cache.ErrCacheClosed
+	}
+
+	record := binary.AppendUvarint(w.buf[:0], uint64(len(payload)))
+	record = append(record, payload...)
+	record = binary.LittleEndian.AppendUint32(record, crc32.ChecksumIEEE(payload))
+	w.buf = record
+
+	if _, err := w.file.Write(record); err != nil {
+		w.err = err
+		return err
+	}
+	w.size += int64(len(record))
+
+	w.pending++
+	if w.config.SyncEvery > 0 && w.pending >= w.config.SyncEvery {
+		if err := w.file.Sync(); err != nil {
+			w.err = err
+			return err
+		}
+		w.pending = 0
+	}
+
+	if w.config.CompactSize > 0 && w.size >= w.config.CompactSize {
+		return w.compactLocked()
+	}
+	return nil
+}
+
+// Get получает значение по ключу
+func (w *WALCache) Get(key string) ([]byte, bool) {
+	return w.cache.Get(key)
+}
+
+// Set сохраняет значение с TTL по умолчанию и записывает его в журнал
+func (w *WALCache) Set(key string, value []byte) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if err := w.cache.Set(key, value); err != nil {
+		return err
+	}
+	return w.logSet(key, value)
+}
+
+// SetWithTTL сохраняет значение с указанным TTL и записывает его в журнал
+func (w *WALCache) SetWithTTL(key string, value []byte, ttl time.Duration) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if err := w.cache.SetWithTTL(key, value, ttl); err != nil {
+		return err
+	}
+	return w.logSet(key, value)
+}
+
+// logSet записывает в журнал сохранение значения, вызывается под mu
+func (w *WALCache) logSet(key string, value []byte) error {
+	payload := []byte{walOpSet}
+	payload = appendWALBytes(payload, []byte(key))
+	payload = appendWALBytes(payload, value)
+	payload = appendWALTime(payload, w.expiresAt(key))
+	return w.write(payload)
+}
+
+// GetOrSet возвращает значение по ключу или загружает его через loader при промахе.
+// Загруженное значение записывается в журнал.
+func (w *WALCache) GetOrSet(key string, loader func() ([]byte, error), ttl time.Duration) ([]byte, error) {
+	if key == "" {
+		return nil, cache.ErrKeyEmpty
+	}
+
+	if value, exists := w.Get(key); exists {
+		return value, nil
+	}
+
+	shared, err := w.loads.Do(key, func() ([]byte, error) {
+		value, err := loader()
+		if err != nil {
+			return nil, err
+		}
+		if err := w.SetWithTTL(key, value, ttl); err != nil {
+			return nil, err
+		}
+		return value, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Результат общий для всех ожидающих, поэтому каждый получает свою копию
+	value := make([]byte, len(shared))
+	copy(value, shared)
+	return value, nil
+}
+
+// GetTTL возвращает оставшееся время жизни ключа
+func (w *WALCache) GetTTL(key string) (time.Duration, bool) {
+	return w.cache.GetTTL(key)
+}
+
+// Expire устанавливает новое время жизни ключа и записывает изменение в журнал.
+// Ошибка записи журнала сохраняется и возвращается из следующей записи или Close.
+func (w *WALCache) Expire(key string, ttl time.Duration) bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if !w.cache.Expire(key, ttl) {
+		return false
+	}
+
+	payload := []byte{walOpExpire}
+	payload = appendWALBytes(payload, []byte(key))
+	payload = appendWALTime(payload, w.expiresAt(key))
+	w.write(payload)
+	return true
+}
+
+// Persist делает ключ бессрочным
+func (w *WALCache) Persist(key string) bool {
+	return w.Expire(key, 0)
+}
+
+// Delete удаляет ключ и записывает удаление в журнал.
+// Ошибка записи журнала сохраняется и возвращается из следующей записи или Close.
+func (w *WALCache) Delete(key string) bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if !w.cache.Delete(key) {
+		return false
+	}
+
+	payload := []byte{walOpDelete}
+	payload = appendWALBytes(payload, []byte(key))
+	w.write(payload)
+	return true
+}
+
+// Keys возвращает все неистекшие ключи
+func (w *WALCache) Keys() []string {
+	return w.cache.Keys()
+}
+
+// Len возвращает количество элементов кэша
+func (w *WALCache) Len() int {
+	return w.cache.Len()
+}
+
+// Clear очищает кэш и записывает очистку в журнал
+func (w *WALCache) Clear() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.cache.Clear()
+	w.write([]byte{walOpClear})
+}
+
+// Stats возвращает статистику кэша
+func (w *WALCache) Stats() cache.Stats {
+	return w.cache.Stats()
+}
+
+// Compact записывает снимок живых ключей и обрезает журнал.
+// Снимок сначала пишется во временный файл и атомарно переименовывается,
+// поэтому сбой посреди компакции не теряет данные.
+func (w *WALCache) Compact() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	return w.compactLocked()
+}
+
+// compactLocked выполняет компакцию, вызывается под mu
+func (w *WALCache) compactLocked() error {
+	if w.err != nil {
+		return w.err
+	}
+
+	if err := w.writeSnapshot(); err != nil {
+		w.err = err
+		return err
+	}
+
+	// Если сбой произойдет до обрезки, записи журнала будут повторно применены
+	// поверх снимка, что дает то же состояние
+	if err := w.file.Truncate(0); err != nil {
+		w.err = err
+		return err
+	}
+	if _, err := w.file.Seek(0, io.SeekStart); err != nil {
+		w.err = err
+		return err
+	}
+	if err := w.file.Sync(); err != nil {
+		w.err = err
+		return err
+	}
+
+	w.size = 0
+	w.pending = 0
+	return nil
+}
+
+// writeSnapshot атомарно заменяет файл снимка текущим состоянием кэша
+func (w *WALCache) writeSnapshot() error {
+	tmpPath := w.snapshotPath() + ".tmp"
+	file, err := os.Create(tmpPath)
+	if err != nil {
+		return err
+	}
+
+	if err := w.cache.SaveSnapshot(file); err != nil {
+		file.Close()
+		os.Remove(tmpPath)
+		return err
+	}
+	if err := file.Sync(); err != nil {
+		file.Close()
+		os.Remove(tmpPath)
+		return err
+	}
+	if err := file.Close(); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+
+	return os.Rename(tmpPath, w.snapshotPath())
+}
+
+// Close сбрасывает журнал на диск, закрывает его и оборачиваемый кэш.
+// Возвращает первую ошибку записи журнала, если она была.
+func (w *WALCache) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.file == nil {
+		return w.err
+	}
+
+	if err := w.file.Sync(); err != nil && w.err == nil {
+		w.err = err
+	}
+	if err := w.file.Close(); err != nil && w.err == nil {
+		w.err = err
+	}
+	w.file = nil
+
+	w.cache.Close()
+	return w.err
+}
@@ -0,0 +1,469 @@
+package memory
+
+import (
+	"container/heap"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	cache "github.com/VsRnA/High-Performance-HTTP-Cache"
+	"github.com/VsRnA/High-Performance-HTTP-Cache/internal"
+)
+
+// weightedItem представляет элемент в Weighted кэше
+type weightedItem struct {
+	key       string
+	value     []byte
+	expiresAt time.Time
+	cost      int64
+	priority  int64  // Уровень инфляции на момент обращения плюс стоимость
+	touched   uint64 // Логическое время последнего обращения, при равном приоритете вытесняется более старый
+	index     int    // Позиция в куче, -1 - элемент временно извлечен
+}
+
+// size возвращает объем ключа и значения в байтах
+func (item *weightedItem) size() int64 {
+	return int64(len(item.key) + len(item.value))
+}
+
+// isExpired проверяет истек ли элемент на момент now
+func (item *weightedItem) isExpired(now time.Time) bool {
+	return !item.expiresAt.IsZero() && now.After(item.expiresAt)
+}
+
+// weightedQueue - минимальная куча элементов по приоритету для container/heap
+type weightedQueue []*weightedItem
+
+func (q weightedQueue) Len() int { return len(q) }
+
+func (q weightedQueue) Less(i, j int) bool {
+	if q[i].priority != q[j].priority {
+		return q[i].priority < q[j].priority
+	}
+	return q[i].touched < q[j].touched
+}
+
+func (q weightedQueue) Swap(i, j int) {
+	q[i], q[j] = q[j], q[i]
+	q[i].index = i
+	q[j].index = j
+}
+
+func (q *weightedQueue) Push(x any) {
+	item := x.(*weightedItem)
+	item.index = len(*q)
+	*q = append(*q, item)
+}
+
+func (q *weightedQueue) Pop() any {
+	old := *q
+	item := old[len(old)-1]
+	old[len(old)-1] = nil
+	item.index = -1
+	*q = old[:len(old)-1]
+	return item
+}
+
+// WeightedCache ограничивает суммарную стоимость элементов, а не их количество.
+// Стоимость задается при записи через SetWithCost и отражает, насколько дорого
+// пересчитать значение. Вытесняется элемент с наименьшим приоритетом по алгоритму
+// GreedyDual: приоритет равен стоимости плюс уровень инфляции, который растет
+// до приоритета каждого вытесненного элемента. Дорогие элементы переживают
+// вытеснение многих дешевых, но без обращений со временем тоже стареют.
+type WeightedCache struct {
+	// Основные данные
+	items map[string]*weightedItem
+	queue weightedQueue
+	mu    sync.RWMutex
+
+	// Конфигурация
+	maxCost    int64
+	defaultTTL time.Duration
+
+	// Текущая стоимость, уровень инфляции, логическое время и объем, изменяются под mu
+	cost      int64
+	inflation int64
+	tick      uint64
+	bytes     int64
+
+	// Источник времени для TTL, изменяется под mu
+	clock Clock
+
+	// Управление жизненным циклом
+	stopCh chan struct{}
+	closed bool
+
+	// Уведомления об удаленных элементах
+	evictQueue evictionQueue
+
+	// Дедупликация одновременных загрузок в GetOrSet
+	loads internal.Group
+
+	// Статистика
+	hits      int64
+	misses    int64
+	evictions int64
+}
+
+// NewWeighted создает Weighted кэш с бюджетом стоимости maxCost.
+// Set и SetWithTTL записывают элементы со стоимостью 1.
+func NewWeighted(maxCost int64) cache.Cache {
+	return NewWeightedWithTTL(maxCost, 0)
+}
+
+// NewWeightedWithTTL создает Weighted кэш с бюджетом стоимости и TTL по умолчанию
+func NewWeightedWithTTL(maxCost int64, defaultTTL time.Duration) cache.Cache {
+	if maxCost <= 0 {
+		maxCost = 1000
+	}
+
+	c := &WeightedCache{
+		items:      make(map[string]*weightedItem),
+		maxCost:    maxCost,
+		defaultTTL: defaultTTL,
+		stopCh:     make(chan struct{}),
+		clock:      realClock{},
+	}
+
+	if defaultTTL > 0 {
+		go c.cleanup()
+	}
+
+	return c
+}
+
+// Get получает значение по ключу. Попадание поднимает приоритет элемента
+// до текущего уровня инфляции плюс его стоимость.
+func (c *WeightedCache) Get(key string) ([]byte, bool) {
+	if key == "" {
+		atomic.AddInt64(&c.misses, 1)
+		return nil, false
+	}
+
+	c.mu.Lock()
+	defer c.unlock()
+
+	item, exists := c.items[key]
+	if !exists || c.closed {
+		atomic.AddInt64(&c.misses, 1)
+		return nil, false
+	}
+
+	if item.isExpired(c.clock.Now()) {
+		c.removeItem(item, cache.ReasonExpired)
+		atomic.AddInt64(&c.misses, 1)
+		return nil, false
+	}
+
+	c.tick++
+	item.priority = c.inflation + item.cost
+	item.touched = c.tick
+	heap.Fix(&c.queue, item.index)
+	atomic.AddInt64(&c.hits, 1)
+
+	value := make([]byte, len(item.value))
+	copy(value, item.value)
+	return value, true
+}
+
+// Set сохраняет значение со стоимостью 1 и TTL по умолчанию
+func (c *WeightedCache) Set(key string, value []byte) error {
+	return c.SetWithCost(key, value, 1, c.defaultTTL)
+}
+
+// SetWithTTL сохраняет значение со стоимостью 1 и указанным TTL
+func (c *WeightedCache) SetWithTTL(key string, value []byte, ttl time.Duration) error {
+	return c.SetWithCost(key, value, 1, ttl)
+}
+
+// SetWithCost сохраняет значение с указанной стоимостью. Стоимость меньше 1
+// считается равной 1. Если бюджет превышен, вытесняются элементы с наименьшим
+// приоритетом, пока новый элемент не поместится. Элемент дороже всего бюджета
+// не сохраняется, возвращается cache.ErrValueTooLarge.
+func (c *WeightedCache) SetWithCost(key string, value []byte, cost int64, ttl time.Duration) error {
+	if key == "" {
+		return cache.ErrKeyEmpty
+	}
+	cost = max(cost, 1)
+
+	c.mu.Lock()
+	defer c.unlock()
+
+	if c.closed {
+		return cache.ErrCacheClosed
+	}
+	if cost > c.maxCost {
+		return fmt.Errorf("%w: стоимость %d при бюджете %d", cache.ErrValueTooLarge, cost, c.maxCost)
+	}
+
+	var expiresAt time.Time
+	if ttl > 0 {
+		expiresAt = c.clock.Now().Add(ttl)
+	} else if c.defaultTTL > 0 {
+		expiresAt = c.clock.Now().Add(c.defaultTTL)
+	}
+
+	valueCopy := make([]byte, len(value))
+	copy(valueCopy, value)
+
+	// Перезаписываемый элемент извлекается из кучи, чтобы не вытеснить его самого
+	item, exists := c.items[key]
+	if exists {
+		heap.Remove(&c.queue, item.index)
+		c.cost -= item.cost
+		c.bytes -= item.size()
+	}
+
+	for c.cost+cost > c.maxCost && len(c.queue) > 0 {
+		c.evictMin()
+	}
+
+	if !exists {
+		item = &weightedItem{key: key}
+		c.items[key] = item
+	}
+	c.tick++
+	item.value = valueCopy
+	item.expiresAt = expiresAt
+	item.cost = cost
+	item.priority = c.inflation + cost
+	item.touched = c.tick
+	heap.Push(&c.queue, item)
+
+	c.cost += cost
+	c.bytes += item.size()
+	return nil
+}
+
+// GetOrSet возвращает значение по ключу или загружает его через loader при промахе
+func (c *WeightedCache) GetOrSet(key string, loader func() ([]byte, error), ttl time.Duration) ([]byte, error) {
+	if key == "" {
+		return nil, cache.ErrKeyEmpty
+	}
+
+	if value, exists := c.Get(key); exists {
+		return value, nil
+	}
+
+	shared, err := c.loads.Do(key, func() ([]byte, error) {
+		value, err := loader()
+		if err != nil {
+			return nil, err
+		}
+		if err := c.SetWithTTL(key, value, ttl); err != nil {
+			return nil, err
+		}
+		return value, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Результат общий для всех ожидающих, поэтому каждый получает свою копию
+	value := make([]byte, len(shared))
+	copy(value, shared)
+	return value, nil
+}
+
+// GetTTL возвращает оставшееся время жизни ключа без обновления статистики
+func (c *WeightedCache) GetTTL(key string) (time.Duration, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	item, exists := c.items[key]
+	if !exists || item.isExpired(c.clock.Now()) {
+		return 0, false
+	}
+	return remainingTTL(c.clock.Now(), item.expiresAt)
+}
+
+// Expire устанавливает новое время жизни ключа
+func (c *WeightedCache) Expire(key string, ttl time.Duration) bool {
+	c.mu.Lock()
+	defer c.unlock()
+
+	item, exists := c.items[key]
+	if !exists || item.isExpired(c.clock.Now()) {
+		return false
+	}
+
+	item.expiresAt = expirationTime(c.clock.Now(), ttl)
+	return true
+}
+
+// Persist делает ключ бессрочным
+func (c *WeightedCache) Persist(key string) bool {
+	return c.Expire(key, 0)
+}
+
+// Delete удаляет ключ из кэша
+func (c *WeightedCache) Delete(key string) bool {
+	if key == "" {
+		return false
+	}
+
+	c.mu.Lock()
+	defer c.unlock()
+
+	item, exists := c.items[key]
+	if !exists || c.closed {
+		return false
+	}
+
+	c.removeItem(item, cache.ReasonDeleted)
+	return true
+}
+
+// Keys возвращает все неистекшие ключи в неопределенном порядке
+func (c *WeightedCache) Keys() []string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	keys := make([]string, 0, len(c.items))
+	for key, item := range c.items {
+		if !item.isExpired(c.clock.Now()) {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// Len возвращает количество элементов без построения Stats.
+// Может учитывать истекшие элементы, которые еще не удалены.
+func (c *WeightedCache) Len() int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return len(c.items)
+}
+
+// Clear очищает весь кэш и сбрасывает уровень инфляции
+func (c *WeightedCache) Clear() {
+	c.mu.Lock()
+	defer c.unlock()
+
+	for key, item := range c.items {
+		c.evictQueue.push(key, item.value, cache.ReasonCleared)
+	}
+
+	c.items = make(map[string]*weightedItem)
+	c.queue = nil
+	c.cost = 0
+	c.inflation = 0
+	c.bytes = 0
+
+	atomic.StoreInt64(&c.hits, 0)
+	atomic.StoreInt64(&c.misses, 0)
+	atomic.StoreInt64(&c.evictions, 0)
+}
+
+// Stats возвращает статистику кэша. RemainingCost - неизрасходованная часть бюджета.
+func (c *WeightedCache) Stats() cache.Stats {
+	c.mu.RLock()
+	keys := int64(len(c.items))
+	bytes := c.bytes
+	remaining := c.maxCost - c.cost
+	c.mu.RUnlock()
+
+	stats := cache.Stats{
+		Hits:          atomic.LoadInt64(&c.hits),
+		Misses:        atomic.LoadInt64(&c.misses),
+		Keys:          keys,
+		Evictions:     atomic.LoadInt64(&c.evictions),
+		Bytes:         bytes,
+		RawBytes:      bytes, // Значения хранятся без сжатия
+		RemainingCost: remaining,
+	}
+
+	stats.CalculateHitRate()
+	return stats
+}
+
+// Close корректно завершает работу кэша
+func (c *WeightedCache) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.closed {
+		return nil
+	}
+
+	c.closed = true
+	close(c.stopCh)
+	return nil
+}
+
+// SetOnEvict устанавливает колбэк, вызываемый при вытеснении, истечении,
+// удалении и очистке элементов. nil отключает уведомления.
+func (c *WeightedCache) SetOnEvict(fn cache.EvictCallback) {
+	c.mu.Lock()
+	c.evictQueue.onEvict = fn
+	c.mu.Unlock()
+}
+
+// unlock снимает блокировку на запись и вызывает колбэк для элементов,
+// удаленных пока она удерживалась
+func (c *WeightedCache) unlock() {
+	onEvict, evicted := c.evictQueue.take()
+	c.mu.Unlock()
+	notifyEvicted(onEvict, evicted)
+}
+
+// evictMin вытесняет элемент с наименьшим приоритетом и поднимает до него уровень инфляции.
+// Истекший элемент удаляется с причиной ReasonExpired и инфляцию не меняет.
+func (c *WeightedCache) evictMin() {
+	item := c.queue[0]
+	if item.isExpired(c.clock.Now()) {
+		c.removeItem(item, cache.ReasonExpired)
+		return
+	}
+
+	c.inflation = item.priority
+	c.removeItem(item, cache.ReasonCapacity)
+	atomic.AddInt64(&c.evictions, 1)
+}
+
+// removeItem полностью удаляет элемент из кэша
+func (c *WeightedCache) removeItem(item *weightedItem, reason cache.EvictionReason) {
+	delete(c.items, item.key)
+	heap.Remove(&c.queue, item.index)
+	c.cost -= item.cost
+	c.bytes -= item.size()
+	c.evictQueue.push(item.key, item.value, reason)
+}
+
+// cleanup фоновая очистка истекших элементов
+func (c *WeightedCache) cleanup() {
+	ticker := time.NewTicker(1 * time.Minute)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			c.removeExpired()
+		case <-c.stopCh:
+			return
+		}
+	}
+}
+
+// removeExpired удаляет все истекшие элементы
+func (c *WeightedCache) removeExpired() {
+	c.mu.Lock()
+	defer c.unlock()
+
+	var expired []*weightedItem
+	for _, item := range c.items {
+		if item.isExpired(c.clock.Now()) {
+			expired = append(expired, item)
+		}
+	}
+
+	for _, item := range expired {
+		c.removeItem(item, cache.ReasonExpired)
+	}
+
+	if len(expired) > 0 {
+		atomic.AddInt64(&c.evictions, int64(len(expired)))
+	}
+}
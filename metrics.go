@@ -0,0 +1,17 @@
+package cache
+
+import "github.com/VsRnA/High-Performance-HTTP-Cache/internal"
+
+// Metrics - детальные метрики кэша: время и перцентили Get, Set и Delete,
+// попадания, промахи, вытеснения, количество ключей и оценка занятой памяти.
+// Подключается к in-memory кэшам через SetMetrics или memory.WithMetrics.
+// Запись и чтение снимка через GetSnapshot безопасны для конкурентного использования.
+type Metrics = internal.Metrics
+
+// MetricsSnapshot - моментальный снимок Metrics, возвращается GetSnapshot и DetailedStats
+type MetricsSnapshot = internal.Snapshot
+
+// NewMetrics создает пустые метрики с отсчетом времени работы от момента создания
+func NewMetrics() *Metrics {
+	return internal.NewMetrics()
+}
@@ -0,0 +1,72 @@
+// Package prometheus экспортирует метрики кэша в формате Prometheus.
+// Вынесен в отдельный модуль, чтобы основной модуль оставался без внешних зависимостей.
+package prometheus
+
+import (
+	prom "github.com/prometheus/client_golang/prometheus"
+
+	cache "github.com/VsRnA/High-Performance-HTTP-Cache"
+)
+
+// Описания экспортируемых метрик
+var (
+	hitsDesc      = prom.NewDesc("cache_hits_total", "Количество попаданий в кэш", nil, nil)
+	missesDesc    = prom.NewDesc("cache_misses_total", "Количество промахов кэша", nil, nil)
+	setsDesc      = prom.NewDesc("cache_sets_total", "Количество операций записи", nil, nil)
+	deletesDesc   = prom.NewDesc("cache_deletes_total", "Количество операций удаления", nil, nil)
+	evictionsDesc = prom.NewDesc("cache_evictions_total", "Количество вытесненных элементов", nil, nil)
+	keysDesc      = prom.NewDesc("cache_keys", "Текущее количество ключей", nil, nil)
+	memoryDesc    = prom.NewDesc("cache_memory_bytes", "Оценка используемой памяти в байтах", nil, nil)
+	hitRateDesc   = prom.NewDesc("cache_hit_rate", "Доля попаданий от 0 до 1", nil, nil)
+	uptimeDesc    = prom.NewDesc("cache_uptime_seconds", "Время с момента создания или сброса метрик", nil, nil)
+	avgSetDesc    = prom.NewDesc("cache_set_duration_seconds_avg", "Среднее время операции записи", nil, nil)
+	avgGetDesc    = prom.NewDesc("cache_get_duration_seconds_avg", "Среднее время операции чтения", nil, nil)
+	avgDeleteDesc = prom.NewDesc("cache_delete_duration_seconds_avg", "Среднее время операции удаления", nil, nil)
+)
+
+// collector читает снимок метрик при каждом сборе
+type collector struct {
+	metrics *cache.Metrics
+}
+
+// NewCollector создает коллектор, отдающий снимок m при каждом запросе Prometheus.
+// m создается через cache.NewMetrics и подключается к кэшу через SetMetrics или memory.WithMetrics.
+// Имена метрик фиксированы, поэтому коллектор регистрируется в реестре один раз.
+func NewCollector(m *cache.Metrics) prom.Collector {
+	return &collector{metrics: m}
+}
+
+// Describe отправляет описания всех метрик коллектора
+func (c *collector) Describe(ch chan<- *prom.Desc) {
+	ch <- hitsDesc
+	ch <- missesDesc
+	ch <- setsDesc
+	ch <- deletesDesc
+	ch <- evictionsDesc
+	ch <- keysDesc
+	ch <- memoryDesc
+	ch <- hitRateDesc
+	ch <- uptimeDesc
+	ch <- avgSetDesc
+	ch <- avgGetDesc
+	ch <- avgDeleteDesc
+}
+
+// Collect отправляет значения метрик из одного согласованного снимка
+func (c *collector) Collect(ch chan<- prom.Metric) {
+	s := c.metrics.GetSnapshot()
+
+	ch <- prom.MustNewConstMetric(hitsDesc, prom.CounterValue, float64(s.Hits))
+	ch <- prom.MustNewConstMetric(missesDesc, prom.CounterValue, float64(s.Misses))
+	ch <- prom.MustNewConstMetric(setsDesc, prom.CounterValue, float64(s.Sets))
+	ch <- prom.MustNewConstMetric(deletesDesc, prom.CounterValue, float64(s.Deletes))
+	ch <- prom.MustNewConstMetric(evictionsDesc, prom.CounterValue, float64(s.Evictions))
+	ch <- prom.MustNewConstMetric(keysDesc, prom.GaugeValue, float64(s.KeyCount))
+	ch <- prom.MustNewConstMetric(memoryDesc, prom.GaugeValue, float64(s.Memory))
+	// Snapshot хранит hit rate в процентах, Prometheus принято отдавать долю
+	ch <- prom.MustNewConstMetric(hitRateDesc, prom.GaugeValue, s.HitRate/100)
+	ch <- prom.MustNewConstMetric(uptimeDesc, prom.GaugeValue, s.Uptime.Seconds())
+	ch <- prom.MustNewConstMetric(avgSetDesc, prom.GaugeValue, s.AvgSetTime.Seconds())
+	ch <- prom.MustNewConstMetric(avgGetDesc, prom.GaugeValue, s.AvgGetTime.Seconds())
+	ch <- prom.MustNewConstMetric(avgDeleteDesc, prom.GaugeValue, s.AvgDeleteTime.Seconds())
+}
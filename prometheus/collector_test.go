@@ -0,0 +1,89 @@
+package prometheus
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	prom "github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	cache "github.com/VsRnA/High-Performance-HTTP-Cache"
+)
+
+// TestCollector проверяет что значения метрик совпадают со снимком
+func TestCollector(t *testing.T) {
+	m := cache.NewMetrics()
+	for i := 0; i < 3; i++ {
+		m.RecordHit()
+		m.RecordGet(time.Millisecond)
+	}
+	m.RecordMiss()
+	m.RecordGet(time.Millisecond)
+	m.RecordSet(2 * time.Millisecond)
+	m.RecordSet(4 * time.Millisecond)
+	m.RecordDelete(time.Millisecond)
+	m.RecordEviction()
+	m.SetKeyCount(5)
+	m.SetMemoryUsage(1024)
+
+	expected := `
+# HELP cache_delete_duration_seconds_avg Среднее время операции удаления
+# TYPE cache_delete_duration_seconds_avg gauge
+cache_delete_duration_seconds_avg 0.001
+# HELP cache_deletes_total Количество операций удаления
+# TYPE cache_deletes_total counter
+cache_deletes_total 1
+# HELP cache_evictions_total Количество вытесненных элементов
+# TYPE cache_evictions_total counter
+cache_evictions_total 1
+# HELP cache_get_duration_seconds_avg Среднее время операции чтения
+# TYPE cache_get_duration_seconds_avg gauge
+cache_get_duration_seconds_avg 0.001
+# HELP cache_hit_rate Доля попаданий от 0 до 1
+# TYPE cache_hit_rate gauge
+cache_hit_rate 0.75
+# HELP cache_hits_total Количество попаданий в кэш
+# TYPE cache_hits_total counter
+cache_hits_total 3
+# HELP cache_keys Текущее количество ключей
+# TYPE cache_keys gauge
+cache_keys 5
+# HELP cache_memory_bytes Оценка используемой памяти в байтах
+# TYPE cache_memory_bytes gauge
+cache_memory_bytes 1024
+# HELP cache_misses_total Количество промахов кэша
+# TYPE cache_misses_total counter
+cache_misses_total 1
+# HELP cache_set_duration_seconds_avg Среднее время операции записи
+# TYPE cache_set_duration_seconds_avg gauge
+cache_set_duration_seconds_avg 0.003
+# HELP cache_sets_total Количество операций записи
+# TYPE cache_sets_total counter
+cache_sets_total 2
+`
+
+	collector := NewCollector(m)
+
+	// Время работы меняется между сборами, поэтому сравниваем остальные метрики
+	names := []string{
+		"cache_hits_total", "cache_misses_total", "cache_sets_total", "cache_deletes_total",
+		"cache_evictions_total", "cache_keys", "cache_memory_bytes", "cache_hit_rate",
+		"cache_set_duration_seconds_avg", "cache_get_duration_seconds_avg", "cache_delete_duration_seconds_avg",
+	}
+	if err := testutil.CollectAndCompare(collector, strings.NewReader(expected), names...); err != nil {
+		t.Fatal(err)
+	}
+
+	if count := testutil.CollectAndCount(collector); count != 12 {
+		t.Fatalf("Expected 12 metrics, got %d", count)
+	}
+
+	registry := prom.NewPedanticRegistry()
+	if err := registry.Register(collector); err != nil {
+		t.Fatalf("Register failed: %v", err)
+	}
+	if _, err := registry.Gather(); err != nil {
+		t.Fatalf("Gather failed: %v", err)
+	}
+}
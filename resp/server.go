@@ -0,0 +1,449 @@
+// Package resp предоставляет минимальный сервер протокола Redis (RESP2) поверх cache.Cache.
+// Поддерживаются команды GET, SET с EX/PX, DEL, EXISTS, TTL, FLUSHALL, DBSIZE, PING и QUIT,
+// поэтому существующие Redis-клиенты могут использовать кэш для простых операций.
+package resp
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	cache "github.com/VsRnA/High-Performance-HTTP-Cache"
+)
+
+const (
+	maxBulkLength = 512 << 20 // Предельная длина аргумента, как proto-max-bulk-len в Redis
+	maxArgs       = 1 << 20   // Предельное число аргументов команды
+	maxInlineLen  = 64 << 10  // Предельная длина inline-команды
+)
+
+// ErrServerClosed возвращается Serve после Close
+var ErrServerClosed = errors.New("resp: сервер закрыт")
+
+// errProtocol означает нарушение протокола, после которого соединение закрывается
+var errProtocol = errors.New("Protocol error")
+
+// Server обслуживает RESP-соединения, выполняя команды над кэшем.
+// Кэш не закрывается вместе с сервером.
+type Server struct {
+	cache cache.Cache
+
+	mu        sync.Mutex
+	listeners map[net.Listener]struct{}
+	conns     map[net.Conn]struct{}
+	closed    bool
+	wg        sync.WaitGroup
+}
+
+// NewServer создает сервер поверх c
+func NewServer(c cache.Cache) *Server {
+	return &Server{
+		cache:     c,
+		listeners: make(map[net.Listener]struct{}),
+		conns:     make(map[net.Conn]struct{}),
+	}
+}
+
+// ListenAndServe слушает TCP адрес addr и обслуживает соединения до Close
+func (s *Server) ListenAndServe(addr string) error {
+	l, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	return s.Serve(l)
+}
+
+// Serve принимает соединения на l и обслуживает каждое в отдельной горутине.
+// Возвращает ErrServerClosed после Close.
+func (s *Server) Serve(l net.Listener) error {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		l.Close()
+		return ErrServerClosed
+	}
+	s.listeners[l] = struct{}{}
+	s.mu.Unlock()
+
+	defer func() {
+		s.mu.Lock()
+		delete(s.listeners, l)
+		s.mu.Unlock()
+	}()
+
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			s.mu.Lock()
+			closed := s.closed
+			s.mu.Unlock()
+			if closed {
+				return ErrServerClosed
+			}
+			return err
+		}
+
+		s.mu.Lock()
+		if s.closed {
+			s.mu.Unlock()
+			conn.Close()
+			return ErrServerClosed
+		}
+		s.conns[conn] = struct{}{}
+		s.wg.Add(1)
+		s.mu.Unlock()
+
+		go s.serveConn(conn)
+	}
+}
+
+// Close закрывает все слушатели и соединения и дожидается завершения их обработки
+func (s *Server) Close() error {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return nil
+	}
+	s.closed = true
+	for l := range s.listeners {
+		l.Close()
+	}
+	for conn := range s.conns {
+		conn.Close()
+	}
+	s.mu.Unlock()
+
+	s.wg.Wait()
+	return nil
+}
+
+// serveConn читает и выполняет команды одного соединения.
+// Ответы буферизуются и отправляются, когда во входном буфере не осталось
+// команд, поэтому конвейер из нескольких команд уходит одной записью.
+func (s *Server) serveConn(conn net.Conn) {
+	defer func() {
+		conn.Close()
+		s.mu.Lock()
+		delete(s.conns, conn)
+		s.mu.Unlock()
+		s.wg.Done()
+	}()
+
+	r := bufio.NewReader(conn)
+	w := bufio.NewWriter(conn)
+
+	for {
+		args, err := readCommand(r)
+		if err != nil {
+			if errors.Is(err, errProtocol) {
+				writeError(w, "ERR "+err.Error())
+				w.Flush()
+			}
+			return
+		}
+		if len(args) == 0 {
+			continue
+		}
+
+		quit := s.execute(w, args)
+		if r.Buffered() == 0 || quit {
+			if err := w.Flush(); err != nil {
+				return
+			}
+		}
+		if quit {
+			return
+		}
+	}
+}
+
+// execute выполняет команду и записывает ответ. Возвращает true для QUIT.
+func (s *Server) execute(w *bufio.Writer, args []string) (quit bool) {
+	command := args[0]
+	name := strings.ToLower(command)
+	args = args[1:]
+
+	switch name {
+	case "ping":
+		switch len(args) {
+		case 0:
+			writeSimple(w, "PONG")
+		case 1:
+			writeBulk(w, []byte(args[0]))
+		default:
+			writeArity(w, name)
+		}
+
+	case "get":
+		if len(args) != 1 {
+			writeArity(w, name)
+			return false
+		}
+		value, exists := s.cache.Get(args[0])
+		if !exists {
+			writeNull(w)
+			return false
+		}
+		writeBulk(w, value)
+
+	case "set":
+		s.set(w, args)
+
+	case "del":
+		if len(args) == 0 {
+			writeArity(w, name)
+			return false
+		}
+		deleted := 0
+		for _, key := range args {
+			if s.cache.Delete(key) {
+				deleted++
+			}
+		}
+		writeInt(w, int64(deleted))
+
+	case "exists":
+		if len(args) == 0 {
+			writeArity(w, name)
+			return false
+		}
+		// GetTTL не влияет на статистику и порядок вытеснения
+		found := 0
+		for _, key := range args {
+			if _, exists := s.cache.GetTTL(key); exists {
+				found++
+			}
+		}
+		writeInt(w, int64(found))
+
+	case "ttl":
+		if len(args) != 1 {
+			writeArity(w, name)
+			return false
+		}
+		ttl, exists := s.cache.GetTTL(args[0])
+		switch {
+		case !exists:
+			writeInt(w, -2)
+		case ttl == cache.NoExpiration:
+			writeInt(w, -1)
+		default:
+			// Округление как в Redis: оставшиеся 1.5 секунды отдаются как 2
+			writeInt(w, int64((ttl+500*time.Millisecond)/time.Second))
+		}
+
+	case "flushall":
+		s.cache.Clear()
+		writeSimple(w, "OK")
+
+	case "dbsize":
+		if len(args) != 0 {
+			writeArity(w, name)
+			return false
+		}
+		writeInt(w, int64(s.cache.Len()))
+
+	case "command":
+		// redis-cli запрашивает описание команд при подключении
+		w.WriteString("*0\r\n")
+
+	case "quit":
+		writeSimple(w, "OK")
+		return true
+
+	default:
+		writeError(w, fmt.Sprintf("ERR unknown command '%s'", truncate(command)))
+	}
+	return false
+}
+
+// set выполняет SET key value [EX seconds | PX milliseconds]
+func (s *Server) set(w *bufio.Writer, args []string) {
+	if len(args) < 2 {
+		writeArity(w, "set")
+		return
+	}
+
+	var ttl time.Duration
+	options := args[2:]
+	for len(options) > 0 {
+		if len(options) < 2 || ttl != 0 {
+			writeError(w, "ERR syntax error")
+			return
+		}
+
+		var unit time.Duration
+		switch strings.ToLower(options[0]) {
+		case "ex":
+			unit = time.Second
+		case "px":
+			unit = time.Millisecond
+		default:
+			writeError(w, "ERR syntax error")
+			return
+		}
+
+		n, err := strconv.ParseInt(options[1], 10, 64)
+		if err != nil {
+			writeError(w, "ERR value is not an integer or out of range")
+			return
+		}
+		// Слишком большое значение переполнило бы time.Duration и дало бы отрицательный TTL
+		if n <= 0 || n > math.MaxInt64/int64(unit) {
+			writeError(w, "ERR invalid expire time in 'set' command")
+			return
+		}
+		ttl = time.Duration(n) * unit
+		options = options[2:]
+	}
+
+	var err error
+	if ttl > 0 {
+		err = s.cache.SetWithTTL(args[0], []byte(args[1]), ttl)
+	} else {
+		err = s.cache.Set(args[0], []byte(args[1]))
+	}
+	if err != nil {
+		writeError(w, "ERR "+err.Error())
+		return
+	}
+	writeSimple(w, "OK")
+}
+
+// readCommand читает одну команду в формате multibulk (*N\r\n$len\r\n...) или inline.
+// Пустая inline-строка возвращает пустую команду.
+func readCommand(r *bufio.Reader) ([]string, error) {
+	prefix, err := r.Peek(1)
+	if err != nil {
+		return nil, err
+	}
+	if prefix[0] != '*' {
+		line, err := readLine(r, maxInlineLen)
+		if err != nil {
+			return nil, err
+		}
+		return strings.Fields(line), nil
+	}
+
+	line, err := readLine(r, maxInlineLen)
+	if err != nil {
+		return nil, err
+	}
+	count, err := strconv.Atoi(line[1:])
+	if err != nil || count > maxArgs {
+		return nil, fmt.Errorf("%w: invalid multibulk length", errProtocol)
+	}
+
+	// Емкость ограничена: заявленное число аргументов еще не подтверждено данными
+	args := make([]string, 0, min(max(count, 0), 64))
+	for i := 0; i < count; i++ {
+		line, err := readLine(r, maxInlineLen)
+		if err != nil {
+			return nil, err
+		}
+		if len(line) == 0 || line[0] != '$' {
+			return nil, fmt.Errorf("%w: expected '$', got '%s'", errProtocol, truncate(line))
+		}
+
+		length, err := strconv.Atoi(line[1:])
+		if err != nil || length < 0 || length > maxBulkLength {
+			return nil, fmt.Errorf("%w: invalid bulk length", errProtocol)
+		}
+
+		arg, err := readBulk(r, length)
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, arg)
+	}
+	return args, nil
+}
+
+// readBulk читает аргумент длины length и завершающий \r\n.
+// Память выделяется по мере чтения, а не по заявленной длине, чтобы клиент
+// одним заголовком не заставлял сервер выделять до maxBulkLength байт.
+func readBulk(r *bufio.Reader, length int) (string, error) {
+	data, err := io.ReadAll(io.LimitReader(r, int64(length)))
+	if err != nil {
+		return "", err
+	}
+	if len(data) != length {
+		return "", io.ErrUnexpectedEOF
+	}
+
+	var crlf [2]byte
+	if _, err := io.ReadFull(r, crlf[:]); err != nil {
+		return "", err
+	}
+	if crlf[0] != '\r' || crlf[1] != '\n' {
+		return "", fmt.Errorf("%w: bulk not terminated by CRLF", errProtocol)
+	}
+	return string(data), nil
+}
+
+// readLine читает строку до \n без завершающих \r\n
+func readLine(r *bufio.Reader, limit int) (string, error) {
+	var line []byte
+	for {
+		chunk, isPrefix, err := r.ReadLine()
+		if err != nil {
+			return "", err
+		}
+		line = append(line, chunk...)
+		if len(line) > limit {
+			return "", fmt.Errorf("%w: too big inline request", errProtocol)
+		}
+		if !isPrefix {
+			return string(line), nil
+		}
+	}
+}
+
+// truncate обрезает имя команды или строку для сообщения об ошибке
+func truncate(s string) string {
+	if len(s) > 128 {
+		return s[:128]
+	}
+	return s
+}
+
+// writeSimple записывает простую строку +OK
+func writeSimple(w *bufio.Writer, s string) {
+	w.WriteString("+" + s + "\r\n")
+}
+
+// writeError записывает ошибку -ERR ...
+func writeError(w *bufio.Writer, s string) {
+	// Перевод строки внутри ошибки сломал бы разбор ответа клиентом
+	s = strings.NewReplacer("\r", " ", "\n", " ").Replace(s)
+	w.WriteString("-" + s + "\r\n")
+}
+
+// writeArity записывает ошибку неверного числа аргументов
+func writeArity(w *bufio.Writer, name string) {
+	writeError(w, fmt.Sprintf("ERR wrong number of arguments for '%s' command", name))
+}
+
+// writeInt записывает целое число :n
+func writeInt(w *bufio.Writer, n int64) {
+	w.WriteString(":" + strconv.FormatInt(n, 10) + "\r\n")
+}
+
+// writeBulk записывает bulk-строку $len\r\ndata\r\n
+func writeBulk(w *bufio.Writer, b []byte) {
+	w.WriteString("$" + strconv.Itoa(len(b)) + "\r\n")
+	w.Write(b)
+	w.WriteString("\r\n")
+}
+
+// writeNull записывает null bulk-строку для отсутствующего ключа
+func writeNull(w *bufio.Writer) {
+	w.WriteString("$-1\r\n")
+}
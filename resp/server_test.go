@@ -0,0 +1,172 @@
+package resp_test
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"net"
+	"runtime"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/VsRnA/High-Performance-HTTP-Cache/memory"
+	"github.com/VsRnA/High-Performance-HTTP-Cache/resp"
+)
+
+// startServer запускает сервер на случайном порту и возвращает соединение с ним
+func startServer(t *testing.T) (net.Conn, *bufio.Reader) {
+	t.Helper()
+
+	c := memory.NewLRU(100)
+	server := resp.NewServer(c)
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen failed: %v", err)
+	}
+	done := make(chan error, 1)
+	go func() { done <- server.Serve(l) }()
+
+	conn, err := net.Dial("tcp", l.Addr().String())
+	if err != nil {
+		t.Fatalf("Dial failed: %v", err)
+	}
+	conn.SetDeadline(time.Now().Add(5 * time.Second))
+
+	t.Cleanup(func() {
+		conn.Close()
+		server.Close()
+		if err := <-done; !errors.Is(err, resp.ErrServerClosed) {
+			t.Errorf("Expected ErrServerClosed, got %v", err)
+		}
+		c.Close()
+	})
+	return conn, bufio.NewReader(conn)
+}
+
+// expectReply отправляет request и сравнивает ответ байт в байт
+func expectReply(t *testing.T, conn net.Conn, r *bufio.Reader, request, reply string) {
+	t.Helper()
+
+	if _, err := io.WriteString(conn, request); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	buf := make([]byte, len(reply))
+	if _, err := io.ReadFull(r, buf); err != nil {
+		t.Fatalf("Reading reply to %q failed: %v (got %q)", request, err, buf)
+	}
+	if string(buf) != reply {
+		t.Errorf("Request %q: expected %q, got %q", request, reply, buf)
+	}
+}
+
+// multibulk кодирует команду как массив bulk-строк
+func multibulk(args ...string) string {
+	var b strings.Builder
+	b.WriteString("*" + strconv.Itoa(len(args)) + "\r\n")
+	for _, arg := range args {
+		b.WriteString("$" + strconv.Itoa(len(arg)) + "\r\n" + arg + "\r\n")
+	}
+	return b.String()
+}
+
+// TestServerCommands проверяет ответы поддерживаемых команд на уровне протокола
+func TestServerCommands(t *testing.T) {
+	conn, r := startServer(t)
+
+	expectReply(t, conn, r, multibulk("PING"), "+PONG\r\n")
+	expectReply(t, conn, r, multibulk("GET", "missing"), "$-1\r\n")
+	expectReply(t, conn, r, multibulk("SET", "key", "hello world"), "+OK\r\n")
+	expectReply(t, conn, r, multibulk("GET", "key"), "$11\r\nhello world\r\n")
+	expectReply(t, conn, r, multibulk("TTL", "key"), ":-1\r\n")
+	expectReply(t, conn, r, multibulk("TTL", "missing"), ":-2\r\n")
+
+	expectReply(t, conn, r, multibulk("SET", "session", "v", "EX", "100"), "+OK\r\n")
+	expectReply(t, conn, r, multibulk("TTL", "session"), ":100\r\n")
+	expectReply(t, conn, r, multibulk("set", "short", "v", "px", "2500"), "+OK\r\n")
+	expectReply(t, conn, r, multibulk("TTL", "short"), ":2\r\n")
+
+	expectReply(t, conn, r, multibulk("EXISTS", "key", "session", "missing"), ":2\r\n")
+	expectReply(t, conn, r, multibulk("DBSIZE"), ":3\r\n")
+	expectReply(t, conn, r, multibulk("DEL", "key", "missing"), ":1\r\n")
+	expectReply(t, conn, r, multibulk("DBSIZE"), ":2\r\n")
+	expectReply(t, conn, r, multibulk("FLUSHALL"), "+OK\r\n")
+	expectReply(t, conn, r, multibulk("DBSIZE"), ":0\r\n")
+
+	// Значения передаются как есть, включая CRLF и нулевые байты
+	binary := "a\r\nb\x00c"
+	expectReply(t, conn, r, multibulk("SET", "bin", binary), "+OK\r\n")
+	expectReply(t, conn, r, multibulk("GET", "bin"), "$6\r\n"+binary+"\r\n")
+}
+
+// TestServerInline проверяет inline-команды и конвейер из нескольких команд
+func TestServerInline(t *testing.T) {
+	conn, r := startServer(t)
+
+	expectReply(t, conn, r, "PING\r\n", "+PONG\r\n")
+	expectReply(t, conn, r, "SET greeting hi\r\n", "+OK\r\n")
+	expectReply(t, conn, r, "GET greeting\n", "$2\r\nhi\r\n")
+
+	// Пустая строка игнорируется, команды конвейера выполняются по порядку
+	expectReply(t, conn, r,
+		"\r\n"+multibulk("SET", "a", "1")+"GET a\r\n"+multibulk("DEL", "a"),
+		"+OK\r\n$1\r\n1\r\n:1\r\n")
+}
+
+// TestServerErrors проверяет ошибки для неизвестных команд и неверных аргументов
+func TestServerErrors(t *testing.T) {
+	conn, r := startServer(t)
+
+	expectReply(t, conn, r, multibulk("HGET", "h", "f"), "-ERR unknown command 'HGET'\r\n")
+	expectReply(t, conn, r, multibulk("GET"), "-ERR wrong number of arguments for 'get' command\r\n")
+	expectReply(t, conn, r, multibulk("SET", "k", "v", "EX"), "-ERR syntax error\r\n")
+	expectReply(t, conn, r, multibulk("SET", "k", "v", "KEEPTTL", "1"), "-ERR syntax error\r\n")
+	expectReply(t, conn, r, multibulk("SET", "k", "v", "EX", "soon"), "-ERR value is not an integer or out of range\r\n")
+	expectReply(t, conn, r, multibulk("SET", "k", "v", "PX", "0"), "-ERR invalid expire time in 'set' command\r\n")
+	expectReply(t, conn, r, multibulk("SET", "k", "v", "EX", "9999999999999"), "-ERR invalid expire time in 'set' command\r\n")
+	expectReply(t, conn, r, multibulk("EXISTS", "k"), ":0\r\n")
+
+	// Соединение остается рабочим после ошибок команд
+	expectReply(t, conn, r, multibulk("PING", "still here"), "$10\r\nstill here\r\n")
+
+	// Нарушение протокола закрывает соединение
+	expectReply(t, conn, r, "*1\r\n+GET\r\n", "-ERR Protocol error: expected '$', got '+GET'\r\n")
+	if _, err := r.ReadByte(); err != io.EOF {
+		t.Errorf("Expected connection to be closed, got %v", err)
+	}
+}
+
+// TestServerBulkLength проверяет, что заявленная длина аргумента не выделяется заранее,
+// а большие значения читаются целиком
+func TestServerBulkLength(t *testing.T) {
+	conn, r := startServer(t)
+
+	value := strings.Repeat("v", 1<<20)
+	expectReply(t, conn, r, multibulk("SET", "big", value), "+OK\r\n")
+	expectReply(t, conn, r, multibulk("GET", "big"), "$"+strconv.Itoa(len(value))+"\r\n"+value+"\r\n")
+
+	// Заголовок с длиной около 512 МиБ без данных не должен приводить к выделению этой памяти
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+	io.WriteString(conn, "*2\r\n$3\r\nGET\r\n$536870000\r\nshort")
+	conn.(*net.TCPConn).CloseWrite()
+	if _, err := r.ReadByte(); err != io.EOF {
+		t.Errorf("Expected connection to be closed on truncated bulk, got %v", err)
+	}
+	runtime.ReadMemStats(&after)
+	if allocated := after.TotalAlloc - before.TotalAlloc; allocated > 64<<20 {
+		t.Errorf("Expected no allocation of the declared bulk length, got %d bytes", allocated)
+	}
+}
+
+// TestServerQuit проверяет, что QUIT отвечает и закрывает соединение
+func TestServerQuit(t *testing.T) {
+	conn, r := startServer(t)
+
+	expectReply(t, conn, r, multibulk("QUIT"), "+OK\r\n")
+	if _, err := r.ReadByte(); err != io.EOF {
+		t.Errorf("Expected connection to be closed, got %v", err)
+	}
+}
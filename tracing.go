@@ -0,0 +1,236 @@
+package cache
+
+import (
+	"encoding/csv"
+	"io"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// traceBufferSize - сколько записей трассировки может ждать записи в io.Writer.
+// При переполнении записи отбрасываются, а не блокируют операции кэша.
+const traceBufferSize = 4096
+
+// Результаты операций в трассировке
+const (
+	traceHit   = "hit"
+	traceMiss  = "miss"
+	traceOK    = "ok"
+	traceError = "error"
+)
+
+// traceRecord - одна строка трассировки
+type traceRecord struct {
+	at     time.Time
+	op     string
+	key    string
+	result string
+}
+
+// Tracing оборачивает кэш и пишет каждую операцию с ключом строкой CSV
+// "timestamp,op,key,result" для последующего анализа и воспроизведения на других политиках.
+// timestamp - время в формате RFC 3339 с наносекундами, result - hit или miss для Get
+// и ok или error для изменяющих операций. Первая строка - заголовок.
+// Записи передаются фоновой горутине через буфер на traceBufferSize записей:
+// медленный io.Writer не замедляет кэш, а при переполнении буфера записи
+// отбрасываются и учитываются в Dropped.
+type Tracing struct {
+	cache Cache
+
+	records chan traceRecord
+	flushes chan chan error
+	stop    chan struct{}
+	done    chan struct{}
+
+	closeOnce sync.Once
+	dropped   atomic.Int64
+	err       error // Ошибка записи, изменяется только фоновой горутиной до закрытия done
+}
+
+// NewTracing создает трассирующую обертку над backing, пишущую в w.
+// Close закрывает backing, дописывает буфер и останавливает фоновую горутину, но не закрывает w.
+func NewTracing(backing Cache, w io.Writer) *Tracing {
+	t := &Tracing{
+		cache:   backing,
+		records: make(chan traceRecord, traceBufferSize),
+		flushes: make(chan chan error),
+		stop:    make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+	go t.run(csv.NewWriter(w))
+	return t
+}
+
+// Cache возвращает нижележащий кэш
+func (t *Tracing) Cache() Cache {
+	return t.cache
+}
+
+// Dropped возвращает количество записей, отброшенных из-за переполнения буфера
+func (t *Tracing) Dropped() int64 {
+	return t.dropped.Load()
+}
+
+// Flush дожидается записи всех операций, выполненных до вызова, и сбрасывает их в w.
+// Возвращает первую ошибку записи. После Close возвращает ошибку, с которой завершилась запись.
+func (t *Tracing) Flush() error {
+	reply := make(chan error, 1)
+	select {
+	case t.flushes <- reply:
+		return <-reply
+	case <-t.done:
+		return t.err
+	}
+}
+
+// trace ставит запись в очередь, не блокируясь
+func (t *Tracing) trace(op, key, result string) {
+	select {
+	case t.records <- traceRecord{at: time.Now(), op: op, key: key, result: result}:
+	default:
+		t.dropped.Add(1)
+	}
+}
+
+// run записывает трассировку до остановки. csv.Writer буферизует вывод,
+// поэтому данные уходят в w при Flush, Close или заполнении его буфера.
+func (t *Tracing) run(w *csv.Writer) {
+	defer close(t.done)
+
+	w.Write([]string{"timestamp", "op", "key", "result"})
+	write := func(r traceRecord) {
+		w.Write([]string{r.at.UTC().Format(time.RFC3339Nano), r.op, r.key, r.result})
+	}
+	// drain записывает все записи, уже стоящие в очереди
+	drain := func() {
+		for n := len(t.records); n > 0; n-- {
+			write(<-t.records)
+		}
+	}
+	flush := func() error {
+		w.Flush()
+		if err := w.Error(); err != nil && t.err == nil {
+			t.err = err
+		}
+		return t.err
+	}
+
+	for {
+		select {
+		case r := <-t.records:
+			write(r)
+		case reply := <-t.flushes:
+			drain()
+			reply <- flush()
+		case <-t.stop:
+			drain()
+			flush()
+			return
+		}
+	}
+}
+
+// traceResult возвращает ok или error для изменяющей операции
+func traceResult(err error) string {
+	if err != nil {
+		return traceError
+	}
+	return traceOK
+}
+
+// traceFound возвращает hit или miss
+func traceFound(ok bool) string {
+	if ok {
+		return traceHit
+	}
+	return traceMiss
+}
+
+// Get получает значение и записывает попадание или промах
+func (t *Tracing) Get(key string) ([]byte, bool) {
+	value, ok := t.cache.Get(key)
+	t.trace("get", key, traceFound(ok))
+	return value, ok
+}
+
+// Set сохраняет значение с TTL по умолчанию
+func (t *Tracing) Set(key string, value []byte) error {
+	err := t.cache.Set(key, value)
+	t.trace("set", key, traceResult(err))
+	return err
+}
+
+// SetWithTTL сохраняет значение с указанным TTL и записывает операцию как set
+func (t *Tracing) SetWithTTL(key string, value []byte, ttl time.Duration) error {
+	err := t.cache.SetWithTTL(key, value, ttl)
+	t.trace("set", key, traceResult(err))
+	return err
+}
+
+// GetOrSet возвращает значение или загружает его. Нижележащий кэш не сообщает,
+// был ли вызван loader, поэтому результат - ok или error.
+func (t *Tracing) GetOrSet(key string, loader func() ([]byte, error), ttl time.Duration) ([]byte, error) {
+	value, err := t.cache.GetOrSet(key, loader, ttl)
+	t.trace("get_or_set", key, traceResult(err))
+	return value, err
+}
+
+// GetTTL возвращает оставшееся время жизни ключа без записи в трассировку:
+// вызов не считается обращением к ключу
+func (t *Tracing) GetTTL(key string) (time.Duration, bool) {
+	return t.cache.GetTTL(key)
+}
+
+// Expire устанавливает новое время жизни ключа и записывает hit или miss
+func (t *Tracing) Expire(key string, ttl time.Duration) bool {
+	ok := t.cache.Expire(key, ttl)
+	t.trace("expire", key, traceFound(ok))
+	return ok
+}
+
+// Persist делает ключ бессрочным и записывает hit или miss
+func (t *Tracing) Persist(key string) bool {
+	ok := t.cache.Persist(key)
+	t.trace("persist", key, traceFound(ok))
+	return ok
+}
+
+// Delete удаляет ключ и записывает hit или miss
+func (t *Tracing) Delete(key string) bool {
+	ok := t.cache.Delete(key)
+	t.trace("delete", key, traceFound(ok))
+	return ok
+}
+
+// Keys возвращает ключи нижележащего кэша
+func (t *Tracing) Keys() []string {
+	return t.cache.Keys()
+}
+
+// Len возвращает количество элементов нижележащего кэша
+func (t *Tracing) Len() int {
+	return t.cache.Len()
+}
+
+// Clear очищает нижележащий кэш и записывает операцию с пустым ключом
+func (t *Tracing) Clear() {
+	t.cache.Clear()
+	t.trace("clear", "", traceOK)
+}
+
+// Stats возвращает статистику нижележащего кэша
+func (t *Tracing) Stats() Stats {
+	return t.cache.Stats()
+}
+
+// Close закрывает нижележащий кэш, дописывает буфер трассировки и останавливает
+// фоновую горутину. Возвращает ошибку закрытия кэша, ошибку записи возвращает Flush.
+func (t *Tracing) Close() error {
+	err := t.cache.Close()
+	t.closeOnce.Do(func() {
+		close(t.stop)
+		<-t.done
+	})
+	return err
+}
@@ -0,0 +1,69 @@
+package cache_test
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"testing"
+	"time"
+
+	cache "github.com/VsRnA/High-Performance-HTTP-Cache"
+	"github.com/VsRnA/High-Performance-HTTP-Cache/memory"
+)
+
+// TestTracing проверяет формат трассировки и запись после Flush и Close
+func TestTracing(t *testing.T) {
+	var out bytes.Buffer
+	c := cache.NewTracing(memory.NewLRU(10), &out)
+
+	c.Set("a", []byte("1"))
+	c.Get("a")
+	c.Get("b")
+	c.Delete("a")
+	if _, err := c.GetOrSet("c", func() ([]byte, error) { return nil, errors.New("origin down") }, 0); err == nil {
+		t.Fatal("Expected loader error")
+	}
+
+	if err := c.Flush(); err != nil {
+		t.Fatalf("Flush failed: %v", err)
+	}
+
+	rows, err := csv.NewReader(bytes.NewReader(out.Bytes())).ReadAll()
+	if err != nil {
+		t.Fatalf("Trace is not valid CSV: %v", err)
+	}
+	expected := [][]string{
+		{"op", "key", "result"},
+		{"set", "a", "ok"},
+		{"get", "a", "hit"},
+		{"get", "b", "miss"},
+		{"delete", "a", "hit"},
+		{"get_or_set", "c", "error"},
+	}
+	if len(rows) != len(expected) {
+		t.Fatalf("Expected %d rows, got %d: %v", len(expected), len(rows), rows)
+	}
+	for i, want := range expected {
+		if got := rows[i][1:]; got[0] != want[0] || got[1] != want[1] || got[2] != want[2] {
+			t.Errorf("Row %d: expected %v, got %v", i, want, got)
+		}
+		if i > 0 {
+			if _, err := time.Parse(time.RFC3339Nano, rows[i][0]); err != nil {
+				t.Errorf("Row %d: invalid timestamp %q", i, rows[i][0])
+			}
+		}
+	}
+
+	// Close дописывает операции, не сброшенные через Flush
+	c.Get("a")
+	c.Close()
+	if !bytes.HasSuffix(out.Bytes(), []byte(",get,a,miss\n")) {
+		t.Errorf("Expected Close to flush the last record, got %q", out.String())
+	}
+	if c.Dropped() != 0 {
+		t.Errorf("Expected no dropped records, got %d", c.Dropped())
+	}
+	if err := c.Flush(); err != nil {
+		t.Errorf("Flush after Close failed: %v", err)
+	}
+}
@@ -0,0 +1,121 @@
+package cache
+
+import (
+	"bytes"
+	"encoding/gob"
+	"encoding/json"
+	"time"
+)
+
+// Codec преобразует значения Go в байты для хранения в кэше и обратно
+type Codec interface {
+	Marshal(v any) ([]byte, error)
+	Unmarshal(b []byte, v any) error
+}
+
+// JSONCodec кодирует значения через encoding/json
+type JSONCodec struct{}
+
+// Marshal кодирует v в JSON
+func (JSONCodec) Marshal(v any) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+// Unmarshal декодирует JSON в v
+func (JSONCodec) Unmarshal(b []byte, v any) error {
+	return json.Unmarshal(b, v)
+}
+
+// GobCodec кодирует значения через encoding/gob.
+// Компактнее JSON для структур, но каждое значение несет описание типа.
+type GobCodec struct{}
+
+// Marshal кодирует v в gob
+func (GobCodec) Marshal(v any) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(v); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Unmarshal декодирует gob в v
+func (GobCodec) Unmarshal(b []byte, v any) error {
+	return gob.NewDecoder(bytes.NewReader(b)).Decode(v)
+}
+
+// Typed оборачивает байтовый кэш и хранит в нем значения типа T через Codec.
+// Нижележащий кэш не меняется и может одновременно использоваться напрямую.
+type Typed[T any] struct {
+	cache Cache
+	codec Codec
+}
+
+// NewTyped создает типизированную обертку над c
+func NewTyped[T any](c Cache, codec Codec) *Typed[T] {
+	return &Typed[T]{cache: c, codec: codec}
+}
+
+// Get получает и декодирует значение по ключу.
+// Ошибка возвращается, если сохраненные байты не удалось декодировать в T.
+func (t *Typed[T]) Get(key string) (T, bool, error) {
+	var value T
+
+	data, exists := t.cache.Get(key)
+	if !exists {
+		return value, false, nil
+	}
+
+	if err := t.codec.Unmarshal(data, &value); err != nil {
+		return value, true, err
+	}
+	return value, true, nil
+}
+
+// Set кодирует и сохраняет значение с TTL по умолчанию
+func (t *Typed[T]) Set(key string, v T) error {
+	data, err := t.codec.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return t.cache.Set(key, data)
+}
+
+// SetWithTTL кодирует и сохраняет значение с указанным TTL
+func (t *Typed[T]) SetWithTTL(key string, v T, ttl time.Duration) error {
+	data, err := t.codec.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return t.cache.SetWithTTL(key, data, ttl)
+}
+
+// GetOrSet возвращает значение по ключу или загружает его через loader при промахе.
+// Одновременные загрузки ключа дедуплицируются нижележащим кэшем.
+func (t *Typed[T]) GetOrSet(key string, loader func() (T, error), ttl time.Duration) (T, error) {
+	var value T
+
+	data, err := t.cache.GetOrSet(key, func() ([]byte, error) {
+		loaded, err := loader()
+		if err != nil {
+			return nil, err
+		}
+		return t.codec.Marshal(loaded)
+	}, ttl)
+	if err != nil {
+		return value, err
+	}
+
+	err = t.codec.Unmarshal(data, &value)
+	return value, err
+}
+
+// Delete удаляет ключ из кэша
+func (t *Typed[T]) Delete(key string) bool {
+	return t.cache.Delete(key)
+}
+
+// Cache возвращает нижележащий байтовый кэш
+func (t *Typed[T]) Cache() Cache {
+	return t.cache
+}
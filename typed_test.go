@@ -0,0 +1,76 @@
+package cache_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	cache "github.com/VsRnA/High-Performance-HTTP-Cache"
+	"github.com/VsRnA/High-Performance-HTTP-Cache/memory"
+)
+
+type user struct {
+	ID    int
+	Name  string
+	Roles []string
+}
+
+// TestTyped проверяет сохранение структур через встроенные кодеки
+func TestTyped(t *testing.T) {
+	codecs := map[string]cache.Codec{
+		"JSON": cache.JSONCodec{},
+		"Gob":  cache.GobCodec{},
+	}
+
+	for name, codec := range codecs {
+		t.Run(name, func(t *testing.T) {
+			c := memory.NewLRU(100)
+			defer c.Close()
+
+			users := cache.NewTyped[user](c, codec)
+			expected := user{ID: 1, Name: "Alice", Roles: []string{"admin"}}
+
+			if err := users.Set("user:1", expected); err != nil {
+				t.Fatalf("Set failed: %v", err)
+			}
+
+			got, exists, err := users.Get("user:1")
+			if err != nil || !exists {
+				t.Fatalf("Get failed: exists=%v err=%v", exists, err)
+			}
+			if got.ID != expected.ID || got.Name != expected.Name || len(got.Roles) != 1 {
+				t.Fatalf("Expected %+v, got %+v", expected, got)
+			}
+
+			if _, exists, err := users.Get("user:2"); exists || err != nil {
+				t.Fatalf("Missing key should return exists=false, err=nil, got %v %v", exists, err)
+			}
+
+			// Байты, не декодируемые в T, дают ошибку
+			c.Set("broken", []byte("not encoded"))
+			if _, exists, err := users.Get("broken"); !exists || err == nil {
+				t.Fatal("Expected decode error for foreign bytes")
+			}
+
+			calls := 0
+			loader := func() (user, error) {
+				calls++
+				return user{ID: 3, Name: "Bob"}, nil
+			}
+			for i := 0; i < 2; i++ {
+				loaded, err := users.GetOrSet("user:3", loader, time.Minute)
+				if err != nil || loaded.Name != "Bob" {
+					t.Fatalf("GetOrSet failed: %+v %v", loaded, err)
+				}
+			}
+			if calls != 1 {
+				t.Fatalf("Loader should run once, ran %d times", calls)
+			}
+
+			loadErr := errors.New("load failed")
+			if _, err := users.GetOrSet("user:4", func() (user, error) { return user{}, loadErr }, 0); err != loadErr {
+				t.Fatalf("Expected loader error, got %v", err)
+			}
+		})
+	}
+}
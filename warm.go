@@ -0,0 +1,80 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// WarmLoader загружает значение ключа из основного хранилища вместе с его TTL
+type WarmLoader func(key string) ([]byte, time.Duration, error)
+
+// Warm заполняет кэш значениями ключей, загружая их в concurrency параллельных горутинах.
+// Ключи, уже присутствующие в кэше, пропускаются. Ошибка загрузки или записи одного ключа
+// не останавливает остальные: все ошибки объединяются через errors.Join.
+// При отмене ctx новые загрузки не начинаются, уже начатые завершаются,
+// и в результат добавляется ctx.Err(). concurrency <= 0 означает одну горутину.
+func Warm(ctx context.Context, c Cache, keys []string, loader WarmLoader, concurrency int) error {
+	if concurrency <= 0 {
+		concurrency = 1
+	}
+
+	var (
+		mu   sync.Mutex
+		errs []error
+		wg   sync.WaitGroup
+	)
+	addErr := func(err error) {
+		mu.Lock()
+		errs = append(errs, err)
+		mu.Unlock()
+	}
+
+	work := make(chan string)
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for key := range work {
+				if _, err := loadKey(c, key, loader); err != nil {
+					addErr(err)
+				}
+			}
+		}()
+	}
+
+dispatch:
+	for _, key := range keys {
+		// GetTTL не меняет статистику попаданий, в отличие от Get
+		if _, exists := c.GetTTL(key); exists {
+			continue
+		}
+
+		select {
+		case work <- key:
+		case <-ctx.Done():
+			break dispatch
+		}
+	}
+	close(work)
+	wg.Wait()
+
+	if err := ctx.Err(); err != nil {
+		errs = append(errs, err)
+	}
+	return errors.Join(errs...)
+}
+
+// loadKey загружает один ключ, сохраняет его в кэш и возвращает загруженное значение
+func loadKey(c Cache, key string, loader WarmLoader) ([]byte, error) {
+	value, ttl, err := loader(key)
+	if err != nil {
+		return nil, fmt.Errorf("загрузка ключа %q: %w", key, err)
+	}
+	if err := c.SetWithTTL(key, value, ttl); err != nil {
+		return nil, fmt.Errorf("запись ключа %q: %w", key, err)
+	}
+	return value, nil
+}
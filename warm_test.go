@@ -0,0 +1,100 @@
+package cache_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	cache "github.com/VsRnA/High-Performance-HTTP-Cache"
+	"github.com/VsRnA/High-Performance-HTTP-Cache/memory"
+)
+
+// TestWarm проверяет параллельную загрузку, пропуск существующих ключей и сбор ошибок
+func TestWarm(t *testing.T) {
+	c := memory.NewLRU(1000)
+	defer c.Close()
+	c.Set("key0", []byte("existing"))
+
+	keys := make([]string, 100)
+	for i := range keys {
+		keys[i] = fmt.Sprintf("key%d", i)
+	}
+
+	var active, maxActive, calls atomic.Int64
+	errBroken := errors.New("broken")
+	loader := func(key string) ([]byte, time.Duration, error) {
+		calls.Add(1)
+		n := active.Add(1)
+		defer active.Add(-1)
+		for {
+			current := maxActive.Load()
+			if n <= current || maxActive.CompareAndSwap(current, n) {
+				break
+			}
+		}
+		time.Sleep(time.Millisecond)
+
+		if key == "key13" {
+			return nil, 0, errBroken
+		}
+		return []byte("loaded:" + key), time.Hour, nil
+	}
+
+	err := cache.Warm(context.Background(), c, keys, loader, 8)
+	if !errors.Is(err, errBroken) {
+		t.Fatalf("Expected loader error to be returned, got %v", err)
+	}
+
+	if calls.Load() != 99 {
+		t.Errorf("Expected existing key to be skipped, got %d loads", calls.Load())
+	}
+	if maxActive.Load() > 8 || maxActive.Load() < 2 {
+		t.Errorf("Expected up to 8 concurrent loads, got %d", maxActive.Load())
+	}
+	if value, _ := c.Get("key0"); string(value) != "existing" {
+		t.Errorf("Existing key was overwritten: %s", value)
+	}
+	if value, _ := c.Get("key50"); string(value) != "loaded:key50" {
+		t.Errorf("Expected loaded value, got %s", value)
+	}
+	if ttl, _ := c.GetTTL("key50"); ttl <= 59*time.Minute {
+		t.Errorf("Expected loader TTL to apply, got %v", ttl)
+	}
+	if _, exists := c.Get("key13"); exists {
+		t.Error("Failed key should not be stored")
+	}
+	if c.Len() != 99 {
+		t.Errorf("Expected 99 keys, got %d", c.Len())
+	}
+}
+
+// TestWarmCancel проверяет, что отмена контекста останавливает запуск новых загрузок
+func TestWarmCancel(t *testing.T) {
+	c := memory.NewLRU(1000)
+	defer c.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var calls atomic.Int64
+	loader := func(key string) ([]byte, time.Duration, error) {
+		if calls.Add(1) == 5 {
+			cancel()
+		}
+		return []byte("v"), 0, nil
+	}
+
+	keys := make([]string, 1000)
+	for i := range keys {
+		keys[i] = fmt.Sprint(i)
+	}
+
+	err := cache.Warm(ctx, c, keys, loader, 2)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("Expected context.Canceled, got %v", err)
+	}
+	if n := calls.Load(); n >= 1000 {
+		t.Errorf("Expected loading to stop after cancel, got %d loads", n)
+	}
+}